@@ -13,6 +13,7 @@ import (
 	"github.com/samber/lo"
 	"github.com/urfave/cli/v3"
 
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/logging"
 )
 
@@ -138,6 +139,8 @@ const (
 	dataFlagPipelineName                   = "pipeline-name"
 	dataFlagIndexName                      = "index-name"
 	dataFlagIndexSpecFile                  = "index-path"
+	dataFlagCaptureDir                     = "capture-dir"
+	dataFlagFormat                         = "format"
 
 	datapipelineFlagSchedule       = "schedule"
 	datapipelineFlagMQL            = "mql"
@@ -308,6 +311,38 @@ var dataTagByFilterFlags = append([]cli.Flag{
 },
 	commonFilterFlags...)
 
+var dataLocalFilterFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:      dataFlagCaptureDir,
+		Usage:     "capture directory to read from. defaults to the data manager's default capture directory",
+		TakesFile: true,
+	},
+	&cli.StringFlag{
+		Name:  dataFlagComponentType,
+		Usage: "component type filter",
+	},
+	&cli.StringFlag{
+		Name:  dataFlagComponentName,
+		Usage: "component name filter",
+	},
+	&cli.StringFlag{
+		Name:  generalFlagMethod,
+		Usage: "method filter",
+	},
+	&cli.StringSliceFlag{
+		Name:  generalFlagTags,
+		Usage: "tags filter. only capture files with all of the tags are included",
+	},
+	&cli.StringFlag{
+		Name:  generalFlagStart,
+		Usage: "ISO-8601 timestamp in RFC3339 format indicating the start of the interval filter",
+	},
+	&cli.StringFlag{
+		Name:  generalFlagEnd,
+		Usage: "ISO-8601 timestamp in RFC3339 format indicating the end of the interval filter",
+	},
+}
+
 type emptyArgs struct{}
 
 type globalArgs struct {
@@ -1276,6 +1311,40 @@ Note: There is no progress meter while copying is in progress.
 			UsageText:       createUsageText("data", nil, false, true),
 			HideHelpCommand: true,
 			Commands: []*cli.Command{
+				{
+					Name:            "local",
+					Usage:           "inspect data captured on this machine that may not have synced yet",
+					UsageText:       createUsageText("data local", nil, false, true),
+					HideHelpCommand: true,
+					Commands: []*cli.Command{
+						{
+							Name:      "list",
+							Usage:     "list local capture files matching the filters",
+							UsageText: createUsageText("data local list", nil, true, false),
+							Flags:     dataLocalFilterFlags,
+							Action:    createActionCommandWithT[dataLocalListArgs](DataLocalListAction),
+						},
+						{
+							Name:      "export",
+							Usage:     "export tabular readings and binary files from local capture files matching the filters",
+							UsageText: createUsageText("data local export", []string{generalFlagDestination}, true, false),
+							Flags: append([]cli.Flag{
+								&cli.StringFlag{
+									Name:      generalFlagDestination,
+									Required:  true,
+									Usage:     "output directory for exported data",
+									TakesFile: true,
+								},
+								&cli.StringFlag{
+									Name:  dataFlagFormat,
+									Usage: "format for tabular readings. must be 'jsonl' or 'csv'",
+									Value: string(data.CaptureExportJSONL),
+								},
+							}, dataLocalFilterFlags...),
+							Action: createActionCommandWithT[dataLocalExportArgs](DataLocalExportAction),
+						},
+					},
+				},
 				{
 					Name:            "export",
 					Usage:           "download data from Viam cloud",
@@ -3073,6 +3142,64 @@ Note: There is no progress meter while copying is in progress.
 							}...),
 							Action: createActionCommandWithT[robotsPartTunnelArgs](RobotsPartTunnelAction),
 						},
+						{
+							Name:  "graph",
+							Usage: "print the resource dependency graph of a machine part as JSON",
+							Description: `
+Prints the state, last error, last reconfiguration time and unresolved dependencies of every
+resource of the machine part. With --resource, prints the resources keeping that resource from
+being ready and the resources waiting on it instead. The resource may be given by its full name
+or, if it is unambiguous, by its name alone.
+`,
+							UsageText: createUsageText("machines part graph", []string{generalFlagPart}, true, false),
+							Flags: append(commonPartFlags, []cli.Flag{
+								&cli.StringFlag{
+									Name:  resourceGraphFlagResource,
+									Usage: "resource to explain the failures of",
+								},
+							}...),
+							Action: createActionCommandWithT[machinesPartGraphArgs](MachinesPartGraphAction),
+						},
+						{
+							Name:  "stored-logs",
+							Usage: "query the logs a machine part stored on its own disk",
+							Description: `
+Reads the logs a machine part stored with --structured-log-file from the part itself rather than
+from the cloud, so that logs the part could not upload while it was offline can still be read.
+Prints logs at info level and above unless --level is given. --logger takes a logger name pattern
+such as "rdk.resource_manager.*".
+`,
+							UsageText: createUsageText("machines part stored-logs", []string{generalFlagPart}, true, false),
+							Flags: append(commonPartFlags, []cli.Flag{
+								&cli.StringFlag{
+									Name:        storedLogsFlagLevel,
+									Usage:       "lowest level of the logs to print (debug, info, warn or error)",
+									DefaultText: "info",
+								},
+								&cli.StringFlag{
+									Name:  storedLogsFlagLogger,
+									Usage: "print only logs of loggers matching this pattern",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagStart,
+									Usage: "ISO-8601 timestamp in RFC3339 format indicating the start of the interval filter (e.g., 2025-01-15T14:00:00Z)",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagEnd,
+									Usage: "ISO-8601 timestamp in RFC3339 format indicating the end of the interval filter (e.g., 2025-01-15T15:00:00Z)",
+								},
+								&cli.StringFlag{
+									Name:  logsFlagKeyword,
+									Usage: "print only logs containing this keyword, ignoring case",
+								},
+								&cli.IntFlag{
+									Name:        generalFlagCount,
+									Usage:       "number of most recent logs to print",
+									DefaultText: "all",
+								},
+							}...),
+							Action: createActionCommandWithT[machinesPartStoredLogsArgs](MachinesPartStoredLogsAction),
+						},
 						{
 							Name: "motion",
 							Commands: []*cli.Command{
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/services/datamanager/builtin/shared"
+)
+
+type dataLocalListArgs struct {
+	CaptureDir    string
+	ComponentType string
+	ComponentName string
+	Method        string
+	Tags          []string
+	Start         string
+	End           string
+}
+
+type dataLocalExportArgs struct {
+	CaptureDir    string
+	ComponentType string
+	ComponentName string
+	Method        string
+	Tags          []string
+	Start         string
+	End           string
+	Destination   string
+	Format        string
+}
+
+func captureDirOrDefault(captureDir string) string {
+	if captureDir == "" {
+		return shared.ViamCaptureDotDir
+	}
+	return captureDir
+}
+
+func localCaptureFilter(componentType, componentName, method string, tags []string, start, end string) (data.CaptureFileFilter, error) {
+	filter := data.CaptureFileFilter{
+		ComponentType: componentType,
+		ComponentName: componentName,
+		Method:        method,
+		Tags:          tags,
+	}
+
+	var err error
+	if start != "" {
+		if filter.Start, err = time.Parse(time.RFC3339, start); err != nil {
+			return filter, errors.Wrap(err, "could not parse start flag")
+		}
+	}
+	if end != "" {
+		if filter.End, err = time.Parse(time.RFC3339, end); err != nil {
+			return filter, errors.Wrap(err, "could not parse end flag")
+		}
+	}
+	return filter, nil
+}
+
+// DataLocalListAction is the corresponding action for 'data local list'.
+func DataLocalListAction(ctx context.Context, cmd *cli.Command, args dataLocalListArgs) error {
+	filter, err := localCaptureFilter(args.ComponentType, args.ComponentName, args.Method, args.Tags, args.Start, args.End)
+	if err != nil {
+		return err
+	}
+
+	files, err := data.ListCaptureFiles(captureDirOrDefault(args.CaptureDir), filter)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		readings, err := data.ReadCaptureFileReadings(file.Path, filter)
+		if err != nil {
+			warningf(cmd.ErrWriter, "failed to read %s: %v", file.Path, err)
+			continue
+		}
+		if len(readings) == 0 {
+			continue
+		}
+
+		state := "complete"
+		if file.InProgress {
+			state = "in progress"
+		}
+		md := file.Metadata
+		printf(cmd.Root().Writer, "%s\n\tresource: %s (%s)\n\tmethod: %s\n\ttags: %v\n\treadings: %d\n\tsize: %s\n\tstate: %s",
+			file.Path, md.GetComponentName(), md.GetComponentType(), md.GetMethodName(), md.GetTags(),
+			len(readings), data.FormatBytesI64(file.Size), state)
+		printf(cmd.Root().Writer, "\tfirst: %s\n\tlast: %s",
+			readings[0].GetMetadata().GetTimeRequested().AsTime().Format(time.RFC3339),
+			readings[len(readings)-1].GetMetadata().GetTimeRequested().AsTime().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// DataLocalExportAction is the corresponding action for 'data local export'.
+func DataLocalExportAction(ctx context.Context, cmd *cli.Command, args dataLocalExportArgs) error {
+	filter, err := localCaptureFilter(args.ComponentType, args.ComponentName, args.Method, args.Tags, args.Start, args.End)
+	if err != nil {
+		return err
+	}
+
+	files, err := data.ListCaptureFiles(captureDirOrDefault(args.CaptureDir), filter)
+	if err != nil {
+		return err
+	}
+
+	format := data.CaptureExportFormat(args.Format)
+	if format == "" {
+		format = data.CaptureExportJSONL
+	}
+	summary, err := data.ExportCaptureFiles(files, filter, format, args.Destination)
+	if err != nil {
+		return err
+	}
+	for _, err := range summary.ReadErrors {
+		warningf(cmd.ErrWriter, "%v", err)
+	}
+	if summary.InProgressFiles > 0 {
+		warningf(cmd.ErrWriter, "skipped %d capture files which are still being written", summary.InProgressFiles)
+	}
+
+	exported := len(files) - summary.InProgressFiles - len(summary.ReadErrors)
+	printf(cmd.Root().Writer, "Exported %d tabular readings and %d binary files from %d capture files to %s",
+		summary.TabularReadings, summary.BinaryFiles, exported, args.Destination)
+	return nil
+}
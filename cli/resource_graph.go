@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/urfave/cli/v3"
+	"go.viam.com/utils"
+)
+
+const resourceGraphFlagResource = "resource"
+
+type machinesPartGraphArgs struct {
+	Organization string
+	Location     string
+	Machine      string
+	Part         string
+	Resource     string
+}
+
+// MachinesPartGraphAction prints the resource graph of a machine part as JSON or, if a resource is
+// given, why that resource is not ready and which resources are waiting on it.
+func MachinesPartGraphAction(ctx context.Context, cmd *cli.Command, args machinesPartGraphArgs) error {
+	client, err := newViamClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	globalArgs, err := getGlobalArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	dialCtx, fqdn, rpcOpts, err := client.prepareDial(ctx, args.Organization, args.Location, args.Machine, args.Part, globalArgs.Debug)
+	if err != nil {
+		return err
+	}
+
+	logger := globalArgs.createLogger()
+
+	robotClient, err := client.connectToRobot(dialCtx, fqdn, rpcOpts, globalArgs.Debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(ctx))
+	}()
+
+	var result any
+	if args.Resource != "" {
+		result, err = robotClient.ResourceFailureChain(ctx, args.Resource)
+	} else {
+		result, err = robotClient.InspectResourceGraph(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	jsonBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	printf(cmd.Root().Writer, "%s", jsonBytes)
+	return nil
+}
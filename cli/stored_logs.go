@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	storedLogsFlagLevel  = "level"
+	storedLogsFlagLogger = "logger"
+)
+
+type machinesPartStoredLogsArgs struct {
+	Organization string
+	Location     string
+	Machine      string
+	Part         string
+	Level        string
+	Logger       string
+	Start        string
+	End          string
+	Keyword      string
+	Count        int
+}
+
+// MachinesPartStoredLogsAction prints the logs a machine part stored on its own disk. Unlike
+// 'machines part logs', which reads the logs uploaded to the cloud, it reads them from the part.
+func MachinesPartStoredLogsAction(ctx context.Context, cmd *cli.Command, args machinesPartStoredLogsArgs) error {
+	query := logging.LogQuery{
+		Logger:   args.Logger,
+		Contains: args.Keyword,
+		Limit:    args.Count,
+	}
+	if args.Level != "" {
+		level, err := logging.LevelFromString(args.Level)
+		if err != nil {
+			return err
+		}
+		query.MinLevel = level
+	}
+	start, err := parseTimeString(args.Start)
+	if err != nil {
+		return errors.Wrap(err, "invalid start time format")
+	}
+	if start != nil {
+		query.Since = start.AsTime()
+	}
+	end, err := parseTimeString(args.End)
+	if err != nil {
+		return errors.Wrap(err, "invalid end time format")
+	}
+	if end != nil {
+		query.Until = end.AsTime()
+	}
+
+	client, err := newViamClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	globalArgs, err := getGlobalArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	dialCtx, fqdn, rpcOpts, err := client.prepareDial(ctx, args.Organization, args.Location, args.Machine, args.Part, globalArgs.Debug)
+	if err != nil {
+		return err
+	}
+
+	logger := globalArgs.createLogger()
+
+	robotClient, err := client.connectToRobot(dialCtx, fqdn, rpcOpts, globalArgs.Debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(ctx))
+	}()
+
+	entries, err := robotClient.QueryLogs(ctx, query)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		columns := []string{
+			entry.Time.Format(logging.DefaultTimeFormatStr),
+			strings.ToUpper(entry.Level.String()),
+			entry.Logger,
+		}
+		if entry.Caller != "" {
+			columns = append(columns, entry.Caller)
+		}
+		columns = append(columns, entry.Message)
+		if len(entry.Fields) > 0 {
+			columns = append(columns, string(entry.Fields))
+		}
+		printf(cmd.Root().Writer, "%s", strings.Join(columns, "\t"))
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// errProtectiveStop is returned by movements which collision checking stopped, like the protective
+// stop of a real arm.
+var errProtectiveStop = errors.New("protective stop")
+
+// obstacle is a geometry for the arm to avoid, in the frame of the base of the arm.
+type obstacle struct {
+	name     string
+	geometry spatialmath.Geometry
+}
+
+// collisionPair names two geometries which collide, in a consistent order.
+type collisionPair [2]string
+
+func newCollisionPair(a, b string) collisionPair {
+	if b < a {
+		a, b = b, a
+	}
+	return collisionPair{a, b}
+}
+
+func (pair collisionPair) String() string {
+	return fmt.Sprintf("%s collided with %s", pair[0], pair[1])
+}
+
+func parseObstacles(configs []spatialmath.GeometryConfig) ([]obstacle, error) {
+	obstacles := make([]obstacle, 0, len(configs))
+	for i, conf := range configs {
+		geometry, err := conf.ParseConfig()
+		if err != nil {
+			return nil, fmt.Errorf("obstacle %d: %w", i, err)
+		}
+		name := geometry.Label()
+		if name == "" {
+			name = fmt.Sprintf("obstacle %d", i)
+		}
+		obstacles = append(obstacles, obstacle{name: name, geometry: geometry})
+	}
+	return obstacles, nil
+}
+
+// currentObstacles returns what the arm must not collide with: the obstacles of its config and the
+// geometries of every other frame in the frame system, where they are now. Frames attached to the
+// arm move with it, so they are left out.
+func (sa *simulatedArm) currentObstacles(ctx context.Context) ([]obstacle, error) {
+	obstacles := append([]obstacle{}, sa.obstacles...)
+	if sa.fsService == nil {
+		return obstacles, nil
+	}
+
+	fs, err := framesystem.NewFromService(ctx, sa.fsService, nil)
+	if err != nil {
+		return nil, err
+	}
+	originName := sa.Name().ShortName() + "_origin"
+	origin := fs.Frame(originName)
+	if origin == nil {
+		// The arm is not in the frame system, so there is nowhere to put the other frames.
+		return obstacles, nil
+	}
+	inputs, err := sa.fsService.CurrentInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	linearInputs := inputs.ToLinearInputs()
+	geometries, err := referenceframe.FrameSystemGeometriesLinearInputs(fs, linearInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	for frameName, gif := range geometries {
+		frames, err := fs.TracebackFrame(fs.Frame(frameName))
+		if err != nil {
+			return nil, err
+		}
+		attached := false
+		for _, frame := range frames {
+			if frame.Name() == originName {
+				attached = true
+				break
+			}
+		}
+		if attached {
+			continue
+		}
+
+		inOrigin, err := fs.Transform(linearInputs, gif, originName)
+		if err != nil {
+			return nil, err
+		}
+		for _, geometry := range inOrigin.(*referenceframe.GeometriesInFrame).Geometries() {
+			name := geometry.Label()
+			if name == "" {
+				name = frameName
+			}
+			obstacles = append(obstacles, obstacle{name: name, geometry: geometry})
+		}
+	}
+	return obstacles, nil
+}
+
+// collisions returns the pairs of geometries which collide when the arm is at the inputs, leaving
+// out the allowed ones. The arm is checked against itself and the obstacles.
+func (sa *simulatedArm) collisions(
+	inputs []float64, obstacles []obstacle, allowed map[collisionPair]bool,
+) ([]collisionPair, error) {
+	gif, err := sa.model.Geometries(inputs)
+	if err != nil {
+		return nil, err
+	}
+	links := gif.Geometries()
+
+	var pairs []collisionPair
+	check := func(a, b spatialmath.Geometry, bName string) error {
+		pair := newCollisionPair(a.Label(), bName)
+		if allowed[pair] {
+			return nil
+		}
+		collides, _, err := a.CollidesWith(b, 0)
+		if err != nil {
+			return err
+		}
+		if collides {
+			pairs = append(pairs, pair)
+		}
+		return nil
+	}
+	for i, link := range links {
+		for _, other := range links[i+1:] {
+			if err := check(link, other, other.Label()); err != nil {
+				return nil, err
+			}
+		}
+		for _, obs := range obstacles {
+			if err := check(link, obs.geometry, obs.name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// allowedCollisions returns which collisions a movement starting at the inputs ignores: links
+// which touch at rest, like neighboring links, and whatever already collides at the start, so
+// that the arm can move away from it.
+func (sa *simulatedArm) allowedCollisions(
+	inputs []float64, obstacles []obstacle,
+) (map[collisionPair]bool, error) {
+	allowed := make(map[collisionPair]bool, len(sa.collisionsAtRest))
+	for pair := range sa.collisionsAtRest {
+		allowed[pair] = true
+	}
+	pairs, err := sa.collisions(inputs, obstacles, allowed)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		sa.logger.Warnw("movement is starting in collision, ignoring it", "collision", pair.String())
+		allowed[pair] = true
+	}
+	return allowed, nil
+}
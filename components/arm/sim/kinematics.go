@@ -119,3 +119,13 @@ func (sa *simulatedArm) Geometries(
 
 	return gif.Geometries(), nil
 }
+
+// jointLimits returns the velocity and acceleration limits of each input of the model, in radians
+// (or mm for prismatic joints) per second and per second squared. Zero means the model does not
+// say.
+func jointLimits(model referenceframe.Model) ([]float64, []float64) {
+	if simpleModel, ok := model.(*referenceframe.SimpleModel); ok {
+		return simpleModel.JointLimits()
+	}
+	return make([]float64, len(model.DoF())), make([]float64, len(model.DoF()))
+}
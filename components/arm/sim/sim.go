@@ -5,6 +5,7 @@ package sim
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
 	"sync"
 	"sync/atomic"
@@ -14,11 +15,29 @@ import (
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
 	"go.viam.com/rdk/spatialmath"
 )
 
+const (
+	// defaultSpeed is how quickly joints move, in radians per second, when neither the config nor
+	// the kinematic model says.
+	defaultSpeed = 1.0
+
+	// stepSize is the longest time the arm moves for between collision checks, and between changes
+	// of speed while accelerating.
+	stepSize = 10 * time.Millisecond
+
+	// trajectoryTimesKey is the key of `extra` for `MoveThroughJointPositions` with the time, in
+	// seconds since the movement started, for the arm to reach each of the positions at.
+	trajectoryTimesKey = "trajectory_times_s"
+
+	epsilon = 1e-9
+)
+
 // operation has the following logical states/invariants:
 // 1. Default constructed -- no operation in flight
 // 2. Operation started -> targetInputs != nil, done == false, stopped == false
@@ -29,6 +48,30 @@ type operation struct {
 	targetInputs []float64
 	done         bool
 	stopped      bool
+	// err is why the operation stopped, when it was not a call to `Stop`.
+	err error
+
+	// startInputs is where the joints were when the operation started. The joints move together
+	// along the straight line from there to `targetInputs`. `progress` is how far along it they
+	// are, from 0 to 1, and `progressVel` is how quickly that changes per second. Both are limited
+	// by the slowest joint for the distance it has to travel.
+	startInputs    []float64
+	progress       float64
+	progressVel    float64
+	maxProgressVel float64
+	maxProgressAcc float64
+
+	// waypoints, when set, are a timed trajectory to follow instead, starting at `startInputs`.
+	// The joints reach each waypoint at its time in `waypointTimes`, in seconds since the operation
+	// started, moving linearly in between.
+	waypoints     [][]float64
+	waypointTimes []float64
+	elapsed       float64
+
+	// obstacles and allowedCollisions are what the arm is checked against when collision checking
+	// is on.
+	obstacles         []obstacle
+	allowedCollisions map[collisionPair]bool
 }
 
 func (op operation) isMoving() bool {
@@ -42,9 +85,20 @@ type simulatedArm struct {
 	// logical properties
 	modelName string
 	model     referenceframe.Model
-	// Speed represents how quickly the joints of the arm will move. Speed is in radians per
-	// second. In this simplified simulation, speed is the same for each joint.
-	speed float64
+	// fs is a frame system with only the model in it, for timing trajectories with motionplan.
+	fs *referenceframe.FrameSystem
+	// maxVelocities and maxAccelerations are how quickly each joint can move, in radians per second,
+	// and speed up or slow down, in radians per second squared. An acceleration of zero means the
+	// joint reaches its speed instantly.
+	maxVelocities    []float64
+	maxAccelerations []float64
+
+	// collisionChecking stops movements which would make the arm collide with itself or obstacles.
+	// `collisionsAtRest` are the links which touch when all joints are at zero, which are allowed.
+	collisionChecking bool
+	collisionsAtRest  map[collisionPair]bool
+	obstacles         []obstacle
+	fsService         framesystem.Service
 
 	// lifetime management
 	resource.AlwaysRebuild
@@ -77,9 +131,22 @@ type Config struct {
 	ModelFilePath string `json:"model-path,omitempty"`
 
 	// Speed represents how quickly the joints of the arm will move. Speed is in radians per
-	// second. In this simplified simulation, speed is the same for each joint.
+	// second. It caps the velocity limits of the kinematic model, and joints without one move at 1
+	// radian per second when unset.
 	Speed float64 `json:"speed,omitempty"`
 
+	// Acceleration is how quickly the joints speed up and slow down, in radians per second
+	// squared. It caps the acceleration limits of the kinematic model. Joints without either reach
+	// their speed instantly.
+	Acceleration float64 `json:"acceleration,omitempty"`
+
+	// CollisionChecking makes the arm stop, like a protective stop, when it would collide with
+	// itself, the obstacles, or the geometries of other frames in the frame system.
+	CollisionChecking bool `json:"collision-checking,omitempty"`
+
+	// Obstacles are geometries, in the frame of the base of the arm, for collision checking.
+	Obstacles []spatialmath.GeometryConfig `json:"obstacles,omitempty"`
+
 	// SimulateTime controls whether the `simulatedArm` will spin up and manage a background
 	// goroutine for continually updating time to a real-world value.
 	SimulateTime bool `json:"simulate-time,omitempty"`
@@ -105,7 +172,21 @@ func (conf *Config) Validate(path string) ([]string, []string, error) {
 	case conf.Model == "" && conf.ModelFilePath != "":
 		_, err = referenceframe.KinematicModelFromFile(conf.ModelFilePath, "")
 	}
-	return nil, nil, err
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if conf.Speed < 0 || conf.Acceleration < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("speed and acceleration cannot be negative"))
+	}
+	if _, err := parseObstacles(conf.Obstacles); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	if conf.CollisionChecking {
+		// The other frames in the frame system are obstacles too.
+		return []string{framesystem.InternalServiceName.String()}, nil, nil
+	}
+	return nil, nil, nil
 }
 
 // NewArm is the `func init` registered constructor intended to be consumed/invoked by the resource
@@ -117,31 +198,67 @@ func NewArm(ctx context.Context, deps resource.Dependencies, resConf resource.Co
 		return nil, err
 	}
 
-	speed := 1.0 // 1 radian per second
-	if armConf.Speed > 0.0 {
-		speed = armConf.Speed
-	}
-
 	model, err := buildModel(resConf.Name, armConf)
 	if err != nil {
 		return nil, err
 	}
 
-	return newArm(resConf.ResourceName().AsNamed(), armConf.Model, model, speed, armConf.SimulateTime, logger), nil
+	var fsService framesystem.Service
+	if armConf.CollisionChecking {
+		// Modules only have the frame system under its public name.
+		fsService, err = resource.FromProvider[framesystem.Service](deps, framesystem.InternalServiceName)
+		if err != nil {
+			fsService, err = framesystem.FromDependencies(deps)
+		}
+		if err != nil {
+			logger.CWarnw(ctx, "frame system unavailable, only checking collisions with configured obstacles", "error", err)
+			fsService = nil
+		}
+	}
+
+	return newArm(resConf.ResourceName().AsNamed(), armConf, model, fsService, logger)
 }
 
 func newArm(
-	namedArm resource.Named, modelName string, model referenceframe.Model, speed float64, simulateTime bool,
+	namedArm resource.Named, conf *Config, model referenceframe.Model, fsService framesystem.Service,
 	logger logging.Logger,
-) *simulatedArm {
+) (*simulatedArm, error) {
+	maxVelocities, maxAccelerations := jointLimits(model)
+	for jointIdx := range maxVelocities {
+		if conf.Speed > 0 && (maxVelocities[jointIdx] == 0 || conf.Speed < maxVelocities[jointIdx]) {
+			maxVelocities[jointIdx] = conf.Speed
+		}
+		if maxVelocities[jointIdx] == 0 {
+			maxVelocities[jointIdx] = defaultSpeed
+		}
+		if conf.Acceleration > 0 && (maxAccelerations[jointIdx] == 0 || conf.Acceleration < maxAccelerations[jointIdx]) {
+			maxAccelerations[jointIdx] = conf.Acceleration
+		}
+	}
+
+	obstacles, err := parseObstacles(conf.Obstacles)
+	if err != nil {
+		return nil, err
+	}
+	fs := referenceframe.NewEmptyFrameSystem(model.Name())
+	if err := fs.AddFrame(model, fs.World()); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	ret := &simulatedArm{
 		Named:  namedArm,
 		logger: logger,
 
-		modelName: modelName,
-		model:     model,
-		speed:     speed,
+		modelName:        conf.Model,
+		model:            model,
+		fs:               fs,
+		maxVelocities:    maxVelocities,
+		maxAccelerations: maxAccelerations,
+
+		collisionChecking: conf.CollisionChecking,
+		obstacles:         obstacles,
+		fsService:         fsService,
 
 		ctx:    ctx,
 		cancel: cancel,
@@ -149,7 +266,19 @@ func newArm(
 		currInputs: make([]float64, len(model.DoF())),
 	}
 
-	if simulateTime {
+	if ret.collisionChecking {
+		atRest, err := ret.collisions(ret.currInputs, nil, nil)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		ret.collisionsAtRest = make(map[collisionPair]bool, len(atRest))
+		for _, pair := range atRest {
+			ret.collisionsAtRest[pair] = true
+		}
+	}
+
+	if conf.SimulateTime {
 		// When simulating time, avoid ever letting the zero value be visible. Lest the first
 		// movement be unpredictable.
 		ret.lastUpdated = time.Now()
@@ -158,7 +287,7 @@ func newArm(
 		})
 	}
 
-	return ret
+	return ret, nil
 }
 
 // Simulated arms only update their position when `updateForTime` is called. This can be used by
@@ -175,85 +304,241 @@ func (sa *simulatedArm) updateForTime(now time.Time) {
 		return
 	}
 
-	// This will track if we need to set `done` to true. So long as even one joint is moving, this
-	// operation is not "done".
-	anyJointStillMoving := false
-
-	// Dan: There are three natural algorithms for simulating an arm in motion:
-	// - Each joint moves at its maximum speed. Joints will finish moving at different times.
-	// - Joints that have less travel will move slower such that all joints finish at the same time.
-	// - Joints move such that the end effector follows a straight-line path from start to finish.
-	//   - It's not obvious to me there is always a valid straight-line path. E.g: avoiding
-	//     self-collisions.
-	//
-	// The following code implements the second algorithm. I've found that to better map with our
-	// motion planning interpolation algorithms.
 	timeSinceLastUpdate := now.Sub(sa.lastUpdated)
 	sa.lastUpdated = now
 
-	// Find the maximum travel distance. Given all joints (right now) move at the same speed, we can
-	// map this to how long the whole movement will take.
-	var maxDist float64
-	for jointIdx, currJointInp := range sa.currInputs {
-		maxDist = math.Max(maxDist, math.Abs(sa.operation.targetInputs[jointIdx]-currJointInp))
+	// Moving at a constant speed can be computed in one step. Speeding up, slowing down and
+	// checking for collisions along the way cannot.
+	if !sa.collisionChecking && (sa.operation.waypoints != nil || math.IsInf(sa.operation.maxProgressAcc, 1)) {
+		sa.step(timeSinceLastUpdate.Seconds())
+		return
+	}
+	for remaining := timeSinceLastUpdate; remaining > 0 && sa.operation.isMoving(); remaining -= stepSize {
+		sa.step(math.Min(remaining.Seconds(), stepSize.Seconds()))
 	}
+}
+
+// step moves the joints for `seconds` along the operation. It must be called with `sa.mu` held.
+func (sa *simulatedArm) step(seconds float64) {
+	op := &sa.operation
+	prevInputs := append([]float64{}, sa.currInputs...)
 
-	const epsilon = 1e-9
-	if maxDist < epsilon {
-		sa.operation.done = true
+	if op.waypoints != nil {
+		op.elapsed += seconds
+		sa.followTrajectory()
+	} else {
+		sa.advance(seconds)
+	}
+
+	if !sa.collisionChecking {
+		return
+	}
+	collisions, err := sa.collisions(sa.currInputs, op.obstacles, op.allowedCollisions)
+	if err == nil && len(collisions) == 0 {
 		return
 	}
+	if err == nil {
+		err = fmt.Errorf("%w: %s", errProtectiveStop, collisions[0])
+	}
+	// Stop where the arm was last known not to collide.
+	copy(sa.currInputs, prevInputs)
+	op.done = false
+	op.stopped = true
+	op.err = err
+	sa.logger.Warnw("stopping arm", "error", err)
+}
 
-	// Find the speed each joint needs to move to finish at the same time.
-	modifiedSpeeds := make([]float64, len(sa.currInputs))
-	for jointIdx, currJointInp := range sa.currInputs {
-		// The remaining distance between our target and where we are.
-		diffRads := math.Abs(sa.operation.targetInputs[jointIdx] - currJointInp)
+// advance moves the joints along the line from `startInputs` to `targetInputs`.
+//
+// Dan: There are three natural algorithms for simulating an arm in motion:
+// - Each joint moves at its maximum speed. Joints will finish moving at different times.
+// - Joints that have less travel will move slower such that all joints finish at the same time.
+// - Joints move such that the end effector follows a straight-line path from start to finish.
+//   - It's not obvious to me there is always a valid straight-line path. E.g: avoiding
+//     self-collisions.
+//
+// The following code implements the second algorithm. I've found that to better map with our
+// motion planning interpolation algorithms.
+func (sa *simulatedArm) advance(seconds float64) {
+	op := &sa.operation
+	if math.IsInf(op.maxProgressAcc, 1) {
+		op.progressVel = op.maxProgressVel
+	} else {
+		// Speed up to the maximum speed, until it is time to slow down to stop at the target.
+		stoppingVel := math.Sqrt(2 * op.maxProgressAcc * (1 - op.progress))
+		targetVel := math.Min(op.maxProgressVel, stoppingVel)
+		if op.progressVel < targetVel {
+			op.progressVel = math.Min(targetVel, op.progressVel+op.maxProgressAcc*seconds)
+		} else {
+			op.progressVel = targetVel
+		}
+	}
+	op.progress += op.progressVel * seconds
 
-		// The joint that moves the farthest is always traveling at `sa.speed`. Our joints speed, in
-		// terms of the max speed, is then simply the ratio of our travel distance over the largest
-		// distance to travel.
-		speedAdjustment := diffRads / maxDist
+	if op.progress > 1-epsilon {
+		// Rather than overshooting, simply set the current joint positions to the target.
+		copy(sa.currInputs, op.targetInputs)
+		op.progressVel = 0
+		op.done = true
+		return
+	}
+	for jointIdx, startJointInp := range op.startInputs {
+		sa.currInputs[jointIdx] = startJointInp + op.progress*(op.targetInputs[jointIdx]-startJointInp)
+	}
+}
 
-		// I.e: if we only need to move 1/4 the distance as the `maxDist`, we will travel at 1/4 *
-		// `sa.speed`.
-		modifiedSpeeds[jointIdx] = speedAdjustment * sa.speed
+// followTrajectory moves the joints to where the timed trajectory is at the elapsed time.
+func (sa *simulatedArm) followTrajectory() {
+	op := &sa.operation
+	last := len(op.waypointTimes) - 1
+	if op.elapsed > op.waypointTimes[last]-epsilon {
+		copy(sa.currInputs, op.targetInputs)
+		op.done = true
+		return
 	}
 
-	for jointIdx, currJointInp := range sa.currInputs {
-		// The remaining distance between our target and where we are. Note the result is signed
-		// based on the direction we are traveling.
-		diffRads := sa.operation.targetInputs[jointIdx] - currJointInp
+	segment := 1
+	for op.waypointTimes[segment] < op.elapsed {
+		segment++
+	}
+	from, to := op.waypoints[segment-1], op.waypoints[segment]
+	fraction := (op.elapsed - op.waypointTimes[segment-1]) / (op.waypointTimes[segment] - op.waypointTimes[segment-1])
+	for jointIdx := range sa.currInputs {
+		sa.currInputs[jointIdx] = from[jointIdx] + fraction*(to[jointIdx]-from[jointIdx])
+	}
+}
 
-		// How far we can theoretically travel since the last update. We will "cap" this to
-		// `diffRads`.
-		toTravelRads := timeSinceLastUpdate.Seconds() * modifiedSpeeds[jointIdx]
-		if toTravelRads > math.Abs(diffRads)-epsilon {
-			// We can travel farther than we need to. For example, when there was only 1 second left
-			// of travel, but this `updateForTime` call is 10 seconds later. Simply set the current
-			// joint position to its target.
-			sa.currInputs[jointIdx] = sa.operation.targetInputs[jointIdx]
-		} else {
-			// We have not reached our destination. Advance the current joint position by
-			// `toTravelRads`.
-			if diffRads < 0 {
-				// `toTravelRads` is always positive, flip the sign if we need to travel in the
-				// opposite direction.
-				toTravelRads = -toTravelRads
-			}
+// maxVelocity returns how quickly a joint may move during a movement with the options.
+func (sa *simulatedArm) maxVelocity(jointIdx int, options *arm.MoveOptions) float64 {
+	velocity := sa.maxVelocities[jointIdx]
+	if options != nil && options.MaxVelRads > 0 {
+		velocity = math.Min(velocity, options.MaxVelRads)
+	}
+	return velocity
+}
 
-			sa.currInputs[jointIdx] = currJointInp + toTravelRads
+// maxAcceleration returns how quickly a joint may speed up during a movement with the options,
+// which is infinite without a limit.
+func (sa *simulatedArm) maxAcceleration(jointIdx int, options *arm.MoveOptions) float64 {
+	acceleration := math.Inf(1)
+	if sa.maxAccelerations[jointIdx] > 0 {
+		acceleration = sa.maxAccelerations[jointIdx]
+	}
+	if options != nil && options.MaxAccRads > 0 {
+		acceleration = math.Min(acceleration, options.MaxAccRads)
+	}
+	return acceleration
+}
 
-			// Theoretically, being all joints movement is scaled to finish at the same time, either
-			// all joints that needed to change enter this `else` block, or none of them do.
-			anyJointStillMoving = true
+// newMove returns an operation moving the joints from the start to the target as quickly as the
+// joints allow.
+func (sa *simulatedArm) newMove(start, target []float64, options *arm.MoveOptions) operation {
+	op := operation{
+		targetInputs:   append([]float64{}, target...),
+		startInputs:    start,
+		maxProgressVel: math.Inf(1),
+		maxProgressAcc: math.Inf(1),
+	}
+	// The joint which is slowest for how far it has to travel determines how quickly all joints
+	// move, so that they finish at the same time.
+	for jointIdx, startJointInp := range start {
+		dist := math.Abs(target[jointIdx] - startJointInp)
+		if dist < epsilon {
+			continue
 		}
+		op.maxProgressVel = math.Min(op.maxProgressVel, sa.maxVelocity(jointIdx, options)/dist)
+		op.maxProgressAcc = math.Min(op.maxProgressAcc, sa.maxAcceleration(jointIdx, options)/dist)
 	}
+	if math.IsInf(op.maxProgressVel, 1) {
+		// Already at the target.
+		copy(sa.currInputs, op.targetInputs)
+		op.done = true
+	}
+	return op
+}
+
+// newTrajectory returns an operation following a timed trajectory through the positions from the
+// start. Without times, the trajectory is timed to go through the positions as quickly as the joints
+// allow without stopping at each one. With times, the arm reaches each position at its time,
+// failing if any joint would move or speed up faster than it can.
+func (sa *simulatedArm) newTrajectory(
+	start []float64, positions [][]referenceframe.Input, times []time.Duration, options *arm.MoveOptions,
+) (operation, error) {
+	name := sa.model.Name()
+	traj := motionplan.Trajectory{{name: start}}
+	for _, position := range positions {
+		traj = append(traj, referenceframe.FrameSystemInputs{name: position})
+	}
+	limits := make([]motionplan.DynamicLimits, len(start))
+	for jointIdx := range limits {
+		limits[jointIdx] = motionplan.DynamicLimits{
+			Velocity:     sa.maxVelocity(jointIdx, options),
+			Acceleration: sa.maxAcceleration(jointIdx, options),
+		}
+	}
+	opts := &motionplan.TimingOptions{FrameLimits: map[string][]motionplan.DynamicLimits{name: limits}}
+
+	var timed motionplan.TimedTrajectory
+	var err error
+	if times == nil {
+		timed, err = motionplan.TimeParameterize(traj, sa.fs, opts)
+	} else {
+		timed, err = motionplan.NewTimedTrajectory(traj, append([]time.Duration{0}, times...), sa.fs, opts)
+	}
+	if err != nil {
+		return operation{}, err
+	}
+	waypoints, waypointTimes, _, err := timed.GetFrameInputs(name)
+	if err != nil {
+		return operation{}, err
+	}
+
+	op := operation{
+		targetInputs: append([]float64{}, positions[len(positions)-1]...),
+		startInputs:  start,
+		waypoints:    waypoints,
+	}
+	for _, waypointTime := range waypointTimes {
+		op.waypointTimes = append(op.waypointTimes, waypointTime.Seconds())
+	}
+	return op, nil
+}
 
-	if !anyJointStillMoving {
-		// All joints hit the "traveling further than we need to" path. The operation is done.
-		sa.operation.done = true
+// trajectoryTimes returns the times for the arm to reach each of the positions at from `extra`,
+// or nil if there are none.
+func trajectoryTimes(extra map[string]interface{}, numPositions int) ([]time.Duration, error) {
+	raw, ok := extra[trajectoryTimesKey]
+	if !ok {
+		return nil, nil
 	}
+	var times []float64
+	switch raw := raw.(type) {
+	case []float64:
+		times = raw
+	case []interface{}:
+		for _, t := range raw {
+			f, ok := t.(float64)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of numbers, got %v", trajectoryTimesKey, t)
+			}
+			times = append(times, f)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be a list of numbers, got %T", trajectoryTimesKey, raw)
+	}
+	if len(times) != numPositions {
+		return nil, fmt.Errorf("%s has %d times for %d positions", trajectoryTimesKey, len(times), numPositions)
+	}
+	durations := make([]time.Duration, 0, len(times))
+	prev := 0.0
+	for _, t := range times {
+		if t <= prev {
+			return nil, fmt.Errorf("%s must be positive and increasing", trajectoryTimesKey)
+		}
+		prev = t
+		durations = append(durations, time.Duration(t*float64(time.Second)))
+	}
+	return durations, nil
 }
 
 func (sa *simulatedArm) EndPosition(
@@ -289,17 +574,46 @@ func (sa *simulatedArm) JointPositions(
 
 func (sa *simulatedArm) MoveToJointPositions(
 	ctx context.Context, target []referenceframe.Input, extra map[string]interface{},
+) error {
+	return sa.moveToJointPositions(ctx, target, nil)
+}
+
+func (sa *simulatedArm) moveToJointPositions(
+	ctx context.Context, target []referenceframe.Input, options *arm.MoveOptions,
 ) error {
 	if err := arm.CheckDesiredJointPositions(ctx, sa, target); err != nil {
 		return err
 	}
 
+	return sa.run(ctx, func(start []float64) (operation, error) {
+		return sa.newMove(start, target, options), nil
+	})
+}
+
+// run starts the operation returned by `newOp` for the current joint positions, and blocks until
+// it completes or is canceled.
+func (sa *simulatedArm) run(ctx context.Context, newOp func(start []float64) (operation, error)) error {
+	var obstacles []obstacle
+	if sa.collisionChecking {
+		// The frame system asks the arm for its joint positions, so this cannot hold `sa.mu`.
+		var err error
+		if obstacles, err = sa.currentObstacles(ctx); err != nil {
+			return err
+		}
+	}
+
 	sa.mu.Lock()
-	sa.operation = operation{
-		targetInputs: target,
-		done:         false,
-		stopped:      false,
+	start := append([]float64{}, sa.currInputs...)
+	op, err := newOp(start)
+	if err == nil && sa.collisionChecking {
+		op.obstacles = obstacles
+		op.allowedCollisions, err = sa.allowedCollisions(start, obstacles)
+	}
+	if err != nil {
+		sa.mu.Unlock()
+		return err
 	}
+	sa.operation = op
 	sa.mu.Unlock()
 
 	// An operation was "started". `MoveToJointPositions` blocks until the movement completes or is
@@ -317,8 +631,7 @@ func (sa *simulatedArm) MoveToJointPositions(
 		default:
 			// Poll for completion:
 			sa.mu.Lock()
-			// Calls to `updateForTime` will nil out `targetInputs` when a movement is completed.
-			done, stopped := sa.operation.done, sa.operation.stopped
+			done, stopped, opErr := sa.operation.done, sa.operation.stopped, sa.operation.err
 			sa.mu.Unlock()
 
 			if done && stopped {
@@ -330,6 +643,9 @@ func (sa *simulatedArm) MoveToJointPositions(
 			}
 
 			if stopped {
+				if opErr != nil {
+					return opErr
+				}
 				return errors.New("stopped before reaching target")
 			}
 
@@ -338,19 +654,35 @@ func (sa *simulatedArm) MoveToJointPositions(
 	}
 }
 
+// MoveThroughJointPositions moves through the positions along a trajectory timed by motionplan,
+// without stopping at each one. When `extra` has a time for each position, in seconds since the
+// movement started, under "trajectory_times_s", the arm reaches each position at its time instead,
+// failing up front if a joint would have to move or speed up faster than it can.
 func (sa *simulatedArm) MoveThroughJointPositions(
 	ctx context.Context,
 	positions [][]referenceframe.Input,
-	_ *arm.MoveOptions,
-	_ map[string]interface{},
+	options *arm.MoveOptions,
+	extra map[string]interface{},
 ) error {
+	times, err := trajectoryTimes(extra, len(positions))
+	if err != nil {
+		return err
+	}
+	if len(positions) == 0 {
+		return nil
+	}
+	if times == nil && len(positions) == 1 {
+		return sa.moveToJointPositions(ctx, positions[0], options)
+	}
+
 	for _, goal := range positions {
-		if err := sa.MoveToJointPositions(ctx, goal, nil); err != nil {
+		if err := arm.CheckDesiredJointPositions(ctx, sa, goal); err != nil {
 			return err
 		}
 	}
-
-	return nil
+	return sa.run(ctx, func(start []float64) (operation, error) {
+		return sa.newTrajectory(start, positions, times, options)
+	})
 }
 
 func (sa *simulatedArm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
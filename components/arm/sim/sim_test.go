@@ -2,15 +2,21 @@ package sim
 
 import (
 	"context"
+	"errors"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/golang/geo/r3"
 	"go.viam.com/test"
 	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/spatialmath"
 )
 
 func TestBasic(t *testing.T) {
@@ -181,3 +187,259 @@ func TestTimeSimulation(t *testing.T) {
 	err = simArm.MoveToJointPositions(ctx, []float64{1, -2, 0, 0, 0, 0}, nil)
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func newTestArm(t *testing.T, conf *Config, deps resource.Dependencies) *simulatedArm {
+	t.Helper()
+	simArmI, err := NewArm(context.Background(), deps, resource.Config{
+		Name:                "arm",
+		API:                 arm.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return simArmI.(*simulatedArm)
+}
+
+// advance moves the time of the arm forward in steps until the movement returns, failing the test
+// if it takes longer than the timeout.
+func advance(t *testing.T, simArm *simulatedArm, moveFuture chan error, timeout time.Duration) error {
+	t.Helper()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		isMoving, err := simArm.IsMoving(context.Background())
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, isMoving, test.ShouldBeTrue)
+	})
+	clock := simArm.lastUpdated
+	for elapsed := time.Duration(0); elapsed <= timeout; elapsed += 100 * time.Millisecond {
+		clock = clock.Add(100 * time.Millisecond)
+		simArm.updateForTime(clock)
+		select {
+		case err := <-moveFuture:
+			return err
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	t.Fatal("movement did not finish in time")
+	return nil
+}
+
+func TestJointLimits(t *testing.T) {
+	// The UR5e joints move at up to 180 degrees per second.
+	simArm := newTestArm(t, &Config{Model: "ur5e"}, nil)
+	for _, velocity := range simArm.maxVelocities {
+		test.That(t, velocity, test.ShouldAlmostEqual, math.Pi)
+	}
+
+	// Speed caps the limits of the model.
+	simArm = newTestArm(t, &Config{Model: "ur5e", Speed: 1, Acceleration: 2}, nil)
+	test.That(t, simArm.maxVelocities, test.ShouldResemble, []float64{1, 1, 1, 1, 1, 1})
+	test.That(t, simArm.maxAccelerations, test.ShouldResemble, []float64{2, 2, 2, 2, 2, 2})
+
+	// Without limits in the model, joints move at the default speed.
+	simArm = newTestArm(t, &Config{Model: "lite6"}, nil)
+	test.That(t, simArm.maxVelocities, test.ShouldResemble, []float64{1, 1, 1, 1, 1, 1})
+	test.That(t, simArm.maxAccelerations, test.ShouldResemble, []float64{0, 0, 0, 0, 0, 0})
+
+	_, _, err := (&Config{Model: "lite6", Acceleration: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestAcceleration(t *testing.T) {
+	ctx := context.Background()
+	simArm := newTestArm(t, &Config{Model: "lite6", Speed: 1, Acceleration: 1}, nil)
+
+	moveFuture := make(chan error, 1)
+	go func() {
+		moveFuture <- simArm.MoveToJointPositions(ctx, []float64{2, 1, 0, 0, 0, 0}, nil)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		isMoving, err := simArm.IsMoving(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, isMoving, test.ShouldBeTrue)
+	})
+
+	// Speeding up to 1 radian per second takes a second and half a radian.
+	clock := simArm.lastUpdated.Add(time.Second)
+	simArm.updateForTime(clock)
+	currInputs, err := simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs[0], test.ShouldAlmostEqual, 0.5, 0.01)
+	test.That(t, currInputs[1], test.ShouldAlmostEqual, 0.25, 0.01)
+
+	// After cruising for a second, slowing down takes another second.
+	simArm.updateForTime(clock.Add(1900 * time.Millisecond))
+	isMoving, err := simArm.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, isMoving, test.ShouldBeTrue)
+	test.That(t, advance(t, simArm, moveFuture, 200*time.Millisecond), test.ShouldBeNil)
+	currInputs, err = simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{2, 1, 0, 0, 0, 0})
+
+	// Move options cap the velocity further, so moving half a radian takes over two seconds.
+	go func() {
+		moveFuture <- simArm.MoveThroughJointPositions(ctx, [][]referenceframe.Input{{2, 1, 0.5, 0, 0, 0}},
+			&arm.MoveOptions{MaxVelRads: 0.25, MaxAccRads: 100}, nil)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		isMoving, err := simArm.IsMoving(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, isMoving, test.ShouldBeTrue)
+	})
+	simArm.updateForTime(simArm.lastUpdated.Add(2 * time.Second))
+	isMoving, err = simArm.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, isMoving, test.ShouldBeTrue)
+	test.That(t, advance(t, simArm, moveFuture, time.Second), test.ShouldBeNil)
+}
+
+func TestTrajectory(t *testing.T) {
+	ctx := context.Background()
+	simArm := newTestArm(t, &Config{Model: "lite6", Speed: 1}, nil)
+
+	positions := [][]referenceframe.Input{{1, 0, 0, 0, 0, 0}, {1, 1, 0, 0, 0, 0}}
+	moveFuture := make(chan error, 1)
+	go func() {
+		moveFuture <- simArm.MoveThroughJointPositions(ctx, positions, nil,
+			map[string]interface{}{trajectoryTimesKey: []interface{}{2.0, 4.0}})
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		isMoving, err := simArm.IsMoving(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, isMoving, test.ShouldBeTrue)
+	})
+
+	// The arm reaches each position at its time, rather than as fast as it can.
+	clock := simArm.lastUpdated.Add(time.Second)
+	simArm.updateForTime(clock)
+	currInputs, err := simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{0.5, 0, 0, 0, 0, 0})
+	clock = clock.Add(2 * time.Second)
+	simArm.updateForTime(clock)
+	currInputs, err = simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{1, 0.5, 0, 0, 0, 0})
+	test.That(t, advance(t, simArm, moveFuture, time.Second), test.ShouldBeNil)
+
+	// Trajectories which are too fast for the joints are rejected up front.
+	err = simArm.MoveThroughJointPositions(ctx, [][]referenceframe.Input{{0, 0, 0, 0, 0, 0}}, nil,
+		map[string]interface{}{trajectoryTimesKey: []float64{0.5}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "faster than its limit")
+
+	err = simArm.MoveThroughJointPositions(ctx, positions, nil,
+		map[string]interface{}{trajectoryTimesKey: []float64{2, 1}})
+	test.That(t, err, test.ShouldNotBeNil)
+	err = simArm.MoveThroughJointPositions(ctx, positions, nil,
+		map[string]interface{}{trajectoryTimesKey: []float64{2}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// Starting and stopping too quickly for the joints is rejected too.
+	simArm = newTestArm(t, &Config{Model: "lite6", Speed: 1, Acceleration: 1}, nil)
+	err = simArm.MoveThroughJointPositions(ctx, [][]referenceframe.Input{{0.5, 0, 0, 0, 0, 0}}, nil,
+		map[string]interface{}{trajectoryTimesKey: []float64{1.2}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "accelerate")
+
+	// Without times, the arm goes through the positions without stopping at each one, which would
+	// take two seconds.
+	simArm = newTestArm(t, &Config{Model: "lite6", Speed: 1, Acceleration: 2}, nil)
+	go func() {
+		moveFuture <- simArm.MoveThroughJointPositions(ctx,
+			[][]referenceframe.Input{{0.5, 0, 0, 0, 0, 0}, {1, 0, 0, 0, 0, 0}}, nil, nil)
+	}()
+	test.That(t, advance(t, simArm, moveFuture, 1800*time.Millisecond), test.ShouldBeNil)
+	currInputs, err = simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{1, 0, 0, 0, 0, 0})
+}
+
+// post is in the way of the wrist of a lite6 at the origin turning its base by 90 degrees.
+var post = r3.Vector{X: 0, Y: 87, Z: 165}
+
+func TestCollisionWithObstacle(t *testing.T) {
+	ctx := context.Background()
+	simArm := newTestArm(t, &Config{
+		Model:             "lite6",
+		Speed:             1,
+		CollisionChecking: true,
+		Obstacles: []spatialmath.GeometryConfig{{
+			Type: spatialmath.BoxType, X: 20, Y: 20, Z: 20, TranslationOffset: post, Label: "post",
+		}},
+	}, nil)
+
+	moveFuture := make(chan error, 1)
+	go func() {
+		moveFuture <- simArm.MoveToJointPositions(ctx, []float64{math.Pi, 0, 0, 0, 0, 0}, nil)
+	}()
+	err := advance(t, simArm, moveFuture, 4*time.Second)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, errors.Is(err, errProtectiveStop), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "post")
+
+	currInputs, err := simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs[0], test.ShouldBeBetween, 0, math.Pi/2)
+
+	// The arm can move away from the obstacle.
+	go func() {
+		moveFuture <- simArm.MoveToJointPositions(ctx, []float64{0, 0, 0, 0, 0, 0}, nil)
+	}()
+	test.That(t, advance(t, simArm, moveFuture, 2*time.Second), test.ShouldBeNil)
+}
+
+func TestSelfCollision(t *testing.T) {
+	ctx := context.Background()
+	simArm := newTestArm(t, &Config{Model: "lite6", Speed: 1, CollisionChecking: true}, nil)
+
+	// Leaning the upper arm far forward brings the wrist down onto the base.
+	moveFuture := make(chan error, 1)
+	go func() {
+		moveFuture <- simArm.MoveToJointPositions(ctx, []float64{0, 2, 0, 0, 0, 0}, nil)
+	}()
+	err := advance(t, simArm, moveFuture, 4*time.Second)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, errors.Is(err, errProtectiveStop), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "arm:base_top")
+}
+
+func TestCollisionWithFrameSystem(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	fsService, err := framesystem.New(ctx, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	simArm := newTestArm(t, &Config{Model: "lite6", Speed: 1, CollisionChecking: true},
+		resource.Dependencies{framesystem.InternalServiceName: fsService})
+
+	// The arm is off the origin of the world, with the post relative to it.
+	armOffset := r3.Vector{X: 100}
+	postGeometry, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{X: 20, Y: 20, Z: 20}, "")
+	test.That(t, err, test.ShouldBeNil)
+	fsConf := &framesystem.Config{Parts: []*referenceframe.FrameSystemPart{
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewPoseFromPoint(armOffset), "arm", nil),
+			ModelFrame:  simArm.model,
+		},
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(
+				referenceframe.World, spatialmath.NewPoseFromPoint(armOffset.Add(post)), "post", postGeometry),
+		},
+	}}
+	err = fsService.Reconfigure(ctx, resource.Dependencies{arm.Named("arm"): simArm},
+		resource.Config{ConvertedAttributes: fsConf})
+	test.That(t, err, test.ShouldBeNil)
+
+	moveFuture := make(chan error, 1)
+	go func() {
+		moveFuture <- simArm.MoveToJointPositions(ctx, []float64{math.Pi, 0, 0, 0, 0, 0}, nil)
+	}()
+	err = advance(t, simArm, moveFuture, 4*time.Second)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, errors.Is(err, errProtectiveStop), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "post")
+}
@@ -5,5 +5,6 @@ import (
 	// register bases.
 	_ "go.viam.com/rdk/components/base/fake"
 	_ "go.viam.com/rdk/components/base/sensorcontrolled"
+	_ "go.viam.com/rdk/components/base/sim"
 	_ "go.viam.com/rdk/components/base/wheeled"
 )
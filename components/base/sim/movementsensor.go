@@ -0,0 +1,184 @@
+package sim
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const mToKm = 1e-3
+
+// MovementSensorConfig is used for converting the config attributes of the simulated movement
+// sensor.
+type MovementSensorConfig struct {
+	// Base is the simulated base the movement sensor is on.
+	Base string `json:"base"`
+	// Odometry makes the movement sensor report where the wheels of the base say it is, like wheeled
+	// odometry, rather than where it really is, like a GPS and compass.
+	Odometry bool `json:"odometry,omitempty"`
+	// OriginLatitude and OriginLongitude are where the base started.
+	OriginLatitude  float64 `json:"origin_latitude,omitempty"`
+	OriginLongitude float64 `json:"origin_longitude,omitempty"`
+	// PositionStdDevM and HeadingStdDevDeg are the standard deviations of the noise added to the
+	// positions and headings the movement sensor reports.
+	PositionStdDevM  float64 `json:"position_std_dev_m,omitempty"`
+	HeadingStdDevDeg float64 `json:"heading_std_dev_deg,omitempty"`
+	// RandomSeed seeds the noise, so that runs can be repeated.
+	RandomSeed int64 `json:"random_seed,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *MovementSensorConfig) Validate(path string) ([]string, []string, error) {
+	if conf.Base == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if conf.PositionStdDevM < 0 || conf.HeadingStdDevDeg < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("standard deviations cannot be negative"))
+	}
+	return []string{conf.Base}, nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(movementsensor.API, Model,
+		resource.Registration[movementsensor.MovementSensor, *MovementSensorConfig]{
+			Constructor: NewMovementSensor,
+		})
+}
+
+type simulatedMovementSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	base             *simulatedBase
+	odometry         bool
+	origin           *geo.Point
+	positionStdDev   float64
+	headingStdDevDeg float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+
+	logger logging.Logger
+}
+
+// NewMovementSensor returns a movement sensor reporting the movement of a simulated base.
+func NewMovementSensor(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	msConf, err := resource.NativeConfig[*MovementSensorConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base.FromProvider(deps, msConf.Base)
+	if err != nil {
+		return nil, err
+	}
+	simBase, ok := b.(*simulatedBase)
+	if !ok {
+		return nil, errors.Errorf("base %q is not a simulated base", msConf.Base)
+	}
+	return &simulatedMovementSensor{
+		Named:            conf.ResourceName().AsNamed(),
+		base:             simBase,
+		odometry:         msConf.Odometry,
+		origin:           geo.NewPoint(msConf.OriginLatitude, msConf.OriginLongitude),
+		positionStdDev:   msConf.PositionStdDevM,
+		headingStdDevDeg: msConf.HeadingStdDevDeg,
+		//nolint:gosec
+		rand:   rand.New(rand.NewSource(msConf.RandomSeed)),
+		logger: logger,
+	}, nil
+}
+
+// noise returns a sample of zero mean noise with the standard deviation.
+func (ms *simulatedMovementSensor) noise(stdDev float64) float64 {
+	if stdDev == 0 {
+		return 0
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.rand.NormFloat64() * stdDev
+}
+
+// pose returns where the movement sensor says the base is.
+func (ms *simulatedMovementSensor) pose() pose {
+	truePose, odometryPose, _, _ := ms.base.state()
+	if ms.odometry {
+		return odometryPose
+	}
+	return truePose
+}
+
+func (ms *simulatedMovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	p := ms.pose()
+	// the base starts out facing north, so X is east.
+	east := p.X + ms.noise(ms.positionStdDev)
+	north := p.Y + ms.noise(ms.positionStdDev)
+	bearing := rdkutils.RadToDeg(math.Atan2(east, north))
+	return ms.origin.PointAtDistanceAndBearing(math.Hypot(east, north)*mToKm, bearing), 0, nil
+}
+
+func (ms *simulatedMovementSensor) heading() float64 {
+	return ms.pose().Theta + rdkutils.DegToRad(ms.noise(ms.headingStdDevDeg))
+}
+
+func (ms *simulatedMovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	// the compass heading grows clockwise while theta grows counterclockwise.
+	heading := math.Mod(-rdkutils.RadToDeg(ms.heading()), 360)
+	if heading < 0 {
+		heading += 360
+	}
+	return heading, nil
+}
+
+func (ms *simulatedMovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	return &spatialmath.OrientationVector{OZ: 1, Theta: ms.heading()}, nil
+}
+
+func (ms *simulatedMovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	_, _, linear, _ := ms.base.state()
+	return r3.Vector{Y: linear}, nil
+}
+
+func (ms *simulatedMovementSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	_, _, _, angular := ms.base.state()
+	return spatialmath.AngularVelocity{Z: rdkutils.RadToDeg(angular)}, nil
+}
+
+func (ms *simulatedMovementSensor) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+}
+
+func (ms *simulatedMovementSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	acc := movementsensor.UnimplementedOptionalAccuracies()
+	acc.AccuracyMap = map[string]float32{"position_std_dev_m": float32(ms.positionStdDev)}
+	acc.CompassDegreeError = float32(ms.headingStdDevDeg)
+	return acc, nil
+}
+
+func (ms *simulatedMovementSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:        true,
+		CompassHeadingSupported:  true,
+		OrientationSupported:     true,
+		LinearVelocitySupported:  true,
+		AngularVelocitySupported: true,
+	}, nil
+}
+
+func (ms *simulatedMovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, ms, extra)
+}
@@ -0,0 +1,471 @@
+// Package sim implements a differential drive base which simulates moving over time, and a movement
+// sensor reporting where the simulated base is. Like the simulated arm, time can be advanced
+// explicitly for deterministic tests.
+package sim
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// Model is the name used to refer to the simulated base model.
+var Model = resource.DefaultModelFamily.WithModel("simulated")
+
+const (
+	defaultWidthMm                  = 600
+	defaultWheelCircumferenceMm     = 380
+	defaultMaxLinearMmPerSec        = 500
+	defaultMaxAngularDegsPerSec     = 90
+	defaultLinearMmPerSecPerSec     = 500
+	defaultAngularDegsPerSecPerSec  = 180
+	maxTimeStep                     = 10 * time.Millisecond
+	positionEpsilon                 = 1e-9
+	errStoppedBeforeReachingTarget  = "stopped before reaching target"
+	errInterruptedByAnotherMovement = "interrupted by another movement"
+)
+
+// Config is used for converting config attributes.
+type Config struct {
+	WidthMm              int `json:"width_mm,omitempty"`
+	WheelCircumferenceMm int `json:"wheel_circumference_mm,omitempty"`
+
+	// MaxLinearMmPerSec and MaxAngularDegsPerSec are the velocities of the base at full power.
+	MaxLinearMmPerSec    float64 `json:"max_linear_mm_per_sec,omitempty"`
+	MaxAngularDegsPerSec float64 `json:"max_angular_degs_per_sec,omitempty"`
+
+	// LinearAccelerationMmPerSecPerSec and AngularAccelerationDegsPerSecPerSec limit how quickly the
+	// base speeds up and slows down. Negative values remove the limit.
+	LinearAccelerationMmPerSecPerSec    float64 `json:"linear_acceleration_mm_per_sec_per_sec,omitempty"`
+	AngularAccelerationDegsPerSecPerSec float64 `json:"angular_acceleration_degs_per_sec_per_sec,omitempty"`
+
+	// SlipStdDev is the standard deviation of how far the base slips from where its wheels take it
+	// after moving a meter or turning a radian, which grows with the square root of the distance like
+	// a random walk. The odometry of the base does not see the slip, so it drifts from where the base
+	// really is.
+	SlipStdDev float64 `json:"slip_std_dev,omitempty"`
+	// RandomSeed seeds the noise of the simulation, so that runs can be repeated.
+	RandomSeed int64 `json:"random_seed,omitempty"`
+
+	// SimulateTime controls whether the base spins up and manages a background goroutine moving it
+	// in real time. Otherwise the base only moves when time is advanced explicitly.
+	SimulateTime bool `json:"simulate-time,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.WidthMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("width_mm cannot be negative"))
+	}
+	if conf.WheelCircumferenceMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("wheel_circumference_mm cannot be negative"))
+	}
+	if conf.MaxLinearMmPerSec < 0 || conf.MaxAngularDegsPerSec < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("maximum velocities cannot be negative"))
+	}
+	if conf.SlipStdDev < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("slip_std_dev cannot be negative"))
+	}
+	return nil, nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(base.API, Model, resource.Registration[base.Base, *Config]{
+		Constructor: NewBase,
+	})
+}
+
+// pose is where a base is on the plane it drives on. X is to the right of where the base started
+// and Y ahead of it, in meters. Theta is counterclockwise from Y in radians.
+type pose struct {
+	X, Y, Theta float64
+}
+
+// move moves the pose forward by distance after turning it by angle.
+func (p *pose) move(distance, angle float64) {
+	// turning by half the angle before and after moving approximates the arc driven.
+	p.Theta += angle / 2
+	p.X -= distance * math.Sin(p.Theta)
+	p.Y += distance * math.Cos(p.Theta)
+	p.Theta = math.Mod(p.Theta+angle/2, 2*math.Pi)
+}
+
+// operation is a MoveStraight or Spin in flight. It has the same states as the operations of the
+// simulated arm: in flight, done or stopped.
+type operation struct {
+	// targetDistance is the distance in meters MoveStraight moves, and targetAngle the angle in
+	// radians Spin turns. Their signs are the direction of the movement.
+	targetDistance float64
+	targetAngle    float64
+	// speed is the speed in meters or radians per second the movement is done at.
+	speed     float64
+	traveled  float64
+	done      bool
+	stopped   bool
+	stopError string
+}
+
+func (op *operation) isMoving() bool {
+	return op != nil && !op.done && !op.stopped
+}
+
+// remaining returns how far the operation has left to travel, in meters or radians.
+func (op *operation) remaining() float64 {
+	return math.Abs(op.targetDistance+op.targetAngle) - op.traveled
+}
+
+type simulatedBase struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	widthMeters              float64
+	wheelCircumferenceMeters float64
+	geometries               []spatialmath.Geometry
+	// maximum velocities in m/s and rad/s, and accelerations in m/s^2 and rad/s^2 which are infinite
+	// without a limit.
+	maxLinear           float64
+	maxAngular          float64
+	linearAcceleration  float64
+	angularAcceleration float64
+	slipStdDev          float64
+
+	ctx    context.Context
+	cancel func()
+
+	mu sync.Mutex
+	// truePose is where the base really is, and odometryPose where its wheels say it is.
+	truePose     pose
+	odometryPose pose
+	// linearVelocity and angularVelocity are the velocities of the wheels in m/s and rad/s, and
+	// the target velocities what they are accelerating to.
+	linearVelocity        float64
+	angularVelocity       float64
+	targetLinearVelocity  float64
+	targetAngularVelocity float64
+	operation             *operation
+	rand                  *rand.Rand
+	// `lastUpdated` can be assumed to be initialized to the zero value when not simulating time.
+	lastUpdated time.Time
+
+	timeSimulation *utils.StoppableWorkers
+
+	logger logging.Logger
+}
+
+// NewBase is the `func init` registered constructor intended to be consumed/invoked by the resource
+// graph.
+func NewBase(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (base.Base, error) {
+	baseConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	var geometries []spatialmath.Geometry
+	if conf.Frame != nil && conf.Frame.Geometry != nil {
+		geometry, err := conf.Frame.Geometry.ParseConfig()
+		if err != nil {
+			return nil, err
+		}
+		geometries = append(geometries, geometry)
+	}
+	return newBase(conf.ResourceName().AsNamed(), baseConf, geometries, logger), nil
+}
+
+func newBase(named resource.Named, conf *Config, geometries []spatialmath.Geometry, logger logging.Logger) *simulatedBase {
+	orDefault := func(value, defaultValue float64) float64 {
+		if value == 0 {
+			return defaultValue
+		}
+		return value
+	}
+	acceleration := func(value, defaultValue float64) float64 {
+		switch {
+		case value < 0:
+			return math.Inf(1)
+		case value == 0:
+			return defaultValue
+		default:
+			return value
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &simulatedBase{
+		Named:                    named,
+		widthMeters:              orDefault(float64(conf.WidthMm), defaultWidthMm) / 1000,
+		wheelCircumferenceMeters: orDefault(float64(conf.WheelCircumferenceMm), defaultWheelCircumferenceMm) / 1000,
+		geometries:               geometries,
+		maxLinear:                orDefault(conf.MaxLinearMmPerSec, defaultMaxLinearMmPerSec) / 1000,
+		maxAngular:               rdkutils.DegToRad(orDefault(conf.MaxAngularDegsPerSec, defaultMaxAngularDegsPerSec)),
+		linearAcceleration:       acceleration(conf.LinearAccelerationMmPerSecPerSec, defaultLinearMmPerSecPerSec) / 1000,
+		angularAcceleration: rdkutils.DegToRad(
+			acceleration(conf.AngularAccelerationDegsPerSecPerSec, defaultAngularDegsPerSecPerSec)),
+		slipStdDev: conf.SlipStdDev,
+		ctx:        ctx,
+		cancel:     cancel,
+		//nolint:gosec
+		rand:   rand.New(rand.NewSource(conf.RandomSeed)),
+		logger: logger,
+	}
+
+	if conf.SimulateTime {
+		// When simulating time, avoid ever letting the zero value be visible. Lest the first
+		// movement be unpredictable.
+		b.lastUpdated = time.Now()
+		b.timeSimulation = utils.NewStoppableWorkerWithTicker(maxTimeStep, func(_ context.Context) {
+			b.updateForTime(time.Now())
+		})
+	}
+	return b
+}
+
+// Simulated bases only move when `updateForTime` is called. This can be used by tests for
+// deterministic passage of time. Or can be called by a background goroutine to follow a realtime
+// clock.
+func (sb *simulatedBase) updateForTime(now time.Time) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	elapsed := now.Sub(sb.lastUpdated)
+	sb.lastUpdated = now
+	// step through long gaps between updates so that the base accelerates and turns smoothly.
+	for elapsed > 0 {
+		dt := min(elapsed, maxTimeStep)
+		elapsed -= dt
+		sb.step(dt.Seconds())
+	}
+}
+
+// step moves the base forward by dt seconds. It must be called with the lock held.
+func (sb *simulatedBase) step(dt float64) {
+	targetLinear, targetAngular := sb.targetLinearVelocity, sb.targetAngularVelocity
+	op := sb.operation
+	if op.isMoving() {
+		// slow down in time to stop at the target.
+		accel := sb.linearAcceleration
+		if op.targetAngle != 0 {
+			accel = sb.angularAcceleration
+		}
+		speed := math.Min(op.speed, math.Sqrt(2*accel*op.remaining()))
+		if op.targetDistance != 0 {
+			targetLinear, targetAngular = math.Copysign(speed, op.targetDistance), 0
+		} else {
+			targetLinear, targetAngular = 0, math.Copysign(speed, op.targetAngle)
+		}
+	}
+	sb.linearVelocity = accelerate(sb.linearVelocity, targetLinear, sb.linearAcceleration*dt)
+	sb.angularVelocity = accelerate(sb.angularVelocity, targetAngular, sb.angularAcceleration*dt)
+
+	distance := sb.linearVelocity * dt
+	angle := sb.angularVelocity * dt
+	if op.isMoving() {
+		moved := math.Abs(distance + angle)
+		if moved >= op.remaining()-positionEpsilon {
+			// the base reaches the target during this step.
+			scale := op.remaining() / moved
+			distance *= scale
+			angle *= scale
+			op.traveled += op.remaining()
+			op.done = true
+			sb.linearVelocity, sb.angularVelocity = 0, 0
+		} else {
+			op.traveled += moved
+		}
+	}
+
+	sb.odometryPose.move(distance, angle)
+	if sb.slipStdDev > 0 {
+		distance += sb.rand.NormFloat64() * sb.slipStdDev * math.Sqrt(math.Abs(distance))
+		angle += sb.rand.NormFloat64() * sb.slipStdDev * math.Sqrt(math.Abs(angle))
+	}
+	sb.truePose.move(distance, angle)
+}
+
+// accelerate returns the velocity changed towards the target by at most maxChange.
+func accelerate(velocity, target, maxChange float64) float64 {
+	if math.Abs(target-velocity) <= maxChange {
+		return target
+	}
+	return velocity + math.Copysign(maxChange, target-velocity)
+}
+
+// startOperation starts a MoveStraight or Spin and blocks until it is done or stopped.
+func (sb *simulatedBase) startOperation(ctx context.Context, op *operation) error {
+	sb.mu.Lock()
+	sb.interrupt()
+	sb.targetLinearVelocity, sb.targetAngularVelocity = 0, 0
+	if op.remaining() <= positionEpsilon || op.speed == 0 {
+		op.done = true
+	}
+	sb.operation = op
+	sb.mu.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Command cancelation stops the base, like a real one.
+			sb.mu.Lock()
+			if op.isMoving() {
+				op.stopped = true
+				op.stopError = errStoppedBeforeReachingTarget
+			}
+			sb.mu.Unlock()
+			return ctx.Err()
+		case <-sb.ctx.Done():
+			// `simulatedBase.Close` was called or robot shutdown.
+			return sb.ctx.Err()
+		default:
+		}
+
+		sb.mu.Lock()
+		done, stopped, stopError := op.done, op.stopped, op.stopError
+		sb.mu.Unlock()
+		if done {
+			return nil
+		}
+		if stopped {
+			return errors.New(stopError)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// interrupt stops the operation in flight, if any, because another movement was requested. It must
+// be called with the lock held.
+func (sb *simulatedBase) interrupt() {
+	if sb.operation.isMoving() {
+		sb.operation.stopped = true
+		sb.operation.stopError = errInterruptedByAnotherMovement
+	}
+}
+
+// MoveStraight moves the base the given distance and blocks until it is there.
+func (sb *simulatedBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	distance := float64(distanceMm) / 1000
+	if mmPerSec < 0 {
+		distance = -distance
+	}
+	return sb.startOperation(ctx, &operation{
+		targetDistance: distance,
+		speed:          math.Min(math.Abs(mmPerSec)/1000, sb.maxLinear),
+	})
+}
+
+// Spin turns the base by the given angle and blocks until it has.
+func (sb *simulatedBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	angle := rdkutils.DegToRad(angleDeg)
+	if degsPerSec < 0 {
+		angle = -angle
+	}
+	return sb.startOperation(ctx, &operation{
+		targetAngle: angle,
+		speed:       math.Min(rdkutils.DegToRad(math.Abs(degsPerSec)), sb.maxAngular),
+	})
+}
+
+// SetPower drives the base at the given fractions of its maximum velocities.
+func (sb *simulatedBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	return sb.setVelocity(
+		math.Max(-1, math.Min(1, linear.Y))*sb.maxLinear,
+		math.Max(-1, math.Min(1, angular.Z))*sb.maxAngular,
+	)
+}
+
+// SetVelocity drives the base at the given velocities, within its maximum velocities.
+func (sb *simulatedBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	return sb.setVelocity(
+		math.Max(-sb.maxLinear, math.Min(sb.maxLinear, linear.Y/1000)),
+		math.Max(-sb.maxAngular, math.Min(sb.maxAngular, rdkutils.DegToRad(angular.Z))),
+	)
+}
+
+func (sb *simulatedBase) setVelocity(linear, angular float64) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.interrupt()
+	sb.targetLinearVelocity = linear
+	sb.targetAngularVelocity = angular
+	return nil
+}
+
+// Stop slows the base down to a stop, within its acceleration limits.
+func (sb *simulatedBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.operation.isMoving() {
+		sb.operation.stopped = true
+		sb.operation.stopError = errStoppedBeforeReachingTarget
+	}
+	sb.targetLinearVelocity, sb.targetAngularVelocity = 0, 0
+	return nil
+}
+
+// IsMoving returns whether the base is moving or about to.
+func (sb *simulatedBase) IsMoving(ctx context.Context) (bool, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.operation.isMoving() || sb.linearVelocity != 0 || sb.angularVelocity != 0 ||
+		sb.targetLinearVelocity != 0 || sb.targetAngularVelocity != 0, nil
+}
+
+func (sb *simulatedBase) Properties(ctx context.Context, extra map[string]interface{}) (base.Properties, error) {
+	return base.Properties{
+		WidthMeters:              sb.widthMeters,
+		WheelCircumferenceMeters: sb.wheelCircumferenceMeters,
+	}, nil
+}
+
+func (sb *simulatedBase) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return sb.geometries, nil
+}
+
+// DoCommand supports "get_pose", which returns where the base really is, and "set_pose", which
+// moves it somewhere, both as {"x_m", "y_m", "theta_deg"}. Setting the pose also resets the
+// odometry of the base.
+func (sb *simulatedBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if _, ok := cmd["get_pose"]; ok {
+		return map[string]interface{}{
+			"x_m":       sb.truePose.X,
+			"y_m":       sb.truePose.Y,
+			"theta_deg": rdkutils.RadToDeg(sb.truePose.Theta),
+		}, nil
+	}
+	if p, ok := cmd["set_pose"].(map[string]interface{}); ok {
+		x, _ := p["x_m"].(float64)
+		y, _ := p["y_m"].(float64)
+		theta, _ := p["theta_deg"].(float64)
+		sb.truePose = pose{X: x, Y: y, Theta: rdkutils.DegToRad(theta)}
+		sb.odometryPose = sb.truePose
+		return map[string]interface{}{}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+// state returns where the base really is, where its odometry says it is, and the velocities of its
+// wheels.
+func (sb *simulatedBase) state() (pose, pose, float64, float64) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.truePose, sb.odometryPose, sb.linearVelocity, sb.angularVelocity
+}
+
+func (sb *simulatedBase) Close(ctx context.Context) error {
+	sb.cancel()
+	if sb.timeSimulation != nil {
+		sb.timeSimulation.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package sim
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func newTestBase(t *testing.T, conf *Config) *simulatedBase {
+	t.Helper()
+	b, err := NewBase(context.Background(), nil, resource.Config{
+		Name:                "base",
+		API:                 base.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return b.(*simulatedBase)
+}
+
+// advance moves the time of the base forward until the movement returns, failing the test if it
+// takes longer than the timeout.
+func advance(t *testing.T, b *simulatedBase, moveFuture chan error, timeout time.Duration) error {
+	t.Helper()
+	clock := b.lastUpdated
+	for elapsed := time.Duration(0); elapsed <= timeout; elapsed += 100 * time.Millisecond {
+		clock = clock.Add(100 * time.Millisecond)
+		b.updateForTime(clock)
+		select {
+		case err := <-moveFuture:
+			return err
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	t.Fatal("movement did not finish in time")
+	return nil
+}
+
+func TestMoveStraightAndSpin(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBase(t, &Config{})
+
+	props, err := b.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.WidthMeters, test.ShouldEqual, 0.6)
+	test.That(t, props.TurningRadiusMeters, test.ShouldEqual, 0)
+
+	moveFuture := make(chan error, 1)
+	go func() {
+		moveFuture <- b.MoveStraight(ctx, 1000, 500, nil)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		isMoving, err := b.IsMoving(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, isMoving, test.ShouldBeTrue)
+	})
+
+	// Halfway through accelerating to 500 mm/s at 500 mm/s^2.
+	b.updateForTime(b.lastUpdated.Add(500 * time.Millisecond))
+	truePose, _, linear, _ := b.state()
+	test.That(t, linear, test.ShouldAlmostEqual, 0.25)
+	test.That(t, truePose.Y, test.ShouldAlmostEqual, 0.0625, 0.005)
+
+	// Accelerating and slowing down each take a second and 0.25 m, so the move takes 3 seconds.
+	test.That(t, advance(t, b, moveFuture, 3*time.Second), test.ShouldBeNil)
+	truePose, odometryPose, linear, _ := b.state()
+	test.That(t, truePose.X, test.ShouldAlmostEqual, 0)
+	test.That(t, truePose.Y, test.ShouldAlmostEqual, 1)
+	test.That(t, odometryPose, test.ShouldResemble, truePose)
+	test.That(t, linear, test.ShouldEqual, 0)
+	isMoving, err := b.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, isMoving, test.ShouldBeFalse)
+
+	// Spin left, then drive forward, which is west.
+	go func() {
+		moveFuture <- b.Spin(ctx, 90, 45, nil)
+	}()
+	test.That(t, advance(t, b, moveFuture, 5*time.Second), test.ShouldBeNil)
+	go func() {
+		moveFuture <- b.MoveStraight(ctx, -500, 500, nil)
+	}()
+	test.That(t, advance(t, b, moveFuture, 3*time.Second), test.ShouldBeNil)
+	truePose, _, _, _ = b.state()
+	test.That(t, truePose.Theta, test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, truePose.X, test.ShouldAlmostEqual, 0.5)
+	test.That(t, truePose.Y, test.ShouldAlmostEqual, 1)
+}
+
+func TestSetVelocityAndStop(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBase(t, &Config{MaxLinearMmPerSec: 400})
+	clock := b.lastUpdated
+
+	// Velocities are limited to the maximum.
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: 1000}, r3.Vector{Z: 10}, nil), test.ShouldBeNil)
+	clock = clock.Add(2 * time.Second)
+	b.updateForTime(clock)
+	_, _, linear, angular := b.state()
+	test.That(t, linear, test.ShouldAlmostEqual, 0.4)
+	test.That(t, angular, test.ShouldAlmostEqual, 10*math.Pi/180)
+
+	// Stopping slows down within the acceleration limit.
+	test.That(t, b.Stop(ctx, nil), test.ShouldBeNil)
+	clock = clock.Add(400 * time.Millisecond)
+	b.updateForTime(clock)
+	_, _, linear, _ = b.state()
+	test.That(t, linear, test.ShouldAlmostEqual, 0.2)
+	isMoving, err := b.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, isMoving, test.ShouldBeTrue)
+	clock = clock.Add(time.Second)
+	b.updateForTime(clock)
+	isMoving, err = b.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, isMoving, test.ShouldBeFalse)
+
+	test.That(t, b.SetPower(ctx, r3.Vector{Y: -0.5}, r3.Vector{}, nil), test.ShouldBeNil)
+	clock = clock.Add(time.Second)
+	b.updateForTime(clock)
+	_, _, linear, _ = b.state()
+	test.That(t, linear, test.ShouldAlmostEqual, -0.2)
+
+	// Stopping interrupts a movement in flight.
+	moveFuture := make(chan error, 1)
+	go func() {
+		moveFuture <- b.MoveStraight(ctx, 1000, 100, nil)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		test.That(tb, b.operation.isMoving(), test.ShouldBeTrue)
+	})
+	test.That(t, b.Stop(ctx, nil), test.ShouldBeNil)
+	select {
+	case err := <-moveFuture:
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldEqual, errStoppedBeforeReachingTarget)
+	case <-time.After(time.Second):
+		t.Fatal("MoveStraight did not return after Stop")
+	}
+}
+
+func TestMovementSensor(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBase(t, &Config{SlipStdDev: 0.2, RandomSeed: 1, LinearAccelerationMmPerSecPerSec: -1})
+	deps := resource.Dependencies{base.Named("base"): b}
+
+	newSensor := func(conf *MovementSensorConfig) movementsensor.MovementSensor {
+		ms, err := NewMovementSensor(ctx, deps, resource.Config{
+			Name:                "ms",
+			API:                 movementsensor.API,
+			Model:               Model,
+			ConvertedAttributes: conf,
+		}, logging.NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		return ms
+	}
+	gps := newSensor(&MovementSensorConfig{Base: "base", OriginLatitude: 40, OriginLongitude: -74})
+	odometry := newSensor(&MovementSensorConfig{Base: "base", Odometry: true, OriginLatitude: 40, OriginLongitude: -74})
+
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: 500}, r3.Vector{}, nil), test.ShouldBeNil)
+	b.updateForTime(b.lastUpdated.Add(10 * time.Second))
+
+	linVel, err := gps.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, linVel.Y, test.ShouldAlmostEqual, 0.5)
+
+	// The wheels turned 5 m worth, but the base slipped.
+	truePose, _, _, _ := b.state()
+	point, _, err := gps.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, point.Lng(), test.ShouldAlmostEqual, -74)
+	test.That(t, (point.Lat()-40)*math.Pi/180*6371e3, test.ShouldAlmostEqual, truePose.Y, 0.01)
+	test.That(t, truePose.Y, test.ShouldNotAlmostEqual, 5, 0.01)
+
+	point, _, err = odometry.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, (point.Lat()-40)*math.Pi/180*6371e3, test.ShouldAlmostEqual, 5, 0.01)
+
+	heading, err := gps.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 0)
+
+	_, err = b.DoCommand(ctx, map[string]interface{}{"set_pose": map[string]interface{}{"theta_deg": 30.0}})
+	test.That(t, err, test.ShouldBeNil)
+	heading, err = odometry.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 330)
+	pose, err := b.DoCommand(ctx, map[string]interface{}{"get_pose": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pose["theta_deg"], test.ShouldAlmostEqual, 30)
+}
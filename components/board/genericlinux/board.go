@@ -340,6 +340,7 @@ func (b *Board) createGpioPin(mapping GPIOBoardMapping) *gpioPin {
 	pin := gpioPin{
 		devicePath:           mapping.GPIOChipDev,
 		offset:               uint32(mapping.GPIO),
+		openLine:             openChipLine,
 		logger:               b.logger,
 		startSoftwarePWMChan: &startSoftwarePWMChan,
 	}
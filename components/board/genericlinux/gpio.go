@@ -20,15 +20,49 @@ import (
 
 const noPin = 0xFFFFFFFF // noPin is the uint32 version of -1. A pin with this offset has no GPIO
 
+// gpioLine is an open line on a GPIO chip. It's an interface so that tests can swap in a mock GPIO
+// backend for the character device.
+type gpioLine interface {
+	SetValue(value byte) error
+	Value() (byte, error)
+	Close() error
+}
+
+// openLineFunc opens the line at the offset on the GPIO chip at the device path, as an input or an
+// output.
+type openLineFunc func(devicePath string, offset uint32, isInput bool) (gpioLine, error)
+
+// openChipLine opens a line through the GPIO character device.
+func openChipLine(devicePath string, offset uint32, isInput bool) (gpioLine, error) {
+	chip, err := gpio.OpenChip(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(chip.Close)
+
+	direction := gpio.Output
+	if isInput {
+		direction = gpio.Input
+	}
+
+	// The 0 just means the default output value for this pin is off. We'll set it to the intended
+	// value in Set(), below, if this is an output pin.
+	// NOTE: we could pass in extra flags to configure the pin to be open-source or open-drain, but
+	// we haven't done that yet, and we instead go with whatever the default on the board is.
+	return chip.OpenLine(offset, 0, direction, "viam-gpio")
+}
+
 type gpioPin struct {
-	// These values should both be considered immutable.
+	// These values should all be considered immutable.
 	devicePath string
 	offset     uint32
+	openLine   openLineFunc
 
 	// These values are mutable. Lock the mutex when interacting with them.
-	line                 *gpio.Line
+	line                 gpioLine
 	isInput              bool
 	hwPwm                *pwmDevice // Defined in hw_pwm.go, will be nil for pins that don't support it.
+	hwPwmRunning         bool       // Indicates whether hwPwm is outputting the PWM signal set up on the pin
 	pwmFreqHz            uint
 	pwmDutyCyclePct      float64
 	enableSoftwarePWM    bool      // Indicates whether a software PWM loop should continue running
@@ -63,6 +97,7 @@ func (pin *gpioPin) openGpioFd(isInput bool) error {
 	if pin.hwPwm != nil {
 		// If the pin is currently used by the hardware PWM chip, shut that down before we can open
 		// it for basic GPIO use.
+		pin.hwPwmRunning = false
 		if err := pin.hwPwm.Close(); err != nil {
 			return pin.wrapError(err)
 		}
@@ -73,22 +108,7 @@ func (pin *gpioPin) openGpioFd(isInput bool) error {
 		return nil
 	}
 
-	chip, err := gpio.OpenChip(pin.devicePath)
-	if err != nil {
-		return pin.wrapError(err)
-	}
-	defer utils.UncheckedErrorFunc(chip.Close)
-
-	direction := gpio.Output
-	if pin.isInput {
-		direction = gpio.Input
-	}
-
-	// The 0 just means the default output value for this pin is off. We'll set it to the intended
-	// value in Set(), below, if this is an output pin.
-	// NOTE: we could pass in extra flags to configure the pin to be open-source or open-drain, but
-	// we haven't done that yet, and we instead go with whatever the default on the board is.
-	line, err := chip.OpenLine(pin.offset, 0, direction, "viam-gpio")
+	line, err := pin.openLine(pin.devicePath, pin.offset, pin.isInput)
 	if err != nil {
 		return pin.wrapError(err)
 	}
@@ -124,6 +144,7 @@ func (pin *gpioPin) Set(ctx context.Context, isHigh bool,
 func (pin *gpioPin) setInternal(isHigh bool) (err error) {
 	// if pin is a hw pwm pin, set to either 0% or 100% duty cycle
 	if pin.hwPwm != nil {
+		pin.hwPwmRunning = false
 		var value float64
 		if isHigh {
 			value = 1.0
@@ -196,6 +217,7 @@ func (pin *gpioPin) startSoftwarePWM() error {
 		// We don't have both parameters set up. Stop any PWM loop we might have started previously.
 		pin.enableSoftwarePWM = false
 		if pin.hwPwm != nil {
+			pin.hwPwmRunning = false
 			return pin.hwPwm.Close()
 		}
 		// If we used to have a software PWM loop, we might have stopped the loop while the pin was
@@ -211,11 +233,16 @@ func (pin *gpioPin) startSoftwarePWM() error {
 			}
 			// Shut down any software PWM loop that might be running.
 			pin.enableSoftwarePWM = false
-			return pin.hwPwm.SetPwm(pin.pwmFreqHz, pin.pwmDutyCyclePct)
+			if err := pin.hwPwm.SetPwm(pin.pwmFreqHz, pin.pwmDutyCyclePct); err != nil {
+				return err
+			}
+			pin.hwPwmRunning = true
+			return nil
 		}
 		// Although this pin has hardware PWM support, many PWM chips cannot output signals at
 		// frequencies this low. Stop any hardware PWM, and fall through to using a software PWM
 		// loop below.
+		pin.hwPwmRunning = false
 		if err := pin.hwPwm.Close(); err != nil {
 			return err
 		}
@@ -207,3 +207,27 @@ func (pwm *pwmDevice) Close() error {
 	defer pwm.mu.Unlock()
 	return pwm.wrapError(pwm.unexport())
 }
+
+// UpdatePwm changes the frequency and duty cycle of a line which SetPwm has already started. Unlike
+// SetPwm, it doesn't go through a safe period or zero active duration on the way, so the signal
+// goes straight from the old period to the new one without any missed or extra pulses.
+func (pwm *pwmDevice) UpdatePwm(freqHz uint, dutyCycle float64) (err error) {
+	pwm.mu.Lock()
+	defer pwm.mu.Unlock()
+
+	periodNs := 1e9 / uint64(freqHz)
+	activeDurationNs := uint64(float64(periodNs) * dutyCycle)
+
+	// The active duration can never be longer than the period. When the period gets shorter, the
+	// new active duration has to be written before the period, and when it gets longer, after it.
+	// Rather than keeping track of which it is, write the active duration first, and if that fails,
+	// write it again after the period.
+	durationErr := pwm.writeLine("duty_cycle", activeDurationNs)
+	if err := pwm.writeLine("period", periodNs); err != nil {
+		return pwm.wrapError(err)
+	}
+	if durationErr != nil {
+		return pwm.wrapError(pwm.writeLine("duty_cycle", activeDurationNs))
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+//go:build linux
+
+// Package genericlinux is for Linux boards. This particular file is for outputting trains of
+// precisely timed pulses on GPIO pins, with hardware PWM where the pin supports it and with a
+// realtime priority thread toggling the pin otherwise.
+package genericlinux
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"golang.org/x/sys/unix"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+)
+
+// realtimePriority is the SCHED_FIFO priority of the threads which output pulses. It's below the
+// default priority (50) of the kernel's threads for interrupts, so that we never hold up the
+// hardware we're trying to talk to.
+const realtimePriority = 40
+
+// maxRealtimeSleep is the longest a realtime thread sleeps before checking whether its context is
+// done.
+const maxRealtimeSleep = 10 * time.Millisecond
+
+// setRealtimePriority locks the calling goroutine to its OS thread, and asks the kernel to run that
+// thread ahead of everything at normal priority, so that it wakes up when it's supposed to rather
+// than when the scheduler gets around to it. It returns whether the thread got realtime priority.
+// The thread is never unlocked: when the goroutine exits, Go throws the thread away rather than
+// reusing it at realtime priority for something else.
+func setRealtimePriority(logger logging.Logger) bool {
+	runtime.LockOSThread()
+	attr := &unix.SchedAttr{
+		Size:     unix.SizeofSchedAttr,
+		Policy:   unix.SCHED_FIFO,
+		Priority: realtimePriority,
+	}
+	if err := unix.SchedSetAttr(0, attr, 0); err != nil {
+		// This needs root or CAP_SYS_NICE. Without it, everything still works, with more jitter.
+		logger.Debugf("Cannot use realtime priority for GPIO pulses, timing will be less precise: %s", err)
+		return false
+	}
+	return true
+}
+
+// sleepUntil sleeps until an absolute time, so that the errors in each sleep don't add up over a
+// train of pulses. If the time has already passed, it returns right away. A thread at realtime
+// priority is woken up by the kernel on time, so it sleeps in the kernel rather than busy-waiting
+// like accurateSleep, which would keep everything else on its CPU from running.
+func sleepUntil(ctx context.Context, deadline time.Time, isRealtime bool) bool {
+	if !isRealtime {
+		return accurateSleep(ctx, time.Until(deadline))
+	}
+	for {
+		if ctx.Err() != nil {
+			return false
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return true
+		}
+		timespec := unix.NsecToTimespec(int64(min(remaining, maxRealtimeSleep)))
+		// An interrupted sleep just goes around again.
+		//nolint:errcheck
+		unix.Nanosleep(&timespec, nil)
+	}
+}
+
+// nextEdge returns when the edge of a pulse after the one due at deadline should happen, duration
+// later. Edges are timed from when they were due rather than when they happened, so that small
+// errors in when the thread wakes up don't add up over a train of pulses. But once the thread has
+// fallen behind by more than a quarter of duration, the next edge is timed from now instead:
+// catching up by outputting the edges back to back would make the pulses faster than asked for,
+// which stalls stepper motors.
+func nextEdge(deadline time.Time, duration time.Duration) time.Time {
+	if now := time.Now(); now.Sub(deadline) > duration/4 {
+		deadline = now
+	}
+	return deadline.Add(duration)
+}
+
+// OutputPulses implements board.PulseGenerator. Pins with hardware PWM output the pulses as a PWM
+// signal whose frequency and duty cycle follow the pulses. Other pins are toggled by a thread at
+// realtime priority. Either way, the pin is not available for anything else until the pulses are
+// done, and then any PWM signal set up on the pin beforehand starts again.
+func (pin *gpioPin) OutputPulses(ctx context.Context, next func() (board.Pulse, bool)) error {
+	// The pulses are output from their own goroutine, since the thread it runs on gets realtime
+	// priority for good.
+	result := make(chan error, 1)
+	go func() {
+		isRealtime := setRealtimePriority(pin.logger)
+
+		pin.mu.Lock()
+		defer pin.mu.Unlock()
+
+		restorePWM := pin.enableSoftwarePWM || pin.hwPwmRunning
+		// Shut down any software PWM loop that might be running.
+		pin.enableSoftwarePWM = false
+		var err error
+		if pin.hwPwm != nil {
+			err = pin.hwPulses(ctx, next, isRealtime)
+		} else {
+			err = pin.softwarePulses(ctx, next, isRealtime)
+		}
+		if restorePWM {
+			err = multierr.Combine(err, pin.startSoftwarePWM())
+		}
+		result <- err
+	}()
+	return <-result
+}
+
+// softwarePulses toggles the pin for each pulse. Lock the mutex before calling this!
+func (pin *gpioPin) softwarePulses(ctx context.Context, next func() (board.Pulse, bool), isRealtime bool) (err error) {
+	if pin.offset == noPin {
+		return errors.New("cannot output pulses on non-GPIO pin")
+	}
+	if err := pin.openGpioFd( /* isInput= */ false); err != nil {
+		return err
+	}
+	defer func() {
+		// Whatever happens, don't leave the pin high.
+		if lowErr := pin.line.SetValue(0); err == nil {
+			err = pin.wrapError(lowErr)
+		}
+	}()
+
+	deadline := time.Now()
+	for {
+		pulse, ok := next()
+		if !ok {
+			return nil
+		}
+		if err := pin.line.SetValue(1); err != nil {
+			return pin.wrapError(err)
+		}
+		deadline = nextEdge(deadline, pulse.High)
+		if !sleepUntil(ctx, deadline, isRealtime) {
+			return ctx.Err()
+		}
+		if err := pin.line.SetValue(0); err != nil {
+			return pin.wrapError(err)
+		}
+		deadline = nextEdge(deadline, pulse.Low)
+		if !sleepUntil(ctx, deadline, isRealtime) {
+			return ctx.Err()
+		}
+	}
+}
+
+// hwPulses outputs the pulses as a PWM signal, changing its frequency and duty cycle whenever the
+// pulses change. The PWM chip times each pulse, and we only time when to change them. Lock the
+// mutex before calling this!
+func (pin *gpioPin) hwPulses(ctx context.Context, next func() (board.Pulse, bool), isRealtime bool) (err error) {
+	if err := pin.closeGpioFd(); err != nil {
+		return err
+	}
+	defer func() {
+		// Turn the PWM signal back off.
+		pin.hwPwmRunning = false
+		if closeErr := pin.hwPwm.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var freqHz uint
+	var dutyCycle float64
+	deadline := time.Now()
+	for {
+		pulse, ok := next()
+		if !ok {
+			return nil
+		}
+		period := pulse.High + pulse.Low
+		if period <= 0 {
+			return errors.New("pulses must have a positive length")
+		}
+		newFreqHz := uint(math.Round(float64(time.Second) / float64(period)))
+		newDutyCycle := float64(pulse.High) / float64(period)
+		switch {
+		case newFreqHz < 1:
+			return errors.New("pulses on hardware PWM pins cannot be longer than a second")
+		case freqHz == 0:
+			// The first pulse starts the signal.
+			if err := pin.hwPwm.SetPwm(newFreqHz, newDutyCycle); err != nil {
+				return err
+			}
+		case newFreqHz != freqHz || newDutyCycle != dutyCycle:
+			if err := pin.hwPwm.UpdatePwm(newFreqHz, newDutyCycle); err != nil {
+				return err
+			}
+		}
+		freqHz, dutyCycle = newFreqHz, newDutyCycle
+
+		deadline = nextEdge(deadline, period)
+		if !sleepUntil(ctx, deadline, isRealtime) {
+			return ctx.Err()
+		}
+	}
+}
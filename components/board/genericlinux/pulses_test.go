@@ -0,0 +1,190 @@
+//go:build linux
+
+package genericlinux
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+)
+
+// edge is a mock GPIO line changing its value.
+type edge struct {
+	value byte
+	at    time.Time
+}
+
+// mockLine is a GPIO line which records its edges instead of touching hardware.
+type mockLine struct {
+	mu    sync.Mutex
+	value byte
+	edges []edge
+}
+
+func (line *mockLine) SetValue(value byte) error {
+	line.mu.Lock()
+	defer line.mu.Unlock()
+	if value != line.value {
+		line.edges = append(line.edges, edge{value: value, at: time.Now()})
+	}
+	line.value = value
+	return nil
+}
+
+func (line *mockLine) Value() (byte, error) {
+	line.mu.Lock()
+	defer line.mu.Unlock()
+	return line.value, nil
+}
+
+func (line *mockLine) Close() error {
+	return nil
+}
+
+func newMockPin(t *testing.T, mapping GPIOBoardMapping) (*gpioPin, *mockLine) {
+	t.Helper()
+	b := &Board{logger: logging.NewTestLogger(t)}
+	pin := b.createGpioPin(mapping)
+	line := &mockLine{}
+	pin.openLine = func(devicePath string, offset uint32, isInput bool) (gpioLine, error) {
+		return line, nil
+	}
+	t.Cleanup(func() {
+		test.That(t, pin.Close(), test.ShouldBeNil)
+	})
+	return pin, line
+}
+
+// pulses returns a function outputting the pulses, as OutputPulses wants.
+func pulses(count int, pulse board.Pulse) func() (board.Pulse, bool) {
+	output := 0
+	return func() (board.Pulse, bool) {
+		output++
+		return pulse, output <= count
+	}
+}
+
+func TestSoftwarePulses(t *testing.T) {
+	ctx := context.Background()
+	pin, line := newMockPin(t, GPIOBoardMapping{GPIOChipDev: "mock", GPIO: 1})
+
+	pulse := board.Pulse{High: time.Millisecond, Low: 3 * time.Millisecond}
+	start := time.Now()
+	test.That(t, pin.OutputPulses(ctx, pulses(10, pulse)), test.ShouldBeNil)
+	elapsed := time.Since(start)
+
+	line.mu.Lock()
+	edges := line.edges
+	line.mu.Unlock()
+	test.That(t, edges, test.ShouldHaveLength, 20)
+	for i, e := range edges {
+		test.That(t, e.value, test.ShouldEqual, 1-i%2)
+	}
+	// The pulses are timed from when they should have started rather than when the last one
+	// ended, so they take as long as they add up to, give or take the jitter of the last one.
+	test.That(t, elapsed, test.ShouldBeGreaterThanOrEqualTo, 40*time.Millisecond)
+	test.That(t, elapsed, test.ShouldBeLessThan, 100*time.Millisecond)
+	test.That(t, edges[19].at.Sub(edges[0].at), test.ShouldBeGreaterThan, 36*time.Millisecond)
+
+	// Cancelling the pulses leaves the pin low.
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err := pin.OutputPulses(cancelCtx, func() (board.Pulse, bool) {
+		return board.Pulse{High: 5 * time.Millisecond, Low: time.Millisecond}, true
+	})
+	test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+	value, err := line.Value()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 0)
+
+	// Software PWM stops while the pulses are output, and starts again once they are done.
+	test.That(t, pin.SetPWMFreq(ctx, 100, nil), test.ShouldBeNil)
+	test.That(t, pin.SetPWM(ctx, 0.5, nil), test.ShouldBeNil)
+	test.That(t, pin.OutputPulses(ctx, pulses(1, pulse)), test.ShouldBeNil)
+	pin.mu.Lock()
+	test.That(t, pin.enableSoftwarePWM, test.ShouldBeTrue)
+	pin.mu.Unlock()
+
+	nonGPIO, _ := newMockPin(t, GPIOBoardMapping{GPIOChipDev: "mock", GPIO: -1})
+	test.That(t, nonGPIO.OutputPulses(ctx, pulses(1, pulse)), test.ShouldNotBeNil)
+}
+
+func TestSoftwarePulsesFallingBehind(t *testing.T) {
+	pin, line := newMockPin(t, GPIOBoardMapping{GPIOChipDev: "mock", GPIO: 1})
+
+	// The thread is held up for several pulses in the middle of the train.
+	pulse := board.Pulse{High: time.Millisecond, Low: time.Millisecond}
+	output := 0
+	err := pin.OutputPulses(context.Background(), func() (board.Pulse, bool) {
+		output++
+		if output == 4 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return pulse, output <= 10
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	line.mu.Lock()
+	edges := line.edges
+	line.mu.Unlock()
+	test.That(t, edges, test.ShouldHaveLength, 20)
+	// The pulses that were missed are not output back to back to catch up.
+	for i := 1; i < len(edges); i++ {
+		test.That(t, edges[i].at.Sub(edges[i-1].at), test.ShouldBeGreaterThanOrEqualTo, 700*time.Microsecond)
+	}
+}
+
+func readSysfs(t *testing.T, path string) string {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	test.That(t, err, test.ShouldBeNil)
+	return string(contents)
+}
+
+func TestHardwarePulses(t *testing.T) {
+	ctx := context.Background()
+	// The PWM chip is a directory of pseudofiles, as in sysfs, with the line already exported.
+	chipPath := t.TempDir()
+	linePath := filepath.Join(chipPath, "pwm0")
+	test.That(t, os.Mkdir(linePath, 0o700), test.ShouldBeNil)
+	pin, line := newMockPin(t, GPIOBoardMapping{
+		GPIOChipDev: "mock", GPIO: 1, HWPWMSupported: true, PWMSysFsDir: chipPath, PWMID: 0,
+	})
+
+	// The pulses speed up from 100Hz to 500Hz.
+	output := 0
+	err := pin.OutputPulses(ctx, func() (board.Pulse, bool) {
+		output++
+		if output <= 2 {
+			return board.Pulse{High: 5 * time.Millisecond, Low: 5 * time.Millisecond}, true
+		}
+		return board.Pulse{High: time.Millisecond, Low: time.Millisecond}, output <= 4
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, readSysfs(t, filepath.Join(linePath, "period")), test.ShouldEqual, "2000000")
+	test.That(t, readSysfs(t, filepath.Join(linePath, "duty_cycle")), test.ShouldEqual, "1000000")
+	// The PWM signal is off once the pulses are done, and the GPIO line was never touched.
+	test.That(t, readSysfs(t, filepath.Join(linePath, "enable")), test.ShouldEqual, "0")
+	test.That(t, readSysfs(t, filepath.Join(chipPath, "unexport")), test.ShouldEqual, "0")
+	line.mu.Lock()
+	test.That(t, line.edges, test.ShouldBeEmpty)
+	line.mu.Unlock()
+
+	// A PWM signal set up before the pulses starts again once they are done.
+	test.That(t, pin.SetPWMFreq(ctx, 50, nil), test.ShouldBeNil)
+	test.That(t, pin.SetPWM(ctx, 0.25, nil), test.ShouldBeNil)
+	test.That(t, pin.OutputPulses(ctx, pulses(2, board.Pulse{High: time.Millisecond, Low: time.Millisecond})),
+		test.ShouldBeNil)
+	test.That(t, readSysfs(t, filepath.Join(linePath, "enable")), test.ShouldEqual, "1")
+	test.That(t, readSysfs(t, filepath.Join(linePath, "period")), test.ShouldEqual, "20000000")
+	test.That(t, readSysfs(t, filepath.Join(linePath, "duty_cycle")), test.ShouldEqual, "5000000")
+}
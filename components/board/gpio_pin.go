@@ -3,6 +3,7 @@ package board
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -108,6 +109,35 @@ type GPIOPin interface {
 	SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error
 }
 
+// A Pulse is a GPIO pin going high for a while and then low for a while.
+type Pulse struct {
+	High time.Duration
+	Low  time.Duration
+}
+
+// A PulseGenerator is a GPIOPin which can output trains of precisely timed pulses, such as the steps
+// of a stepper motor or the position of a servo, with less jitter than setting the pin high and low
+// itself. Boards implement it on their pins where they can.
+//
+// OutputPulses example:
+//
+//	pin, err := myBoard.GPIOPinByName("15")
+//	generator, ok := pin.(board.PulseGenerator)
+//
+//	// Output 200 pulses of 1ms each.
+//	steps := 0
+//	err = generator.OutputPulses(context.Background(), func() (board.Pulse, bool) {
+//		steps++
+//		return board.Pulse{High: 500 * time.Microsecond, Low: 500 * time.Microsecond}, steps <= 200
+//	})
+type PulseGenerator interface {
+	// OutputPulses outputs pulses until next returns false, the context is cancelled, or there is
+	// an error, and then leaves the pin low, or outputting the PWM signal it had before. next is
+	// called just before each pulse is output, so each call after the first means the pulse before
+	// it is done. It is called in time critical code, so it must return quickly.
+	OutputPulses(ctx context.Context, next func() (Pulse, bool)) error
+}
+
 // ValidatePWMDutyCycle makes sure the value passed in is a believable duty cycle value. It returns
 // the preferred duty cycle value if it is, and an error if it is not.
 func ValidatePWMDutyCycle(dutyCyclePct float64) (float64, error) {
@@ -0,0 +1,124 @@
+package sim
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// target is a geometry the camera can see, in the frame of the camera.
+type target struct {
+	name     string
+	geometry spatialmath.Geometry
+	color    color.NRGBA
+}
+
+func newTarget(name string, geometry spatialmath.Geometry) target {
+	// every target gets its own color, the same one each time, so that images are easy to segment.
+	hash := fnv.New32a()
+	//nolint:errcheck
+	hash.Write([]byte(name))
+	sum := hash.Sum32()
+	return target{
+		name:     name,
+		geometry: geometry,
+		color:    color.NRGBA{R: uint8(64 + sum%192), G: uint8(64 + (sum>>8)%192), B: uint8(64 + (sum>>16)%192), A: 255},
+	}
+}
+
+// frame is what the camera saw: an image with each target in its color, the depth of each pixel
+// in mm, zero where nothing was seen, and the points seen in the frame of the camera.
+type frame struct {
+	color *image.NRGBA
+	depth *rimage.DepthMap
+	cloud pointcloud.PointCloud
+}
+
+// depthRange is the closest and farthest the camera can see, in mm.
+type depthRange struct {
+	min, max float64
+}
+
+// render ray-casts the targets through each pixel of the camera. The camera looks along +Z, with
+// +X to the right of the image and +Y down it. The noise function returns noise to add to the
+// depth of each pixel which sees a target.
+func render(
+	intrinsics *transform.PinholeCameraIntrinsics, targets []target, depths depthRange, noise func() float64,
+) (*frame, error) {
+	width, height := intrinsics.Width, intrinsics.Height
+	hits := make([]int, width*height)
+	distances := make([]float64, width*height)
+
+	// rows are cast in parallel, since every pixel checks every target.
+	rows := make(chan int, height)
+	for y := 0; y < height; y++ {
+		rows <- y
+	}
+	close(rows)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				for x := 0; x < width; x++ {
+					hits[y*width+x], distances[y*width+x] = cast(intrinsics, targets, x, y)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	f := &frame{
+		color: image.NewNRGBA(image.Rect(0, 0, width, height)),
+		depth: rimage.NewEmptyDepthMap(width, height),
+		cloud: pointcloud.NewBasicEmpty(),
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			hit := hits[y*width+x]
+			if hit < 0 {
+				continue
+			}
+			z := distances[y*width+x] + noise()
+			if z < depths.min || z > depths.max || z > float64(rimage.MaxDepth) {
+				continue
+			}
+			f.color.SetNRGBA(x, y, targets[hit].color)
+			f.depth.Set(x, y, rimage.Depth(math.Round(z)))
+			px, py, pz := intrinsics.PixelToPoint(float64(x), float64(y), z)
+			if err := f.cloud.Set(r3.Vector{X: px, Y: py, Z: pz}, pointcloud.NewColoredData(targets[hit].color)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
+
+// cast returns the index of the nearest target seen through the pixel, or -1 if there is none, and
+// its depth along Z.
+func cast(intrinsics *transform.PinholeCameraIntrinsics, targets []target, x, y int) (int, float64) {
+	dx, dy, dz := intrinsics.PixelToPoint(float64(x), float64(y), 1)
+	ray := r3.Vector{X: dx, Y: dy, Z: dz}
+	norm := ray.Norm()
+	direction := ray.Mul(1 / norm)
+
+	nearest, nearestDist := -1, math.Inf(1)
+	for i, t := range targets {
+		if dist, ok := spatialmath.RayIntersection(t.geometry, r3.Vector{}, direction); ok && dist < nearestDist {
+			nearest, nearestDist = i, dist
+		}
+	}
+	// the depth of a pixel is along Z rather than the ray.
+	return nearest, nearestDist / norm
+}
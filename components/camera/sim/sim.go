@@ -0,0 +1,431 @@
+// Package sim implements a simulated depth camera, which sees the geometries of the frame system.
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/worldstatestore"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the name used to refer to the simulated camera model.
+var Model = resource.DefaultModelFamily.WithModel("simulated")
+
+const (
+	defaultWidthPx           = 640
+	defaultHeightPx          = 480
+	defaultHorizontalFOVDegs = 60
+	defaultMaxDepthMm        = 10000
+)
+
+// Config is used for converting the config attributes of the simulated camera.
+type Config struct {
+	// IntrinsicParams of the camera. Without them, the camera is WidthPx by HeightPx with a
+	// horizontal field of view of HorizontalFOVDegs and square pixels.
+	IntrinsicParams   *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
+	WidthPx           int                                `json:"width_px,omitempty"`
+	HeightPx          int                                `json:"height_px,omitempty"`
+	HorizontalFOVDegs float64                            `json:"horizontal_fov_degs,omitempty"`
+	// MinDepthMm and MaxDepthMm are the closest and farthest the camera can see.
+	MinDepthMm float64 `json:"min_depth_mm,omitempty"`
+	MaxDepthMm float64 `json:"max_depth_mm,omitempty"`
+	// DepthStdDevMm is the standard deviation of the noise added to each depth the camera sees.
+	DepthStdDevMm float64 `json:"depth_std_dev_mm,omitempty"`
+	// RandomSeed seeds the noise, so that runs can be repeated.
+	RandomSeed int64 `json:"random_seed,omitempty"`
+
+	// Obstacles are geometries in the world frame for the camera to see, on top of the geometries
+	// of the frame system.
+	Obstacles []spatialmath.GeometryConfig `json:"obstacles,omitempty"`
+	// Components whose geometries the camera sees too, for components with geometries which are
+	// not in the frame system.
+	Components []string `json:"components,omitempty"`
+	// WorldStateStore is a world state store service whose geometries the camera sees too.
+	WorldStateStore string `json:"world_state_store,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.IntrinsicParams != nil {
+		if err := conf.IntrinsicParams.CheckValid(); err != nil {
+			return nil, nil, resource.NewConfigValidationError(path, err)
+		}
+	}
+	if conf.WidthPx < 0 || conf.HeightPx < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("width_px and height_px cannot be negative"))
+	}
+	if conf.HorizontalFOVDegs < 0 || conf.HorizontalFOVDegs >= 180 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("horizontal_fov_degs must be between 0 and 180"))
+	}
+	if conf.MinDepthMm < 0 || conf.MaxDepthMm < 0 || conf.DepthStdDevMm < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("depths and standard deviations cannot be negative"))
+	}
+	if conf.MaxDepthMm > 0 && conf.MaxDepthMm <= conf.MinDepthMm {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("max_depth_mm must be more than min_depth_mm"))
+	}
+	if _, err := parseObstacles(conf.Obstacles); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+
+	deps := append([]string{framesystem.InternalServiceName.String()}, conf.Components...)
+	if conf.WorldStateStore != "" {
+		deps = append(deps, worldstatestore.Named(conf.WorldStateStore).String())
+	}
+	return deps, nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(camera.API, Model, resource.Registration[camera.Camera, *Config]{
+		Constructor: NewCamera,
+	})
+}
+
+type simulatedCamera struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	intrinsics    *transform.PinholeCameraIntrinsics
+	depths        depthRange
+	depthStdDevMm float64
+	obstacles     []target
+
+	fsService       framesystem.Service
+	components      map[string]resource.Shaped
+	worldStateStore worldstatestore.Service
+
+	mu   sync.Mutex
+	rand *rand.Rand
+
+	logger logging.Logger
+}
+
+// NewCamera returns a camera which ray-casts the geometries of the frame system from where the
+// camera is in it.
+func NewCamera(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (camera.Camera, error) {
+	camConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Modules only have the frame system under its public name.
+	fsService, err := resource.FromProvider[framesystem.Service](deps, framesystem.InternalServiceName)
+	if err != nil {
+		fsService, err = framesystem.FromDependencies(deps)
+	}
+	if err != nil {
+		logger.CWarnw(ctx, "frame system unavailable, the camera is at the world origin and only sees its obstacles", "error", err)
+		fsService = nil
+	}
+
+	components := make(map[string]resource.Shaped, len(camConf.Components))
+	for name, dep := range deps {
+		if !slices.Contains(camConf.Components, name.Name) {
+			continue
+		}
+		shaped, ok := dep.(resource.Shaped)
+		if !ok {
+			return nil, errors.Errorf("%q has no geometries", name.Name)
+		}
+		components[name.Name] = shaped
+	}
+
+	var worldStateStore worldstatestore.Service
+	if camConf.WorldStateStore != "" {
+		worldStateStore, err = worldstatestore.FromProvider(deps, camConf.WorldStateStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newCamera(conf.ResourceName().AsNamed(), camConf, fsService, components, worldStateStore, logger)
+}
+
+func newCamera(
+	named resource.Named, conf *Config, fsService framesystem.Service, components map[string]resource.Shaped,
+	worldStateStore worldstatestore.Service, logger logging.Logger,
+) (*simulatedCamera, error) {
+	intrinsics := conf.IntrinsicParams
+	if intrinsics == nil {
+		intrinsics = intrinsicsFromFOV(conf.WidthPx, conf.HeightPx, conf.HorizontalFOVDegs)
+	}
+	depths := depthRange{min: conf.MinDepthMm, max: conf.MaxDepthMm}
+	if depths.max == 0 {
+		depths.max = defaultMaxDepthMm
+	}
+	obstacles, err := parseObstacles(conf.Obstacles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &simulatedCamera{
+		Named:           named,
+		intrinsics:      intrinsics,
+		depths:          depths,
+		depthStdDevMm:   conf.DepthStdDevMm,
+		obstacles:       obstacles,
+		fsService:       fsService,
+		components:      components,
+		worldStateStore: worldStateStore,
+		//nolint:gosec
+		rand:   rand.New(rand.NewSource(conf.RandomSeed)),
+		logger: logger,
+	}, nil
+}
+
+// intrinsicsFromFOV returns the intrinsics of an ideal camera with square pixels.
+func intrinsicsFromFOV(width, height int, horizontalFOVDegs float64) *transform.PinholeCameraIntrinsics {
+	if width == 0 {
+		width = defaultWidthPx
+	}
+	if height == 0 {
+		height = defaultHeightPx
+	}
+	if horizontalFOVDegs == 0 {
+		horizontalFOVDegs = defaultHorizontalFOVDegs
+	}
+	focal := float64(width) / 2 / math.Tan(utils.DegToRad(horizontalFOVDegs)/2)
+	return &transform.PinholeCameraIntrinsics{
+		Width:  width,
+		Height: height,
+		Fx:     focal,
+		Fy:     focal,
+		Ppx:    float64(width) / 2,
+		Ppy:    float64(height) / 2,
+	}
+}
+
+func parseObstacles(configs []spatialmath.GeometryConfig) ([]target, error) {
+	obstacles := make([]target, 0, len(configs))
+	for i, conf := range configs {
+		geometry, err := conf.ParseConfig()
+		if err != nil {
+			return nil, fmt.Errorf("obstacle %d: %w", i, err)
+		}
+		name := geometry.Label()
+		if name == "" {
+			name = fmt.Sprintf("obstacle %d", i)
+		}
+		obstacles = append(obstacles, newTarget(name, geometry))
+	}
+	return obstacles, nil
+}
+
+// noise returns a sample of zero mean noise with the standard deviation of the depths.
+func (c *simulatedCamera) noise() float64 {
+	if c.depthStdDevMm == 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.NormFloat64() * c.depthStdDevMm
+}
+
+// scene returns everything the camera can see, where it is now, in the frame of the camera.
+func (c *simulatedCamera) scene(ctx context.Context) ([]target, error) {
+	var fs *referenceframe.FrameSystem
+	var inputs *referenceframe.LinearInputs
+	if c.fsService != nil {
+		var err error
+		fs, err = framesystem.NewFromService(ctx, c.fsService, nil)
+		if err != nil {
+			return nil, err
+		}
+		currentInputs, err := c.fsService.CurrentInputs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		inputs = currentInputs.ToLinearInputs()
+	}
+
+	// toWorld returns geometries in the world frame. Frames which are not in the frame system are
+	// taken to be the world frame.
+	toWorld := func(gif *referenceframe.GeometriesInFrame) ([]spatialmath.Geometry, error) {
+		if fs == nil || fs.Frame(gif.Parent()) == nil {
+			return gif.Geometries(), nil
+		}
+		inWorld, err := fs.Transform(inputs, gif, referenceframe.World)
+		if err != nil {
+			return nil, err
+		}
+		return inWorld.(*referenceframe.GeometriesInFrame).Geometries(), nil
+	}
+	targets := append([]target{}, c.obstacles...)
+	addTargets := func(name string, gif *referenceframe.GeometriesInFrame) error {
+		geometries, err := toWorld(gif)
+		if err != nil {
+			return err
+		}
+		for _, geometry := range geometries {
+			label := geometry.Label()
+			if label == "" {
+				label = name
+			}
+			targets = append(targets, newTarget(label, geometry))
+		}
+		return nil
+	}
+
+	cameraPose := spatialmath.NewZeroPose()
+	name := c.Name().ShortName()
+	if fs != nil {
+		if fs.Frame(name) != nil {
+			inWorld, err := fs.Transform(inputs, referenceframe.NewZeroPoseInFrame(name), referenceframe.World)
+			if err != nil {
+				return nil, err
+			}
+			cameraPose = inWorld.(*referenceframe.PoseInFrame).Pose()
+		}
+
+		geometries, err := referenceframe.FrameSystemGeometriesLinearInputs(fs, inputs)
+		if err != nil {
+			return nil, err
+		}
+		for frameName, gif := range geometries {
+			// The camera does not see itself.
+			if frameName == name || frameName == name+"_origin" {
+				continue
+			}
+			if err := addTargets(frameName, gif); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for componentName, component := range c.components {
+		geometries, err := component.Geometries(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := addTargets(componentName, referenceframe.NewGeometriesInFrame(componentName, geometries)); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.worldStateStore != nil {
+		uuids, err := c.worldStateStore.ListUUIDs(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, uuid := range uuids {
+			tf, err := c.worldStateStore.GetTransform(ctx, uuid, nil)
+			if err != nil {
+				return nil, err
+			}
+			link, err := referenceframe.LinkInFrameFromTransformProtobuf(tf)
+			if err != nil {
+				return nil, err
+			}
+			if link.Geometry() == nil {
+				continue
+			}
+			// Geometries in a frame are taken to have been moved by the frame already, which the pose
+			// of the transform has not, so it is moved as a pose instead.
+			pose := link.Pose()
+			if fs != nil && fs.Frame(link.Parent()) != nil {
+				inWorld, err := fs.Transform(inputs, link.PoseInFrame, referenceframe.World)
+				if err != nil {
+					return nil, err
+				}
+				pose = inWorld.(*referenceframe.PoseInFrame).Pose()
+			}
+			name := link.Geometry().Label()
+			if name == "" {
+				name = link.Name()
+			}
+			targets = append(targets, newTarget(name, link.Geometry().Transform(pose)))
+		}
+	}
+
+	toCamera := spatialmath.PoseInverse(cameraPose)
+	for i := range targets {
+		targets[i].geometry = targets[i].geometry.Transform(toCamera)
+	}
+	return targets, nil
+}
+
+func (c *simulatedCamera) render(ctx context.Context) (*frame, error) {
+	targets, err := c.scene(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return render(c.intrinsics, targets, c.depths, c.noise)
+}
+
+// Images returns the geometries the camera sees, each in its own color, and their depths.
+func (c *simulatedCamera) Images(
+	ctx context.Context, filterSourceNames []string, extra map[string]interface{},
+) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	validSourceNames := []string{"color", "depth"}
+	for _, name := range filterSourceNames {
+		if !slices.Contains(validSourceNames, name) {
+			return nil, resource.ResponseMetadata{}, fmt.Errorf("invalid source name: %s", name)
+		}
+	}
+
+	f, err := c.render(ctx)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	ts := time.Now()
+
+	imgs := []camera.NamedImage{}
+	if len(filterSourceNames) == 0 || slices.Contains(filterSourceNames, "color") {
+		namedImg, err := camera.NamedImageFromImage(f.color, "color", utils.MimeTypePNG, data.Annotations{})
+		if err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+		imgs = append(imgs, namedImg)
+	}
+	if len(filterSourceNames) == 0 || slices.Contains(filterSourceNames, "depth") {
+		namedImg, err := camera.NamedImageFromImage(f.depth, "depth", utils.MimeTypeRawDepth, data.Annotations{})
+		if err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+		imgs = append(imgs, namedImg)
+	}
+	return imgs, resource.ResponseMetadata{CapturedAt: ts}, nil
+}
+
+// NextPointCloud returns the points the camera sees, in the frame of the camera.
+func (c *simulatedCamera) NextPointCloud(ctx context.Context, extra map[string]interface{}) (pointcloud.PointCloud, error) {
+	f, err := c.render(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.cloud, nil
+}
+
+// Properties returns the intrinsics of the camera, which sees depths.
+func (c *simulatedCamera) Properties(ctx context.Context) (camera.Properties, error) {
+	return camera.Properties{
+		SupportsPCD:     true,
+		ImageType:       camera.DepthStream,
+		IntrinsicParams: c.intrinsics,
+		MimeTypes:       []string{utils.MimeTypePNG, utils.MimeTypeRawDepth},
+	}, nil
+}
+
+// Geometries returns nothing, since the camera does not see itself.
+func (c *simulatedCamera) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return make([]spatialmath.Geometry, 0), nil
+}
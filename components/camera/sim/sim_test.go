@@ -0,0 +1,191 @@
+package sim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// wall is a box 500mm in front of a camera at the origin, which only covers the middle of the image.
+var wall = spatialmath.GeometryConfig{
+	Type:              spatialmath.BoxType,
+	X:                 400,
+	Y:                 400,
+	Z:                 10,
+	TranslationOffset: r3.Vector{Z: 500},
+	Label:             "wall",
+}
+
+func newTestCamera(t *testing.T, conf *Config, deps resource.Dependencies) *simulatedCamera {
+	t.Helper()
+	if conf.WidthPx == 0 {
+		conf.WidthPx, conf.HeightPx, conf.HorizontalFOVDegs = 64, 48, 90
+	}
+	_, _, err := conf.Validate("")
+	test.That(t, err, test.ShouldBeNil)
+	cam, err := NewCamera(context.Background(), deps, resource.Config{
+		Name:                "cam",
+		API:                 camera.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return cam.(*simulatedCamera)
+}
+
+func depthAt(t *testing.T, cam camera.Camera, x, y int) rimage.Depth {
+	t.Helper()
+	imgs, _, err := cam.Images(context.Background(), []string{"depth"}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 1)
+	img, err := imgs[0].Image(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	dm, err := rimage.ConvertImageToDepthMap(context.Background(), img)
+	test.That(t, err, test.ShouldBeNil)
+	return dm.GetDepth(x, y)
+}
+
+func TestRender(t *testing.T) {
+	ctx := context.Background()
+	cam := newTestCamera(t, &Config{Obstacles: []spatialmath.GeometryConfig{wall}}, nil)
+
+	props, err := cam.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.SupportsPCD, test.ShouldBeTrue)
+	test.That(t, props.IntrinsicParams.Width, test.ShouldEqual, 64)
+	test.That(t, props.IntrinsicParams.Fx, test.ShouldAlmostEqual, 32)
+	test.That(t, props.MimeTypes, test.ShouldResemble, []string{utils.MimeTypePNG, utils.MimeTypeRawDepth})
+
+	imgs, _, err := cam.Images(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 2)
+	test.That(t, imgs[0].SourceName, test.ShouldEqual, "color")
+	test.That(t, imgs[0].MimeType(), test.ShouldEqual, utils.MimeTypePNG)
+	colorImg, err := imgs[0].Image(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	_, _, _, alpha := colorImg.At(32, 24).RGBA()
+	test.That(t, alpha, test.ShouldNotEqual, 0)
+	_, _, _, alpha = colorImg.At(0, 0).RGBA()
+	test.That(t, alpha, test.ShouldEqual, 0)
+
+	_, _, err = cam.Images(ctx, []string{"infrared"}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// The near face of the wall is 495mm away, and the corners of the image see past it.
+	test.That(t, depthAt(t, cam, 32, 24), test.ShouldEqual, 495)
+	test.That(t, depthAt(t, cam, 0, 0), test.ShouldEqual, 0)
+
+	pc, err := cam.NextPointCloud(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldBeGreaterThan, 0)
+	test.That(t, pc.Size(), test.ShouldBeLessThan, 64*48)
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		test.That(t, p.Z, test.ShouldAlmostEqual, 495)
+		test.That(t, p.X, test.ShouldBeBetweenOrEqual, -200, 200)
+		test.That(t, p.Y, test.ShouldBeBetweenOrEqual, -200, 200)
+		return true
+	})
+
+	// The wall is beyond the range of the camera.
+	cam = newTestCamera(t, &Config{Obstacles: []spatialmath.GeometryConfig{wall}, MaxDepthMm: 400}, nil)
+	pc, err = cam.NextPointCloud(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 0)
+}
+
+func TestNoise(t *testing.T) {
+	cam := newTestCamera(t, &Config{Obstacles: []spatialmath.GeometryConfig{wall}, DepthStdDevMm: 5, RandomSeed: 1}, nil)
+	pc, err := cam.NextPointCloud(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	minZ, maxZ := 495.0, 495.0
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		minZ, maxZ = min(minZ, p.Z), max(maxZ, p.Z)
+		return true
+	})
+	test.That(t, maxZ-minZ, test.ShouldBeGreaterThan, 5)
+	test.That(t, minZ, test.ShouldBeGreaterThan, 495-50)
+	test.That(t, maxZ, test.ShouldBeLessThan, 495+50)
+}
+
+type shapedComponent struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	geometries []spatialmath.Geometry
+}
+
+func (s *shapedComponent) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return s.geometries, nil
+}
+
+func TestFrameSystem(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	fsService, err := framesystem.New(ctx, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// A gripper with geometries only it knows about is beside the post, and lower.
+	gripperGeometry, err := spatialmath.NewSphere(spatialmath.NewZeroPose(), 50, "")
+	test.That(t, err, test.ShouldBeNil)
+	gripper := &shapedComponent{
+		Named:      resource.NewName(resource.APINamespaceRDK.WithComponentType("gripper"), "gripper").AsNamed(),
+		geometries: []spatialmath.Geometry{gripperGeometry},
+	}
+	cam := newTestCamera(t, &Config{Components: []string{"gripper"}}, resource.Dependencies{
+		framesystem.InternalServiceName: fsService,
+		gripper.Name():                  gripper,
+	})
+
+	// The camera is a meter above the post, looking down at it.
+	postGeometry, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{X: 100, Y: 100, Z: 100}, "")
+	test.That(t, err, test.ShouldBeNil)
+	fsConf := &framesystem.Config{Parts: []*referenceframe.FrameSystemPart{
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World,
+				spatialmath.NewPose(r3.Vector{Z: 1000}, &spatialmath.OrientationVector{OZ: -1}), "cam", nil),
+		},
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewZeroPose(), "post", postGeometry),
+		},
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewPoseFromPoint(r3.Vector{Y: 300, Z: -100}), "gripper", nil),
+		},
+	}}
+	err = fsService.Reconfigure(ctx, resource.Dependencies{cam.Name(): cam, gripper.Name(): gripper},
+		resource.Config{ConvertedAttributes: fsConf})
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, depthAt(t, cam, 32, 24), test.ShouldEqual, 950)
+
+	// The top of the gripper is 1050mm below the camera.
+	pc, err := cam.NextPointCloud(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	var nearest float64
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		if p.Z > 960 && (nearest == 0 || p.Z < nearest) {
+			nearest = p.Z
+		}
+		return true
+	})
+	test.That(t, nearest, test.ShouldAlmostEqual, 1050, 5)
+
+	// Moving the camera moves what it sees.
+	fsConf.Parts[0].FrameConfig = referenceframe.NewLinkInFrame(referenceframe.World,
+		spatialmath.NewPose(r3.Vector{Z: 500}, &spatialmath.OrientationVector{OZ: -1}), "cam", nil)
+	err = fsService.Reconfigure(ctx, resource.Dependencies{cam.Name(): cam, gripper.Name(): gripper},
+		resource.Config{ConvertedAttributes: fsConf})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, depthAt(t, cam, 32, 24), test.ShouldEqual, 450)
+}
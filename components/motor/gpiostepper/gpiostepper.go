@@ -13,9 +13,11 @@ package gpiostepper
            A4998:  https://lastminuteengineers.com/a4988-stepper-motor-driver-arduino-tutorial/
            L298N: https://lastminuteengineers.com/stepper-motor-l298n-arduino-tutorial/
 
-   This driver uses hardware PWM on the step pin to generate precise step pulses at the desired
-   frequency. A 1kHz tracking goroutine estimates position based on elapsed time and the confirmed
-   PWM frequency.
+   When the board can output pulse trains on the step pin (see board.PulseGenerator), this driver
+   outputs each step itself, ramping the step rate up and down with the configured acceleration so
+   that the motor does not stall at high speeds, and counts steps for its position. Otherwise, it
+   uses PWM on the step pin to generate step pulses at the desired frequency, and a 1kHz tracking
+   goroutine estimates position based on elapsed time and the confirmed PWM frequency.
 
    Configuration:
    Required pins: a step pin to send pulses and a direction pin to set the direction.
@@ -24,6 +26,10 @@ package gpiostepper
 
    An optional configurable stepper_delay parameter configures the minimum delay between pulses
    for a particular stepper motor. This sets the maximum step frequency (1/stepper_delay).
+
+   An optional acceleration_rpm_per_sec parameter configures how fast the motor speeds up and slows
+   down, on boards which can output pulse trains. Without it, the motor starts and stops at full
+   speed.
 */
 
 import (
@@ -62,6 +68,7 @@ type Config struct {
 	BoardName        string    `json:"board"`
 	StepperDelay     int       `json:"stepper_delay_usec,omitempty"` // When using stepper motors, the time to remain high
 	TicksPerRotation int       `json:"ticks_per_rotation"`
+	Acceleration     float64   `json:"acceleration_rpm_per_sec,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -79,6 +86,9 @@ func (cfg *Config) Validate(path string) ([]string, []string, error) {
 	if cfg.Pins.Step == "" {
 		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "step")
 	}
+	if cfg.Acceleration < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("acceleration_rpm_per_sec cannot be negative"))
+	}
 	deps = append(deps, cfg.BoardName)
 	return deps, nil, nil
 }
@@ -127,6 +137,7 @@ func newGPIOStepper(
 		Named:            conf.ResourceName().AsNamed(),
 		theBoard:         b,
 		stepsPerRotation: mc.TicksPerRotation,
+		acceleration:     mc.Acceleration * float64(mc.TicksPerRotation) / 60,
 		logger:           logger,
 		opMgr:            operation.NewSingleOperationManager(),
 	}
@@ -176,6 +187,7 @@ type gpioStepper struct {
 	theBoard                    board.Board
 	stepsPerRotation            int
 	minDelay                    time.Duration
+	acceleration                float64 // in steps per second per second, 0 for none
 	enablePinHigh, enablePinLow board.GPIOPin
 	stepPin, dirPin             board.GPIOPin
 	logger                      logging.Logger
@@ -186,6 +198,7 @@ type gpioStepper struct {
 
 	stepPosition       atomic.Int64
 	targetStepPosition atomic.Int64
+	stepRate           atomic.Uint64 // float64 bits of the steps per second, negative backwards
 	trackingCancel     context.CancelFunc
 	trackingDone       <-chan struct{} // closed when tracking goroutine exits
 }
@@ -314,6 +327,107 @@ func (m *gpioStepper) trackPosition(ctx context.Context, doneCh chan<- error,
 	}
 }
 
+// startMoving starts the motor stepping towards the target at the step frequency, in a goroutine
+// which sends the result to doneCh when the movement is done, if doneCh is not nil, and closes
+// trackingDone when it exits. Must be called under m.lock.
+func (m *gpioStepper) startMoving(ctx, trackCtx context.Context, doneCh chan<- error, trackingDone chan struct{},
+	target int64, forward bool, freqHz uint,
+) error {
+	generator, ok := m.stepPin.(board.PulseGenerator)
+	if !ok {
+		actualFreq, err := m.startPWM(ctx, forward, freqHz)
+		if err != nil {
+			return err
+		}
+		utils.PanicCapturingGo(func() {
+			defer close(trackingDone)
+			m.trackPosition(trackCtx, doneCh, target, forward, actualFreq)
+		})
+		return nil
+	}
+
+	if err := m.dirPin.Set(ctx, forward, nil); err != nil {
+		return fmt.Errorf("error setting direction pin: %w", err)
+	}
+	if err := m.enable(ctx, true); err != nil {
+		return fmt.Errorf("error enabling motor: %w", err)
+	}
+	utils.PanicCapturingGo(func() {
+		defer close(trackingDone)
+		m.outputSteps(trackCtx, generator, doneCh, target, forward, float64(freqHz))
+	})
+	return nil
+}
+
+// outputSteps is a per-movement goroutine that outputs the steps to the target, speeding up to
+// the step frequency and slowing down again with the acceleration of the motor, and counts them.
+func (m *gpioStepper) outputSteps(ctx context.Context, generator board.PulseGenerator, doneCh chan<- error,
+	targetSteps int64, forward bool, freqHz float64,
+) {
+	var result error
+	defer func() {
+		if ctx.Err() == nil {
+			// Natural exit (target reached) — we own hardware cleanup
+			m.stepRate.Store(0)
+			if err := m.stopHardware(context.Background()); err != nil {
+				m.logger.Warnf("error stopping hardware after motion complete: %v", err)
+			}
+		}
+		// If cancelled, hardware is managed by the caller (new movement or Stop)
+		if doneCh != nil {
+			doneCh <- result
+		}
+	}()
+
+	var direction int64 = 1
+	if !forward {
+		direction = -1
+	}
+	indefinite := targetSteps == math.MaxInt64 || targetSteps == math.MinInt64
+	// A movement in the same direction as the last one carries on at its speed.
+	rate := math.Float64frombits(m.stepRate.Load()) * float64(direction)
+	if rate < 0 || m.acceleration == 0 {
+		rate = 0
+	}
+
+	started := false
+	next := func() (board.Pulse, bool) {
+		if started {
+			// The step before this one is done.
+			m.stepPosition.Add(direction)
+		}
+		started = true
+
+		remaining := math.Inf(1)
+		if !indefinite {
+			remaining = float64((targetSteps - m.stepPosition.Load()) * direction)
+			if remaining <= 0 {
+				return board.Pulse{}, false
+			}
+		}
+		if m.acceleration == 0 {
+			rate = freqHz
+		} else {
+			// Speed up by the acceleration over one step, without going over the step frequency or
+			// going too fast to slow down to a stop by the target.
+			rate = min(freqHz, math.Sqrt(rate*rate+2*m.acceleration), math.Sqrt(2*m.acceleration*remaining))
+		}
+		m.stepRate.Store(math.Float64bits(rate * float64(direction)))
+
+		period := time.Duration(float64(time.Second) / rate)
+		return board.Pulse{High: period / 2, Low: period - period/2}, true
+	}
+
+	if err := generator.OutputPulses(ctx, next); err != nil {
+		if ctx.Err() != nil {
+			result = errors.New("outputSteps: context cancelled")
+			return
+		}
+		result = err
+		m.logger.Warnf("error outputting steps: %v", err)
+	}
+}
+
 // SetPower sets the percentage of power the motor should employ between 0-1.
 func (m *gpioStepper) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
 	if math.Abs(powerPct) <= .0001 {
@@ -342,24 +456,18 @@ func (m *gpioStepper) SetPower(ctx context.Context, powerPct float64, extra map[
 	m.targetStepPosition.Store(target)
 
 	m.lock.Lock()
+	defer m.lock.Unlock()
 	if m.trackingCancel != nil {
 		m.trackingCancel()
 	}
-	actualFreq, err := m.startPWM(ctx, forward, freqHz)
-	if err != nil {
-		m.lock.Unlock()
+	trackCtx, cancel := context.WithCancel(context.Background())
+	trackingDone := make(chan struct{})
+	if err := m.startMoving(ctx, trackCtx, nil, trackingDone, target, forward, freqHz); err != nil {
+		cancel()
 		return err
 	}
-	trackCtx, cancel := context.WithCancel(context.Background())
 	m.trackingCancel = cancel
-	trackingDone := make(chan struct{})
 	m.trackingDone = trackingDone
-	m.lock.Unlock()
-
-	utils.PanicCapturingGo(func() {
-		defer close(trackingDone)
-		m.trackPosition(trackCtx, nil, target, forward, actualFreq)
-	})
 	return nil
 }
 
@@ -393,23 +501,19 @@ func (m *gpioStepper) GoFor(ctx context.Context, rpm, revolutions float64, extra
 	if m.trackingCancel != nil {
 		m.trackingCancel()
 	}
-	actualFreq, err := m.startPWM(ctx, forward, m.rpmToFreqHz(rpm))
-	if err != nil {
+	trackCtx, cancel := context.WithCancel(ctx)
+	trackingDone := make(chan struct{})
+	doneCh := make(chan error, 1)
+	if err := m.startMoving(ctx, trackCtx, doneCh, trackingDone, target, forward, m.rpmToFreqHz(rpm)); err != nil {
+		cancel()
 		m.lock.Unlock()
 		return err
 	}
-	trackCtx, cancel := context.WithCancel(ctx)
 	m.trackingCancel = cancel
-	trackingDone := make(chan struct{})
 	m.trackingDone = trackingDone
 	m.lock.Unlock()
 
-	doneCh := make(chan error, 1)
-	utils.PanicCapturingGo(func() {
-		defer close(trackingDone)
-		m.trackPosition(trackCtx, doneCh, target, forward, actualFreq)
-	})
-	err = <-doneCh
+	err := <-doneCh
 	if ctx.Err() != nil {
 		// Context was cancelled (external cancel or opMgr interrupt) — clean up
 		m.targetStepPosition.Store(m.stepPosition.Load())
@@ -459,30 +563,25 @@ func (m *gpioStepper) SetRPM(ctx context.Context, rpm float64, extra map[string]
 	m.targetStepPosition.Store(target)
 
 	m.lock.Lock()
+	defer m.lock.Unlock()
 	if m.trackingCancel != nil {
 		m.trackingCancel()
 	}
-	actualFreq, err := m.startPWM(ctx, forward, m.rpmToFreqHz(rpm))
-	if err != nil {
-		m.lock.Unlock()
+	trackCtx, cancel := context.WithCancel(context.Background())
+	trackingDone := make(chan struct{})
+	if err := m.startMoving(ctx, trackCtx, nil, trackingDone, target, forward, m.rpmToFreqHz(rpm)); err != nil {
+		cancel()
 		return err
 	}
-	trackCtx, cancel := context.WithCancel(context.Background())
 	m.trackingCancel = cancel
-	trackingDone := make(chan struct{})
 	m.trackingDone = trackingDone
-	m.lock.Unlock()
-
-	utils.PanicCapturingGo(func() {
-		defer close(trackingDone)
-		m.trackPosition(trackCtx, nil, target, forward, actualFreq)
-	})
 	return nil
 }
 
 // Set the current position (+/- offset) to be the new zero (home) position.
 func (m *gpioStepper) ResetZeroPosition(ctx context.Context, offset float64, extra map[string]interface{}) error {
 	m.stopTracking()
+	m.stepRate.Store(0)
 	if err := m.stopHardware(ctx); err != nil {
 		return err
 	}
@@ -514,6 +613,7 @@ func (m *gpioStepper) IsMoving(ctx context.Context) (bool, error) {
 // Stop turns the power to the motor off immediately, without any gradual step down.
 func (m *gpioStepper) Stop(ctx context.Context, extra map[string]interface{}) error {
 	m.stopTracking()
+	m.stepRate.Store(0)
 	m.targetStepPosition.Store(m.stepPosition.Load())
 	return m.stopHardware(ctx)
 }
@@ -91,6 +91,15 @@ func TestConfigs(t *testing.T) {
 		test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("", "ticks_per_rotation"))
 	})
 
+	t.Run("config negative acceleration", func(t *testing.T) {
+		mc := goodConfig
+		mc.Acceleration = -1
+
+		_, _, err := mc.Validate("")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "acceleration_rpm_per_sec")
+	})
+
 	t.Run("config missing board", func(t *testing.T) {
 		mc := goodConfig
 		mc.BoardName = ""
@@ -674,3 +683,150 @@ func TestRunning(t *testing.T) {
 
 	cancel()
 }
+
+// pulsePin is a step pin which outputs pulse trains, like the pins of Linux boards. Unless it is
+// realtime, it records the pulses without waiting for them.
+type pulsePin struct {
+	fakeboard.GPIOPin
+	realtime bool
+
+	mu     sync.Mutex
+	pulses []board.Pulse
+}
+
+func (p *pulsePin) OutputPulses(ctx context.Context, next func() (board.Pulse, bool)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pulse, ok := next()
+		if !ok {
+			return nil
+		}
+		p.mu.Lock()
+		p.pulses = append(p.pulses, pulse)
+		p.mu.Unlock()
+		if p.realtime {
+			time.Sleep(pulse.High + pulse.Low)
+		}
+	}
+}
+
+func (p *pulsePin) takePulses() []board.Pulse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pulses := p.pulses
+	p.pulses = nil
+	return pulses
+}
+
+// pulseBoard is a board whose step pin outputs pulse trains.
+type pulseBoard struct {
+	*fakeboard.Board
+	step *pulsePin
+}
+
+func (b *pulseBoard) GPIOPinByName(name string) (board.GPIOPin, error) {
+	if name == "c" {
+		return b.step, nil
+	}
+	return b.Board.GPIOPinByName(name)
+}
+
+func TestPulses(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	conf := &Config{
+		Pins:             PinConfig{Direction: "b", Step: "c", EnablePinHigh: "d"},
+		TicksPerRotation: 200,
+		BoardName:        "brd",
+		StepperDelay:     30,
+		Acceleration:     60,
+	}
+	c := resource.Config{Name: "fake_gpiostepper", ConvertedAttributes: conf}
+	step := &pulsePin{}
+	b := &pulseBoard{Board: &fakeboard.Board{GPIOPins: map[string]*fakeboard.GPIOPin{}}, step: step}
+	deps := resource.Dependencies{resource.NewName(board.API, "brd"): b}
+
+	m, err := newGPIOStepper(ctx, deps, c, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer m.Close(ctx)
+	s := m.(*gpioStepper)
+
+	t.Run("steps ramp up and down", func(t *testing.T) {
+		// At 60 RPM, the motor steps at 200Hz, which it takes 100 steps to get to and from at an
+		// acceleration of 200 steps per second per second.
+		test.That(t, m.GoFor(ctx, 60, 2, nil), test.ShouldBeNil)
+		pulses := step.takePulses()
+		test.That(t, pulses, test.ShouldHaveLength, 400)
+		pos, err := m.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pos, test.ShouldEqual, 2)
+
+		period := func(p board.Pulse) time.Duration { return p.High + p.Low }
+		test.That(t, period(pulses[0]), test.ShouldEqual, 50*time.Millisecond)
+		for i := 1; i < 100; i++ {
+			test.That(t, period(pulses[i]), test.ShouldBeLessThan, period(pulses[i-1]))
+		}
+		for i := 100; i <= 300; i++ {
+			test.That(t, period(pulses[i]), test.ShouldEqual, 5*time.Millisecond)
+		}
+		for i := 301; i < 400; i++ {
+			test.That(t, period(pulses[i]), test.ShouldBeGreaterThan, period(pulses[i-1]))
+		}
+		test.That(t, period(pulses[399]), test.ShouldEqual, 50*time.Millisecond)
+		test.That(t, pulses[200].High, test.ShouldEqual, 2500*time.Microsecond)
+
+		enabled, err := b.Board.GPIOPins["d"].Get(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, enabled, test.ShouldBeFalse)
+		moving, err := m.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+	})
+
+	t.Run("moving on carries on at the same speed", func(t *testing.T) {
+		s.stepRate.Store(math.Float64bits(200))
+		test.That(t, m.GoFor(ctx, 60, 1, nil), test.ShouldBeNil)
+		pulses := step.takePulses()
+		test.That(t, pulses[0].High+pulses[0].Low, test.ShouldEqual, 5*time.Millisecond)
+
+		// Going the other way starts from a stop.
+		s.stepRate.Store(math.Float64bits(200))
+		test.That(t, m.GoFor(ctx, -60, 1, nil), test.ShouldBeNil)
+		pulses = step.takePulses()
+		test.That(t, pulses[0].High+pulses[0].Low, test.ShouldEqual, 50*time.Millisecond)
+		pos, err := m.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pos, test.ShouldEqual, 2)
+	})
+
+	t.Run("no acceleration", func(t *testing.T) {
+		s.acceleration = 0
+		defer func() { s.acceleration = 200 }()
+		test.That(t, m.GoFor(ctx, 60, 1, nil), test.ShouldBeNil)
+		for _, pulse := range step.takePulses() {
+			test.That(t, pulse.High+pulse.Low, test.ShouldEqual, 5*time.Millisecond)
+		}
+	})
+
+	t.Run("stopping stops the pulses", func(t *testing.T) {
+		step.realtime = true
+		defer func() { step.realtime = false }()
+		test.That(t, m.SetRPM(ctx, 60, nil), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			pos, err := m.Position(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, pos, test.ShouldBeGreaterThan, 0.5)
+		})
+		test.That(t, m.Stop(ctx, nil), test.ShouldBeNil)
+		moving, err := m.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+
+		step.takePulses()
+		time.Sleep(100 * time.Millisecond)
+		test.That(t, step.takePulses(), test.ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,547 @@
+// Package ekf implements a movementsensor which fuses IMU, wheel odometry, GPS and compass readings
+// of a base with an extended Kalman filter.
+package ekf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the name of the extended Kalman filter model of a movementsensor component.
+var Model = resource.DefaultModelFamily.WithModel("ekf")
+
+const (
+	defaultIMUFrequencyHz      = 50
+	defaultOdometryFrequencyHz = 20
+	defaultGPSFrequencyHz      = 5
+	defaultCompassFrequencyHz  = 10
+
+	defaultGPSStdDevM                       = 2.5
+	defaultCompassStdDevDeg                 = 5
+	defaultLinearVelocityStdDevMPS          = 0.05
+	defaultAngularVelocityStdDevDegPerSec   = 2
+	defaultLinearAccelerationStdDevMPS2     = 1
+	defaultAngularAccelerationStdDevDegPerS = 45
+
+	// defaultGPSOutlierThreshold is the 99.9th percentile of the chi-squared distribution with two
+	// degrees of freedom, so that a good fix is rejected once in a thousand.
+	defaultGPSOutlierThreshold = 13.8
+	defaultMaxRejectedGPSFixes = 10
+
+	earthRadiusM = 6371e3
+	mToKm        = 1e-3
+	resetCommand = "reset"
+)
+
+// Config is the config of the ekf movement sensor model. At least one of imu, odometry, gps and
+// compass is required.
+type Config struct {
+	// IMU is a movement sensor reporting the angular velocity of the base.
+	IMU string `json:"imu,omitempty"`
+	// Odometry is a movement sensor reporting the forward linear velocity and angular velocity of
+	// the base, such as a wheeled-odometry movement sensor.
+	Odometry string `json:"odometry,omitempty"`
+	// GPS is a movement sensor reporting the position of the base.
+	GPS string `json:"gps,omitempty"`
+	// Compass is a movement sensor reporting the compass heading of the base.
+	Compass string `json:"compass,omitempty"`
+
+	IMUFrequencyHz      float64 `json:"imu_frequency_hz,omitempty"`
+	OdometryFrequencyHz float64 `json:"odometry_frequency_hz,omitempty"`
+	GPSFrequencyHz      float64 `json:"gps_frequency_hz,omitempty"`
+	CompassFrequencyHz  float64 `json:"compass_frequency_hz,omitempty"`
+
+	// GPSStdDevM is the standard deviation of GPS positions, which is scaled by the HDOP of the GPS
+	// when it reports one.
+	GPSStdDevM                   float64 `json:"gps_std_dev_m,omitempty"`
+	CompassStdDevDeg             float64 `json:"compass_std_dev_deg,omitempty"`
+	LinearVelocityStdDevMPS      float64 `json:"linear_velocity_std_dev_meters_per_sec,omitempty"`
+	AngularVelocityStdDevDegPerS float64 `json:"angular_velocity_std_dev_deg_per_sec,omitempty"`
+
+	// LinearAccelerationStdDevMPS2 and AngularAccelerationStdDevDegPerS2 are how quickly the base
+	// can change speed between readings.
+	LinearAccelerationStdDevMPS2      float64 `json:"linear_acceleration_std_dev_meters_per_sec_per_sec,omitempty"`
+	AngularAccelerationStdDevDegPerS2 float64 `json:"angular_acceleration_std_dev_deg_per_sec_per_sec,omitempty"`
+
+	// GPSOutlierThreshold is the squared Mahalanobis distance from the estimated position beyond
+	// which a GPS fix is discarded as a glitch.
+	GPSOutlierThreshold float64 `json:"gps_outlier_threshold,omitempty"`
+	// MaxRejectedGPSFixes is how many GPS fixes in a row may be discarded before the estimated
+	// position is reset to the GPS position, in case it is the estimate which drifted.
+	MaxRejectedGPSFixes int `json:"max_rejected_gps_fixes,omitempty"`
+}
+
+// Validate validates the ekf model's configuration.
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	var deps []string
+	for _, name := range []string{cfg.IMU, cfg.Odometry, cfg.GPS, cfg.Compass} {
+		if name != "" {
+			deps = append(deps, name)
+		}
+	}
+	if len(deps) == 0 {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("at least one of imu, odometry, gps and compass is required"))
+	}
+	for field, value := range map[string]float64{
+		"imu_frequency_hz":                                   cfg.IMUFrequencyHz,
+		"odometry_frequency_hz":                              cfg.OdometryFrequencyHz,
+		"gps_frequency_hz":                                   cfg.GPSFrequencyHz,
+		"compass_frequency_hz":                               cfg.CompassFrequencyHz,
+		"gps_std_dev_m":                                      cfg.GPSStdDevM,
+		"compass_std_dev_deg":                                cfg.CompassStdDevDeg,
+		"linear_velocity_std_dev_meters_per_sec":             cfg.LinearVelocityStdDevMPS,
+		"angular_velocity_std_dev_deg_per_sec":               cfg.AngularVelocityStdDevDegPerS,
+		"linear_acceleration_std_dev_meters_per_sec_per_sec": cfg.LinearAccelerationStdDevMPS2,
+		"angular_acceleration_std_dev_deg_per_sec_per_sec":   cfg.AngularAccelerationStdDevDegPerS2,
+		"gps_outlier_threshold":                              cfg.GPSOutlierThreshold,
+	} {
+		if value < 0 {
+			return nil, nil, resource.NewConfigValidationError(path, errors.Errorf("%s cannot be negative", field))
+		}
+	}
+	if cfg.MaxRejectedGPSFixes < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("max_rejected_gps_fixes cannot be negative"))
+	}
+	return deps, nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		movementsensor.API,
+		Model,
+		resource.Registration[movementsensor.MovementSensor, *Config]{Constructor: newEKF})
+}
+
+// source is a movement sensor the filter polls for readings.
+type source struct {
+	kind   string
+	ms     movementsensor.MovementSensor
+	period time.Duration
+	read   func(ctx context.Context, now time.Time) error
+	// failing is whether the last reading failed, so that a failing source is logged once.
+	failing bool
+}
+
+type ekf struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	mu     sync.Mutex
+	filter *filter
+	now    func() time.Time
+
+	sources []*source
+	imu     movementsensor.MovementSensor
+	odom    movementsensor.MovementSensor
+	gps     movementsensor.MovementSensor
+	compass movementsensor.MovementSensor
+
+	gpsStdDev             float64
+	compassStdDev         float64
+	linearVelocityStdDev  float64
+	angularVelocityStdDev float64
+	gpsOutlierThreshold   float64
+	maxRejectedGPSFixes   int
+
+	origin      *geo.Point
+	altitude    float64
+	lastFix     *geo.Point
+	gpsAccuracy *movementsensor.Accuracy
+	// rejectedFixes counts the GPS fixes discarded as glitches in a row, and totalRejectedFixes all
+	// of them.
+	rejectedFixes      int
+	totalRejectedFixes int
+
+	workers *goutils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func newEKF(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	e, err := newFilteredSensor(deps, conf, logger)
+	if err != nil {
+		return nil, err
+	}
+	e.startPolling()
+	return e, nil
+}
+
+// newFilteredSensor returns the sensor without polling its sources.
+func newFilteredSensor(deps resource.Dependencies, conf resource.Config, logger logging.Logger) (*ekf, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &ekf{
+		Named:  conf.ResourceName().AsNamed(),
+		now:    time.Now,
+		logger: logger,
+		filter: newFilter(processNoise{
+			linearAcceleration: orDefault(newConf.LinearAccelerationStdDevMPS2, defaultLinearAccelerationStdDevMPS2),
+			angularAcceleration: utils.DegToRad(
+				orDefault(newConf.AngularAccelerationStdDevDegPerS2, defaultAngularAccelerationStdDevDegPerS)),
+		}),
+		gpsStdDev:             orDefault(newConf.GPSStdDevM, defaultGPSStdDevM),
+		compassStdDev:         utils.DegToRad(orDefault(newConf.CompassStdDevDeg, defaultCompassStdDevDeg)),
+		linearVelocityStdDev:  orDefault(newConf.LinearVelocityStdDevMPS, defaultLinearVelocityStdDevMPS),
+		angularVelocityStdDev: utils.DegToRad(orDefault(newConf.AngularVelocityStdDevDegPerS, defaultAngularVelocityStdDevDegPerSec)),
+		gpsOutlierThreshold:   orDefault(newConf.GPSOutlierThreshold, defaultGPSOutlierThreshold),
+		maxRejectedGPSFixes:   newConf.MaxRejectedGPSFixes,
+	}
+	if e.maxRejectedGPSFixes == 0 {
+		e.maxRejectedGPSFixes = defaultMaxRejectedGPSFixes
+	}
+
+	addSource := func(kind, name string, frequencyHz, defaultFrequencyHz float64,
+		read func(ctx context.Context, now time.Time) error,
+	) (movementsensor.MovementSensor, error) {
+		if name == "" {
+			return nil, nil
+		}
+		ms, err := movementsensor.FromProvider(deps, name)
+		if err != nil {
+			return nil, err
+		}
+		period := time.Duration(float64(time.Second) / orDefault(frequencyHz, defaultFrequencyHz))
+		e.sources = append(e.sources, &source{kind: kind, ms: ms, period: period, read: read})
+		logger.Debugf("using movement sensor %v as %s", name, kind)
+		return ms, nil
+	}
+	if e.imu, err = addSource("imu", newConf.IMU, newConf.IMUFrequencyHz, defaultIMUFrequencyHz, e.readIMU); err != nil {
+		return nil, err
+	}
+	if e.odom, err = addSource(
+		"odometry", newConf.Odometry, newConf.OdometryFrequencyHz, defaultOdometryFrequencyHz, e.readOdometry,
+	); err != nil {
+		return nil, err
+	}
+	if e.gps, err = addSource("gps", newConf.GPS, newConf.GPSFrequencyHz, defaultGPSFrequencyHz, e.readGPS); err != nil {
+		return nil, err
+	}
+	if e.compass, err = addSource(
+		"compass", newConf.Compass, newConf.CompassFrequencyHz, defaultCompassFrequencyHz, e.readCompass,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func orDefault(value, defaultValue float64) float64 {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// startPolling reads each source in its own goroutine at its own rate. Each reading is applied to
+// the filter at the time it was received.
+func (e *ekf) startPolling() {
+	e.workers = goutils.NewBackgroundStoppableWorkers()
+	for _, src := range e.sources {
+		e.workers.Add(func(ctx context.Context) {
+			ticker := time.NewTicker(src.period)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+				e.poll(ctx, src)
+			}
+		})
+	}
+}
+
+func (e *ekf) poll(ctx context.Context, src *source) {
+	err := src.read(ctx, e.now())
+	switch {
+	case err != nil && ctx.Err() != nil:
+	case err != nil && !src.failing:
+		e.logger.CWarnw(ctx, "failed to read movement sensor, the estimate will drift until it recovers",
+			"source", src.kind, "movement_sensor", src.ms.Name().ShortName(), "error", err)
+	case err == nil && src.failing:
+		e.logger.CInfow(ctx, "movement sensor recovered", "source", src.kind, "movement_sensor", src.ms.Name().ShortName())
+	}
+	src.failing = err != nil
+}
+
+func (e *ekf) readIMU(ctx context.Context, now time.Time) error {
+	angVel, err := e.imu.AngularVelocity(ctx, nil)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.filter.predict(now)
+	_, err = e.filter.update(measurement{
+		states: []int{stateAngularVelocity},
+		values: []float64{utils.DegToRad(angVel.Z)},
+		stdDev: []float64{e.angularVelocityStdDev},
+	}, 0)
+	return err
+}
+
+func (e *ekf) readOdometry(ctx context.Context, now time.Time) error {
+	linVel, err := e.odom.LinearVelocity(ctx, nil)
+	if err != nil {
+		return err
+	}
+	angVel, err := e.odom.AngularVelocity(ctx, nil)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.filter.predict(now)
+	_, err = e.filter.update(measurement{
+		states: []int{stateLinearVelocity, stateAngularVelocity},
+		values: []float64{linVel.Y, utils.DegToRad(angVel.Z)},
+		stdDev: []float64{e.linearVelocityStdDev, e.angularVelocityStdDev},
+	}, 0)
+	return err
+}
+
+func (e *ekf) readCompass(ctx context.Context, now time.Time) error {
+	heading, err := e.compass.CompassHeading(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(heading) {
+		return errors.New("compass heading is NaN")
+	}
+	m := measurement{
+		states: []int{stateHeading},
+		values: []float64{wrapAngle(utils.DegToRad(heading))},
+		stdDev: []float64{e.compassStdDev},
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.filter.predict(now)
+	if !e.filter.headingInitialized {
+		e.filter.resetStates(m)
+		e.filter.headingInitialized = true
+		return nil
+	}
+	_, err = e.filter.update(m, 0)
+	return err
+}
+
+func (e *ekf) readGPS(ctx context.Context, now time.Time) error {
+	point, altitude, err := e.gps.Position(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if point == nil || movementsensor.IsPositionNaN(point) || movementsensor.IsZeroPosition(point) {
+		return errors.New("no GPS fix")
+	}
+	// most GPS report new fixes less often than the filter polls them, and applying the same fix
+	// twice would make the filter overconfident.
+	e.mu.Lock()
+	repeated := e.lastFix != nil && movementsensor.ArePointsEqual(point, e.lastFix)
+	e.mu.Unlock()
+	if repeated {
+		return nil
+	}
+
+	stdDev := e.gpsStdDev
+	acc, err := e.gps.Accuracy(ctx, nil)
+	if err == nil && acc != nil && !math.IsNaN(float64(acc.Hdop)) && acc.Hdop > 0 {
+		stdDev *= float64(acc.Hdop)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastFix = point
+	e.altitude = altitude
+	if err == nil {
+		e.gpsAccuracy = acc
+	}
+	e.filter.predict(now)
+	if e.origin == nil {
+		e.origin = point
+	}
+	east, north := e.toLocal(point)
+	m := measurement{
+		states: []int{stateEast, stateNorth},
+		values: []float64{east, north},
+		stdDev: []float64{stdDev, stdDev},
+	}
+	if !e.filter.positionInitialized {
+		e.filter.resetStates(m)
+		e.filter.positionInitialized = true
+		return nil
+	}
+
+	applied, err := e.filter.update(m, e.gpsOutlierThreshold)
+	if err != nil {
+		return err
+	}
+	if applied {
+		e.rejectedFixes = 0
+		return nil
+	}
+	e.rejectedFixes++
+	e.totalRejectedFixes++
+	if e.rejectedFixes < e.maxRejectedGPSFixes {
+		e.logger.CDebugw(ctx, "discarding GPS fix too far from the estimated position",
+			"latitude", point.Lat(), "longitude", point.Lng())
+		return nil
+	}
+	e.logger.CWarnf(ctx, "discarded %d GPS fixes in a row, resetting the estimated position to the GPS position", e.rejectedFixes)
+	e.filter.resetStates(m)
+	e.rejectedFixes = 0
+	return nil
+}
+
+// toLocal returns how many meters east and north of the origin a point is. The filter works in
+// this plane, which is accurate over the kilometers a base travels.
+func (e *ekf) toLocal(point *geo.Point) (float64, float64) {
+	east := utils.DegToRad(point.Lng()-e.origin.Lng()) * math.Cos(utils.DegToRad(e.origin.Lat())) * earthRadiusM
+	north := utils.DegToRad(point.Lat()-e.origin.Lat()) * earthRadiusM
+	return east, north
+}
+
+// estimate predicts the state of the filter forward to now, so that readings move smoothly between
+// the updates of the sources. It must be called with the lock held.
+func (e *ekf) estimate() *filter {
+	e.filter.predict(e.now())
+	return e.filter
+}
+
+func (e *ekf) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	if e.gps == nil {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), movementsensor.ErrMethodUnimplementedPosition
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	f := e.estimate()
+	if !f.positionInitialized {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), errors.New("waiting for the first GPS fix")
+	}
+	east, north := f.x.AtVec(stateEast), f.x.AtVec(stateNorth)
+	bearing := utils.RadToDeg(math.Atan2(east, north))
+	return e.origin.PointAtDistanceAndBearing(math.Hypot(east, north)*mToKm, bearing), e.altitude, nil
+}
+
+func (e *ekf) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// the yaw of the base grows counterclockwise, the opposite of its compass heading.
+	return &spatialmath.OrientationVector{OZ: 1, Theta: -e.estimate().x.AtVec(stateHeading)}, nil
+}
+
+func (e *ekf) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if e.compass == nil {
+		return math.NaN(), movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	heading := utils.RadToDeg(e.estimate().x.AtVec(stateHeading))
+	if heading < 0 {
+		heading += 360
+	}
+	return heading, nil
+}
+
+func (e *ekf) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return r3.Vector{Y: e.estimate().x.AtVec(stateLinearVelocity)}, nil
+}
+
+func (e *ekf) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return spatialmath.AngularVelocity{Z: utils.RadToDeg(e.estimate().x.AtVec(stateAngularVelocity))}, nil
+}
+
+func (e *ekf) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+}
+
+// Accuracy returns the standard deviations of the estimates and the covariance of the east and
+// north positions. The HDOP, VDOP and fix of the GPS are passed through.
+func (e *ekf) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	f := e.estimate()
+
+	acc := movementsensor.UnimplementedOptionalAccuracies()
+	acc.AccuracyMap = map[string]float32{
+		"heading_std_dev_deg":                    float32(utils.RadToDeg(f.stdDev(stateHeading))),
+		"linear_velocity_std_dev_meters_per_sec": float32(f.stdDev(stateLinearVelocity)),
+		"angular_velocity_std_dev_deg_per_sec":   float32(utils.RadToDeg(f.stdDev(stateAngularVelocity))),
+	}
+	if e.gps != nil {
+		acc.AccuracyMap["position_east_std_dev_m"] = float32(f.stdDev(stateEast))
+		acc.AccuracyMap["position_north_std_dev_m"] = float32(f.stdDev(stateNorth))
+		acc.AccuracyMap["position_east_north_covariance_m2"] = float32(f.p.At(stateEast, stateNorth))
+		acc.AccuracyMap["rejected_gps_fixes"] = float32(e.totalRejectedFixes)
+		if e.gpsAccuracy != nil {
+			acc.Hdop = e.gpsAccuracy.Hdop
+			acc.Vdop = e.gpsAccuracy.Vdop
+			acc.NmeaFix = e.gpsAccuracy.NmeaFix
+		}
+	}
+	if e.compass != nil {
+		acc.CompassDegreeError = acc.AccuracyMap["heading_std_dev_deg"]
+	}
+	return acc, nil
+}
+
+func (e *ekf) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:        e.gps != nil,
+		CompassHeadingSupported:  e.compass != nil,
+		OrientationSupported:     e.compass != nil || e.imu != nil || e.odom != nil,
+		LinearVelocitySupported:  e.odom != nil || e.gps != nil,
+		AngularVelocitySupported: e.imu != nil || e.odom != nil || e.compass != nil,
+	}, nil
+}
+
+func (e *ekf) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, e, extra)
+}
+
+// DoCommand resets the filter when given {"reset": true}, e.g. after the base is carried somewhere.
+func (e *ekf) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if reset, ok := cmd[resetCommand].(bool); ok && reset {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.filter.reset()
+		e.origin = nil
+		e.lastFix = nil
+		e.rejectedFixes = 0
+		return map[string]interface{}{resetCommand: "filter reset"}, nil
+	}
+	return nil, fmt.Errorf("unknown command %v", cmd)
+}
+
+func (e *ekf) Close(ctx context.Context) error {
+	// the movement sensors this depends on are closed by their own drivers.
+	if e.workers != nil {
+		e.workers.Stop()
+	}
+	return nil
+}
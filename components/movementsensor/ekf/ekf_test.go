@@ -0,0 +1,229 @@
+package ekf
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+var origin = geo.NewPoint(40.7, -74.0)
+
+func TestValidate(t *testing.T) {
+	cfg := Config{}
+	_, _, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least one of")
+
+	cfg = Config{Odometry: "odom", GPS: "gps", GPSStdDevM: -1}
+	_, _, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "gps_std_dev_m")
+
+	cfg = Config{IMU: "imu", Odometry: "odom", GPS: "gps", Compass: "compass"}
+	deps, _, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"imu", "odom", "gps", "compass"})
+}
+
+func TestWrapAngle(t *testing.T) {
+	test.That(t, wrapAngle(0), test.ShouldAlmostEqual, 0)
+	test.That(t, wrapAngle(3*math.Pi/2), test.ShouldAlmostEqual, -math.Pi/2)
+	test.That(t, wrapAngle(-3*math.Pi/2), test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, wrapAngle(5*math.Pi), test.ShouldAlmostEqual, -math.Pi)
+}
+
+// simulatedBase drives north at a constant speed, reported by noisy sensors.
+type simulatedBase struct {
+	start    time.Time
+	now      time.Time
+	speed    float64
+	rand     *rand.Rand
+	glitch   r3.Vector
+	gpsNoise float64
+}
+
+func (b *simulatedBase) north() float64 {
+	return b.speed * b.now.Sub(b.start).Seconds()
+}
+
+func (b *simulatedBase) deps() resource.Dependencies {
+	odom := inject.NewMovementSensor("odom")
+	odom.LinearVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+		return r3.Vector{Y: b.speed + b.rand.NormFloat64()*0.05}, nil
+	}
+	odom.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{Z: b.rand.NormFloat64()}, nil
+	}
+
+	imu := inject.NewMovementSensor("imu")
+	imu.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{Z: b.rand.NormFloat64()}, nil
+	}
+
+	gps := inject.NewMovementSensor("gps")
+	gps.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		east := b.glitch.X + b.rand.NormFloat64()*b.gpsNoise
+		north := b.north() + b.glitch.Y + b.rand.NormFloat64()*b.gpsNoise
+		bearing := math.Atan2(east, north) * 180 / math.Pi
+		return origin.PointAtDistanceAndBearing(math.Hypot(east, north)*mToKm, bearing), 10, nil
+	}
+	gps.AccuracyFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+		return &movementsensor.Accuracy{Hdop: 1, Vdop: 2, NmeaFix: 4}, nil
+	}
+
+	compass := inject.NewMovementSensor("compass")
+	compass.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return math.Mod(360+b.rand.NormFloat64()*3, 360), nil
+	}
+
+	return resource.Dependencies{
+		movementsensor.Named("odom"):    odom,
+		movementsensor.Named("imu"):     imu,
+		movementsensor.Named("gps"):     gps,
+		movementsensor.Named("compass"): compass,
+	}
+}
+
+// run polls each source of e at its own period for the duration, moving the simulated time.
+func (b *simulatedBase) run(ctx context.Context, e *ekf, duration time.Duration) {
+	next := make([]time.Time, len(e.sources))
+	for i := range next {
+		next[i] = b.now
+	}
+	end := b.now.Add(duration)
+	for step := 10 * time.Millisecond; b.now.Before(end); b.now = b.now.Add(step) {
+		for i, src := range e.sources {
+			if !b.now.Before(next[i]) {
+				e.poll(ctx, src)
+				next[i] = next[i].Add(src.period)
+			}
+		}
+	}
+}
+
+func setUpEKF(t *testing.T, b *simulatedBase, conf *Config) *ekf {
+	t.Helper()
+	e, err := newFilteredSensor(b.deps(), resource.Config{
+		Name:                "fused",
+		API:                 movementsensor.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	e.now = func() time.Time { return b.now }
+	return e
+}
+
+func localPosition(t *testing.T, e *ekf) (float64, float64) {
+	t.Helper()
+	point, alt, err := e.Position(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, alt, test.ShouldEqual, 10)
+	// the filter's origin is its first, noisy, GPS fix rather than where the base started.
+	start := &ekf{origin: origin}
+	return start.toLocal(point)
+}
+
+func TestFusion(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+	b := &simulatedBase{start: start, now: start, speed: 1, rand: rand.New(rand.NewSource(1)), gpsNoise: 1}
+	e := setUpEKF(t, b, &Config{
+		IMU: "imu", Odometry: "odom", GPS: "gps", Compass: "compass",
+		GPSFrequencyHz: 1,
+	})
+
+	props, err := e.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.PositionSupported, test.ShouldBeTrue)
+	test.That(t, props.CompassHeadingSupported, test.ShouldBeTrue)
+	test.That(t, props.LinearAccelerationSupported, test.ShouldBeFalse)
+
+	_, _, err = e.Position(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	b.run(ctx, e, 30*time.Second)
+
+	east, north := localPosition(t, e)
+	test.That(t, east, test.ShouldAlmostEqual, 0, 1)
+	test.That(t, north, test.ShouldAlmostEqual, b.north(), 1)
+
+	heading, err := e.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, math.Min(heading, 360-heading), test.ShouldBeLessThan, 3)
+
+	linVel, err := e.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, linVel.Y, test.ShouldAlmostEqual, 1, 0.1)
+
+	acc, err := e.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	// fusing the readings is more accurate than the GPS alone.
+	test.That(t, acc.AccuracyMap["position_north_std_dev_m"], test.ShouldBeLessThan, 1)
+	test.That(t, acc.AccuracyMap["position_east_std_dev_m"], test.ShouldBeLessThan, 1)
+	test.That(t, acc.AccuracyMap["rejected_gps_fixes"], test.ShouldEqual, 0)
+	test.That(t, acc.CompassDegreeError, test.ShouldEqual, acc.AccuracyMap["heading_std_dev_deg"])
+	test.That(t, acc.NmeaFix, test.ShouldEqual, 4)
+
+	// a GPS glitch is smoothed out by the odometry.
+	b.glitch = r3.Vector{X: 20, Y: -20}
+	b.run(ctx, e, 2*time.Second)
+	east, north = localPosition(t, e)
+	test.That(t, east, test.ShouldAlmostEqual, 0, 1)
+	test.That(t, north, test.ShouldAlmostEqual, b.north(), 1)
+	acc, err = e.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.AccuracyMap["rejected_gps_fixes"], test.ShouldEqual, 2)
+
+	// but a GPS which keeps disagreeing is trusted over the estimate eventually.
+	b.run(ctx, e, 15*time.Second)
+	east, north = localPosition(t, e)
+	test.That(t, east, test.ShouldAlmostEqual, 20, 3)
+	test.That(t, north, test.ShouldAlmostEqual, b.north()-20, 3)
+
+	_, err = e.DoCommand(ctx, map[string]interface{}{resetCommand: true})
+	test.That(t, err, test.ShouldBeNil)
+	_, _, err = e.Position(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestDifferentRates(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+	b := &simulatedBase{start: start, now: start, speed: 2, rand: rand.New(rand.NewSource(2)), gpsNoise: 2}
+	// a slow GPS with fast odometry, without a compass or IMU.
+	e := setUpEKF(t, b, &Config{Odometry: "odom", GPS: "gps", GPSFrequencyHz: 0.5, OdometryFrequencyHz: 50})
+
+	props, err := e.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.CompassHeadingSupported, test.ShouldBeFalse)
+
+	b.run(ctx, e, 60*time.Second)
+
+	// the GPS and odometry together make the heading observable.
+	east, north := localPosition(t, e)
+	test.That(t, east, test.ShouldAlmostEqual, 0, 2)
+	test.That(t, north, test.ShouldAlmostEqual, b.north(), 2)
+	e.mu.Lock()
+	heading := e.filter.x.AtVec(stateHeading)
+	e.mu.Unlock()
+	test.That(t, heading, test.ShouldAlmostEqual, 0, 0.1)
+
+	// readings between updates are predicted forward.
+	b.now = b.now.Add(time.Second)
+	east2, north2 := localPosition(t, e)
+	test.That(t, east2, test.ShouldAlmostEqual, east, 0.2)
+	test.That(t, north2, test.ShouldAlmostEqual, north+2, 0.2)
+}
@@ -0,0 +1,213 @@
+package ekf
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Indexes of the state of the filter. Positions are east and north of the origin in meters, the
+// heading is the compass heading in radians (clockwise from north), the linear velocity is forward
+// in meters per second and the angular velocity is counterclockwise about Z in radians per second.
+const (
+	stateEast = iota
+	stateNorth
+	stateHeading
+	stateLinearVelocity
+	stateAngularVelocity
+	stateSize
+)
+
+// initialHeadingVariance is the variance of the heading before any compass reading.
+const initialHeadingVariance = math.Pi * math.Pi
+
+// processNoise is the standard deviation of the unmodeled accelerations which change the velocities
+// of the state between measurements.
+type processNoise struct {
+	linearAcceleration  float64 // m/s^2
+	angularAcceleration float64 // rad/s^2
+}
+
+// filter is an extended Kalman filter tracking the planar pose and velocity of a base, with a
+// constant velocity motion model. Measurements are applied in the order they arrive, after
+// predicting the state forward to the time they were taken at, so sources may report at any rate.
+type filter struct {
+	x    *mat.VecDense
+	p    *mat.Dense
+	last time.Time
+	q    processNoise
+
+	headingInitialized  bool
+	positionInitialized bool
+}
+
+func newFilter(q processNoise) *filter {
+	f := &filter{q: q}
+	f.reset()
+	return f
+}
+
+// reset forgets everything the filter has estimated.
+func (f *filter) reset() {
+	f.x = mat.NewVecDense(stateSize, nil)
+	f.p = mat.NewDense(stateSize, stateSize, nil)
+	f.p.Set(stateHeading, stateHeading, initialHeadingVariance)
+	// the base is assumed to start out at rest, with the uncertainty of a slowly moving base.
+	f.p.Set(stateLinearVelocity, stateLinearVelocity, 1)
+	f.p.Set(stateAngularVelocity, stateAngularVelocity, 1)
+	f.last = time.Time{}
+	f.headingInitialized = false
+	f.positionInitialized = false
+}
+
+// predict moves the state forward to t. Measurements taken before the last one are applied at the
+// time of the last one.
+func (f *filter) predict(t time.Time) {
+	if f.last.IsZero() {
+		f.last = t
+		return
+	}
+	dt := t.Sub(f.last).Seconds()
+	if dt <= 0 {
+		return
+	}
+	f.last = t
+
+	heading := f.x.AtVec(stateHeading)
+	v := f.x.AtVec(stateLinearVelocity)
+	w := f.x.AtVec(stateAngularVelocity)
+	sin, cos := math.Sincos(heading)
+
+	f.x.SetVec(stateEast, f.x.AtVec(stateEast)+v*sin*dt)
+	f.x.SetVec(stateNorth, f.x.AtVec(stateNorth)+v*cos*dt)
+	// the compass heading grows clockwise while the angular velocity is counterclockwise.
+	f.x.SetVec(stateHeading, wrapAngle(heading-w*dt))
+
+	jacobian := identity()
+	jacobian.Set(stateEast, stateHeading, v*cos*dt)
+	jacobian.Set(stateEast, stateLinearVelocity, sin*dt)
+	jacobian.Set(stateNorth, stateHeading, -v*sin*dt)
+	jacobian.Set(stateNorth, stateLinearVelocity, cos*dt)
+	jacobian.Set(stateHeading, stateAngularVelocity, -dt)
+
+	var fp, p mat.Dense
+	fp.Mul(jacobian, f.p)
+	p.Mul(&fp, jacobian.T())
+	p.Set(stateLinearVelocity, stateLinearVelocity,
+		p.At(stateLinearVelocity, stateLinearVelocity)+f.q.linearAcceleration*f.q.linearAcceleration*dt)
+	p.Set(stateAngularVelocity, stateAngularVelocity,
+		p.At(stateAngularVelocity, stateAngularVelocity)+f.q.angularAcceleration*f.q.angularAcceleration*dt)
+	f.p = &p
+}
+
+// measurement is a reading of some of the states of the filter, each with its standard deviation.
+type measurement struct {
+	states []int
+	values []float64
+	stdDev []float64
+}
+
+// mahalanobis returns the squared Mahalanobis distance of the measurement from the current estimate,
+// along with the innovation and its covariance.
+func (f *filter) mahalanobis(m measurement) (float64, *mat.VecDense, *mat.Dense, *mat.Dense, error) {
+	n := len(m.states)
+	h := mat.NewDense(n, stateSize, nil)
+	innovation := mat.NewVecDense(n, nil)
+	r := mat.NewDense(n, n, nil)
+	for i, state := range m.states {
+		h.Set(i, state, 1)
+		diff := m.values[i] - f.x.AtVec(state)
+		if state == stateHeading {
+			diff = wrapAngle(diff)
+		}
+		innovation.SetVec(i, diff)
+		r.Set(i, i, m.stdDev[i]*m.stdDev[i])
+	}
+
+	var hp, s mat.Dense
+	hp.Mul(h, f.p)
+	s.Mul(&hp, h.T())
+	s.Add(&s, r)
+	var sInv mat.Dense
+	if err := sInv.Inverse(&s); err != nil {
+		return 0, nil, nil, nil, err
+	}
+	var weighted mat.VecDense
+	weighted.MulVec(&sInv, innovation)
+	return mat.Dot(innovation, &weighted), innovation, h, &sInv, nil
+}
+
+// update corrects the state with a measurement unless its squared Mahalanobis distance from the
+// estimate is more than gate, which disables gating when zero. It returns whether the measurement
+// was applied.
+func (f *filter) update(m measurement, gate float64) (bool, error) {
+	distance, innovation, h, sInv, err := f.mahalanobis(m)
+	if err != nil {
+		return false, err
+	}
+	if gate > 0 && distance > gate {
+		return false, nil
+	}
+
+	var pht, gain mat.Dense
+	pht.Mul(f.p, h.T())
+	gain.Mul(&pht, sInv)
+
+	var correction mat.VecDense
+	correction.MulVec(&gain, innovation)
+	f.x.AddVec(f.x, &correction)
+	f.x.SetVec(stateHeading, wrapAngle(f.x.AtVec(stateHeading)))
+
+	// the Joseph form keeps the covariance symmetric and positive definite.
+	var kh, a mat.Dense
+	kh.Mul(&gain, h)
+	a.Sub(identity(), &kh)
+	var ap, p mat.Dense
+	ap.Mul(&a, f.p)
+	p.Mul(&ap, a.T())
+	var kr, krk mat.Dense
+	kr.Mul(&gain, measurementCovariance(m))
+	krk.Mul(&kr, gain.T())
+	p.Add(&p, &krk)
+	f.p = &p
+	return true, nil
+}
+
+// resetStates sets states to a measurement of them, dropping what the filter knew about them.
+func (f *filter) resetStates(m measurement) {
+	for i, state := range m.states {
+		f.x.SetVec(state, m.values[i])
+		for j := 0; j < stateSize; j++ {
+			f.p.Set(state, j, 0)
+			f.p.Set(j, state, 0)
+		}
+		f.p.Set(state, state, m.stdDev[i]*m.stdDev[i])
+	}
+}
+
+// stdDev returns the standard deviation of the estimate of a state.
+func (f *filter) stdDev(state int) float64 {
+	return math.Sqrt(f.p.At(state, state))
+}
+
+func measurementCovariance(m measurement) *mat.Dense {
+	r := mat.NewDense(len(m.states), len(m.states), nil)
+	for i, stdDev := range m.stdDev {
+		r.Set(i, i, stdDev*stdDev)
+	}
+	return r
+}
+
+func identity() *mat.Dense {
+	i := mat.NewDense(stateSize, stateSize, nil)
+	for j := 0; j < stateSize; j++ {
+		i.Set(j, j, 1)
+	}
+	return i
+}
+
+// wrapAngle returns the angle in radians in [-pi, pi).
+func wrapAngle(angle float64) float64 {
+	return math.Mod(math.Mod(angle+math.Pi, 2*math.Pi)+2*math.Pi, 2*math.Pi) - math.Pi
+}
@@ -3,6 +3,7 @@ package register
 
 import (
 	// Load all movementsensors.
+	_ "go.viam.com/rdk/components/movementsensor/ekf"
 	_ "go.viam.com/rdk/components/movementsensor/fake"
 	_ "go.viam.com/rdk/components/movementsensor/merged"
 	_ "go.viam.com/rdk/components/movementsensor/replay"
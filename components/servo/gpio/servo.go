@@ -1,10 +1,13 @@
-// Package gpio implements a pin based servo
+// Package gpio implements a pin based servo. When the board can output trains of pulses on the
+// pin (see board.PulseGenerator), the servo's pulses are output as one, which jitters less than
+// the pin's PWM. Otherwise, the pin's PWM outputs them.
 package gpio
 
 import (
 	"context"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -100,7 +103,6 @@ func init() {
 
 type servoGPIO struct {
 	resource.Named
-	resource.TriviallyCloseable
 	pin       board.GPIOPin
 	minDeg    float64
 	maxDeg    float64
@@ -112,6 +114,15 @@ type servoGPIO struct {
 	pwmRes    uint
 	currPct   float64
 	mu        sync.Mutex
+
+	// pulses is pin if the board can output trains of pulses on it, and nil otherwise.
+	pulses board.PulseGenerator
+	// pulseWidth is the length in nanoseconds of the pulses output on pulses, or 0 if they are
+	// stopped.
+	pulseWidth   atomic.Int64
+	pulsesMu     sync.Mutex
+	cancelPulses context.CancelFunc
+	pulsesDone   chan struct{}
 }
 
 func newGPIOServo(
@@ -136,6 +147,9 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// The pulses start again with the new config when the servo moves to its start position.
+	s.stopPulseTrain()
+
 	newConf, err := resource.NativeConfig[*servoConfig](conf)
 	if err != nil {
 		return err
@@ -152,6 +166,7 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	if err != nil {
 		return errors.Wrap(err, "couldn't get servo pin")
 	}
+	s.pulses, _ = s.pin.(board.PulseGenerator)
 
 	s.minDeg = defaultMinDeg
 	if newConf.MinDeg != nil {
@@ -211,6 +226,12 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		s.frequency = maxFrequency
 	}
 
+	if s.pulses != nil {
+		// Pulse trains are timed to the nanosecond, so there is no PWM resolution to detect.
+		s.pwmRes = 0
+		return s.Move(ctx, uint32(startPos), nil)
+	}
+
 	if err := s.pin.SetPWMFreq(ctx, s.frequency, nil); err != nil {
 		return errors.Wrap(err, "error setting servo pin frequency")
 	}
@@ -350,7 +371,7 @@ func (s *servoGPIO) Move(ctx context.Context, ang uint32, extra map[string]inter
 		pct = realTick / float64(s.pwmRes)
 	}
 
-	if err := s.pin.SetPWM(ctx, pct, nil); err != nil {
+	if err := s.setDutyCycle(ctx, pct, nil); err != nil {
 		return errors.Wrap(err, "couldn't move the servo")
 	}
 
@@ -360,7 +381,7 @@ func (s *servoGPIO) Move(ctx context.Context, ang uint32, extra map[string]inter
 
 // Position returns the current set angle (degrees) of the servo.
 func (s *servoGPIO) Position(ctx context.Context, extra map[string]interface{}) (uint32, error) {
-	pct, err := s.pin.PWM(ctx, nil)
+	pct, err := s.dutyCycle(ctx)
 	if err != nil {
 		return 0, errors.Wrap(err, "couldn't get servo pin duty cycle")
 	}
@@ -369,7 +390,7 @@ func (s *servoGPIO) Position(ctx context.Context, extra map[string]interface{})
 	// from going back to zero position.
 	if pct == 0 {
 		pct = s.currPct
-		err := s.pin.SetPWM(ctx, pct, extra)
+		err := s.setDutyCycle(ctx, pct, extra)
 		if err != nil {
 			return 0, errors.Wrap(err, "couldn't get servo pin duty cycle")
 		}
@@ -384,7 +405,7 @@ func (s *servoGPIO) Stop(ctx context.Context, extra map[string]interface{}) erro
 	defer done()
 	// Turning the pin all the way off (i.e., setting the duty cycle to 0%) will cut power to the
 	// motor. If you wanted to send it to position 0, you should set it to `minUs` instead.
-	if err := s.pin.SetPWM(ctx, 0.0, nil); err != nil {
+	if err := s.setDutyCycle(ctx, 0.0, nil); err != nil {
 		return errors.Wrap(err, "couldn't stop servo")
 	}
 	return nil
@@ -392,7 +413,7 @@ func (s *servoGPIO) Stop(ctx context.Context, extra map[string]interface{}) erro
 
 // IsMoving returns whether or not the servo is moving.
 func (s *servoGPIO) IsMoving(ctx context.Context) (bool, error) {
-	res, err := s.pin.PWM(ctx, nil)
+	res, err := s.dutyCycle(ctx)
 	if err != nil {
 		return false, errors.Wrap(err, "servo error while checking if moving")
 	}
@@ -401,3 +422,77 @@ func (s *servoGPIO) IsMoving(ctx context.Context) (bool, error) {
 	}
 	return s.opMgr.OpRunning(), nil
 }
+
+// Close stops the servo's pulses, if they are output as a pulse train.
+func (s *servoGPIO) Close(ctx context.Context) error {
+	s.stopPulseTrain()
+	return nil
+}
+
+// setDutyCycle sets the duty cycle of the servo's pulses, where 0 stops them.
+func (s *servoGPIO) setDutyCycle(ctx context.Context, pct float64, extra map[string]interface{}) error {
+	if s.pulses == nil {
+		return s.pin.SetPWM(ctx, pct, extra)
+	}
+	s.pulseWidth.Store(int64(pct * float64(s.period())))
+	if pct == 0 {
+		s.stopPulseTrain()
+		return nil
+	}
+	s.startPulseTrain()
+	return nil
+}
+
+// dutyCycle returns the duty cycle of the servo's pulses.
+func (s *servoGPIO) dutyCycle(ctx context.Context) (float64, error) {
+	if s.pulses == nil {
+		return s.pin.PWM(ctx, nil)
+	}
+	return float64(s.pulseWidth.Load()) / float64(s.period()), nil
+}
+
+func (s *servoGPIO) period() time.Duration {
+	return time.Second / time.Duration(s.frequency)
+}
+
+// startPulseTrain starts outputting pulses of pulseWidth on pulses, unless they already are.
+func (s *servoGPIO) startPulseTrain() {
+	s.pulsesMu.Lock()
+	defer s.pulsesMu.Unlock()
+	if s.pulsesDone != nil {
+		select {
+		case <-s.pulsesDone:
+			// The pulses stopped on an error, so start them again.
+		default:
+			// The pulses pick up the new width on their own.
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	s.cancelPulses, s.pulsesDone = cancel, done
+	period := s.period()
+	viamutils.PanicCapturingGo(func() {
+		defer close(done)
+		err := s.pulses.OutputPulses(ctx, func() (board.Pulse, bool) {
+			width := time.Duration(s.pulseWidth.Load())
+			return board.Pulse{High: width, Low: period - width}, width > 0
+		})
+		if err != nil && ctx.Err() == nil {
+			s.logger.Warnw("servo pulses stopped", "error", err)
+		}
+	})
+}
+
+// stopPulseTrain stops outputting pulses on pulses, and waits for the pin to be left low.
+func (s *servoGPIO) stopPulseTrain() {
+	s.pulsesMu.Lock()
+	defer s.pulsesMu.Unlock()
+	if s.pulsesDone == nil {
+		return
+	}
+	s.cancelPulses()
+	<-s.pulsesDone
+	s.cancelPulses, s.pulsesDone = nil, nil
+}
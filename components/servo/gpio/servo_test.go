@@ -3,10 +3,14 @@ package gpio
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.viam.com/test"
+	goutils "go.viam.com/utils"
+	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/logging"
@@ -205,3 +209,84 @@ func TestServoMove(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, pos, test.ShouldEqual, 63)
 }
+
+// pulsePin is a servo pin which outputs pulse trains, like the pins of Linux boards. It records the
+// last pulse it output.
+type pulsePin struct {
+	inject.GPIOPin
+
+	mu      sync.Mutex
+	last    board.Pulse
+	running bool
+}
+
+func (p *pulsePin) OutputPulses(ctx context.Context, next func() (board.Pulse, bool)) error {
+	p.setRunning(true)
+	defer p.setRunning(false)
+	for {
+		pulse, ok := next()
+		if !ok {
+			return nil
+		}
+		p.mu.Lock()
+		p.last = pulse
+		p.mu.Unlock()
+		if !goutils.SelectContextOrWait(ctx, pulse.High+pulse.Low) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *pulsePin) setRunning(running bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running = running
+}
+
+func (p *pulsePin) state() (board.Pulse, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last, p.running
+}
+
+func TestServoPulses(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	// The servo's pulses never go through the pin's PWM, whose functions are not injected.
+	pin := &pulsePin{}
+	pin.PWMFreqFunc = func(ctx context.Context, extra map[string]interface{}) (uint, error) {
+		return 50, nil
+	}
+	b := inject.NewBoard("mock")
+	b.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		return pin, nil
+	}
+	deps := resource.Dependencies{board.Named("mock"): b}
+
+	conf := servoConfig{Pin: "0", Board: "mock", StartPos: ptr(0.0)}
+	s, err := newGPIOServo(ctx, deps, resource.Config{ConvertedAttributes: &conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// At 50Hz, the pulses are 20ms apart, and 1.5ms long at the middle of the servo's range.
+	test.That(t, s.Move(ctx, 90, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		pulse, running := pin.state()
+		test.That(tb, running, test.ShouldBeTrue)
+		test.That(tb, pulse, test.ShouldResemble, board.Pulse{High: 1500 * time.Microsecond, Low: 18500 * time.Microsecond})
+	})
+	pos, err := s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 90)
+
+	// Stopping the servo stops the pulses.
+	test.That(t, s.Stop(ctx, nil), test.ShouldBeNil)
+	_, running := pin.state()
+	test.That(t, running, test.ShouldBeFalse)
+	moving, err := s.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+}
@@ -62,6 +62,17 @@ type Module struct {
 	// such as "1ns".
 	FirstRunTimeout goutils.Duration `json:"first_run_timeout,omitempty"`
 
+	// ResourceLimits optionally limits the CPU, memory and number of processes the module may use.
+	ResourceLimits *ModuleResourceLimits `json:"resource_limits,omitempty"`
+	// HealthCheck optionally enables periodic probing of the module, so that hung modules are
+	// restarted rather than only modules which exit.
+	HealthCheck *ModuleHealthCheck `json:"health_check,omitempty"`
+	// RestartPolicy optionally configures how the module is restarted after it exits or fails its
+	// health check.
+	RestartPolicy *ModuleRestartPolicy `json:"restart_policy,omitempty"`
+	// Sandbox optionally runs the module isolated from the rest of the system.
+	Sandbox *ModuleSandbox `json:"sandbox,omitempty"`
+
 	// Status refers to the validations done in the APP to make sure a module is configured correctly
 	Status           *AppValidationStatus `json:"status"`
 	alreadyValidated bool
@@ -93,6 +104,111 @@ const (
 	ModuleTypeRegistry ModuleType = "registry"
 )
 
+// ModuleResourceLimits are limits on the resources a module's processes may use. They are enforced
+// with cgroup v2 on Linux; on other systems, or when viam-server cannot manage cgroups, the module
+// runs without limits and a warning is logged. Zero values are unlimited.
+type ModuleResourceLimits struct {
+	// CPUs is the number of CPUs worth of time the module may use, e.g. 0.5 for half of one CPU.
+	CPUs float64 `json:"cpus,omitempty"`
+	// MemoryMB is the memory, in megabytes, the module may use before it is killed.
+	MemoryMB float64 `json:"memory_mb,omitempty"`
+	// MaxProcesses is the number of processes and threads the module may have.
+	MaxProcesses int `json:"max_processes,omitempty"`
+}
+
+// Validate checks if the limits are valid.
+func (l ModuleResourceLimits) Validate(path string) error {
+	if l.CPUs < 0 {
+		return resource.NewConfigValidationError(path, errors.New("resource_limits cpus cannot be negative"))
+	}
+	if l.MemoryMB < 0 {
+		return resource.NewConfigValidationError(path, errors.New("resource_limits memory_mb cannot be negative"))
+	}
+	if l.MaxProcesses < 0 {
+		return resource.NewConfigValidationError(path, errors.New("resource_limits max_processes cannot be negative"))
+	}
+	return nil
+}
+
+// ModuleHealthCheck configures probing a running module with the gRPC health checking protocol. A
+// module which fails FailureThreshold probes in a row is considered hung; it is killed and restarted
+// according to its restart policy. Zero values use the defaults.
+type ModuleHealthCheck struct {
+	// Interval is the time between probes. Defaults to 10s.
+	Interval goutils.Duration `json:"interval,omitempty"`
+	// Timeout is how long a probe may take before it fails. Defaults to 5s.
+	Timeout goutils.Duration `json:"timeout,omitempty"`
+	// FailureThreshold is the number of failed probes in a row after which the module is restarted.
+	// Defaults to 3.
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+}
+
+// Validate checks if the health check is valid.
+func (h ModuleHealthCheck) Validate(path string) error {
+	if h.Interval < 0 || h.Timeout < 0 {
+		return resource.NewConfigValidationError(path, errors.New("health_check interval and timeout cannot be negative"))
+	}
+	if h.FailureThreshold < 0 {
+		return resource.NewConfigValidationError(path, errors.New("health_check failure_threshold cannot be negative"))
+	}
+	return nil
+}
+
+// ModuleRestartPolicy configures how a module is restarted. Restart attempts which fail are retried
+// after a backoff that starts at InitialBackoff and is multiplied by BackoffMultiplier after every
+// failure, up to MaxBackoff. Zero values use the defaults, which retry every 5s forever.
+type ModuleRestartPolicy struct {
+	// MaxRestarts is the number of restart attempts allowed within Window. Once reached, the module is
+	// marked failed and is no longer restarted until it is reconfigured. Zero is unlimited.
+	MaxRestarts int `json:"max_restarts,omitempty"`
+	// Window is the period MaxRestarts applies to. Defaults to 10m.
+	Window goutils.Duration `json:"window,omitempty"`
+	// InitialBackoff is the time waited after the first failed restart attempt. Defaults to 5s.
+	InitialBackoff goutils.Duration `json:"initial_backoff,omitempty"`
+	// MaxBackoff is the longest time waited between restart attempts. Defaults to InitialBackoff.
+	MaxBackoff goutils.Duration `json:"max_backoff,omitempty"`
+	// BackoffMultiplier is what the backoff is multiplied by after every failed restart attempt.
+	// Defaults to 1.
+	BackoffMultiplier float64 `json:"backoff_multiplier,omitempty"`
+}
+
+// Validate checks if the restart policy is valid.
+func (p ModuleRestartPolicy) Validate(path string) error {
+	if p.MaxRestarts < 0 {
+		return resource.NewConfigValidationError(path, errors.New("restart_policy max_restarts cannot be negative"))
+	}
+	if p.Window < 0 || p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return resource.NewConfigValidationError(path, errors.New("restart_policy durations cannot be negative"))
+	}
+	if p.BackoffMultiplier != 0 && p.BackoffMultiplier < 1 {
+		return resource.NewConfigValidationError(path, errors.New("restart_policy backoff_multiplier must be at least 1"))
+	}
+	return nil
+}
+
+// ModuleSandbox configures running a module in its own user, mount and network namespaces, which
+// does not require viam-server to run as root. Sandboxed modules see the filesystem read-only, except
+// for their module data directory and the directory of the socket they serve on, and see only pseudo
+// devices such as /dev/null besides those listed in Devices. Sandboxing is only supported on Linux;
+// sandboxed modules fail to start elsewhere.
+type ModuleSandbox struct {
+	// Devices are the paths of devices, such as /dev/video0, the module may use.
+	Devices []string `json:"devices,omitempty"`
+	// AllowNetwork gives the module access to the network. Modules in TCP mode always have it, since
+	// they connect to viam-server over the network.
+	AllowNetwork bool `json:"allow_network,omitempty"`
+}
+
+// Validate checks if the sandbox is valid.
+func (s ModuleSandbox) Validate(path string) error {
+	for _, device := range s.Devices {
+		if !filepath.IsAbs(device) {
+			return resource.NewConfigValidationError(path, fmt.Errorf("sandbox device %q must be an absolute path", device))
+		}
+	}
+	return nil
+}
+
 // Validate checks if the config is valid.
 func (m *Module) Validate(path string) error {
 	if m.alreadyValidated {
@@ -130,6 +246,27 @@ func (m *Module) validate(path string) error {
 		return fmt.Errorf("module %s cannot use the reserved name of %s", path, reservedModuleName)
 	}
 
+	if m.ResourceLimits != nil {
+		if err := m.ResourceLimits.Validate(path); err != nil {
+			return err
+		}
+	}
+	if m.HealthCheck != nil {
+		if err := m.HealthCheck.Validate(path); err != nil {
+			return err
+		}
+	}
+	if m.RestartPolicy != nil {
+		if err := m.RestartPolicy.Validate(path); err != nil {
+			return err
+		}
+	}
+	if m.Sandbox != nil {
+		if err := m.Sandbox.Validate(path); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -6,10 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap/zaptest/observer"
 	"go.viam.com/test"
+	goutils "go.viam.com/utils"
 
 	"go.viam.com/rdk/logging"
 )
@@ -407,6 +409,60 @@ func TestMergeEnvVars(t *testing.T) {
 	})
 }
 
+func TestModuleResourceLimits(t *testing.T) {
+	m := Module{
+		Name:           "limited",
+		Type:           ModuleTypeRegistry,
+		ResourceLimits: &ModuleResourceLimits{CPUs: 0.5, MemoryMB: 256, MaxProcesses: 64},
+	}
+	test.That(t, m.Validate("path"), test.ShouldBeNil)
+
+	for _, limits := range []ModuleResourceLimits{{CPUs: -1}, {MemoryMB: -1}, {MaxProcesses: -1}} {
+		m := Module{Name: "limited", Type: ModuleTypeRegistry, ResourceLimits: &limits}
+		test.That(t, m.Validate("path"), test.ShouldNotBeNil)
+	}
+
+	var parsed Module
+	err := json.Unmarshal([]byte(`{"name": "limited", "resource_limits": {"cpus": 1.5, "memory_mb": 512}}`), &parsed)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, *parsed.ResourceLimits, test.ShouldResemble, ModuleResourceLimits{CPUs: 1.5, MemoryMB: 512})
+}
+
+func TestModuleRestartPolicy(t *testing.T) {
+	m := Module{
+		Name:          "restarted",
+		Type:          ModuleTypeRegistry,
+		HealthCheck:   &ModuleHealthCheck{Interval: goutils.Duration(time.Second), FailureThreshold: 2},
+		RestartPolicy: &ModuleRestartPolicy{MaxRestarts: 3, BackoffMultiplier: 2},
+	}
+	test.That(t, m.Validate("path"), test.ShouldBeNil)
+
+	m = Module{Name: "restarted", Type: ModuleTypeRegistry, HealthCheck: &ModuleHealthCheck{FailureThreshold: -1}}
+	test.That(t, m.Validate("path"), test.ShouldNotBeNil)
+	m = Module{Name: "restarted", Type: ModuleTypeRegistry, RestartPolicy: &ModuleRestartPolicy{BackoffMultiplier: 0.5}}
+	test.That(t, m.Validate("path"), test.ShouldNotBeNil)
+
+	var parsed Module
+	err := json.Unmarshal([]byte(`{"name": "restarted", "restart_policy": {"max_restarts": 5, "window": "1m"}}`), &parsed)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, *parsed.RestartPolicy, test.ShouldResemble, ModuleRestartPolicy{MaxRestarts: 5, Window: goutils.Duration(time.Minute)})
+}
+
+func TestModuleSandbox(t *testing.T) {
+	m := Module{Name: "sandboxed", Type: ModuleTypeRegistry, Sandbox: &ModuleSandbox{Devices: []string{"/dev/video0"}}}
+	test.That(t, m.Validate("path"), test.ShouldBeNil)
+
+	m = Module{Name: "sandboxed", Type: ModuleTypeRegistry, Sandbox: &ModuleSandbox{Devices: []string{"video0"}}}
+	err := m.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "absolute")
+
+	var parsed Module
+	err = json.Unmarshal([]byte(`{"name": "sandboxed", "sandbox": {"devices": ["/dev/i2c-1"], "allow_network": true}}`), &parsed)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, *parsed.Sandbox, test.ShouldResemble, ModuleSandbox{Devices: []string{"/dev/i2c-1"}, AllowNetwork: true})
+}
+
 // testWriteJSON is a t.Helper that serializes `value` to `path` as json.
 func testWriteJSON(t *testing.T, path string, value any) {
 	t.Helper()
@@ -0,0 +1,373 @@
+package data
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	rutils "go.viam.com/rdk/utils"
+)
+
+// CaptureFileFilter selects capture files and readings within them. Zero valued fields match
+// everything.
+type CaptureFileFilter struct {
+	ComponentType string
+	ComponentName string
+	Method        string
+	// Tags matches files which have all of the given tags.
+	Tags []string
+	// Start and End select readings whose requested time falls in [Start, End).
+	Start time.Time
+	End   time.Time
+}
+
+// MatchesMetadata returns whether a capture file with the given metadata can contain readings
+// selected by the filter.
+func (f CaptureFileFilter) MatchesMetadata(md *v1.DataCaptureMetadata) bool {
+	if md == nil {
+		return false
+	}
+	if f.ComponentType != "" && !strings.EqualFold(f.ComponentType, md.GetComponentType()) &&
+		!strings.HasSuffix(md.GetComponentType(), ":"+f.ComponentType) {
+		return false
+	}
+	if f.ComponentName != "" && f.ComponentName != md.GetComponentName() {
+		return false
+	}
+	if f.Method != "" && f.Method != md.GetMethodName() {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(md.GetTags(), tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesReading returns whether the reading falls within the filter's time range.
+func (f CaptureFileFilter) MatchesReading(reading *v1.SensorData) bool {
+	if f.Start.IsZero() && f.End.IsZero() {
+		return true
+	}
+	requested := reading.GetMetadata().GetTimeRequested().AsTime()
+	if !f.Start.IsZero() && requested.Before(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && !requested.Before(f.End) {
+		return false
+	}
+	return true
+}
+
+// CaptureFileInfo describes a capture file on disk.
+type CaptureFileInfo struct {
+	Path       string
+	Size       int64
+	InProgress bool
+	Metadata   *v1.DataCaptureMetadata
+}
+
+// ListCaptureFiles walks the capture directory and returns every completed or in progress capture
+// file whose metadata matches the filter, ordered by path. Files whose metadata cannot be read are
+// skipped. The time range of the filter is not applied; see ReadCaptureFileReadings.
+func ListCaptureFiles(captureDir string, filter CaptureFileFilter) ([]CaptureFileInfo, error) {
+	var ret []CaptureFileInfo
+	err := filepath.WalkDir(captureDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || (ext != CompletedCaptureFileExt && ext != InProgressCaptureFileExt) {
+			return nil
+		}
+
+		md, size, err := readCaptureFileMetadata(path)
+		if err != nil {
+			// In progress files may not have had their metadata flushed yet.
+			return nil
+		}
+		if !filter.MatchesMetadata(md) {
+			return nil
+		}
+		ret = append(ret, CaptureFileInfo{
+			Path:       path,
+			Size:       size,
+			InProgress: ext == InProgressCaptureFileExt,
+			Metadata:   md,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Path < ret[j].Path })
+	return ret, nil
+}
+
+func readCaptureFileMetadata(path string) (*v1.DataCaptureMetadata, int64, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	captureFile, err := ReadCaptureFile(f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return captureFile.ReadMetadata(), captureFile.Size(), nil
+}
+
+// ReadCaptureFileReadings returns the readings in the capture file at path which fall within the
+// filter's time range.
+func ReadCaptureFileReadings(path string, filter CaptureFileFilter) ([]*v1.SensorData, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	captureFile, err := ReadCaptureFile(f)
+	if err != nil {
+		return nil, err
+	}
+	readings, err := SensorDataFromCaptureFile(captureFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*v1.SensorData, 0, len(readings))
+	for _, reading := range readings {
+		if filter.MatchesReading(reading) {
+			ret = append(ret, reading)
+		}
+	}
+	return ret, nil
+}
+
+// CaptureExportFormat is the format tabular readings are exported in.
+type CaptureExportFormat string
+
+const (
+	// CaptureExportJSONL writes one JSON object per tabular reading.
+	CaptureExportJSONL CaptureExportFormat = "jsonl"
+	// CaptureExportCSV writes one row per tabular reading, with one column per flattened field.
+	CaptureExportCSV CaptureExportFormat = "csv"
+)
+
+// exportedReading is the JSONL representation of a tabular reading.
+type exportedReading struct {
+	ComponentType string         `json:"component_type"`
+	ComponentName string         `json:"component_name"`
+	Method        string         `json:"method"`
+	Tags          []string       `json:"tags,omitempty"`
+	TimeRequested time.Time      `json:"time_requested"`
+	TimeReceived  time.Time      `json:"time_received"`
+	Data          map[string]any `json:"data"`
+}
+
+// CaptureExportSummary counts what an ExportCaptureFiles call wrote, and which files it skipped.
+type CaptureExportSummary struct {
+	TabularReadings int
+	BinaryFiles     int
+	// InProgressFiles counts the files skipped because they are still being written to.
+	InProgressFiles int
+	// ReadErrors holds an error for each file skipped because it could not be read.
+	ReadErrors []error
+}
+
+// ExportCaptureFiles exports the readings of the given capture files that match the filter's time
+// range into outputDir. Tabular readings from every file are written to a single
+// "tabular.jsonl" or "tabular.csv" file. Each binary reading is written to its own file, named
+// after the capture file, with the file's original extension. In progress files and files which
+// cannot be read are skipped, and counted in the summary.
+func ExportCaptureFiles(
+	files []CaptureFileInfo,
+	filter CaptureFileFilter,
+	format CaptureExportFormat,
+	outputDir string,
+) (CaptureExportSummary, error) {
+	var summary CaptureExportSummary
+	if format != CaptureExportJSONL && format != CaptureExportCSV {
+		return summary, errors.Errorf("unsupported export format %q, expected %q or %q", format, CaptureExportJSONL, CaptureExportCSV)
+	}
+	if err := os.MkdirAll(outputDir, 0o700); err != nil {
+		return summary, err
+	}
+
+	var tabular []exportedReading
+	for _, file := range files {
+		if file.InProgress {
+			summary.InProgressFiles++
+			continue
+		}
+		readings, err := ReadCaptureFileReadings(file.Path, filter)
+		if err != nil {
+			summary.ReadErrors = append(summary.ReadErrors, errors.Wrapf(err, "failed to read %s", file.Path))
+			continue
+		}
+
+		md := file.Metadata
+		// Capture files from different resources can share a timestamp, so prefix with the resource
+		// name and method to avoid collisions in the flat output directory.
+		base := fmt.Sprintf("%s_%s_%s", md.GetComponentName(), md.GetMethodName(),
+			strings.TrimSuffix(filepath.Base(file.Path), filepath.Ext(file.Path)))
+		for idx, reading := range readings {
+			if IsBinary(reading) {
+				name := base
+				if len(readings) > 1 {
+					name = fmt.Sprintf("%s_%d", base, idx)
+				}
+				outPath := filepath.Join(outputDir, name+binaryFileExt(md))
+				if err := os.WriteFile(outPath, reading.GetBinary(), 0o600); err != nil {
+					return summary, err
+				}
+				summary.BinaryFiles++
+				continue
+			}
+
+			tabular = append(tabular, exportedReading{
+				ComponentType: md.GetComponentType(),
+				ComponentName: md.GetComponentName(),
+				Method:        md.GetMethodName(),
+				Tags:          md.GetTags(),
+				TimeRequested: reading.GetMetadata().GetTimeRequested().AsTime(),
+				TimeReceived:  reading.GetMetadata().GetTimeReceived().AsTime(),
+				Data:          reading.GetStruct().AsMap(),
+			})
+		}
+	}
+
+	if len(tabular) == 0 {
+		return summary, nil
+	}
+	sort.SliceStable(tabular, func(i, j int) bool { return tabular[i].TimeRequested.Before(tabular[j].TimeRequested) })
+
+	//nolint:gosec
+	out, err := os.Create(filepath.Join(outputDir, "tabular."+string(format)))
+	if err != nil {
+		return summary, err
+	}
+	if format == CaptureExportJSONL {
+		err = writeReadingsJSONL(out, tabular)
+	} else {
+		err = writeReadingsCSV(out, tabular)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return summary, err
+	}
+	summary.TabularReadings = len(tabular)
+	return summary, nil
+}
+
+// binaryFileExt returns the extension binary readings from a capture file should be written with.
+func binaryFileExt(md *v1.DataCaptureMetadata) string {
+	if ext := md.GetFileExtension(); ext != "" {
+		return ext
+	}
+	switch md.GetMimeType() {
+	case rutils.MimeTypeJPEG:
+		return ExtJpeg
+	case rutils.MimeTypePNG:
+		return ExtPng
+	case rutils.MimeTypePCD:
+		return ExtPcd
+	default:
+		return ".bin"
+	}
+}
+
+func writeReadingsJSONL(w io.Writer, readings []exportedReading) error {
+	encoder := json.NewEncoder(w)
+	for _, reading := range readings {
+		if err := encoder.Encode(reading); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenReading flattens nested maps into dot separated keys, e.g. {"a": {"b": 1}} becomes
+// {"a.b": 1}. Lists are encoded as JSON.
+func flattenReading(prefix string, value any, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			flattenReading(name, inner, out)
+		}
+	case []any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			out[prefix] = fmt.Sprint(v)
+			return
+		}
+		out[prefix] = string(encoded)
+	case nil:
+		out[prefix] = ""
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+func writeReadingsCSV(w io.Writer, readings []exportedReading) error {
+	flattened := make([]map[string]string, len(readings))
+	columnSet := map[string]struct{}{}
+	for idx, reading := range readings {
+		flattened[idx] = map[string]string{}
+		flattenReading("", reading.Data, flattened[idx])
+		for column := range flattened[idx] {
+			columnSet[column] = struct{}{}
+		}
+	}
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+
+	writer := csv.NewWriter(w)
+	header := append([]string{"time_requested", "time_received", "component_type", "component_name", "method", "tags"}, columns...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for idx, reading := range readings {
+		row := []string{
+			reading.TimeRequested.Format(time.RFC3339Nano),
+			reading.TimeReceived.Format(time.RFC3339Nano),
+			reading.ComponentType,
+			reading.ComponentName,
+			reading.Method,
+			strings.Join(reading.Tags, ";"),
+		}
+		for _, column := range columns {
+			row = append(row, flattened[idx][column])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,171 @@
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+func TestListAndExportCaptureFiles(t *testing.T) {
+	captureDir := t.TempDir()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sensorMetadata := func(offset time.Duration) *v1.SensorMetadata {
+		return &v1.SensorMetadata{
+			TimeRequested: timestamppb.New(start.Add(offset)),
+			TimeReceived:  timestamppb.New(start.Add(offset + time.Millisecond)),
+		}
+	}
+
+	// A tabular capture file with three readings.
+	tabularMD, _ := BuildCaptureMetadata(resource.APINamespaceRDK.WithComponentType("sensor"), "sensor1", "Readings",
+		nil, nil, []string{"tagA"})
+	test.That(t, os.MkdirAll(filepath.Join(captureDir, "sensor"), 0o700), test.ShouldBeNil)
+	tabularBuffer := NewCaptureBuffer(filepath.Join(captureDir, "sensor"), tabularMD, 4096)
+	for idx := range 3 {
+		reading, err := structpb.NewStruct(map[string]any{"readings": map[string]any{"a": idx, "b": "x"}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, tabularBuffer.WriteTabular(&v1.SensorData{
+			Metadata: sensorMetadata(time.Duration(idx) * time.Minute),
+			Data:     &v1.SensorData_Struct{Struct: reading},
+		}), test.ShouldBeNil)
+	}
+	test.That(t, tabularBuffer.Flush(), test.ShouldBeNil)
+
+	// A binary capture file.
+	binaryMD, _ := BuildCaptureMetadata(resource.APINamespaceRDK.WithComponentType("camera"), "cam1", readImage,
+		map[string]any{"mime_type": utils.MimeTypeJPEG}, nil, nil)
+	test.That(t, os.MkdirAll(filepath.Join(captureDir, "camera"), 0o700), test.ShouldBeNil)
+	binaryBuffer := NewCaptureBuffer(filepath.Join(captureDir, "camera"), binaryMD, 4096)
+	test.That(t, binaryBuffer.WriteBinary(&v1.SensorData{
+		Metadata: sensorMetadata(time.Minute),
+		Data:     &v1.SensorData_Binary{Binary: []byte("not really a jpeg")},
+	}, utils.MimeTypeJPEG), test.ShouldBeNil)
+
+	t.Run("list", func(t *testing.T) {
+		files, err := ListCaptureFiles(captureDir, CaptureFileFilter{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(files), test.ShouldEqual, 2)
+
+		files, err = ListCaptureFiles(captureDir, CaptureFileFilter{ComponentType: "sensor"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(files), test.ShouldEqual, 1)
+		test.That(t, files[0].Metadata.GetComponentName(), test.ShouldEqual, "sensor1")
+		test.That(t, files[0].InProgress, test.ShouldBeFalse)
+
+		files, err = ListCaptureFiles(captureDir, CaptureFileFilter{Tags: []string{"tagA", "tagB"}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(files), test.ShouldEqual, 0)
+
+		files, err = ListCaptureFiles(captureDir, CaptureFileFilter{Method: readImage})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(files), test.ShouldEqual, 1)
+	})
+
+	t.Run("time range", func(t *testing.T) {
+		files, err := ListCaptureFiles(captureDir, CaptureFileFilter{ComponentName: "sensor1"})
+		test.That(t, err, test.ShouldBeNil)
+		readings, err := ReadCaptureFileReadings(files[0].Path, CaptureFileFilter{
+			Start: start.Add(30 * time.Second),
+			End:   start.Add(2 * time.Minute),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(readings), test.ShouldEqual, 1)
+	})
+
+	t.Run("export jsonl", func(t *testing.T) {
+		files, err := ListCaptureFiles(captureDir, CaptureFileFilter{})
+		test.That(t, err, test.ShouldBeNil)
+
+		outputDir := t.TempDir()
+		summary, err := ExportCaptureFiles(files, CaptureFileFilter{}, CaptureExportJSONL, outputDir)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, summary, test.ShouldResemble, CaptureExportSummary{TabularReadings: 3, BinaryFiles: 1})
+
+		//nolint:gosec
+		jsonl, err := os.Open(filepath.Join(outputDir, "tabular.jsonl"))
+		test.That(t, err, test.ShouldBeNil)
+		defer jsonl.Close() //nolint:errcheck
+		scanner := bufio.NewScanner(jsonl)
+		lines := 0
+		for scanner.Scan() {
+			var reading map[string]any
+			test.That(t, json.Unmarshal(scanner.Bytes(), &reading), test.ShouldBeNil)
+			test.That(t, reading["component_name"], test.ShouldEqual, "sensor1")
+			lines++
+		}
+		test.That(t, lines, test.ShouldEqual, 3)
+
+		jpegs, err := filepath.Glob(filepath.Join(outputDir, "cam1_ReadImage_*.jpeg"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(jpegs), test.ShouldEqual, 1)
+		//nolint:gosec
+		contents, err := os.ReadFile(jpegs[0])
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(contents), test.ShouldEqual, "not really a jpeg")
+	})
+
+	t.Run("export csv", func(t *testing.T) {
+		files, err := ListCaptureFiles(captureDir, CaptureFileFilter{ComponentName: "sensor1"})
+		test.That(t, err, test.ShouldBeNil)
+
+		outputDir := t.TempDir()
+		_, err = ExportCaptureFiles(files, CaptureFileFilter{}, CaptureExportCSV, outputDir)
+		test.That(t, err, test.ShouldBeNil)
+
+		//nolint:gosec
+		contents, err := os.ReadFile(filepath.Join(outputDir, "tabular.csv"))
+		test.That(t, err, test.ShouldBeNil)
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		test.That(t, len(lines), test.ShouldEqual, 4)
+		test.That(t, lines[0], test.ShouldEqual,
+			"time_requested,time_received,component_type,component_name,method,tags,readings.a,readings.b")
+		test.That(t, lines[1], test.ShouldEndWith, ",rdk:component:sensor,sensor1,Readings,tagA,0,x")
+
+		_, err = ExportCaptureFiles(files, CaptureFileFilter{}, "xml", outputDir)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("export skips in progress and unreadable files", func(t *testing.T) {
+		files, err := ListCaptureFiles(captureDir, CaptureFileFilter{ComponentName: "sensor1"})
+		test.That(t, err, test.ShouldBeNil)
+
+		// A capture file which is still being written to.
+		progressDir := t.TempDir()
+		progressBuffer := NewCaptureBuffer(progressDir, tabularMD, 1<<20)
+		reading, err := structpb.NewStruct(map[string]any{"readings": map[string]any{"a": 3}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progressBuffer.WriteTabular(&v1.SensorData{
+			Metadata: sensorMetadata(time.Hour),
+			Data:     &v1.SensorData_Struct{Struct: reading},
+		}), test.ShouldBeNil)
+		inProgress, err := ListCaptureFiles(progressDir, CaptureFileFilter{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(inProgress), test.ShouldEqual, 1)
+		test.That(t, inProgress[0].InProgress, test.ShouldBeTrue)
+		test.That(t, filepath.Ext(inProgress[0].Path), test.ShouldEqual, InProgressCaptureFileExt)
+		files = append(files, inProgress...)
+
+		// A capture file which was removed after it was listed.
+		files = append(files, CaptureFileInfo{Path: filepath.Join(progressDir, "gone"+CompletedCaptureFileExt), Metadata: tabularMD})
+
+		outputDir := t.TempDir()
+		summary, err := ExportCaptureFiles(files, CaptureFileFilter{}, CaptureExportJSONL, outputDir)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, summary.TabularReadings, test.ShouldEqual, 3)
+		test.That(t, summary.InProgressFiles, test.ShouldEqual, 1)
+		test.That(t, summary.ReadErrors, test.ShouldHaveLength, 1)
+		test.That(t, summary.ReadErrors[0].Error(), test.ShouldContainSubstring, "gone")
+	})
+}
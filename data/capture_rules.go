@@ -0,0 +1,264 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// CaptureRules are conditions a collector evaluates against each capture before it is written. A
+// capture is only written when every configured rule keeps it. A nil *CaptureRules keeps everything.
+type CaptureRules struct {
+	// Deadband only writes tabular readings which differ from the last written reading.
+	Deadband *DeadbandRule `json:"deadband,omitempty"`
+	// Detections only writes binary captures annotated with a sufficiently confident detection or
+	// classification, e.g. from a vision service's CaptureAllFromCamera.
+	Detections *DetectionRule `json:"detections,omitempty"`
+	// Burst only captures for a period of time after a sensor reports a trigger.
+	Burst *BurstRule `json:"burst,omitempty"`
+}
+
+// DeadbandRule writes a tabular reading when any of its fields changed by more than Threshold
+// since the last written reading. Fields are addressed by their dot separated path within the
+// reading, e.g. "readings.temperature". Non numeric fields are compared for equality.
+type DeadbandRule struct {
+	// Fields restricts the comparison to the given paths. All fields are compared when empty.
+	Fields []string `json:"fields,omitempty"`
+	// Threshold is the absolute change a numeric field must exceed. Zero writes on any change.
+	Threshold float64 `json:"threshold"`
+	// MaxIntervalSecs, when positive, writes a reading at least this often even if nothing changed.
+	MaxIntervalSecs float64 `json:"max_interval_secs,omitempty"`
+}
+
+// DetectionRule keeps binary captures which are annotated with a bounding box or classification
+// whose confidence is at least MinConfidence.
+type DetectionRule struct {
+	MinConfidence float64 `json:"min_confidence"`
+	// Labels restricts which annotations count. All labels count when empty.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// BurstRule only captures for DurationSecs after the sensor named Sensor reports a trigger. The
+// sensor is polled on each capture interval while no burst is in progress. The value of Key in its
+// readings triggers a burst when it is true, or when it is a number at or above Threshold.
+type BurstRule struct {
+	Sensor       string  `json:"sensor"`
+	Key          string  `json:"key"`
+	Threshold    float64 `json:"threshold,omitempty"`
+	DurationSecs float64 `json:"duration_secs"`
+}
+
+// TriggerSensorFunc returns the readings of a sensor which triggers a BurstRule or a RingBuffer.
+type TriggerSensorFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// Validate returns an error if the rules are invalid for a collector of the given data type.
+func (r *CaptureRules) Validate(dataType CaptureType) error {
+	if r == nil {
+		return nil
+	}
+	if r.Deadband != nil {
+		if dataType != CaptureTypeTabular {
+			return errors.New("capture_rules.deadband only applies to tabular data")
+		}
+		if r.Deadband.Threshold < 0 {
+			return errors.New("capture_rules.deadband.threshold can't be negative")
+		}
+		if r.Deadband.MaxIntervalSecs < 0 {
+			return errors.New("capture_rules.deadband.max_interval_secs can't be negative")
+		}
+	}
+	if r.Detections != nil {
+		if dataType != CaptureTypeBinary {
+			return errors.New("capture_rules.detections only applies to binary data")
+		}
+		if r.Detections.MinConfidence < 0 || r.Detections.MinConfidence > 1 {
+			return errors.New("capture_rules.detections.min_confidence must be between 0 and 1")
+		}
+	}
+	if r.Burst != nil {
+		if r.Burst.Sensor == "" || r.Burst.Key == "" {
+			return errors.New("capture_rules.burst requires a sensor and key")
+		}
+		if r.Burst.DurationSecs <= 0 {
+			return errors.New("capture_rules.burst.duration_secs must be positive")
+		}
+	}
+	return nil
+}
+
+// captureFilter holds the state needed to evaluate CaptureRules across captures. It is only used by
+// the collector's capture goroutine and is therefore not safe for concurrent use.
+type captureFilter struct {
+	rules   *CaptureRules
+	trigger TriggerSensorFunc
+
+	lastWritten     map[string]*structpb.Value
+	lastWrittenTime time.Time
+	burstUntil      time.Time
+}
+
+func newCaptureFilter(rules *CaptureRules, trigger TriggerSensorFunc) *captureFilter {
+	if rules == nil || (rules.Deadband == nil && rules.Detections == nil && rules.Burst == nil) {
+		return nil
+	}
+	return &captureFilter{rules: rules, trigger: trigger}
+}
+
+// shouldCapture returns whether the collector should capture at time now. It is false while a
+// BurstRule is configured and no burst is in progress.
+func (f *captureFilter) shouldCapture(ctx context.Context, now time.Time) (bool, error) {
+	if f == nil || f.rules.Burst == nil {
+		return true, nil
+	}
+	if now.Before(f.burstUntil) {
+		return true, nil
+	}
+	if f.trigger == nil {
+		return false, errors.Errorf("burst trigger sensor %q is not available", f.rules.Burst.Sensor)
+	}
+
+	readings, err := f.trigger(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get readings from burst trigger sensor %q", f.rules.Burst.Sensor)
+	}
+	if !isTriggered(readings[f.rules.Burst.Key], f.rules.Burst.Threshold) {
+		return false, nil
+	}
+	f.burstUntil = now.Add(time.Duration(f.rules.Burst.DurationSecs * float64(time.Second)))
+	return true, nil
+}
+
+// isTriggered returns whether a trigger sensor reading is true, or is a number at or above threshold.
+func isTriggered(value interface{}, threshold float64) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v >= threshold
+	case float32:
+		return float64(v) >= threshold
+	case int:
+		return float64(v) >= threshold
+	case int64:
+		return float64(v) >= threshold
+	case int32:
+		return float64(v) >= threshold
+	default:
+		return false
+	}
+}
+
+// filter returns the result with the binaries that fail the DetectionRule removed, and whether the
+// result should be written at all. Results that are kept update the deadband state.
+func (f *captureFilter) filter(result CaptureResult, now time.Time) (CaptureResult, bool) {
+	if f == nil {
+		return result, true
+	}
+
+	if rule := f.rules.Detections; rule != nil && result.Type == CaptureTypeBinary {
+		kept := make([]Binary, 0, len(result.Binaries))
+		for _, binary := range result.Binaries {
+			if rule.matches(binary.Annotations) {
+				kept = append(kept, binary)
+			}
+		}
+		if len(kept) == 0 {
+			return result, false
+		}
+		result.Binaries = kept
+	}
+
+	if rule := f.rules.Deadband; rule != nil && result.Type == CaptureTypeTabular {
+		current := map[string]*structpb.Value{}
+		flattenStruct("", result.TabularData.Payload, current)
+		heartbeat := rule.MaxIntervalSecs > 0 &&
+			now.Sub(f.lastWrittenTime) >= time.Duration(rule.MaxIntervalSecs*float64(time.Second))
+		if f.lastWritten != nil && !heartbeat && !rule.changed(f.lastWritten, current) {
+			return result, false
+		}
+		f.lastWritten = current
+		f.lastWrittenTime = now
+	}
+	return result, true
+}
+
+func (rule *DetectionRule) matches(annotations Annotations) bool {
+	qualifies := func(label string, confidence *float64) bool {
+		if len(rule.Labels) > 0 && !slices.Contains(rule.Labels, label) {
+			return false
+		}
+		// Annotations without a confidence only count when any confidence is acceptable.
+		if confidence == nil {
+			return rule.MinConfidence == 0
+		}
+		return *confidence >= rule.MinConfidence
+	}
+	for _, bb := range annotations.BoundingBoxes {
+		if qualifies(bb.Label, bb.Confidence) {
+			return true
+		}
+	}
+	for _, c := range annotations.Classifications {
+		if qualifies(c.Label, c.Confidence) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *DeadbandRule) changed(last, current map[string]*structpb.Value) bool {
+	fields := rule.Fields
+	if len(fields) == 0 {
+		if len(last) != len(current) {
+			return true
+		}
+		fields = make([]string, 0, len(current))
+		for field := range current {
+			fields = append(fields, field)
+		}
+	}
+
+	for _, field := range fields {
+		lastValue, lastOK := last[field]
+		currentValue, currentOK := current[field]
+		if lastOK != currentOK {
+			return true
+		}
+		if !currentOK {
+			continue
+		}
+		lastNumber, lastIsNumber := lastValue.GetKind().(*structpb.Value_NumberValue)
+		currentNumber, currentIsNumber := currentValue.GetKind().(*structpb.Value_NumberValue)
+		if lastIsNumber && currentIsNumber {
+			diff := math.Abs(currentNumber.NumberValue - lastNumber.NumberValue)
+			if diff > rule.Threshold {
+				return true
+			}
+			continue
+		}
+		if fmt.Sprint(lastValue.AsInterface()) != fmt.Sprint(currentValue.AsInterface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenStruct flattens nested structs into dot separated keys. Lists are treated as leaf values.
+func flattenStruct(prefix string, s *structpb.Struct, out map[string]*structpb.Value) {
+	for key, value := range s.GetFields() {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		if nested := value.GetStructValue(); nested != nil {
+			flattenStruct(name, nested, out)
+			continue
+		}
+		out[name] = value
+	}
+}
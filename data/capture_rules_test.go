@@ -0,0 +1,145 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func tabularResult(t *testing.T, fields map[string]interface{}) CaptureResult {
+	t.Helper()
+	payload, err := structpb.NewStruct(fields)
+	test.That(t, err, test.ShouldBeNil)
+	return CaptureResult{Type: CaptureTypeTabular, TabularData: TabularData{Payload: payload}}
+}
+
+func TestCaptureRulesValidate(t *testing.T) {
+	var nilRules *CaptureRules
+	test.That(t, nilRules.Validate(CaptureTypeTabular), test.ShouldBeNil)
+
+	deadband := &CaptureRules{Deadband: &DeadbandRule{Threshold: 1}}
+	test.That(t, deadband.Validate(CaptureTypeTabular), test.ShouldBeNil)
+	test.That(t, deadband.Validate(CaptureTypeBinary), test.ShouldNotBeNil)
+
+	detections := &CaptureRules{Detections: &DetectionRule{MinConfidence: 0.5}}
+	test.That(t, detections.Validate(CaptureTypeBinary), test.ShouldBeNil)
+	test.That(t, detections.Validate(CaptureTypeTabular), test.ShouldNotBeNil)
+	detections.Detections.MinConfidence = 2
+	test.That(t, detections.Validate(CaptureTypeBinary), test.ShouldNotBeNil)
+
+	burst := &CaptureRules{Burst: &BurstRule{Sensor: "s", Key: "k"}}
+	test.That(t, burst.Validate(CaptureTypeBinary), test.ShouldNotBeNil)
+	burst.Burst.DurationSecs = 5
+	test.That(t, burst.Validate(CaptureTypeBinary), test.ShouldBeNil)
+}
+
+func TestDeadbandRule(t *testing.T) {
+	now := time.Now()
+
+	t.Run("threshold", func(t *testing.T) {
+		f := newCaptureFilter(&CaptureRules{Deadband: &DeadbandRule{Threshold: 1}}, nil)
+		keep := func(temp float64) bool {
+			_, ok := f.filter(tabularResult(t, map[string]interface{}{"readings": map[string]interface{}{"temp": temp}}), now)
+			return ok
+		}
+		test.That(t, keep(20), test.ShouldBeTrue)
+		test.That(t, keep(20.5), test.ShouldBeFalse)
+		// the change is measured against the last written reading, not the last captured one
+		test.That(t, keep(21.01), test.ShouldBeTrue)
+		test.That(t, keep(20.5), test.ShouldBeFalse)
+		test.That(t, keep(19), test.ShouldBeTrue)
+	})
+
+	t.Run("on change of selected fields", func(t *testing.T) {
+		f := newCaptureFilter(&CaptureRules{Deadband: &DeadbandRule{Fields: []string{"readings.state"}}}, nil)
+		keep := func(state string, counter float64) bool {
+			_, ok := f.filter(tabularResult(t, map[string]interface{}{
+				"readings": map[string]interface{}{"state": state, "counter": counter},
+			}), now)
+			return ok
+		}
+		test.That(t, keep("idle", 1), test.ShouldBeTrue)
+		test.That(t, keep("idle", 2), test.ShouldBeFalse)
+		test.That(t, keep("moving", 3), test.ShouldBeTrue)
+	})
+
+	t.Run("max interval", func(t *testing.T) {
+		f := newCaptureFilter(&CaptureRules{Deadband: &DeadbandRule{MaxIntervalSecs: 10}}, nil)
+		reading := tabularResult(t, map[string]interface{}{"a": 1})
+		_, ok := f.filter(reading, now)
+		test.That(t, ok, test.ShouldBeTrue)
+		_, ok = f.filter(reading, now.Add(5*time.Second))
+		test.That(t, ok, test.ShouldBeFalse)
+		_, ok = f.filter(reading, now.Add(10*time.Second))
+		test.That(t, ok, test.ShouldBeTrue)
+	})
+}
+
+func TestDetectionRule(t *testing.T) {
+	low, high := 0.2, 0.9
+	f := newCaptureFilter(&CaptureRules{Detections: &DetectionRule{MinConfidence: 0.5, Labels: []string{"person"}}}, nil)
+	result := CaptureResult{
+		Type: CaptureTypeBinary,
+		Binaries: []Binary{
+			{Payload: []byte("a"), Annotations: Annotations{BoundingBoxes: []BoundingBox{{Label: "person", Confidence: &low}}}},
+			{Payload: []byte("b"), Annotations: Annotations{BoundingBoxes: []BoundingBox{{Label: "person", Confidence: &high}}}},
+			{Payload: []byte("c"), Annotations: Annotations{Classifications: []Classification{{Label: "dog", Confidence: &high}}}},
+			{Payload: []byte("d")},
+		},
+	}
+
+	filtered, ok := f.filter(result, time.Now())
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, filtered.Binaries, test.ShouldHaveLength, 1)
+	test.That(t, string(filtered.Binaries[0].Payload), test.ShouldEqual, "b")
+
+	result.Binaries = result.Binaries[2:]
+	_, ok = f.filter(result, time.Now())
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestBurstRule(t *testing.T) {
+	now := time.Now()
+	var reading interface{}
+	var readErr error
+	polls := 0
+	trigger := func(ctx context.Context) (map[string]interface{}, error) {
+		polls++
+		return map[string]interface{}{"motion": reading}, readErr
+	}
+	f := newCaptureFilter(&CaptureRules{Burst: &BurstRule{Sensor: "pir", Key: "motion", Threshold: 0.5, DurationSecs: 10}}, trigger)
+
+	reading = 0.1
+	ok, err := f.shouldCapture(context.Background(), now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	reading = 0.7
+	ok, err = f.shouldCapture(context.Background(), now.Add(time.Second))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	// the sensor is not polled during a burst
+	reading = false
+	ok, err = f.shouldCapture(context.Background(), now.Add(10*time.Second))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, polls, test.ShouldEqual, 2)
+
+	ok, err = f.shouldCapture(context.Background(), now.Add(11*time.Second))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	reading = true
+	ok, err = f.shouldCapture(context.Background(), now.Add(12*time.Second))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	readErr = errors.New("boom")
+	_, err = f.shouldCapture(context.Background(), now.Add(30*time.Second))
+	test.That(t, err, test.ShouldNotBeNil)
+}
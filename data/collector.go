@@ -60,6 +60,14 @@ type Collector interface {
 	Flush()
 }
 
+// TriggerableCollector is a Collector configured with a RingBufferConfig.
+type TriggerableCollector interface {
+	Collector
+	// Trigger writes the captures held in memory to the capture directory and starts the post
+	// trigger window.
+	Trigger() error
+}
+
 type collector struct {
 	clock clock.Clock
 
@@ -71,7 +79,7 @@ type collector struct {
 	captureErrors   chan error
 	interval        time.Duration
 	params          map[string]*anypb.Any
-	// `lock` serializes calls to `Flush` and `Close`.
+	// `lock` serializes calls to `Flush`, `Close` and `Trigger`.
 	lock             sync.Mutex
 	logger           logging.Logger
 	captureWorkers   sync.WaitGroup
@@ -82,6 +90,51 @@ type collector struct {
 	target           CaptureBufferedWriter
 	lastLoggedErrors map[string]int64
 	dataType         CaptureType
+	// filter is nil when no capture rules are configured.
+	filter *captureFilter
+	// ringBuffer and ringBufferConfig are nil unless black box capture is configured, in which
+	// case ringBuffer is also target.
+	ringBuffer        *RingBuffer
+	ringBufferConfig  *RingBufferConfig
+	ringBufferTrigger TriggerSensorFunc
+}
+
+// Trigger implements TriggerableCollector. It returns an error if the collector was not configured
+// with a RingBufferConfig or has been closed.
+func (c *collector) Trigger() error {
+	if c.ringBuffer == nil {
+		return errors.New("collector is not configured with a ring buffer")
+	}
+	// Hold the lock so that Close's final flush happens after the ring buffer is written.
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.cancelCtx.Err() != nil {
+		return errors.New("collector is closed")
+	}
+	c.logger.Infow("ring buffer triggered", "component", c.componentName, "method", c.methodName)
+	return c.ringBuffer.Trigger()
+}
+
+// maybeTriggerFromSensor polls the ring buffer's trigger sensor, if one is configured, while the ring
+// buffer is not already triggered.
+func (c *collector) maybeTriggerFromSensor() {
+	if c.ringBuffer == nil || c.ringBufferConfig.TriggerSensor == "" || c.ringBuffer.Triggered() {
+		return
+	}
+	if c.ringBufferTrigger == nil {
+		c.captureErrors <- errors.Errorf("ring buffer trigger sensor %q is not available", c.ringBufferConfig.TriggerSensor)
+		return
+	}
+	readings, err := c.ringBufferTrigger(c.cancelCtx)
+	if err != nil {
+		c.captureErrors <- errors.Wrapf(err, "failed to get readings from ring buffer trigger sensor %q", c.ringBufferConfig.TriggerSensor)
+		return
+	}
+	if isTriggered(readings[c.ringBufferConfig.TriggerKey], c.ringBufferConfig.TriggerThreshold) {
+		if err := c.Trigger(); err != nil {
+			c.captureErrors <- errors.Wrap(err, "failed to write ring buffer")
+		}
+	}
 }
 
 // Close closes the channels backing the Collector. It should always be called before disposing of a Collector to avoid
@@ -202,6 +255,16 @@ func (c *collector) validateReadingType(t CaptureType) error {
 }
 
 func (c *collector) getAndPushNextReading() {
+	shouldCapture, err := c.filter.shouldCapture(c.cancelCtx, c.clock.Now())
+	if err != nil {
+		c.captureErrors <- err
+		return
+	}
+	if !shouldCapture {
+		return
+	}
+	c.maybeTriggerFromSensor()
+
 	result, err := c.captureFunc(c.cancelCtx, c.params)
 
 	if c.cancelCtx.Err() != nil {
@@ -213,6 +276,11 @@ func (c *collector) getAndPushNextReading() {
 			c.logger.Debug("capture filtered out by modular resource")
 			return
 		}
+		if c.ringBuffer != nil && c.ringBufferConfig.TriggerOnError && !c.ringBuffer.Triggered() {
+			if triggerErr := c.Trigger(); triggerErr != nil {
+				c.captureErrors <- errors.Wrap(triggerErr, "failed to write ring buffer")
+			}
+		}
 		c.captureErrors <- errors.Wrap(err, "error while capturing data")
 		return
 	}
@@ -227,6 +295,11 @@ func (c *collector) getAndPushNextReading() {
 		return
 	}
 
+	result, keep := c.filter.filter(result, c.clock.Now())
+	if !keep {
+		return
+	}
+
 	select {
 	// If c.captureResults is full, c.captureResults <- a can block indefinitely.
 	// This additional select block allows cancel to
@@ -250,23 +323,33 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 	} else {
 		c = params.Clock
 	}
+	target := params.Target
+	var ringBuffer *RingBuffer
+	if params.RingBuffer != nil {
+		ringBuffer = NewRingBuffer(params.Target, *params.RingBuffer, c)
+		target = ringBuffer
+	}
 	return &collector{
-		componentName:    params.ComponentName,
-		componentType:    params.ComponentType,
-		methodName:       params.MethodName,
-		mongoCollection:  params.MongoCollection,
-		captureResults:   make(chan CaptureResult, params.QueueSize),
-		captureErrors:    make(chan error, params.QueueSize),
-		dataType:         params.DataType,
-		interval:         params.Interval,
-		params:           params.MethodParams,
-		logger:           params.Logger,
-		cancelCtx:        cancelCtx,
-		cancel:           cancelFunc,
-		captureFunc:      captureFunc,
-		target:           params.Target,
-		clock:            c,
-		lastLoggedErrors: make(map[string]int64, 0),
+		componentName:     params.ComponentName,
+		componentType:     params.ComponentType,
+		methodName:        params.MethodName,
+		mongoCollection:   params.MongoCollection,
+		captureResults:    make(chan CaptureResult, params.QueueSize),
+		captureErrors:     make(chan error, params.QueueSize),
+		dataType:          params.DataType,
+		interval:          params.Interval,
+		params:            params.MethodParams,
+		logger:            params.Logger,
+		cancelCtx:         cancelCtx,
+		cancel:            cancelFunc,
+		captureFunc:       captureFunc,
+		target:            target,
+		clock:             c,
+		lastLoggedErrors:  make(map[string]int64, 0),
+		filter:            newCaptureFilter(params.Rules, params.BurstTrigger),
+		ringBuffer:        ringBuffer,
+		ringBufferConfig:  params.RingBuffer,
+		ringBufferTrigger: params.RingBufferTrigger,
 	}, nil
 }
 
@@ -31,6 +31,14 @@ type CollectorParams struct {
 	MongoCollection *mongo.Collection
 	QueueSize       int
 	Target          CaptureBufferedWriter
+	// Rules, when non nil, decide which captures are written to Target.
+	Rules *CaptureRules
+	// BurstTrigger returns the readings of the sensor named by Rules.Burst.
+	BurstTrigger TriggerSensorFunc
+	// RingBuffer, when non nil, holds captures in memory until the collector is triggered.
+	RingBuffer *RingBufferConfig
+	// RingBufferTrigger returns the readings of the sensor named by RingBuffer.TriggerSensor.
+	RingBufferTrigger TriggerSensorFunc
 }
 
 // Validate validates that p contains all required parameters.
@@ -47,7 +55,10 @@ func (p CollectorParams) Validate() error {
 	if p.DataType != CaptureTypeBinary && p.DataType != CaptureTypeTabular {
 		return errors.New("invalid DataType")
 	}
-	return nil
+	if err := p.RingBuffer.Validate(); err != nil {
+		return err
+	}
+	return p.Rules.Validate(p.DataType)
 }
 
 // MethodMetadata contains the metadata identifying a component method that we are going to capture and collect.
@@ -0,0 +1,192 @@
+package data
+
+import (
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultRingBufferMaxBytes bounds the memory used by a RingBuffer whose config does not set MaxBytes.
+const defaultRingBufferMaxBytes = 64 * 1024 * 1024
+
+// RingBufferConfig configures "black box" capture: the collector keeps the last PreTriggerSecs of
+// captures in memory and only writes them to the capture directory when triggered, along with
+// everything captured in the following PostTriggerSecs.
+type RingBufferConfig struct {
+	PreTriggerSecs  float64 `json:"pre_trigger_secs"`
+	PostTriggerSecs float64 `json:"post_trigger_secs"`
+	// MaxBytes bounds the size of the captures held in memory. The oldest captures are dropped first.
+	// Defaults to 64MiB.
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+	// TriggerOnError triggers when capturing from the resource fails.
+	TriggerOnError bool `json:"trigger_on_error,omitempty"`
+	// TriggerSensor is polled on each capture interval. The value of TriggerKey in its readings
+	// triggers when it is true, or when it is a number at or above TriggerThreshold.
+	TriggerSensor    string  `json:"trigger_sensor,omitempty"`
+	TriggerKey       string  `json:"trigger_key,omitempty"`
+	TriggerThreshold float64 `json:"trigger_threshold,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (c *RingBufferConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.PreTriggerSecs <= 0 {
+		return errors.New("ring_buffer.pre_trigger_secs must be positive")
+	}
+	if c.PostTriggerSecs < 0 {
+		return errors.New("ring_buffer.post_trigger_secs can't be negative")
+	}
+	if c.MaxBytes < 0 {
+		return errors.New("ring_buffer.max_bytes can't be negative")
+	}
+	if (c.TriggerSensor == "") != (c.TriggerKey == "") {
+		return errors.New("ring_buffer.trigger_sensor and ring_buffer.trigger_key must be set together")
+	}
+	return nil
+}
+
+type ringBufferItem struct {
+	item     *v1.SensorData
+	binary   bool
+	mimeType string
+	size     int64
+}
+
+// RingBuffer is a CaptureBufferedWriter which holds recent captures in memory and only writes them
+// to its target once triggered. Captures which are still in memory when the RingBuffer is discarded
+// are lost.
+type RingBuffer struct {
+	target      CaptureBufferedWriter
+	clock       clock.Clock
+	preTrigger  time.Duration
+	postTrigger time.Duration
+	maxBytes    int64
+
+	mu             sync.Mutex
+	items          []ringBufferItem
+	bytes          int64
+	triggeredUntil time.Time
+}
+
+// NewRingBuffer returns a RingBuffer which writes to target when triggered.
+func NewRingBuffer(target CaptureBufferedWriter, config RingBufferConfig, clk clock.Clock) *RingBuffer {
+	if clk == nil {
+		clk = clock.New()
+	}
+	maxBytes := config.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = defaultRingBufferMaxBytes
+	}
+	return &RingBuffer{
+		target:      target,
+		clock:       clk,
+		preTrigger:  time.Duration(config.PreTriggerSecs * float64(time.Second)),
+		postTrigger: time.Duration(config.PostTriggerSecs * float64(time.Second)),
+		maxBytes:    maxBytes,
+	}
+}
+
+// WriteBinary writes the item to the target while triggered and holds it in memory otherwise.
+func (rb *RingBuffer) WriteBinary(item *v1.SensorData, mimeType string) error {
+	if !IsBinary(item) {
+		return errInvalidBinarySensorData
+	}
+	return rb.write(ringBufferItem{item: item, binary: true, mimeType: mimeType})
+}
+
+// WriteTabular writes the item to the target while triggered and holds it in memory otherwise.
+func (rb *RingBuffer) WriteTabular(item *v1.SensorData) error {
+	if IsBinary(item) {
+		return errInvalidTabularSensorData
+	}
+	return rb.write(ringBufferItem{item: item})
+}
+
+func (rb *RingBuffer) write(item ringBufferItem) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	now := rb.clock.Now()
+	if now.Before(rb.triggeredUntil) {
+		return rb.writeToTarget(item)
+	}
+
+	item.size = int64(proto.Size(item.item))
+	rb.items = append(rb.items, item)
+	rb.bytes += item.size
+	rb.evict(now)
+	return nil
+}
+
+// evict drops captures which are older than the pre trigger window or exceed the byte limit.
+func (rb *RingBuffer) evict(now time.Time) {
+	cutoff := now.Add(-rb.preTrigger)
+	drop := 0
+	for drop < len(rb.items) {
+		item := rb.items[drop]
+		if rb.bytes <= rb.maxBytes && !item.item.GetMetadata().GetTimeRequested().AsTime().Before(cutoff) {
+			break
+		}
+		rb.bytes -= item.size
+		drop++
+	}
+	if drop > 0 {
+		// Clear the dropped entries so their payloads can be garbage collected.
+		clear(rb.items[:drop])
+		rb.items = rb.items[drop:]
+	}
+}
+
+func (rb *RingBuffer) writeToTarget(item ringBufferItem) error {
+	if item.binary {
+		return rb.target.WriteBinary(item.item, item.mimeType)
+	}
+	return rb.target.WriteTabular(item.item)
+}
+
+// Trigger writes the captures held in memory to the target, and causes captures made during the
+// post trigger window to be written directly to the target. Triggering during a post trigger
+// window extends it. If a capture fails to be written, it and the captures after it are kept in
+// memory, and the post trigger window does not start, so that triggering again retries them.
+func (rb *RingBuffer) Trigger() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	now := rb.clock.Now()
+	rb.evict(now)
+	for len(rb.items) > 0 {
+		item := rb.items[0]
+		if err := rb.writeToTarget(item); err != nil {
+			return err
+		}
+		rb.bytes -= item.size
+		rb.items[0] = ringBufferItem{}
+		rb.items = rb.items[1:]
+	}
+	rb.items = nil
+	rb.triggeredUntil = now.Add(rb.postTrigger)
+	return nil
+}
+
+// Triggered returns whether captures are currently being written to the target.
+func (rb *RingBuffer) Triggered() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.clock.Now().Before(rb.triggeredUntil)
+}
+
+// Flush flushes the target. Captures held in memory are not written.
+func (rb *RingBuffer) Flush() error {
+	return rb.target.Flush()
+}
+
+// Path returns the path of the target.
+func (rb *RingBuffer) Path() string {
+	return rb.target.Path()
+}
@@ -0,0 +1,157 @@
+package data
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/logging"
+)
+
+// recordingWriter is a CaptureBufferedWriter which records the binary payloads written to it.
+type recordingWriter struct {
+	mu       sync.Mutex
+	payloads []string
+}
+
+func (w *recordingWriter) WriteBinary(item *v1.SensorData, _ string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.payloads = append(w.payloads, string(item.GetBinary()))
+	return nil
+}
+
+func (w *recordingWriter) WriteTabular(item *v1.SensorData) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.payloads = append(w.payloads, item.GetStruct().String())
+	return nil
+}
+
+func (w *recordingWriter) Flush() error { return nil }
+
+// failingWriter is a recordingWriter which fails to write the binary payload failOn.
+type failingWriter struct {
+	recordingWriter
+	failOn string
+}
+
+func (w *failingWriter) WriteBinary(item *v1.SensorData, mimeType string) error {
+	if string(item.GetBinary()) == w.failOn {
+		return errors.New("disk full")
+	}
+	return w.recordingWriter.WriteBinary(item, mimeType)
+}
+
+func (w *recordingWriter) Path() string { return "" }
+
+func (w *recordingWriter) written() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.payloads...)
+}
+
+func ringBufferItemData(requested time.Time, payload string) *v1.SensorData {
+	return &v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(requested), TimeReceived: timestamppb.New(requested)},
+		Data:     &v1.SensorData_Binary{Binary: []byte(payload)},
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	t.Run("only writes the pre and post trigger windows", func(t *testing.T) {
+		clk := clock.NewMock()
+		target := &recordingWriter{}
+		rb := NewRingBuffer(target, RingBufferConfig{PreTriggerSecs: 2, PostTriggerSecs: 1}, clk)
+
+		for _, payload := range []string{"a", "b", "c", "d"} {
+			test.That(t, rb.WriteBinary(ringBufferItemData(clk.Now(), payload), ""), test.ShouldBeNil)
+			clk.Add(time.Second)
+		}
+		test.That(t, target.written(), test.ShouldBeEmpty)
+		test.That(t, rb.Triggered(), test.ShouldBeFalse)
+
+		// "a" and "b" fell out of the 2 second window before the trigger.
+		test.That(t, rb.Trigger(), test.ShouldBeNil)
+		test.That(t, target.written(), test.ShouldResemble, []string{"c", "d"})
+		test.That(t, rb.Triggered(), test.ShouldBeTrue)
+
+		test.That(t, rb.WriteBinary(ringBufferItemData(clk.Now(), "e"), ""), test.ShouldBeNil)
+		clk.Add(time.Second)
+		test.That(t, rb.Triggered(), test.ShouldBeFalse)
+		test.That(t, rb.WriteBinary(ringBufferItemData(clk.Now(), "f"), ""), test.ShouldBeNil)
+		test.That(t, target.written(), test.ShouldResemble, []string{"c", "d", "e"})
+	})
+
+	t.Run("max bytes drops the oldest captures", func(t *testing.T) {
+		clk := clock.NewMock()
+		target := &recordingWriter{}
+		item := ringBufferItemData(clk.Now(), "aaaa")
+		size := int64(proto.Size(item))
+		rb := NewRingBuffer(target, RingBufferConfig{PreTriggerSecs: 60, MaxBytes: 2 * size}, clk)
+
+		for _, payload := range []string{"aaaa", "bbbb", "cccc"} {
+			test.That(t, rb.WriteBinary(ringBufferItemData(clk.Now(), payload), ""), test.ShouldBeNil)
+		}
+		test.That(t, rb.Trigger(), test.ShouldBeNil)
+		test.That(t, target.written(), test.ShouldResemble, []string{"bbbb", "cccc"})
+	})
+
+	t.Run("keeps the captures which failed to be written", func(t *testing.T) {
+		clk := clock.NewMock()
+		target := &failingWriter{failOn: "b"}
+		rb := NewRingBuffer(target, RingBufferConfig{PreTriggerSecs: 60, PostTriggerSecs: 1}, clk)
+
+		for _, payload := range []string{"a", "b"} {
+			test.That(t, rb.WriteBinary(ringBufferItemData(clk.Now(), payload), ""), test.ShouldBeNil)
+		}
+		test.That(t, rb.Trigger(), test.ShouldNotBeNil)
+		test.That(t, target.written(), test.ShouldResemble, []string{"a"})
+		test.That(t, rb.Triggered(), test.ShouldBeFalse)
+		test.That(t, rb.WriteBinary(ringBufferItemData(clk.Now(), "c"), ""), test.ShouldBeNil)
+
+		target.failOn = ""
+		test.That(t, rb.Trigger(), test.ShouldBeNil)
+		test.That(t, target.written(), test.ShouldResemble, []string{"a", "b", "c"})
+		test.That(t, rb.Triggered(), test.ShouldBeTrue)
+	})
+
+	t.Run("rejects mismatched data types", func(t *testing.T) {
+		rb := NewRingBuffer(&recordingWriter{}, RingBufferConfig{PreTriggerSecs: 1}, clock.NewMock())
+		test.That(t, rb.WriteTabular(ringBufferItemData(time.Now(), "a")), test.ShouldBeError, errInvalidTabularSensorData)
+		test.That(t, rb.WriteBinary(&v1.SensorData{}, ""), test.ShouldBeError, errInvalidBinarySensorData)
+	})
+
+	t.Run("closed collectors are not triggered", func(t *testing.T) {
+		target := &recordingWriter{}
+		coll, err := NewCollector(nil, CollectorParams{
+			DataType:      CaptureTypeBinary,
+			ComponentName: "name",
+			Logger:        logging.NewTestLogger(t),
+			Target:        target,
+			RingBuffer:    &RingBufferConfig{PreTriggerSecs: 1},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		triggerable, ok := coll.(TriggerableCollector)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, triggerable.Trigger(), test.ShouldBeNil)
+
+		coll.Close()
+		test.That(t, triggerable.Trigger(), test.ShouldNotBeNil)
+	})
+}
+
+func TestRingBufferConfigValidate(t *testing.T) {
+	var nilConfig *RingBufferConfig
+	test.That(t, nilConfig.Validate(), test.ShouldBeNil)
+	test.That(t, (&RingBufferConfig{}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&RingBufferConfig{PreTriggerSecs: 5, PostTriggerSecs: 5}).Validate(), test.ShouldBeNil)
+	test.That(t, (&RingBufferConfig{PreTriggerSecs: 5, TriggerSensor: "s"}).Validate(), test.ShouldNotBeNil)
+}
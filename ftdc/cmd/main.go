@@ -2,16 +2,61 @@
 package main
 
 import (
+	"flag"
 	"os"
+	"time"
 
 	"go.viam.com/rdk/ftdc/parser"
 )
 
+const timeFormat = "2006-01-02T15:04:05"
+
 func main() {
-	if len(os.Args) < 2 {
-		parser.NolintPrintln("Expected an FTDC filename. E.g: go run parser.go <path-to>/viam-server.ftdc")
+	csvOutput := flag.String("csv", "", "Export the FTDC data as CSV to the given path ('-' for stdout) instead of launching the REPL.")
+	statsQuery := flag.String("stats", "", "Print summary statistics for a query instead of launching the REPL. "+
+		"E.g: -stats 'rate(proc.*.UserCPUSecs)'")
+	tableQuery := flag.String("table", "", "Print the values of a query instead of launching the REPL.")
+	start := flag.String("start", "", "Only include datapoints at or after this UTC time for -stats/-table. E.g: 2024-09-24T18:00:00")
+	end := flag.String("end", "", "Only include datapoints at or before this UTC time for -stats/-table. E.g: 2024-09-24T18:30:00")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		parser.NolintPrintln("Expected an FTDC filename. E.g: go run parser.go [-csv <output.csv>] " +
+			"[-stats <query> | -table <query>] <path-to>/viam-server.ftdc")
 		return
 	}
 
-	parser.LaunchREPL(os.Args[1])
+	switch {
+	case *csvOutput != "":
+		if err := parser.ExportCSV(flag.Arg(0), *csvOutput); err != nil {
+			parser.NolintPrintln("Error exporting CSV:", err)
+			os.Exit(1)
+		}
+	case *statsQuery != "" || *tableQuery != "":
+		opts := parser.QueryOptions{Query: *statsQuery}
+		if *tableQuery != "" {
+			opts = parser.QueryOptions{Query: *tableQuery, Table: true}
+		}
+
+		var err error
+		if *start != "" {
+			if opts.Start, err = time.Parse(timeFormat, *start); err != nil {
+				parser.NolintPrintln("Error parsing start time:", err)
+				os.Exit(1)
+			}
+		}
+		if *end != "" {
+			if opts.End, err = time.Parse(timeFormat, *end); err != nil {
+				parser.NolintPrintln("Error parsing end time:", err)
+				os.Exit(1)
+			}
+		}
+
+		if err := parser.RunQuery(flag.Arg(0), opts, os.Stdout); err != nil {
+			parser.NolintPrintln("Error running query:", err)
+			os.Exit(1)
+		}
+	default:
+		parser.LaunchREPL(flag.Arg(0))
+	}
 }
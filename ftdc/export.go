@@ -0,0 +1,246 @@
+package ftdc
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.viam.com/utils"
+)
+
+// openMetricsContentType is the content type of the OpenMetrics text exposition format.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// openMetricsPrefix is prepended to every metric name served over the OpenMetrics endpoint.
+const openMetricsPrefix = "viam_"
+
+// Columns is a columnar representation of a sequence of `FlatDatum`s. Every metric that appears in
+// any of the input datums gets its own column. Columns are parallel to `Times`. A metric that was
+// not present in some datum, e.g: because the schema changed, has a `NaN` value at that index.
+type Columns struct {
+	// Times is in nanoseconds since the epoch.
+	Times []int64
+	// MetricNames are sorted and index into `Values`.
+	MetricNames []string
+	Values      [][]float32
+}
+
+// ToColumns converts a sequence of `FlatDatum`s, as returned by `Parse`, into `Columns`.
+func ToColumns(data []FlatDatum) *Columns {
+	metricIdx := make(map[string]int)
+	for _, flatDatum := range data {
+		for _, reading := range flatDatum.Readings {
+			metricIdx[reading.MetricName] = 0
+		}
+	}
+
+	ret := &Columns{
+		Times:       make([]int64, len(data)),
+		MetricNames: make([]string, 0, len(metricIdx)),
+	}
+	for metricName := range metricIdx {
+		ret.MetricNames = append(ret.MetricNames, metricName)
+	}
+	slices.Sort(ret.MetricNames)
+
+	ret.Values = make([][]float32, len(ret.MetricNames))
+	nan := float32(math.NaN())
+	for idx, metricName := range ret.MetricNames {
+		metricIdx[metricName] = idx
+		column := make([]float32, len(data))
+		for row := range column {
+			column[row] = nan
+		}
+		ret.Values[idx] = column
+	}
+
+	for row, flatDatum := range data {
+		ret.Times[row] = flatDatum.Time
+		for _, reading := range flatDatum.Readings {
+			ret.Values[metricIdx[reading.MetricName]][row] = reading.Value
+		}
+	}
+
+	return ret
+}
+
+// Column returns the values for the given metric name. The second return value is false if the
+// metric does not exist.
+func (columns *Columns) Column(metricName string) ([]float32, bool) {
+	idx, found := slices.BinarySearch(columns.MetricNames, metricName)
+	if !found {
+		return nil, false
+	}
+	return columns.Values[idx], true
+}
+
+// WriteCSV writes the columns as CSV. The first column is the "time" in RFC3339 format with
+// nanosecond precision and each following column is a metric. Missing values are left empty.
+func (columns *Columns) WriteCSV(output io.Writer) error {
+	writer := csv.NewWriter(output)
+	if err := writer.Write(append([]string{"time"}, columns.MetricNames...)); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns.MetricNames)+1)
+	for row, nanos := range columns.Times {
+		record[0] = time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+		for idx, column := range columns.Values {
+			if math.IsNaN(float64(column[row])) {
+				record[idx+1] = ""
+				continue
+			}
+			record[idx+1] = strconv.FormatFloat(float64(column[row]), 'g', -1, 32)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteCSV is a convenience for `ToColumns(data).WriteCSV(output)`.
+func WriteCSV(data []FlatDatum, output io.Writer) error {
+	return ToColumns(data).WriteCSV(output)
+}
+
+// openMetricsName converts a fully qualified FTDC metric name (e.g: `proc.viam-server.UserCPUSecs`)
+// into a legal OpenMetrics metric name (e.g: `viam_proc_viam_server_UserCPUSecs`). OpenMetrics names
+// must match `[a-zA-Z_:][a-zA-Z0-9_:]*`.
+func openMetricsName(metricName string) string {
+	var builder strings.Builder
+	builder.WriteString(openMetricsPrefix)
+	for _, char := range metricName {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9', char == '_', char == ':':
+			builder.WriteRune(char)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+	return builder.String()
+}
+
+// WriteOpenMetrics writes a single `FlatDatum` in the OpenMetrics text exposition format. Every
+// reading is exposed as a gauge. Readings whose names collide after sanitization are only written
+// once.
+func WriteOpenMetrics(flatDatum FlatDatum, output io.Writer) error {
+	timestamp := strconv.FormatFloat(float64(flatDatum.Time)/float64(time.Second), 'f', 3, 64)
+	seen := make(map[string]struct{}, len(flatDatum.Readings))
+	for _, reading := range flatDatum.Readings {
+		name := openMetricsName(reading.MetricName)
+		if _, exists := seen[name]; exists {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		value := strconv.FormatFloat(float64(reading.Value), 'g', -1, 32)
+		switch {
+		case math.IsNaN(float64(reading.Value)):
+			value = "NaN"
+		case math.IsInf(float64(reading.Value), 1):
+			value = "+Inf"
+		case math.IsInf(float64(reading.Value), -1):
+			value = "-Inf"
+		}
+		if _, err := fmt.Fprintf(output, "# TYPE %s gauge\n%s %s %s\n", name, name, value, timestamp); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(output, "# EOF\n")
+	return err
+}
+
+// Latest returns the most recent datum that was written by this FTDC object. The second return
+// value is false if no datum has been written yet.
+func (ftdc *FTDC) Latest() (FlatDatum, bool) {
+	ftdc.latestMu.Lock()
+	defer ftdc.latestMu.Unlock()
+
+	if ftdc.latest == nil {
+		return FlatDatum{}, false
+	}
+	return *ftdc.latest, true
+}
+
+func (ftdc *FTDC) setLatest(time int64, schema *schema, flatData []float32) {
+	latest := &FlatDatum{Time: time, Readings: schema.Zip(flatData)}
+
+	ftdc.latestMu.Lock()
+	defer ftdc.latestMu.Unlock()
+	ftdc.latest = latest
+}
+
+// OpenMetricsHandler returns an `http.Handler` that serves the latest datum in the OpenMetrics text
+// exposition format. This is suitable for being scraped by Prometheus.
+func (ftdc *FTDC) OpenMetricsHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		latest, exists := ftdc.Latest()
+		if !exists {
+			http.Error(writer, "no ftdc data has been collected yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		writer.Header().Set("Content-Type", openMetricsContentType)
+		if err := WriteOpenMetrics(latest, writer); err != nil {
+			ftdc.logger.Debugw("Error writing OpenMetrics response", "err", err)
+		}
+	})
+}
+
+// EnableOpenMetrics requests that `Start` also serve the latest datum over HTTP at `/metrics` on the
+// given listen address (e.g: `localhost:9090`). It must be called before `Start`.
+func (ftdc *FTDC) EnableOpenMetrics(listenAddr string) {
+	ftdc.openMetricsAddr = listenAddr
+}
+
+// startOpenMetricsServer starts the HTTP server requested by `EnableOpenMetrics`. Failing to listen
+// is logged, but does not prevent FTDC from writing to disk.
+func (ftdc *FTDC) startOpenMetricsServer() {
+	if ftdc.openMetricsAddr == "" {
+		return
+	}
+
+	listener, err := net.Listen("tcp", ftdc.openMetricsAddr)
+	if err != nil {
+		ftdc.logger.Warnw("Unable to start OpenMetrics server", "addr", ftdc.openMetricsAddr, "err", err)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", ftdc.OpenMetricsHandler())
+	ftdc.openMetricsServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	ftdc.logger.Infow("Serving FTDC OpenMetrics", "addr", listener.Addr().String())
+
+	server := ftdc.openMetricsServer
+	utils.PanicCapturingGo(func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ftdc.logger.Warnw("OpenMetrics server exited", "err", err)
+		}
+	})
+}
+
+func (ftdc *FTDC) stopOpenMetricsServer(ctx context.Context) {
+	if ftdc.openMetricsServer == nil {
+		return
+	}
+
+	if err := ftdc.openMetricsServer.Shutdown(ctx); err != nil {
+		ftdc.logger.Debugw("Error shutting down OpenMetrics server", "err", err)
+	}
+}
@@ -0,0 +1,101 @@
+package ftdc
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestToColumnsAndCSV(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	ftdcData := bytes.NewBuffer(nil)
+	ftdc := NewWithWriter(ftdcData, logger.Sublogger("ftdc"))
+
+	statser := mockStatser{
+		stats: struct {
+			X int
+		}{5},
+	}
+	ftdc.Add("mock", &statser)
+	test.That(t, ftdc.writeDatum(datum{Time: 1_000_000_000, Data: ftdc.constructDatum().Data}), test.ShouldBeNil)
+
+	// Change the schema such that `Y` is missing from the first datum.
+	statser.stats = struct {
+		X int
+		Y float64
+	}{3, 4.5}
+	test.That(t, ftdc.writeDatum(datum{Time: 2_000_000_000, Data: ftdc.constructDatum().Data}), test.ShouldBeNil)
+
+	datums, _, err := Parse(ftdcData)
+	test.That(t, err, test.ShouldBeNil)
+
+	columns := ToColumns(datums)
+	test.That(t, columns.Times, test.ShouldResemble, []int64{1_000_000_000, 2_000_000_000})
+	test.That(t, columns.MetricNames, test.ShouldResemble, []string{"mock.X", "mock.Y"})
+
+	xs, exists := columns.Column("mock.X")
+	test.That(t, exists, test.ShouldBeTrue)
+	test.That(t, xs, test.ShouldResemble, []float32{5, 3})
+
+	ys, exists := columns.Column("mock.Y")
+	test.That(t, exists, test.ShouldBeTrue)
+	test.That(t, math.IsNaN(float64(ys[0])), test.ShouldBeTrue)
+	test.That(t, ys[1], test.ShouldEqual, 4.5)
+
+	_, exists = columns.Column("mock.Z")
+	test.That(t, exists, test.ShouldBeFalse)
+
+	csvOutput := bytes.NewBuffer(nil)
+	test.That(t, WriteCSV(datums, csvOutput), test.ShouldBeNil)
+	test.That(t, csvOutput.String(), test.ShouldEqual,
+		"time,mock.X,mock.Y\n"+
+			"1970-01-01T00:00:01Z,5,\n"+
+			"1970-01-01T00:00:02Z,3,4.5\n")
+}
+
+func TestOpenMetrics(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	ftdc := NewWithWriter(bytes.NewBuffer(nil), logger.Sublogger("ftdc"))
+	handler := ftdc.OpenMetricsHandler()
+
+	// Nothing has been written yet.
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	test.That(t, recorder.Code, test.ShouldEqual, http.StatusServiceUnavailable)
+
+	ftdc.Add("proc.viam-server", &foo{x: 1, y: 2})
+	test.That(t, ftdc.writeDatum(datum{Time: 1_500_000_000, Data: ftdc.constructDatum().Data}), test.ShouldBeNil)
+
+	latest, exists := ftdc.Latest()
+	test.That(t, exists, test.ShouldBeTrue)
+	test.That(t, latest.Readings, test.ShouldResemble, []Reading{
+		{"proc.viam-server.X", 1},
+		{"proc.viam-server.Y", 2},
+	})
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	test.That(t, recorder.Code, test.ShouldEqual, http.StatusOK)
+	test.That(t, recorder.Header().Get("Content-Type"), test.ShouldStartWith, "application/openmetrics-text")
+
+	body, err := io.ReadAll(recorder.Body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(body), test.ShouldEqual, strings.Join([]string{
+		"# TYPE viam_proc_viam_server_X gauge",
+		"viam_proc_viam_server_X 1 1.500",
+		"# TYPE viam_proc_viam_server_Y gauge",
+		"viam_proc_viam_server_Y 2 1.500",
+		"# EOF",
+		"",
+	}, "\n"))
+}
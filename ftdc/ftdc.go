@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -119,6 +120,14 @@ type FTDC struct {
 	// ftdcDir controls where FTDC data files will be written.
 	ftdcDir string
 
+	// latest is the most recently written datum. It is served by the OpenMetrics endpoint, which
+	// reads it concurrently with the `statsWriter`.
+	latestMu sync.Mutex
+	latest   *FlatDatum
+	// openMetricsAddr is the listen address for serving `latest`. Empty means it is not served.
+	openMetricsAddr   string
+	openMetricsServer *http.Server
+
 	uploader *uploader
 	logger   logging.Logger
 }
@@ -226,6 +235,7 @@ func (ftdc *FTDC) Start() {
 	if ftdc.uploader != nil {
 		ftdc.uploader.start()
 	}
+	ftdc.startOpenMetricsServer()
 }
 
 func (ftdc *FTDC) statsReader(ctx context.Context) {
@@ -290,6 +300,7 @@ func (ftdc *FTDC) StopAndJoin(ctx context.Context) {
 	if ftdc.uploader != nil {
 		ftdc.uploader.stopAndJoin()
 	}
+	ftdc.stopOpenMetricsServer(ctx)
 
 	// Closing the `statsCh` signals to the `outputWorker` to complete and exit. We use a timeout to
 	// limit how long we're willing to wait for the `outputWorker` to drain.
@@ -455,6 +466,7 @@ func (ftdc *FTDC) writeDatum(datum datum) error {
 		return err
 	}
 	ftdc.prevFlatData = flatData
+	ftdc.setLatest(datum.Time, ftdc.currSchema, flatData)
 
 	return nil
 }
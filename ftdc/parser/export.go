@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.viam.com/rdk/ftdc"
+	"go.viam.com/rdk/logging"
+)
+
+// ExportCSV reads an ftdc file or directory and writes all of its datapoints as CSV to
+// `outputPath`. An `outputPath` of "-" writes to stdout.
+func ExportCSV(ftdcFilepath, outputPath string) error {
+	logger := logging.NewLogger("parser")
+	data, _, err := getFTDCData(filepath.Clean(ftdcFilepath), logger)
+	if err != nil {
+		return err
+	}
+
+	return writeCSVFile(data, outputPath)
+}
+
+// writeCSVFile writes the `data` as CSV to `outputPath`, or stdout if `outputPath` is "-".
+func writeCSVFile(data []ftdc.FlatDatum, outputPath string) (err error) {
+	var output io.Writer = os.Stdout
+	if outputPath != "-" {
+		//nolint:gosec
+		outputFile, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := outputFile.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		output = outputFile
+	}
+
+	return ftdc.WriteCSV(data, output)
+}
+
+// dataInRange returns the subset of `data` that falls within the time range selected in the
+// `graphOptions`.
+func dataInRange(data []ftdc.FlatDatum, graphOptions graphOptions) []ftdc.FlatDatum {
+	ret := make([]ftdc.FlatDatum, 0, len(data))
+	for _, flatDatum := range data {
+		timeSecs := flatDatum.ConvertedTime().Unix()
+		if timeSecs < graphOptions.minTimeSeconds || timeSecs > graphOptions.maxTimeSeconds {
+			continue
+		}
+		ret = append(ret, flatDatum)
+	}
+
+	return ret
+}
@@ -133,6 +133,28 @@ type graphOptions struct {
 	// selectList is a list of metric names to always show at the top of the generated image.
 	// This option will override hideAllZeroes and show a graph at the top even if all values of the graph is 0.
 	selectList *orderedmap.OrderedMap
+
+	// filters is a list of glob patterns. When non-empty, only metrics matching at least one
+	// pattern are graphed. Derived metrics are always graphed.
+	filters []*regexp.Regexp
+
+	// derived maps a user chosen graph name to a query. Each query is evaluated and graphed in
+	// addition to the raw metrics.
+	derived *orderedmap.OrderedMap
+}
+
+// matchesFilters returns whether the metric should be graphed given the user's `filter`s.
+func (opts *graphOptions) matchesFilters(metricName string) bool {
+	if len(opts.filters) == 0 {
+		return true
+	}
+
+	for _, filter := range opts.filters {
+		if filter.MatchString(metricName) {
+			return true
+		}
+	}
+	return false
 }
 
 // defaultGraphOptions returns a default set of graph options. It adds all but the last
@@ -151,6 +173,7 @@ func defaultGraphOptions(fileBoundaryTimestamps []int64) graphOptions {
 		fileBoundariesAtSeconds: fileBoundaryTimestamps[:len(fileBoundaryTimestamps)-1],
 		maxPoints:               1000,
 		selectList:              orderedmap.New(),
+		derived:                 orderedmap.New(),
 	}
 }
 
@@ -532,6 +555,10 @@ func (gpw *gnuplotWriter) addFlatDatum(datum ftdc.FlatDatum) map[string]*ratioRe
 	// of the ratio metric, the "metric identifier". There may be `rdk.foo_module.UserCPUSecs` in
 	// addition to `rdk.bar_modular.UserCPUSecs`. Which should create two CPU% graphs.
 	for _, reading := range datum.Readings {
+		if !gpw.options.matchesFilters(reading.MetricName) {
+			continue
+		}
+
 		// pullRatios will identify if the metric is a "ratio" metric. If so, we do not currently
 		// know what to graph and `pullRatios` will accumulate the relevant information into
 		// `deferredReadings`.
@@ -624,6 +651,37 @@ func (gpw *gnuplotWriter) writeDeferredValues(deferredValues []map[string]*ratio
 	}
 }
 
+// addDerivedValues evaluates each query the user `define`d and adds its values as points. A query
+// that selects multiple series creates one graph per series.
+func (gpw *gnuplotWriter) addDerivedValues(columns *ftdc.Columns, logger logging.Logger) {
+	for _, name := range gpw.options.derived.Keys() {
+		query, _ := gpw.options.derived.Get(name)
+		times, allSeries, err := evalQuery(query.(string), columns, gpw.options.minTimeSeconds, gpw.options.maxTimeSeconds)
+		if err != nil {
+			logger.Warnw("Error evaluating derived metric", "name", name, "query", query, "err", err)
+			continue
+		}
+
+		for _, series := range allSeries {
+			graphName := name
+			if len(allSeries) > 1 {
+				// Prefer the text matched by the wildcards. It's the shortest unique identifier.
+				suffix := series.key
+				if suffix == "" {
+					suffix = series.name
+				}
+				graphName = fmt.Sprintf("%v[%v]", name, suffix)
+			}
+			for idx, value := range series.values {
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					continue
+				}
+				gpw.addPoint(times[idx]/time.Second.Nanoseconds(), graphName, float32(value))
+			}
+		}
+	}
+}
+
 // Render runs the compiler and invokes gnuplot, creating an image file.
 func (gpw *gnuplotWriter) Render() {
 	filename := gpw.CompileAndClose()
@@ -907,7 +965,7 @@ func getFTDCData(ftdcPath string, logger logging.Logger) ([]ftdc.FlatDatum, []in
 	return flatDatums, fileBoundaryTimestamps, nil
 }
 
-func renderPlot(data []ftdc.FlatDatum, graphOptions graphOptions, logger logging.Logger) *gnuplotWriter {
+func renderPlot(data []ftdc.FlatDatum, columns *ftdc.Columns, graphOptions graphOptions, logger logging.Logger) *gnuplotWriter {
 	deferredValues := make([]map[string]*ratioReading, 0)
 	gpw := newGnuPlotWriter(graphOptions, len(data), data[0].Time, data[len(data)-1].Time)
 	for idx := 0; idx < len(data)-1; idx++ {
@@ -922,6 +980,7 @@ func renderPlot(data []ftdc.FlatDatum, graphOptions graphOptions, logger logging
 	}
 
 	gpw.writeDeferredValues(deferredValues, logger)
+	gpw.addDerivedValues(columns, logger)
 
 	gpw.Render()
 	return gpw
@@ -941,8 +1000,9 @@ func LaunchREPL(ftdcFilepath string) {
 	stdinReader := bufio.NewReader(os.Stdin)
 
 	graphOptions := defaultGraphOptions(fileBoundaryTimestamps)
+	columns := ftdc.ToColumns(data)
 
-	gpw := renderPlot(data, graphOptions, logger)
+	gpw := renderPlot(data, columns, graphOptions, logger)
 	for {
 		render := true
 
@@ -990,6 +1050,40 @@ func LaunchREPL(ftdcFilepath string) {
 			NolintPrintln("hide zeroes")
 			NolintPrintln("-  Generate graphs omitting plots with all zeroes.")
 			NolintPrintln()
+			NolintPrintln("filter <glob1,glob2>")
+			NolintPrintln("-  Only graph metrics matching at least one of the comma-separated glob patterns. `*` matches anything.")
+			NolintPrintln("-  E.g: filter proc.*,net.*.RxBytes")
+			NolintPrintln("-       filter reset")
+			NolintPrintln()
+			NolintPrintln("define <name> = <query>")
+			NolintPrintln("-  Graph the result of a query under the given name. With no arguments, list the definitions.")
+			NolintPrintln("-  E.g: define CPU% = rate(proc.*.UserCPUSecs) * 100")
+			NolintPrintln("-       define CallLatency = delta(`rdk:component:arm/arm1.MoveToPosition.timeSpent`) / " +
+				"delta(`rdk:component:arm/arm1.MoveToPosition`)")
+			NolintPrintln()
+			NolintPrintln("undefine <name>")
+			NolintPrintln("-  Stop graphing a query added with `define`.")
+			NolintPrintln("-  E.g: undefine CPU%")
+			NolintPrintln("-       undefine all")
+			NolintPrintln()
+			NolintPrintln("stats <query>")
+			NolintPrintln("-  Print count/min/max/mean/p50/p90/p99/last of each series within the current range.")
+			NolintPrintln("-  E.g: stats avg(rate(net.*.TxBytes), 30s)")
+			NolintPrintln()
+			NolintPrintln("table <query>")
+			NolintPrintln(fmt.Sprintf("-  Print the values of each series within the current range. At most %d rows are printed.",
+				maxTableRows))
+			NolintPrintln("-  E.g: table proc.*.UserCPUSecs / proc.*.ElapsedTimeSecs")
+			NolintPrintln()
+			NolintPrintln("Queries select metrics by glob and combine them with `+ - * /` (surrounded by whitespace),")
+			NolintPrintln("numbers and the functions rate(q[, window]), delta(q[, window]) and avg(q[, window]).")
+			NolintPrintln("Globs on both sides of an operator are paired by the text their wildcards matched.")
+			NolintPrintln("Quote metric names containing other characters (e.g: `/`) with double quotes or backticks.")
+			NolintPrintln()
+			NolintPrintln("csv <path>")
+			NolintPrintln("-  Export all metrics within the current range as CSV to the given path. Use `-` for stdout.")
+			NolintPrintln("-  E.g: csv /tmp/metrics.csv")
+			NolintPrintln()
 			NolintPrintln("`quit` or Ctrl-d to exit")
 		case strings.HasPrefix(cmd, "range "):
 			pieces := strings.SplitN(cmd, " ", 3)
@@ -1095,6 +1189,81 @@ func LaunchREPL(ftdcFilepath string) {
 		case cmd == "hide zeroes":
 			graphOptions.hideAllZeroes = true
 			NolintPrintln("Generating graphs omitting plots with all zeroes")
+		case strings.HasPrefix(cmd, "filter "):
+			withoutCmd := strings.TrimSpace(strings.TrimPrefix(cmd, "filter "))
+			if withoutCmd == "reset" {
+				graphOptions.filters = nil
+				NolintPrintln("Graphing all metrics")
+				break
+			}
+
+			filters := make([]*regexp.Regexp, 0)
+			for _, piece := range strings.Split(withoutCmd, ",") {
+				filter, err := globToRegexp(strings.TrimSpace(piece))
+				if err != nil {
+					NolintPrintln("Error parsing filter:", piece, "Err:", err)
+					continue
+				}
+				filters = append(filters, filter)
+			}
+			graphOptions.filters = filters
+			NolintPrintln("Only graphing metrics matching:", withoutCmd)
+		case cmd == "define":
+			render = false
+			for _, name := range graphOptions.derived.Keys() {
+				query, _ := graphOptions.derived.Get(name)
+				NolintPrintln(name, "=", query)
+			}
+		case strings.HasPrefix(cmd, "define "):
+			pieces := strings.SplitN(strings.TrimPrefix(cmd, "define "), "=", 2)
+			if len(pieces) != 2 {
+				NolintPrintln("Expected `define <name> = <query>`")
+				render = false
+				break
+			}
+
+			name, query := strings.TrimSpace(pieces[0]), strings.TrimSpace(pieces[1])
+			if _, _, err := evalQuery(query, columns, 0, math.MaxInt64); err != nil {
+				NolintPrintln("Error evaluating query:", err)
+				render = false
+				break
+			}
+			graphOptions.derived.Set(name, query)
+			NolintPrintln("Defined:", name)
+		case strings.HasPrefix(cmd, "undefine "):
+			name := strings.TrimSpace(strings.TrimPrefix(cmd, "undefine "))
+			if name == "all" {
+				graphOptions.derived = orderedmap.New()
+				NolintPrintln("Removed all definitions")
+				break
+			}
+			graphOptions.derived.Delete(name)
+			NolintPrintln("Removed definition:", name)
+		case strings.HasPrefix(cmd, "stats ") || strings.HasPrefix(cmd, "table "):
+			render = false
+			query := strings.TrimSpace(cmd[len("stats "):])
+			times, allSeries, err := evalQuery(query, columns, graphOptions.minTimeSeconds, graphOptions.maxTimeSeconds)
+			if err != nil {
+				NolintPrintln("Error evaluating query:", err)
+				break
+			}
+
+			if strings.HasPrefix(cmd, "stats ") {
+				err = writeStatsTable(os.Stdout, allSeries)
+			} else {
+				err = writeValuesTable(os.Stdout, times, allSeries)
+			}
+			if err != nil {
+				NolintPrintln("Error writing table:", err)
+			}
+		case strings.HasPrefix(cmd, "csv "):
+			render = false
+			outputPath := strings.TrimSpace(strings.TrimPrefix(cmd, "csv "))
+			if err := writeCSVFile(dataInRange(data, graphOptions), outputPath); err != nil {
+				NolintPrintln("Error exporting CSV:", err)
+				break
+			}
+			NolintPrintln("Exported CSV to:", outputPath)
 		case len(cmd) == 0:
 			render = false
 		default:
@@ -1103,7 +1272,7 @@ func LaunchREPL(ftdcFilepath string) {
 		}
 
 		if render {
-			gpw = renderPlot(data, graphOptions, logger)
+			gpw = renderPlot(data, columns, graphOptions, logger)
 		}
 	}
 }
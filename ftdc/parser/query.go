@@ -0,0 +1,698 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"go.viam.com/rdk/ftdc"
+	"go.viam.com/rdk/logging"
+)
+
+// The query language is a small arithmetic expression language over FTDC metrics. A query
+// evaluates to a set of "series". Each series is a named, time-aligned list of values. Examples:
+//
+//	proc.viam-server.UserCPUSecs               A single metric.
+//	proc.*.UserCPUSecs                         All metrics matching a glob. `*` also matches dots.
+//	rate(net.*.RxBytes)                        Per-second rate of change.
+//	rate(proc.*.UserCPUSecs, 10s)              Rate of change over a sliding 10 second window.
+//	proc.*.UserCPUSecs / proc.*.ElapsedTimeSecs * 100
+//	                                           Ratios between metrics. Globs on either side of a
+//	                                           binary operator are paired by what their wildcards
+//	                                           matched.
+//	avg(rate(net.*.TxBytes), 30s)              Moving average over a 30 second window.
+//	delta(rdk.*.GetReadings)                   Difference between consecutive readings.
+//
+// Unquoted metric names may contain letters, digits and `_.:*?-`. Metric names containing any other
+// characters (e.g: the `/` in `rdk:component:arm/arm1.MoveToPosition`) must be wrapped in double
+// quotes or backticks. Because `-` and `*` are legal metric name characters, subtraction and
+// multiplication require whitespace around the operator.
+
+// series is a named list of values, parallel to the `Times` of the `ftdc.Columns` it was evaluated
+// against. Missing values are NaN.
+type series struct {
+	name string
+	// key identifies a series for pairing against other series in binary operations. For a metric
+	// selected by a glob, the key is the text matched by the wildcards.
+	key    string
+	values []float64
+}
+
+// queryExpr is a parsed query expression that can be evaluated against FTDC data.
+type queryExpr interface {
+	eval(columns *ftdc.Columns) ([]*series, error)
+	String() string
+}
+
+// metricExpr selects every metric matching a glob pattern.
+type metricExpr struct {
+	pattern string
+	regex   *regexp.Regexp
+}
+
+// globToRegexp converts a glob pattern into an anchored regular expression. Each wildcard becomes a
+// capture group such that the matched text can be used to pair series.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("^")
+	for _, char := range pattern {
+		switch char {
+		case '*':
+			builder.WriteString("(.*)")
+		case '?':
+			builder.WriteString("(.)")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.Compile(builder.String())
+}
+
+func newMetricExpr(pattern string) (*metricExpr, error) {
+	regex, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &metricExpr{pattern: pattern, regex: regex}, nil
+}
+
+func (expr *metricExpr) eval(columns *ftdc.Columns) ([]*series, error) {
+	ret := make([]*series, 0)
+	for idx, metricName := range columns.MetricNames {
+		matches := expr.regex.FindStringSubmatch(metricName)
+		if matches == nil {
+			continue
+		}
+
+		values := make([]float64, len(columns.Times))
+		for row, value := range columns.Values[idx] {
+			values[row] = float64(value)
+		}
+		ret = append(ret, &series{name: metricName, key: strings.Join(matches[1:], "|"), values: values})
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no metrics match %q", expr.pattern)
+	}
+	return ret, nil
+}
+
+func (expr *metricExpr) String() string {
+	return expr.pattern
+}
+
+// numberExpr is a numeric literal.
+type numberExpr struct {
+	value float64
+}
+
+func (expr *numberExpr) eval(columns *ftdc.Columns) ([]*series, error) {
+	values := make([]float64, len(columns.Times))
+	for row := range values {
+		values[row] = expr.value
+	}
+	return []*series{{name: expr.String(), values: values}}, nil
+}
+
+func (expr *numberExpr) String() string {
+	return strconv.FormatFloat(expr.value, 'g', -1, 64)
+}
+
+// binaryExpr applies an arithmetic operator between two expressions.
+type binaryExpr struct {
+	op          byte
+	left, right queryExpr
+}
+
+func applyOp(op byte, left, right float64) float64 {
+	switch op {
+	case '+':
+		return left + right
+	case '-':
+		return left - right
+	case '*':
+		return left * right
+	default:
+		if right == 0 {
+			return math.NaN()
+		}
+		return left / right
+	}
+}
+
+func (expr *binaryExpr) eval(columns *ftdc.Columns) ([]*series, error) {
+	lefts, err := expr.left.eval(columns)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := expr.right.eval(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	combine := func(left, right *series, name, key string) *series {
+		values := make([]float64, len(columns.Times))
+		for row := range values {
+			values[row] = applyOp(expr.op, left.values[row], right.values[row])
+		}
+		return &series{name: name, key: key, values: values}
+	}
+
+	ret := make([]*series, 0)
+	switch {
+	case len(rights) == 1:
+		// Broadcast a single series (or constant) on the right against everything on the left.
+		for _, left := range lefts {
+			ret = append(ret, combine(left, rights[0], fmt.Sprintf("%v %c %v", left.name, expr.op, rights[0].name), left.key))
+		}
+	case len(lefts) == 1:
+		for _, right := range rights {
+			ret = append(ret, combine(lefts[0], right, fmt.Sprintf("%v %c %v", lefts[0].name, expr.op, right.name), right.key))
+		}
+	default:
+		// Pair up series whose wildcards matched the same text. E.g: `proc.*.UserCPUSecs /
+		// proc.*.ElapsedTimeSecs` pairs each process' CPU time with its own elapsed time.
+		for _, left := range lefts {
+			for _, right := range rights {
+				if left.key != right.key {
+					continue
+				}
+				ret = append(ret, combine(left, right, fmt.Sprintf("%v %c %v", left.name, expr.op, right.name), left.key))
+			}
+		}
+		if len(ret) == 0 {
+			return nil, fmt.Errorf("no series in %q pair up with series in %q", expr.left, expr.right)
+		}
+	}
+
+	return ret, nil
+}
+
+func (expr *binaryExpr) String() string {
+	return fmt.Sprintf("(%v %c %v)", expr.left, expr.op, expr.right)
+}
+
+// negateExpr is a unary minus.
+type negateExpr struct {
+	inner queryExpr
+}
+
+func (expr *negateExpr) eval(columns *ftdc.Columns) ([]*series, error) {
+	inners, err := expr.inner.eval(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*series, len(inners))
+	for idx, inner := range inners {
+		values := make([]float64, len(inner.values))
+		for row, value := range inner.values {
+			values[row] = -value
+		}
+		ret[idx] = &series{name: "-" + inner.name, key: inner.key, values: values}
+	}
+	return ret, nil
+}
+
+func (expr *negateExpr) String() string {
+	return fmt.Sprintf("-%v", expr.inner)
+}
+
+// queryFunc transforms a single series. The `times` are in nanoseconds since the epoch and the
+// `window` is zero when the user did not provide one.
+type queryFunc func(times []int64, values []float64, window time.Duration) []float64
+
+// queryFuncs are the functions available to queries. Each accepts a series expression and an
+// optional window duration.
+var queryFuncs = map[string]queryFunc{
+	"rate":  rateFunc,
+	"delta": deltaFunc,
+	"avg":   movingAverageFunc,
+}
+
+// windowStart returns the earliest index whose time is within `window` of `times[idx]`. A zero
+// `window` looks back exactly one element.
+func windowStart(times []int64, idx int, window time.Duration) int {
+	if window == 0 {
+		return max(idx-1, 0)
+	}
+
+	start := idx
+	for start > 0 && times[idx]-times[start-1] <= window.Nanoseconds() {
+		start--
+	}
+	return start
+}
+
+// previousValid returns the index of the first non-NaN value in [start, idx), or -1.
+func previousValid(values []float64, start, idx int) int {
+	for prev := start; prev < idx; prev++ {
+		if !math.IsNaN(values[prev]) {
+			return prev
+		}
+	}
+	return -1
+}
+
+func rateFunc(times []int64, values []float64, window time.Duration) []float64 {
+	ret := make([]float64, len(values))
+	for idx := range values {
+		ret[idx] = math.NaN()
+		prev := previousValid(values, windowStart(times, idx, window), idx)
+		if prev < 0 || math.IsNaN(values[idx]) || times[idx] == times[prev] {
+			continue
+		}
+		ret[idx] = (values[idx] - values[prev]) / time.Duration(times[idx]-times[prev]).Seconds()
+	}
+	return ret
+}
+
+func deltaFunc(times []int64, values []float64, window time.Duration) []float64 {
+	ret := make([]float64, len(values))
+	for idx := range values {
+		ret[idx] = math.NaN()
+		prev := previousValid(values, windowStart(times, idx, window), idx)
+		if prev < 0 || math.IsNaN(values[idx]) {
+			continue
+		}
+		ret[idx] = values[idx] - values[prev]
+	}
+	return ret
+}
+
+func movingAverageFunc(times []int64, values []float64, window time.Duration) []float64 {
+	if window == 0 {
+		window = windowSize
+	}
+
+	ret := make([]float64, len(values))
+	for idx := range values {
+		var sum float64
+		var count int
+		for prev := windowStart(times, idx, window); prev <= idx; prev++ {
+			if !math.IsNaN(values[prev]) {
+				sum += values[prev]
+				count++
+			}
+		}
+		ret[idx] = math.NaN()
+		if count > 0 {
+			ret[idx] = sum / float64(count)
+		}
+	}
+	return ret
+}
+
+// funcExpr applies one of the `queryFuncs` to every series of its argument.
+type funcExpr struct {
+	name   string
+	fn     queryFunc
+	arg    queryExpr
+	window time.Duration
+}
+
+func (expr *funcExpr) eval(columns *ftdc.Columns) ([]*series, error) {
+	args, err := expr.arg.eval(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*series, len(args))
+	for idx, arg := range args {
+		ret[idx] = &series{
+			name:   fmt.Sprintf("%v(%v)", expr.name, arg.name),
+			key:    arg.key,
+			values: expr.fn(columns.Times, arg.values, expr.window),
+		}
+	}
+	return ret, nil
+}
+
+func (expr *funcExpr) String() string {
+	if expr.window == 0 {
+		return fmt.Sprintf("%v(%v)", expr.name, expr.arg)
+	}
+	return fmt.Sprintf("%v(%v, %v)", expr.name, expr.arg, expr.window)
+}
+
+// queryParser is a recursive descent parser for the grammar:
+//
+//	expr   := term (('+' | '-') term)*
+//	term   := unary (('*' | '/') unary)*
+//	unary  := '-' unary | factor
+//	factor := number | metric | func '(' expr [',' duration] ')' | '(' expr ')'
+type queryParser struct {
+	input string
+	pos   int
+}
+
+// parseQuery parses an entire query expression.
+func parseQuery(input string) (queryExpr, error) {
+	parser := &queryParser{input: input}
+	expr, err := parser.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	parser.skipSpace()
+	if parser.pos != len(parser.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", parser.input[parser.pos:], parser.pos)
+	}
+	return expr, nil
+}
+
+func (parser *queryParser) skipSpace() {
+	for parser.pos < len(parser.input) && unicode.IsSpace(rune(parser.input[parser.pos])) {
+		parser.pos++
+	}
+}
+
+// peek returns the next non-whitespace character, or 0 at the end of input.
+func (parser *queryParser) peek() byte {
+	parser.skipSpace()
+	if parser.pos >= len(parser.input) {
+		return 0
+	}
+	return parser.input[parser.pos]
+}
+
+func (parser *queryParser) expect(char byte) error {
+	if parser.peek() != char {
+		return fmt.Errorf("expected %q at position %d", char, parser.pos)
+	}
+	parser.pos++
+	return nil
+}
+
+func (parser *queryParser) parseExpr() (queryExpr, error) {
+	left, err := parser.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := parser.peek(); op == '+' || op == '-'; op = parser.peek() {
+		parser.pos++
+		right, err := parser.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *queryParser) parseTerm() (queryExpr, error) {
+	left, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := parser.peek(); op == '*' || op == '/'; op = parser.peek() {
+		// A `*` directly followed by a metric name character is the start of a glob, not a
+		// multiplication. E.g: `*.UserCPUSecs`.
+		if op == '*' && parser.pos+1 < len(parser.input) && isMetricChar(parser.input[parser.pos+1]) {
+			break
+		}
+		parser.pos++
+		right, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *queryParser) parseUnary() (queryExpr, error) {
+	if parser.peek() == '-' {
+		parser.pos++
+		inner, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateExpr{inner: inner}, nil
+	}
+	return parser.parseFactor()
+}
+
+func isMetricChar(char byte) bool {
+	return char == '_' || char == '.' || char == ':' || char == '*' || char == '?' || char == '-' ||
+		(char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
+func (parser *queryParser) parseFactor() (queryExpr, error) {
+	switch char := parser.peek(); {
+	case char == 0:
+		return nil, errors.New("unexpected end of query")
+	case char == '(':
+		parser.pos++
+		inner, err := parser.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := parser.expect(')'); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case char == '"' || char == '`':
+		end := strings.IndexByte(parser.input[parser.pos+1:], char)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quote at position %d", parser.pos)
+		}
+		pattern := parser.input[parser.pos+1 : parser.pos+1+end]
+		parser.pos += end + 2
+		return newMetricExpr(pattern)
+	case isMetricChar(char):
+		start := parser.pos
+		for parser.pos < len(parser.input) && isMetricChar(parser.input[parser.pos]) {
+			parser.pos++
+		}
+		word := parser.input[start:parser.pos]
+
+		if value, err := strconv.ParseFloat(word, 64); err == nil {
+			return &numberExpr{value: value}, nil
+		}
+		if parser.peek() == '(' {
+			return parser.parseFunc(word)
+		}
+		return newMetricExpr(word)
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", char, parser.pos)
+	}
+}
+
+func (parser *queryParser) parseFunc(name string) (queryExpr, error) {
+	fn, exists := queryFuncs[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	if err := parser.expect('('); err != nil {
+		return nil, err
+	}
+
+	arg, err := parser.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	var window time.Duration
+	if parser.peek() == ',' {
+		parser.pos++
+		parser.skipSpace()
+		end := strings.IndexByte(parser.input[parser.pos:], ')')
+		if end < 0 {
+			return nil, fmt.Errorf("expected ')' after window in %v(...)", name)
+		}
+		windowStr := strings.TrimSpace(parser.input[parser.pos : parser.pos+end])
+		if window, err = time.ParseDuration(windowStr); err != nil {
+			return nil, fmt.Errorf("invalid window %q, expected a duration such as `10s`: %w", windowStr, err)
+		}
+		if window <= 0 {
+			return nil, fmt.Errorf("window must be positive, got %v", window)
+		}
+		parser.pos += end
+	}
+
+	if err := parser.expect(')'); err != nil {
+		return nil, err
+	}
+	return &funcExpr{name: name, fn: fn, arg: arg, window: window}, nil
+}
+
+// QueryOptions describes a query to run against FTDC data outside of the REPL.
+type QueryOptions struct {
+	// Query is an expression in the query language. See `LaunchREPL`'s `help` output.
+	Query string
+	// Table outputs every value of the selected series rather than summary statistics.
+	Table bool
+	// Start and End restrict the time range of the output. Zero values are unbounded.
+	Start time.Time
+	End   time.Time
+}
+
+// RunQuery evaluates a query against an ftdc file or directory and writes the resulting table to
+// `output`.
+func RunQuery(ftdcFilepath string, opts QueryOptions, output io.Writer) error {
+	logger := logging.NewLogger("parser")
+	data, _, err := getFTDCData(filepath.Clean(ftdcFilepath), logger)
+	if err != nil {
+		return err
+	}
+
+	minTimeSeconds, maxTimeSeconds := int64(0), int64(math.MaxInt64)
+	if !opts.Start.IsZero() {
+		minTimeSeconds = opts.Start.Unix()
+	}
+	if !opts.End.IsZero() {
+		maxTimeSeconds = opts.End.Unix()
+	}
+
+	times, allSeries, err := evalQuery(opts.Query, ftdc.ToColumns(data), minTimeSeconds, maxTimeSeconds)
+	if err != nil {
+		return err
+	}
+	if opts.Table {
+		return writeValuesTable(output, times, allSeries)
+	}
+	return writeStatsTable(output, allSeries)
+}
+
+// evalQuery parses and evaluates a query against the columns, restricting the output to times
+// within [minTimeSeconds, maxTimeSeconds]. The returned `times` are in nanoseconds since the epoch.
+func evalQuery(query string, columns *ftdc.Columns, minTimeSeconds, maxTimeSeconds int64) ([]int64, []*series, error) {
+	expr, err := parseQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allSeries, err := expr.eval(columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Evaluate against all of the data before restricting the time range. Such that windowed
+	// functions have history to work with at the start of the range.
+	start, end := 0, len(columns.Times)
+	for start < end && columns.Times[start]/time.Second.Nanoseconds() < minTimeSeconds {
+		start++
+	}
+	for end > start && columns.Times[end-1]/time.Second.Nanoseconds() > maxTimeSeconds {
+		end--
+	}
+
+	for _, series := range allSeries {
+		series.values = series.values[start:end]
+	}
+	return columns.Times[start:end], allSeries, nil
+}
+
+// seriesStats are summary statistics of the non-NaN values of a series.
+type seriesStats struct {
+	count         int
+	minVal        float64
+	maxVal        float64
+	mean          float64
+	p50, p90, p99 float64
+	last          float64
+}
+
+// percentile returns the `pct` percentile of the sorted input using linear interpolation.
+func percentile(sortedValues []float64, pct float64) float64 {
+	if len(sortedValues) == 1 {
+		return sortedValues[0]
+	}
+
+	rank := pct / 100 * float64(len(sortedValues)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	frac := rank - float64(lower)
+	return sortedValues[lower] + frac*(sortedValues[upper]-sortedValues[lower])
+}
+
+func computeStats(values []float64) seriesStats {
+	valid := make([]float64, 0, len(values))
+	var sum float64
+	for _, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		valid = append(valid, value)
+		sum += value
+	}
+
+	if len(valid) == 0 {
+		nan := math.NaN()
+		return seriesStats{minVal: nan, maxVal: nan, mean: nan, p50: nan, p90: nan, p99: nan, last: nan}
+	}
+
+	ret := seriesStats{count: len(valid), mean: sum / float64(len(valid)), last: valid[len(valid)-1]}
+	slices.Sort(valid)
+	ret.minVal = valid[0]
+	ret.maxVal = valid[len(valid)-1]
+	ret.p50 = percentile(valid, 50)
+	ret.p90 = percentile(valid, 90)
+	ret.p99 = percentile(valid, 99)
+	return ret
+}
+
+func formatValue(value float64) string {
+	if math.IsNaN(value) {
+		return "-"
+	}
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
+
+// writeStatsTable writes one row of summary statistics per series.
+func writeStatsTable(output io.Writer, allSeries []*series) error {
+	tabWriter := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tabWriter, "metric\tcount\tmin\tmax\tmean\tp50\tp90\tp99\tlast"); err != nil {
+		return err
+	}
+	for _, series := range allSeries {
+		stats := computeStats(series.values)
+		if _, err := fmt.Fprintf(tabWriter, "%v\t%d\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", series.name, stats.count,
+			formatValue(stats.minVal), formatValue(stats.maxVal), formatValue(stats.mean),
+			formatValue(stats.p50), formatValue(stats.p90), formatValue(stats.p99), formatValue(stats.last)); err != nil {
+			return err
+		}
+	}
+	return tabWriter.Flush()
+}
+
+// maxTableRows is the most rows `writeValuesTable` will output. Larger time ranges are evenly
+// downsampled. Users wanting every value are expected to narrow the range or export a CSV.
+const maxTableRows = 100
+
+// writeValuesTable writes one row per timestamp and one column per series.
+func writeValuesTable(output io.Writer, times []int64, allSeries []*series) error {
+	tabWriter := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	header := []string{"time"}
+	for _, series := range allSeries {
+		header = append(header, series.name)
+	}
+	if _, err := fmt.Fprintln(tabWriter, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+
+	step := max(1, (len(times)+maxTableRows-1)/maxTableRows)
+	for row := 0; row < len(times); row += step {
+		record := []string{time.Unix(0, times[row]).UTC().Format("2006-01-02T15:04:05")}
+		for _, series := range allSeries {
+			record = append(record, formatValue(series.values[row]))
+		}
+		if _, err := fmt.Fprintln(tabWriter, strings.Join(record, "\t")); err != nil {
+			return err
+		}
+	}
+	return tabWriter.Flush()
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/ftdc"
+)
+
+func testColumns() *ftdc.Columns {
+	data := make([]ftdc.FlatDatum, 0)
+	for idx := range 10 {
+		data = append(data, ftdc.FlatDatum{
+			Time: int64(idx) * 1_000_000_000,
+			Readings: []ftdc.Reading{
+				{MetricName: "proc.viam-server.UserCPUSecs", Value: float32(idx)},
+				{MetricName: "proc.viam-server.ElapsedTimeSecs", Value: float32(2 * idx)},
+				{MetricName: "proc.modules.foo.UserCPUSecs", Value: float32(3 * idx)},
+				{MetricName: "proc.modules.foo.ElapsedTimeSecs", Value: float32(4 * idx)},
+				{MetricName: "rdk:component:arm/arm1.MoveToPosition", Value: 7},
+			},
+		})
+	}
+	return ftdc.ToColumns(data)
+}
+
+func TestQueryParse(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected string
+	}{
+		{"proc.viam-server.UserCPUSecs", "proc.viam-server.UserCPUSecs"},
+		{"rate(proc.*.UserCPUSecs, 10s)", "rate(proc.*.UserCPUSecs, 10s)"},
+		{"a / b * 100", "((a / b) * 100)"},
+		{"a - b + -c", "((a - b) + -c)"},
+		{"a + b * c", "(a + (b * c))"},
+		{"*.foo * 2", "(*.foo * 2)"},
+		{"avg(`x/y`, 1m)", "avg(x/y, 1m0s)"},
+	} {
+		expr, err := parseQuery(tc.query)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, expr.String(), test.ShouldEqual, tc.expected)
+	}
+
+	for _, query := range []string{"", "rate(", "unknown(a)", "a +", "rate(a, nonsense)", "\"unterminated", "a ) b"} {
+		_, err := parseQuery(query)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestQueryEval(t *testing.T) {
+	columns := testColumns()
+
+	t.Run("glob", func(t *testing.T) {
+		_, allSeries, err := evalQuery("proc.*.UserCPUSecs", columns, 0, math.MaxInt64)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(allSeries), test.ShouldEqual, 2)
+		test.That(t, allSeries[0].name, test.ShouldEqual, "proc.modules.foo.UserCPUSecs")
+		test.That(t, allSeries[0].key, test.ShouldEqual, "modules.foo")
+
+		_, _, err = evalQuery("does.not.exist", columns, 0, math.MaxInt64)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("paired ratio", func(t *testing.T) {
+		_, allSeries, err := evalQuery("proc.*.UserCPUSecs / proc.*.ElapsedTimeSecs * 100", columns, 0, math.MaxInt64)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(allSeries), test.ShouldEqual, 2)
+		// 3 * idx / 4 * idx for `foo`. Dividing by zero at idx 0 is NaN.
+		test.That(t, math.IsNaN(allSeries[0].values[0]), test.ShouldBeTrue)
+		test.That(t, allSeries[0].values[5], test.ShouldAlmostEqual, 75)
+		test.That(t, allSeries[1].values[5], test.ShouldAlmostEqual, 50)
+	})
+
+	t.Run("functions", func(t *testing.T) {
+		_, allSeries, err := evalQuery("rate(proc.modules.foo.UserCPUSecs)", columns, 0, math.MaxInt64)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, math.IsNaN(allSeries[0].values[0]), test.ShouldBeTrue)
+		test.That(t, allSeries[0].values[3], test.ShouldAlmostEqual, 3)
+
+		_, allSeries, err = evalQuery("delta(proc.modules.foo.UserCPUSecs, 2s)", columns, 0, math.MaxInt64)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, allSeries[0].values[3], test.ShouldAlmostEqual, 6)
+
+		_, allSeries, err = evalQuery("avg(proc.viam-server.UserCPUSecs, 2s)", columns, 0, math.MaxInt64)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, allSeries[0].values[4], test.ShouldAlmostEqual, 3)
+
+		_, allSeries, err = evalQuery("\"rdk:component:arm/arm1.MoveToPosition\" - 2", columns, 0, math.MaxInt64)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, allSeries[0].values[0], test.ShouldAlmostEqual, 5)
+	})
+
+	t.Run("time range", func(t *testing.T) {
+		times, allSeries, err := evalQuery("rate(proc.viam-server.UserCPUSecs)", columns, 3, 5)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, times, test.ShouldResemble, []int64{3_000_000_000, 4_000_000_000, 5_000_000_000})
+		// Windowed functions still see data prior to the range.
+		test.That(t, allSeries[0].values, test.ShouldResemble, []float64{1, 1, 1})
+	})
+}
+
+func TestQueryTables(t *testing.T) {
+	columns := testColumns()
+
+	stats := computeStats([]float64{math.NaN(), 4, 1, 3, 2})
+	test.That(t, stats.count, test.ShouldEqual, 4)
+	test.That(t, stats.minVal, test.ShouldEqual, 1)
+	test.That(t, stats.maxVal, test.ShouldEqual, 4)
+	test.That(t, stats.mean, test.ShouldEqual, 2.5)
+	test.That(t, stats.p50, test.ShouldEqual, 2.5)
+	test.That(t, stats.last, test.ShouldEqual, 2)
+
+	times, allSeries, err := evalQuery("proc.*.UserCPUSecs", columns, 0, math.MaxInt64)
+	test.That(t, err, test.ShouldBeNil)
+
+	output := bytes.NewBuffer(nil)
+	test.That(t, writeStatsTable(output, allSeries), test.ShouldBeNil)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	test.That(t, len(lines), test.ShouldEqual, 3)
+	test.That(t, lines[0], test.ShouldStartWith, "metric")
+	test.That(t, lines[2], test.ShouldStartWith, "proc.viam-server.UserCPUSecs")
+
+	output.Reset()
+	test.That(t, writeValuesTable(output, times, allSeries), test.ShouldBeNil)
+	lines = strings.Split(strings.TrimSpace(output.String()), "\n")
+	test.That(t, len(lines), test.ShouldEqual, 11)
+	test.That(t, lines[1], test.ShouldStartWith, "1970-01-01T00:00:00")
+}
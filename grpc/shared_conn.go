@@ -359,6 +359,18 @@ func (sc *SharedConn) ProcessEncodedAnswer(encodedAnswer string) error {
 	return nil
 }
 
+// DisablePeerConn closes the PeerConnection of a shared connection to a module which cannot be
+// reached over WebRTC. `SharedConn.PeerConn` will return nil until a following `Reset`.
+func (sc *SharedConn) DisablePeerConn() {
+	sc.peerConnMu.Lock()
+	defer sc.peerConnMu.Unlock()
+	if sc.peerConn != nil {
+		utils.UncheckedError(sc.peerConn.GracefulClose())
+		sc.peerConn = nil
+		close(sc.peerConnFailed)
+	}
+}
+
 // Close closes a shared connection.
 func (sc *SharedConn) Close() error {
 	var err error
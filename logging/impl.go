@@ -7,6 +7,7 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -45,6 +46,11 @@ type (
 		recentMessageEntries map[string]LogEntry
 		// Start of current window.
 		recentMessageWindowStart time.Time
+
+		// limiter samples the entries of loggers with a rate limit configured. It is nil otherwise.
+		limiter atomic.Pointer[rateLimiter]
+		// suppressedLogs counts the entries the limiter has suppressed.
+		suppressedLogs atomic.Uint64
 	}
 
 	// LogEntry embeds a zapcore Entry and slice of Fields.
@@ -145,6 +151,8 @@ func (imp *impl) Sublogger(subname string) Logger {
 		make(map[string]int),
 		make(map[string]LogEntry),
 		time.Now(),
+		atomic.Pointer[rateLimiter]{},
+		atomic.Uint64{},
 	}
 
 	// If there are multiple callers racing to create the same logger name (e.g: `viam.networking`),
@@ -304,6 +312,35 @@ func (imp *impl) Write(entry *LogEntry) {
 		imp.recentMessageMu.Unlock()
 	}
 
+	if limiter := imp.limiter.Load(); limiter != nil {
+		level := levelFromZap(entry.Level)
+		allowed, suppressed := limiter.allow(level, entry.Time)
+		if suppressed > 0 {
+			// Summarize what the rate limit suppressed over the last interval before moving on.
+			imp.reportSuppressed(limiter.policy.interval, level, suppressed)
+		}
+		if !allowed {
+			imp.suppressedLogs.Add(1)
+			return
+		}
+	}
+
+	imp.writeToAppenders(entry)
+}
+
+// reportSuppressed writes a summary of the entries at a level which the rate limit suppressed over
+// an interval.
+func (imp *impl) reportSuppressed(interval time.Duration, level Level, suppressed int) {
+	summaryEntry := LogEntry{Entry: zapcore.Entry{
+		Level:      level.AsZap(),
+		Time:       time.Now(),
+		LoggerName: imp.name,
+		Message:    fmt.Sprintf("Suppressed %d messages in past %v due to the rate limit", suppressed, interval),
+	}}
+	imp.writeToAppenders(&summaryEntry)
+}
+
+func (imp *impl) writeToAppenders(entry *LogEntry) {
 	imp.testHelper()
 	for _, appender := range imp.appenders {
 		err := appender.Write(entry.Entry, entry.Fields)
@@ -13,6 +13,8 @@ import (
 
 	"go.uber.org/zap/zapcore"
 	"go.viam.com/test"
+	"go.viam.com/utils"
+	gotestutils "go.viam.com/utils/testutils"
 )
 
 type BasicStruct struct {
@@ -642,3 +644,129 @@ func TestNeverDeduplicate(t *testing.T) {
 			`2023-10-30T13:19:45.806Z	INFO	impl	logging/impl_test.go:132	identical message`)
 	}
 }
+
+func TestRateLimit(t *testing.T) {
+	registry := newRegistry()
+	notStdout := &bytes.Buffer{}
+	logger := &impl{
+		name:                     "impl",
+		level:                    NewAtomicLevelAt(DEBUG),
+		appenders:                []Appender{NewWriterAppender(notStdout)},
+		registry:                 registry,
+		testHelper:               func() {},
+		recentMessageCounts:      make(map[string]int),
+		recentMessageEntries:     make(map[string]LogEntry),
+		recentMessageWindowStart: time.Now(),
+	}
+	registry.Update([]LoggerPatternConfig{
+		{
+			Pattern: "impl.camera",
+			RateLimit: &RateLimitConfig{
+				Interval:   utils.Duration(500 * time.Millisecond),
+				First:      2,
+				Thereafter: 3,
+				Levels:     []string{"info"},
+			},
+		},
+	}, logger)
+
+	// A sublogger created after the update is rate limited too.
+	cameraLogger := logger.Sublogger("camera")
+	test.That(t, cameraLogger.GetLevel(), test.ShouldEqual, DEBUG)
+
+	// The first two entries of the interval are written, then one in three.
+	for i := 1; i <= 8; i++ {
+		cameraLogger.Infof("frame %d", i)
+	}
+	for _, i := range []int{1, 2, 5, 8} {
+		assertLogMatches(t, notStdout,
+			fmt.Sprintf(`2023-10-30T13:19:45.806Z	INFO	impl.camera	logging/impl_test.go:132	frame %d`, i))
+	}
+	test.That(t, notStdout.Len(), test.ShouldEqual, 0)
+
+	// Levels the limit does not apply to are not sampled.
+	for range 3 {
+		cameraLogger.Warn("low light")
+		assertLogMatches(t, notStdout,
+			`2023-10-30T13:19:45.806Z	WARN	impl.camera	logging/impl_test.go:132	low light`)
+	}
+
+	stats, ok := registry.Stats().(RateLimitStats)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, stats.Suppressed, test.ShouldEqual, 4)
+	test.That(t, stats.SuppressedByLogger, test.ShouldResemble, map[string]uint64{"impl.camera": 4})
+
+	// Removing the limit summarizes what it suppressed right away, and stops sampling.
+	registry.Update(nil, logger)
+	summary, err := notStdout.ReadString('\n')
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, summary, test.ShouldContainSubstring, "INFO\timpl.camera\tSuppressed 4 messages in past 500ms due to the rate limit")
+	for i := 10; i <= 13; i++ {
+		cameraLogger.Infof("frame %d", i)
+		assertLogMatches(t, notStdout,
+			fmt.Sprintf(`2023-10-30T13:19:45.806Z	INFO	impl.camera	logging/impl_test.go:132	frame %d`, i))
+	}
+}
+
+func TestRateLimitSummary(t *testing.T) {
+	parent, observedLogs, registry := NewObservedTestLoggerWithRegistry(t, "impl")
+	registry.Update([]LoggerPatternConfig{
+		{Pattern: "impl.camera", RateLimit: &RateLimitConfig{Interval: utils.Duration(100 * time.Millisecond), First: 1}},
+	}, parent)
+	logger := parent.Sublogger("camera")
+	for i := 1; i <= 3; i++ {
+		logger.Infof("frame %d", i)
+	}
+
+	// The summary is written once the interval ends, even though nothing is logged after it.
+	gotestutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, observedLogs.FilterMessage("Suppressed 2 messages in past 100ms due to the rate limit").Len(),
+			test.ShouldEqual, 1)
+	})
+	test.That(t, observedLogs.FilterMessageSnippet("frame").Len(), test.ShouldEqual, 1)
+
+	// An entry after the interval does not summarize it again.
+	logger.Info("frame 4")
+	test.That(t, observedLogs.FilterMessageSnippet("Suppressed").Len(), test.ShouldEqual, 1)
+}
+
+func TestRateLimiter(t *testing.T) {
+	policy, err := newRateLimitPolicy(RateLimitConfig{Interval: utils.Duration(time.Hour), First: 1})
+	test.That(t, err, test.ShouldBeNil)
+	var reported []int
+	limiter := newRateLimiter(policy, func(level Level, suppressed int) {
+		reported = append(reported, suppressed)
+	})
+
+	// The first entry of the next interval returns how many were suppressed in the last one, if
+	// its summary was not written yet.
+	start := time.Now()
+	for i, expected := range []bool{true, false, false} {
+		allowed, suppressed := limiter.allow(INFO, start.Add(time.Duration(i)*time.Second))
+		test.That(t, allowed, test.ShouldEqual, expected)
+		test.That(t, suppressed, test.ShouldEqual, 0)
+	}
+	allowed, suppressed := limiter.allow(INFO, start.Add(time.Hour))
+	test.That(t, allowed, test.ShouldBeTrue)
+	test.That(t, suppressed, test.ShouldEqual, 2)
+
+	// Closing the limiter reports what the current interval suppressed.
+	limiter.allow(INFO, start.Add(time.Hour))
+	limiter.close()
+	test.That(t, reported, test.ShouldResemble, []int{1})
+}
+
+func TestRateLimitPolicy(t *testing.T) {
+	_, err := newRateLimitPolicy(RateLimitConfig{First: 1, Thereafter: -1})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = newRateLimitPolicy(RateLimitConfig{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = newRateLimitPolicy(RateLimitConfig{First: 1, Levels: []string{"loud"}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	policy, err := newRateLimitPolicy(RateLimitConfig{Thereafter: 10})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, policy.interval, test.ShouldEqual, DefaultRateLimitInterval)
+	test.That(t, policy.levels, test.ShouldBeNil)
+}
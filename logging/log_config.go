@@ -7,7 +7,11 @@ import (
 // LoggerPatternConfig is an instance of a level specification for a given logger.
 type LoggerPatternConfig struct {
 	Pattern string `json:"pattern"`
-	Level   string `json:"level"`
+	// Level may be left empty when the config only sets a RateLimit.
+	Level string `json:"level"`
+	// RateLimit optionally bounds how many entries matching loggers write. When several patterns
+	// with rate limits match a logger, the last one applies.
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
 }
 
 // BuildRegexFromPattern creates a compilable regex from a log pattern.
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"sync"
 	"sync/atomic"
@@ -61,6 +62,7 @@ func (lr *Registry) Update(logConfig []LoggerPatternConfig, warnLogger Logger) {
 	lr.mu.Unlock()
 
 	appliedConfigs := make(map[string]Level)
+	appliedRateLimits := make(map[string]*rateLimitPolicy)
 	for _, lpc := range logConfig {
 		r, err := regexp.Compile(BuildRegexFromPattern(lpc.Pattern))
 		if err != nil {
@@ -69,6 +71,22 @@ func (lr *Registry) Update(logConfig []LoggerPatternConfig, warnLogger Logger) {
 			continue
 		}
 
+		if lpc.RateLimit != nil {
+			policy, err := newRateLimitPolicy(*lpc.RateLimit)
+			if err != nil {
+				warnLogger.Warnw("Log rate limit is invalid", "pattern", lpc.Pattern, "err", err)
+			} else {
+				for _, name := range lr.getRegisteredLoggerNames() {
+					if r.MatchString(name) {
+						appliedRateLimits[name] = policy
+					}
+				}
+			}
+			if lpc.Level == "" {
+				continue
+			}
+		}
+
 		level, err := LevelFromString(lpc.Level)
 		if err != nil {
 			warnLogger.Warnw("Log level did not parse", "pattern", lpc.Pattern, "level", lpc.Level)
@@ -95,6 +113,36 @@ func (lr *Registry) Update(logConfig []LoggerPatternConfig, warnLogger Logger) {
 		if err != nil {
 			warnLogger.Warnw("Logger disappeared after seeing its name", "name", name, "level", level)
 		}
+		lr.updateLoggerRateLimit(name, appliedRateLimits[name])
+	}
+}
+
+// updateLoggerRateLimit samples the entries of the named logger with the policy, or stops
+// sampling them if the policy is nil. A logger keeps its sampling state while its policy is
+// unchanged.
+func (lr *Registry) updateLoggerRateLimit(name string, policy *rateLimitPolicy) {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	if imp, ok := lr.loggers[name].(*impl); ok {
+		setRateLimit(imp, policy)
+	}
+}
+
+func setRateLimit(imp *impl, policy *rateLimitPolicy) {
+	current := imp.limiter.Load()
+	switch {
+	case policy == nil:
+		imp.limiter.Store(nil)
+	case current == nil || !reflect.DeepEqual(current.policy, policy):
+		imp.limiter.Store(newRateLimiter(policy, func(level Level, suppressed int) {
+			imp.reportSuppressed(policy.interval, level, suppressed)
+		}))
+	default:
+		return
+	}
+	if current != nil {
+		// Report what the replaced limiter suppressed rather than waiting for its windows to end.
+		current.close()
 	}
 }
 
@@ -150,6 +198,16 @@ func (lr *Registry) getOrRegister(name string, logger Logger) Logger {
 			continue
 		}
 		if r.MatchString(name) {
+			if lpc.RateLimit != nil {
+				if policy, err := newRateLimitPolicy(*lpc.RateLimit); err == nil {
+					if imp, ok := logger.(*impl); ok {
+						setRateLimit(imp, policy)
+					}
+				}
+				if lpc.Level == "" {
+					continue
+				}
+			}
 			level, err := LevelFromString(lpc.Level)
 			if err != nil {
 				// Can ignore error here; invalid level will already have been
@@ -161,3 +219,22 @@ func (lr *Registry) getOrRegister(name string, logger Logger) Logger {
 	}
 	return logger
 }
+
+// Stats returns the number of log entries the loggers in the registry suppressed due to their
+// rate limits, to be recorded in FTDC.
+func (lr *Registry) Stats() any {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	stats := RateLimitStats{SuppressedByLogger: make(map[string]uint64)}
+	for name, logger := range lr.loggers {
+		imp, ok := logger.(*impl)
+		if !ok {
+			continue
+		}
+		if suppressed := imp.suppressedLogs.Load(); suppressed > 0 {
+			stats.Suppressed += suppressed
+			stats.SuppressedByLogger[name] = suppressed
+		}
+	}
+	return stats
+}
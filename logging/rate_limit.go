@@ -0,0 +1,166 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.viam.com/utils"
+)
+
+// DefaultRateLimitInterval is the interval a RateLimitConfig without one counts entries over.
+const DefaultRateLimitInterval = time.Second
+
+// RateLimitConfig samples the entries a logger writes, separately for each level: in every
+// interval the first First entries are written, then one in every Thereafter. Entries that are not
+// written are counted and summarized by a "Suppressed N messages" entry as soon as the interval
+// ends.
+type RateLimitConfig struct {
+	// Interval is the period entries are counted over. Defaults to DefaultRateLimitInterval.
+	Interval utils.Duration `json:"interval,omitempty"`
+	// First is the number of entries written at the start of every interval.
+	First int `json:"first,omitempty"`
+	// Thereafter writes one in every Thereafter entries past the first First of an interval. Zero
+	// suppresses them all.
+	Thereafter int `json:"thereafter,omitempty"`
+	// Levels are the levels the limit applies to. Empty applies it to all levels.
+	Levels []string `json:"levels,omitempty"`
+}
+
+// rateLimitPolicy is a validated RateLimitConfig.
+type rateLimitPolicy struct {
+	interval   time.Duration
+	first      int
+	thereafter int
+	// levels is nil when the policy applies to all levels.
+	levels map[Level]struct{}
+}
+
+func newRateLimitPolicy(cfg RateLimitConfig) (*rateLimitPolicy, error) {
+	if cfg.Interval < 0 || cfg.First < 0 || cfg.Thereafter < 0 {
+		return nil, errors.New("rate limit interval, first and thereafter cannot be negative")
+	}
+	if cfg.First == 0 && cfg.Thereafter == 0 {
+		return nil, errors.New("rate limit must allow some entries through, set the level to silence a logger")
+	}
+	policy := &rateLimitPolicy{
+		interval:   time.Duration(cfg.Interval),
+		first:      cfg.First,
+		thereafter: cfg.Thereafter,
+	}
+	if policy.interval == 0 {
+		policy.interval = DefaultRateLimitInterval
+	}
+	if len(cfg.Levels) > 0 {
+		policy.levels = make(map[Level]struct{}, len(cfg.Levels))
+		for _, levelStr := range cfg.Levels {
+			level, err := LevelFromString(levelStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate limit level: %w", err)
+			}
+			policy.levels[level] = struct{}{}
+		}
+	}
+	return policy, nil
+}
+
+type rateLimitWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+	// summary reports the suppressed entries once the window ends. It is nil until an entry is
+	// suppressed.
+	summary *time.Timer
+}
+
+// rateLimiter applies a rateLimitPolicy to the entries of one logger.
+type rateLimiter struct {
+	policy *rateLimitPolicy
+	// report writes the summary of the entries at a level that were suppressed in an interval.
+	report func(level Level, suppressed int)
+
+	mu      sync.Mutex
+	windows map[Level]*rateLimitWindow
+}
+
+func newRateLimiter(policy *rateLimitPolicy, report func(level Level, suppressed int)) *rateLimiter {
+	return &rateLimiter{policy: policy, report: report, windows: make(map[Level]*rateLimitWindow)}
+}
+
+// allow reports whether an entry logged at `level` at `now` may be written. When it is the first
+// entry after an interval in which entries were suppressed, and they have not been reported yet,
+// it also returns how many were.
+func (rl *rateLimiter) allow(level Level, now time.Time) (allowed bool, suppressed int) {
+	if rl.policy.levels != nil {
+		if _, ok := rl.policy.levels[level]; !ok {
+			return true, 0
+		}
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	window, ok := rl.windows[level]
+	if !ok {
+		window = &rateLimitWindow{start: now}
+		rl.windows[level] = window
+	}
+	if now.Sub(window.start) >= rl.policy.interval {
+		suppressed = rl.endWindow(window)
+		window = &rateLimitWindow{start: now}
+		rl.windows[level] = window
+	}
+
+	window.count++
+	past := window.count - rl.policy.first
+	if past <= 0 || (rl.policy.thereafter > 0 && past%rl.policy.thereafter == 0) {
+		return true, suppressed
+	}
+	window.suppressed++
+	if window.summary == nil {
+		// Report the suppressed entries when the window ends, even if no entry comes after it.
+		window.summary = time.AfterFunc(window.start.Add(rl.policy.interval).Sub(now), func() {
+			rl.mu.Lock()
+			suppressed := rl.endWindow(window)
+			rl.mu.Unlock()
+			if suppressed > 0 {
+				rl.report(level, suppressed)
+			}
+		})
+	}
+	return false, suppressed
+}
+
+// endWindow returns the number of entries suppressed in the window which have not been reported
+// yet, and marks them as reported. Lock the mutex before calling this!
+func (rl *rateLimiter) endWindow(window *rateLimitWindow) int {
+	if window.summary != nil {
+		window.summary.Stop()
+	}
+	suppressed := window.suppressed
+	window.suppressed = 0
+	return suppressed
+}
+
+// close reports the suppressed entries of every window right away, for when the limiter is
+// replaced or removed.
+func (rl *rateLimiter) close() {
+	rl.mu.Lock()
+	suppressed := make(map[Level]int, len(rl.windows))
+	for level, window := range rl.windows {
+		suppressed[level] = rl.endWindow(window)
+	}
+	rl.mu.Unlock()
+	for level, count := range suppressed {
+		if count > 0 {
+			rl.report(level, count)
+		}
+	}
+}
+
+// RateLimitStats counts the log entries that were suppressed by rate limits.
+type RateLimitStats struct {
+	Suppressed uint64
+	// SuppressedByLogger only holds the loggers which suppressed entries.
+	SuppressedByLogger map[string]uint64
+}
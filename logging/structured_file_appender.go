@@ -0,0 +1,344 @@
+package logging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// DefaultStructuredLogMaxSizeMB is the size in megabytes a structured log file grows to before
+	// it is rotated.
+	DefaultStructuredLogMaxSizeMB = 20
+
+	// DefaultStructuredLogMaxBackups is the number of rotated structured log files that are kept.
+	DefaultStructuredLogMaxBackups = 10
+
+	// lumberjackBackupTimeFormat is the format of the time lumberjack puts in the names of the
+	// files it rotates out.
+	lumberjackBackupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// A StoredLogEntry is a log entry as a StructuredFileAppender stores it, one JSON object a line.
+type StoredLogEntry struct {
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Logger  string    `json:"logger"`
+	Caller  string    `json:"caller,omitempty"`
+	Message string    `json:"message"`
+	// Fields holds the structured fields of the entry as a JSON object.
+	Fields json.RawMessage `json:"fields,omitempty"`
+}
+
+// StructuredFileAppenderConfig configures where a StructuredFileAppender stores log entries and
+// when it rotates its file.
+type StructuredFileAppenderConfig struct {
+	Filename string
+	// MaxSizeMB is the size in megabytes the file grows to before it is rotated. It defaults to
+	// DefaultStructuredLogMaxSizeMB.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files kept. It defaults to
+	// DefaultStructuredLogMaxBackups.
+	MaxBackups int
+	// MaxAge is how long rotated files are kept, rounded up to whole days. Zero keeps them until
+	// there are more than MaxBackups.
+	MaxAge time.Duration
+	// RotationInterval rotates the file once it has been written to for this long, whatever its
+	// size. Zero rotates by size only.
+	RotationInterval time.Duration
+}
+
+// StructuredFileAppender stores log entries as JSON lines in a file which is rotated by size and
+// time, so that logs survive restarts and can be searched with QueryLogFiles while the machine is
+// offline.
+type StructuredFileAppender struct {
+	mu               sync.Mutex
+	file             *lumberjack.Logger
+	rotationInterval time.Duration
+	rotatedAt        time.Time
+}
+
+// NewStructuredFileAppender returns an appender storing log entries as configured. Entries are
+// appended to the file left by an earlier run, if any.
+func NewStructuredFileAppender(cfg StructuredFileAppenderConfig) *StructuredFileAppender {
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = DefaultStructuredLogMaxSizeMB
+	}
+	maxBackups := cfg.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = DefaultStructuredLogMaxBackups
+	}
+	var maxAgeDays int
+	if cfg.MaxAge > 0 {
+		maxAgeDays = int((cfg.MaxAge + 24*time.Hour - 1) / (24 * time.Hour))
+	}
+	return &StructuredFileAppender{
+		file: &lumberjack.Logger{
+			Filename:   cfg.Filename,
+			MaxSize:    maxSize,
+			MaxBackups: maxBackups,
+			MaxAge:     maxAgeDays,
+			Compress:   true,
+		},
+		rotationInterval: cfg.RotationInterval,
+		rotatedAt:        time.Now(),
+	}
+}
+
+// Write stores the log entry.
+func (appender *StructuredFileAppender) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	stored := StoredLogEntry{
+		Time:    entry.Time.UTC(),
+		Level:   levelFromZap(entry.Level),
+		Logger:  entry.LoggerName,
+		Message: entry.Message,
+	}
+	if entry.Caller.Defined {
+		stored.Caller = callerToString(&entry.Caller)
+	}
+	if len(fields) > 0 {
+		fieldsJSON, err := ZapcoreFieldsToJSON(fields)
+		if err != nil {
+			errJSON, err := json.Marshal(map[string]string{"logging_err": err.Error()})
+			if err != nil {
+				return err
+			}
+			fieldsJSON = string(errJSON)
+		}
+		stored.Fields = json.RawMessage(fieldsJSON)
+	}
+	line, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	if appender.rotationInterval > 0 && time.Since(appender.rotatedAt) >= appender.rotationInterval {
+		appender.rotatedAt = time.Now()
+		if err := appender.file.Rotate(); err != nil {
+			return err
+		}
+	}
+	_, err = appender.file.Write(append(line, '\n'))
+	return err
+}
+
+// Sync is a no-op, entries are written to the file as they come.
+func (appender *StructuredFileAppender) Sync() error {
+	return nil
+}
+
+// Close closes the file.
+func (appender *StructuredFileAppender) Close() error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	return appender.file.Close()
+}
+
+func levelFromZap(level zapcore.Level) Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return DEBUG
+	case level == zapcore.InfoLevel:
+		return INFO
+	case level == zapcore.WarnLevel:
+		return WARN
+	default:
+		return ERROR
+	}
+}
+
+// A LogQuery selects stored log entries. Zero fields other than MinLevel match every entry.
+type LogQuery struct {
+	// MinLevel matches entries logged at this level or above. The zero value is INFO, so queries
+	// wanting debug logs must say so.
+	MinLevel Level
+	// Logger matches entries of loggers with names matching it. It is a pattern in the syntax of
+	// LoggerPatternConfig, e.g. "rdk.resource_manager.*".
+	Logger string
+	// Since and Until bound the time entries were logged at. Until is exclusive.
+	Since time.Time
+	Until time.Time
+	// Contains matches entries whose message or fields contain it, ignoring case.
+	Contains string
+	// Limit bounds the number of entries returned, keeping the most recent ones.
+	Limit int
+}
+
+type logMatcher struct {
+	query    LogQuery
+	logger   *regexp.Regexp
+	contains string
+}
+
+func newLogMatcher(query LogQuery) (*logMatcher, error) {
+	matcher := &logMatcher{query: query, contains: strings.ToLower(query.Contains)}
+	if query.Logger != "" {
+		re, err := regexp.Compile(BuildRegexFromPattern(query.Logger))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid logger pattern %q", query.Logger)
+		}
+		matcher.logger = re
+	}
+	return matcher, nil
+}
+
+func (m *logMatcher) matches(entry *StoredLogEntry) bool {
+	if entry.Level < m.query.MinLevel {
+		return false
+	}
+	if m.logger != nil && !m.logger.MatchString(entry.Logger) {
+		return false
+	}
+	if !m.query.Since.IsZero() && entry.Time.Before(m.query.Since) {
+		return false
+	}
+	if !m.query.Until.IsZero() && !entry.Time.Before(m.query.Until) {
+		return false
+	}
+	if m.contains != "" &&
+		!strings.Contains(strings.ToLower(entry.Message), m.contains) &&
+		!strings.Contains(strings.ToLower(string(entry.Fields)), m.contains) {
+		return false
+	}
+	return true
+}
+
+// QueryLogFiles returns the entries matching the query that a StructuredFileAppender stored in
+// filename and the files rotated out of it, oldest first.
+func QueryLogFiles(filename string, query LogQuery) ([]StoredLogEntry, error) {
+	matcher, err := newLogMatcher(query)
+	if err != nil {
+		return nil, err
+	}
+	files, err := storedLogFiles(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []StoredLogEntry{}
+	for _, file := range files {
+		// a rotated file only holds entries from before it was rotated out.
+		if !file.rotatedAt.IsZero() && !query.Since.IsZero() && file.rotatedAt.Before(query.Since) {
+			continue
+		}
+		if err := readStoredLogFile(file.path, func(entry *StoredLogEntry) {
+			if !matcher.matches(entry) {
+				return
+			}
+			entries = append(entries, *entry)
+			if query.Limit > 0 && len(entries) > 2*query.Limit {
+				entries = slices.Clone(entries[len(entries)-query.Limit:])
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if query.Limit > 0 && len(entries) > query.Limit {
+		entries = entries[len(entries)-query.Limit:]
+	}
+	return entries, nil
+}
+
+type storedLogFile struct {
+	path string
+	// rotatedAt is when the file was rotated out, and zero for the file still written to.
+	rotatedAt time.Time
+}
+
+// storedLogFiles returns the files a StructuredFileAppender writing to filename left behind, oldest
+// first.
+func storedLogFiles(filename string) ([]storedLogFile, error) {
+	dir := filepath.Dir(filename)
+	ext := filepath.Ext(filename)
+	prefix := strings.TrimSuffix(filepath.Base(filename), ext) + "-"
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []storedLogFile
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		uncompressed := strings.TrimSuffix(name, ".gz")
+		if uncompressed != name && slices.ContainsFunc(dirEntries, func(other os.DirEntry) bool {
+			return other.Name() == uncompressed
+		}) {
+			// still being compressed, so only the uncompressed file is complete.
+			continue
+		}
+		stamp := strings.TrimPrefix(strings.TrimSuffix(uncompressed, ext), prefix)
+		rotatedAt, err := time.Parse(lumberjackBackupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		files = append(files, storedLogFile{path: filepath.Join(dir, name), rotatedAt: rotatedAt})
+	}
+	slices.SortFunc(files, func(a, b storedLogFile) int {
+		return a.rotatedAt.Compare(b.rotatedAt)
+	})
+	if _, err := os.Stat(filename); err == nil {
+		files = append(files, storedLogFile{path: filename})
+	}
+	return files, nil
+}
+
+func readStoredLogFile(path string, visit func(*StoredLogEntry)) (err error) {
+	//nolint:gosec
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// rotated out or removed since the directory was listed.
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		err = multierr.Combine(err, file.Close())
+	}()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzReader, err := gzip.NewReader(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read log file %q", path)
+		}
+		defer func() {
+			err = multierr.Combine(err, gzReader.Close())
+		}()
+		reader = gzReader
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry StoredLogEntry
+		// skip lines cut short by a crash.
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		visit(&entry)
+	}
+	return errors.Wrapf(scanner.Err(), "failed to read log file %q", path)
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.viam.com/test"
+)
+
+func TestStructuredFileAppender(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "viam.jsonl")
+	appender := NewStructuredFileAppender(StructuredFileAppenderConfig{Filename: filename})
+
+	logger := NewBlankLogger("rdk")
+	logger.SetLevel(DEBUG)
+	logger.AddAppender(appender)
+	managerLogger := logger.Sublogger("resource_manager")
+
+	logger.Debug("starting")
+	managerLogger.Infow("constructing resource", "resource", "arm1")
+	managerLogger.Warnw("resource build error", "resource", "arm1", "error", "cannot reach arm")
+	logger.Error("shutting down")
+	test.That(t, appender.Close(), test.ShouldBeNil)
+
+	query := func(q LogQuery) []StoredLogEntry {
+		t.Helper()
+		entries, err := QueryLogFiles(filename, q)
+		test.That(t, err, test.ShouldBeNil)
+		return entries
+	}
+
+	entries := query(LogQuery{MinLevel: DEBUG})
+	test.That(t, entries, test.ShouldHaveLength, 4)
+	test.That(t, entries[0].Message, test.ShouldEqual, "starting")
+	test.That(t, entries[0].Level, test.ShouldEqual, DEBUG)
+	test.That(t, entries[0].Caller, test.ShouldContainSubstring, "structured_file_appender_test.go")
+	test.That(t, entries[2].Logger, test.ShouldEqual, "rdk.resource_manager")
+	test.That(t, string(entries[2].Fields), test.ShouldEqual, `{"resource":"arm1","error":"cannot reach arm"}`)
+
+	test.That(t, query(LogQuery{}), test.ShouldHaveLength, 3)
+	test.That(t, query(LogQuery{MinLevel: WARN}), test.ShouldHaveLength, 2)
+	test.That(t, query(LogQuery{MinLevel: DEBUG, Logger: "rdk.resource_manager*"}), test.ShouldHaveLength, 2)
+	test.That(t, query(LogQuery{Contains: "CANNOT REACH"}), test.ShouldHaveLength, 1)
+	test.That(t, query(LogQuery{Since: time.Now().Add(time.Minute)}), test.ShouldBeEmpty)
+	test.That(t, query(LogQuery{Until: time.Now().Add(-time.Minute)}), test.ShouldBeEmpty)
+
+	entries = query(LogQuery{Limit: 1})
+	test.That(t, entries, test.ShouldHaveLength, 1)
+	test.That(t, entries[0].Message, test.ShouldEqual, "shutting down")
+
+	_, err := QueryLogFiles(filename, LogQuery{Logger: "rdk.(["})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStructuredFileAppenderRotation(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "viam.jsonl")
+	appender := NewStructuredFileAppender(StructuredFileAppenderConfig{Filename: filename, RotationInterval: time.Millisecond})
+
+	write := func(msg string) {
+		t.Helper()
+		entry := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), LoggerName: "rdk", Message: msg}
+		test.That(t, appender.Write(entry, []zapcore.Field{zap.Int("n", 1)}), test.ShouldBeNil)
+	}
+	write("first")
+	time.Sleep(5 * time.Millisecond)
+	write("second")
+	time.Sleep(5 * time.Millisecond)
+	write("third")
+	test.That(t, appender.Close(), test.ShouldBeNil)
+
+	files, err := storedLogFiles(filename)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(files), test.ShouldBeGreaterThan, 1)
+
+	// entries are returned oldest first across rotated, possibly compressed, files.
+	entries, err := QueryLogFiles(filename, LogQuery{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 3)
+	test.That(t, entries[0].Message, test.ShouldEqual, "first")
+	test.That(t, entries[2].Message, test.ShouldEqual, "third")
+
+	// rotated files are compressed in the background, wait for that to finish before the
+	// directory is removed.
+	for {
+		names, err := filepath.Glob(filepath.Join(dir, "viam-*.jsonl"))
+		test.That(t, err, test.ShouldBeNil)
+		if len(names) == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// a missing file has no entries.
+	entries, err = QueryLogFiles(filepath.Join(dir, "missing", "viam.jsonl"), LogQuery{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldBeEmpty)
+}
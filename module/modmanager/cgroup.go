@@ -0,0 +1,218 @@
+package modmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"go.viam.com/utils/pexec"
+)
+
+// cgroupEnvVar holds the cgroup a module with resource limits starts in. viam-server starts such
+// modules by running itself with this variable set and the module as its first argument, and the
+// init function of this package joins the cgroup and executes the module in its place, so that the
+// module is limited from its first instruction.
+const cgroupEnvVar = "VIAM_MODULE_CGROUP"
+
+var errResourceLimitsUnsupported = errors.New("module resource limits require Linux with cgroup v2")
+
+// throttleSampleInterval is how often a module's cgroup is checked for whether the module reached its
+// CPU limit. A module is reported as throttled until a sample finds it was not throttled since the
+// previous one.
+var throttleSampleInterval = 10 * time.Second
+
+// ResourceLimitStats are statistics about a module's resource usage, read from the cgroup its
+// resource limits are enforced with.
+type ResourceLimitStats struct {
+	CPUSecs float64
+	// ThrottledPeriods is the number of scheduling periods the module was throttled for reaching its
+	// CPU limit.
+	ThrottledPeriods uint64
+	ThrottledSecs    float64
+	MemoryMB         float64
+	// OOMKills is the number of times a module process was killed for exceeding the memory limit.
+	OOMKills  uint64
+	Processes uint64
+}
+
+// cgroupStatser is an ftdc.Statser for a module's cgroup.
+type cgroupStatser struct {
+	cgroup *moduleCgroup
+}
+
+func (s cgroupStatser) Stats() any {
+	return s.cgroup.stats()
+}
+
+// cgroupProcess changes the process config of a module with resource limits to start it in the
+// cgroup its limits are enforced with, creating the cgroup on the first start. If the cgroup cannot
+// be created, the module is started as usual and applyResourceLimits reports the error. Sandboxed
+// modules join their cgroup when the sandbox starts instead.
+func (m *module) cgroupProcess(pconf *pexec.ProcessConfig) {
+	cgroup := m.cgroupPath()
+	if cgroup == "" {
+		return
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	pconf.Args = append([]string{pconf.Name}, pconf.Args...)
+	pconf.Name = self
+	environment := map[string]string{cgroupEnvVar: cgroup}
+	for key, value := range pconf.Environment {
+		environment[key] = value
+	}
+	pconf.Environment = environment
+}
+
+// applyResourceLimits checks that the module's process is in the cgroup its resource limits are
+// enforced with, moving it there if it could not join the cgroup as it started. Failures are logged
+// and the module runs without limits rather than not at all.
+func (m *module) applyResourceLimits() {
+	if m.cfg.ResourceLimits == nil {
+		return
+	}
+	m.limitsMu.Lock()
+	defer m.limitsMu.Unlock()
+
+	m.limitsErr = func() error {
+		pid, err := m.process.UnixPid()
+		if err != nil {
+			return err
+		}
+		if err := m.createCgroup(); err != nil {
+			return err
+		}
+		return m.cgroup.addProcess(pid)
+	}()
+	if m.limitsErr != nil {
+		m.logger.Warnw("Cannot apply resource limits, module will run without them", "module", m.cfg.Name, "err", m.limitsErr)
+	}
+}
+
+// createCgroup creates the module's cgroup if it does not exist yet. limitsMu must be held.
+func (m *module) createCgroup() error {
+	if m.cgroup != nil {
+		return nil
+	}
+	cgroup, err := newModuleCgroup(m.cfg.Name, *m.cfg.ResourceLimits)
+	if err != nil {
+		return err
+	}
+	m.cgroup = cgroup
+	if m.ftdc != nil {
+		m.ftdc.Add(m.getLimitsFTDCName(), cgroupStatser{cgroup})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.samplerCancel = cancel
+	m.samplerWorkers.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer m.samplerWorkers.Done()
+		for utils.SelectContextOrWait(ctx, throttleSampleInterval) {
+			m.sampleThrottling()
+		}
+	})
+	return nil
+}
+
+// sampleThrottling records whether the module was throttled for reaching its CPU limit since the
+// previous sample.
+func (m *module) sampleThrottling() {
+	m.limitsMu.Lock()
+	defer m.limitsMu.Unlock()
+	if m.cgroup == nil {
+		return
+	}
+	throttledPeriods := m.cgroup.stats().ThrottledPeriods
+	m.throttled = throttledPeriods > m.throttledPeriodsSampled
+	m.throttledPeriodsSampled = throttledPeriods
+}
+
+// cgroupPath returns the path of the module's cgroup, creating it, or "" if the module has no
+// resource limits or the cgroup cannot be created. Errors are reported by applyResourceLimits.
+func (m *module) cgroupPath() string {
+	if m.cfg.ResourceLimits == nil {
+		return ""
+	}
+	m.limitsMu.Lock()
+	defer m.limitsMu.Unlock()
+	if err := m.createCgroup(); err != nil {
+		return ""
+	}
+	return m.cgroup.path
+}
+
+// removeResourceLimits removes the module's cgroup. It must only be called once the module's
+// process has stopped.
+func (m *module) removeResourceLimits() {
+	func() {
+		m.limitsMu.Lock()
+		defer m.limitsMu.Unlock()
+		if m.cgroup == nil {
+			return
+		}
+		m.samplerCancel()
+		if m.ftdc != nil {
+			m.ftdc.Remove(m.getLimitsFTDCName())
+		}
+		if err := m.cgroup.remove(); err != nil {
+			m.logger.Warnw("Error removing module cgroup", "module", m.cfg.Name, "err", err)
+		}
+		m.cgroup = nil
+		m.throttled, m.throttledPeriodsSampled = false, 0
+	}()
+	// the sampler takes limitsMu, so it's waited for once that's released
+	m.samplerWorkers.Wait()
+}
+
+// logOOMKill logs an error if the module's process was killed for exceeding its memory limit since
+// the last time it was called.
+func (m *module) logOOMKill() {
+	m.limitsMu.Lock()
+	defer m.limitsMu.Unlock()
+	if m.cgroup == nil {
+		return
+	}
+	stats := m.cgroup.stats()
+	if stats.OOMKills > m.oomKillsLogged {
+		m.logger.Errorw("Module was killed for exceeding its memory limit",
+			"module", m.cfg.Name, "memory_mb", m.cfg.ResourceLimits.MemoryMB, "oom_kills", stats.OOMKills)
+		m.oomKillsLogged = stats.OOMKills
+	}
+}
+
+// resourceLimitHealth returns the module's resource limit statistics, if its limits are enforced,
+// and the reasons its limits make it unhealthy.
+func (m *module) resourceLimitHealth() (*ResourceLimitStats, []string) {
+	if m.cfg.ResourceLimits == nil {
+		return nil, nil
+	}
+	m.limitsMu.Lock()
+	defer m.limitsMu.Unlock()
+	if m.limitsErr != nil {
+		return nil, []string{fmt.Sprintf("resource limits are not enforced: %v", m.limitsErr)}
+	}
+	if m.cgroup == nil {
+		return nil, nil
+	}
+
+	stats := m.cgroup.stats()
+	var reasons []string
+	if stats.OOMKills > 0 {
+		reasons = append(reasons, fmt.Sprintf("killed %d time(s) for exceeding its memory limit", stats.OOMKills))
+	}
+	if m.throttled {
+		reasons = append(reasons, "throttled for reaching its CPU limit")
+	}
+	return &stats, reasons
+}
+
+func (m *module) getLimitsFTDCName() string {
+	return fmt.Sprintf("proc.modules.%s.limits", m.cfg.Name)
+}
@@ -0,0 +1,368 @@
+//go:build linux
+
+package modmanager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+)
+
+const (
+	cgroupMountPoint = "/sys/fs/cgroup"
+	// modulesCgroupName is the cgroup, within viam-server's own cgroup, that module cgroups are
+	// created in.
+	modulesCgroupName = "viam-modules"
+	// serverCgroupName is the cgroup viam-server moves itself to, since a cgroup with processes
+	// cannot delegate controllers to its children.
+	serverCgroupName = "viam-server"
+	cpuMaxPeriodUsec = 100000
+)
+
+// errSharedCgroup is returned when viam-server cannot delegate controllers to module cgroups
+// because other processes share its cgroup.
+var errSharedCgroup = errors.New("module resource limits require viam-server to run in a cgroup of its own, " +
+	"such as a systemd service with Delegate=yes, but other processes share its cgroup")
+
+var (
+	modulesCgroupOnce sync.Once
+	modulesCgroupDir  string
+	modulesCgroupErr  error
+)
+
+// Modules with resource limits are started by running viam-server with cgroupEnvVar set, see
+// cgroupProcess. Like the sandbox, this happens in init so nothing else runs before the module.
+func init() {
+	cgroup, ok := os.LookupEnv(cgroupEnvVar)
+	if !ok || len(os.Args) < 2 {
+		return
+	}
+	utils.UncheckedError(os.Unsetenv(cgroupEnvVar))
+	if err := moveProcess(os.Getpid(), cgroup); err != nil {
+		// viam-server moves the module into its cgroup once it has started, and reports the error if
+		// that fails too.
+		fmt.Fprintf(os.Stderr, "cannot start module in its cgroup: %v\n", err)
+	}
+	//nolint:gosec
+	err := syscall.Exec(os.Args[1], os.Args[1:], os.Environ())
+	fmt.Fprintf(os.Stderr, "cannot start module: %v\n", err)
+	os.Exit(1)
+}
+
+// moduleCgroup is the cgroup v2 a module's processes are limited by.
+type moduleCgroup struct {
+	path string
+}
+
+func newModuleCgroup(name string, limits config.ModuleResourceLimits) (*moduleCgroup, error) {
+	modulesCgroupOnce.Do(func() {
+		modulesCgroupDir, modulesCgroupErr = createModulesCgroup()
+	})
+	if modulesCgroupErr != nil {
+		return nil, modulesCgroupErr
+	}
+	return createModuleCgroup(modulesCgroupDir, name, limits)
+}
+
+// createModulesCgroup creates the cgroup module cgroups are created in, and delegates the cpu,
+// memory and pids controllers to it.
+func createModulesCgroup() (string, error) {
+	if _, err := os.Stat(filepath.Join(cgroupMountPoint, "cgroup.controllers")); err != nil {
+		return "", errResourceLimitsUnsupported
+	}
+	self, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	var own string
+	for _, line := range strings.Split(string(self), "\n") {
+		if rest, ok := strings.CutPrefix(line, "0::"); ok {
+			own = rest
+			break
+		}
+	}
+	if own == "" {
+		return "", errResourceLimitsUnsupported
+	}
+
+	serverDir := filepath.Join(cgroupMountPoint, own)
+	if err := enableControllers(serverDir); err != nil {
+		if !errors.Is(err, syscall.EBUSY) {
+			return "", err
+		}
+		if err := moveOwnProcesses(serverDir, filepath.Join(serverDir, serverCgroupName)); err != nil {
+			return "", err
+		}
+	}
+
+	modulesDir := filepath.Join(serverDir, modulesCgroupName)
+	if err := os.MkdirAll(modulesDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := enableControllers(modulesDir); err != nil {
+		return "", err
+	}
+	return modulesDir, nil
+}
+
+// maxMoveAttempts is how many times moveOwnProcesses looks for processes to move, since modules can
+// be started while it moves them.
+const maxMoveAttempts = 5
+
+// moveOwnProcesses moves viam-server and the processes it started, such as modules without
+// resource limits, from its cgroup at serverDir to serverOnlyDir, and then delegates controllers
+// from serverDir. Other processes in its cgroup, like the shell it was started from, are not ours
+// to move, so they need viam-server to have a cgroup of its own. If controllers still cannot be
+// delegated, the processes are moved back.
+func moveOwnProcesses(serverDir, serverOnlyDir string) error {
+	var moved []int
+	moveBack := func() {
+		for _, pid := range moved {
+			utils.UncheckedError(moveProcess(pid, serverDir))
+		}
+	}
+	for range maxMoveAttempts {
+		pids, err := ownProcesses(serverDir, os.Getpid())
+		if err != nil {
+			moveBack()
+			return err
+		}
+		for _, pid := range pids {
+			if err := moveProcess(pid, serverOnlyDir); err != nil {
+				if errors.Is(err, syscall.ESRCH) {
+					// The process exited.
+					continue
+				}
+				moveBack()
+				return errors.Wrap(err, "cannot move viam-server out of its cgroup to delegate controllers")
+			}
+			moved = append(moved, pid)
+		}
+
+		err = enableControllers(serverDir)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, syscall.EBUSY) {
+			moveBack()
+			return err
+		}
+		if len(pids) == 0 {
+			// Only processes which are not ours are left.
+			break
+		}
+	}
+	moveBack()
+	return errSharedCgroup
+}
+
+// ownProcesses returns the processes in the cgroup at dir which are self or were started by it,
+// directly or not.
+func ownProcesses(dir string, self int) ([]int, error) {
+	procs, err := os.ReadFile(filepath.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, field := range strings.Fields(string(procs)) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		if pid == self || isDescendant(pid, self) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// isDescendant returns whether the process pid was started by ancestor, directly or not.
+func isDescendant(pid, ancestor int) bool {
+	for pid > 1 {
+		ppid, err := parentPID(pid)
+		if err != nil {
+			return false
+		}
+		if ppid == ancestor {
+			return true
+		}
+		pid = ppid
+	}
+	return false
+}
+
+// parentPID returns the parent of the process pid.
+func parentPID(pid int) (int, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+	// The command name is in parentheses and may contain spaces, so the fields are counted from
+	// after it: the state, then the parent.
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, errors.Errorf("malformed stat of process %d", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 2 {
+		return 0, errors.Errorf("malformed stat of process %d", pid)
+	}
+	return strconv.Atoi(fields[1])
+}
+
+// enableControllers delegates those of the cpu, memory and pids controllers available in the cgroup
+// to its children.
+func enableControllers(dir string) error {
+	available, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return err
+	}
+	var enable []string
+	for _, controller := range strings.Fields(string(available)) {
+		switch controller {
+		case "cpu", "memory", "pids":
+			enable = append(enable, "+"+controller)
+		}
+	}
+	if len(enable) == 0 {
+		return nil
+	}
+	return os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte(strings.Join(enable, " ")), 0o644)
+}
+
+// moveProcess moves the process into the cgroup to, creating it.
+func moveProcess(pid int, to string) error {
+	if err := os.MkdirAll(to, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(to, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0o644)
+}
+
+// createModuleCgroup creates the cgroup for a module within parent and sets its limits.
+func createModuleCgroup(parent, name string, limits config.ModuleResourceLimits) (*moduleCgroup, error) {
+	delegated, err := os.ReadFile(filepath.Join(parent, "cgroup.subtree_control"))
+	if err != nil {
+		return nil, err
+	}
+	required := map[string]bool{"cpu": limits.CPUs > 0, "memory": limits.MemoryMB > 0, "pids": limits.MaxProcesses > 0}
+	for controller, isRequired := range required {
+		if isRequired && !strings.Contains(" "+string(delegated)+" ", " "+controller+" ") {
+			return nil, errors.Errorf("the %s cgroup controller is not available", controller)
+		}
+	}
+
+	cgroup := &moduleCgroup{path: filepath.Join(parent, name)}
+	if err := os.MkdirAll(cgroup.path, 0o755); err != nil {
+		return nil, err
+	}
+
+	cpuMax := fmt.Sprintf("max %d", cpuMaxPeriodUsec)
+	if limits.CPUs > 0 {
+		cpuMax = fmt.Sprintf("%d %d", max(int(limits.CPUs*cpuMaxPeriodUsec), 1000), cpuMaxPeriodUsec)
+	}
+	memoryMax, swapMax := "max", "max"
+	if limits.MemoryMB > 0 {
+		// Swap is disabled for modules with a memory limit so the limit bounds what they may use,
+		// rather than how much of it is resident.
+		memoryMax, swapMax = strconv.FormatInt(int64(limits.MemoryMB*1024*1024), 10), "0"
+	}
+	pidsMax := "max"
+	if limits.MaxProcesses > 0 {
+		pidsMax = strconv.Itoa(limits.MaxProcesses)
+	}
+
+	for _, limit := range []struct {
+		file, value string
+		required    bool
+	}{
+		{"cpu.max", cpuMax, required["cpu"]},
+		{"memory.max", memoryMax, required["memory"]},
+		{"memory.swap.max", swapMax, false},
+		{"pids.max", pidsMax, required["pids"]},
+	} {
+		if err := cgroup.write(limit.file, limit.value, limit.required); err != nil {
+			utils.UncheckedError(os.Remove(cgroup.path))
+			return nil, errors.Wrapf(err, "cannot set %s", limit.file)
+		}
+	}
+	return cgroup, nil
+}
+
+// write writes the value to a file of the cgroup. Files which do not exist, because their
+// controller is not enabled, are skipped unless required.
+func (cg *moduleCgroup) write(file, value string, required bool) error {
+	path := filepath.Join(cg.path, file)
+	if !required {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+	return os.WriteFile(path, []byte(value), 0o644)
+}
+
+func (cg *moduleCgroup) addProcess(pid int) error {
+	return cg.write("cgroup.procs", strconv.Itoa(pid), true)
+}
+
+// remove removes the cgroup. The cgroup must not have any processes.
+func (cg *moduleCgroup) remove() error {
+	return os.Remove(cg.path)
+}
+
+func (cg *moduleCgroup) stats() ResourceLimitStats {
+	cpu := cg.readKeyed("cpu.stat")
+	events := cg.readKeyed("memory.events")
+	return ResourceLimitStats{
+		CPUSecs:          float64(cpu["usage_usec"]) / 1e6,
+		ThrottledPeriods: cpu["nr_throttled"],
+		ThrottledSecs:    float64(cpu["throttled_usec"]) / 1e6,
+		MemoryMB:         float64(cg.readValue("memory.current")) / (1024 * 1024),
+		OOMKills:         events["oom_kill"],
+		Processes:        cg.readValue("pids.current"),
+	}
+}
+
+// readValue reads a file of the cgroup containing a single number, returning zero if it cannot.
+func (cg *moduleCgroup) readValue(file string) uint64 {
+	data, err := os.ReadFile(filepath.Join(cg.path, file))
+	if err != nil {
+		return 0
+	}
+	value, err := strconv.ParseUint(string(bytes.TrimSpace(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// readKeyed reads a file of the cgroup containing "key value" lines. Missing files and malformed
+// lines are ignored.
+func (cg *moduleCgroup) readKeyed(file string) map[string]uint64 {
+	values := map[string]uint64{}
+	data, err := os.ReadFile(filepath.Join(cg.path, file))
+	if err != nil {
+		return values
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,140 @@
+//go:build linux
+
+package modmanager
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+func TestModuleCgroup(t *testing.T) {
+	// The cgroup filesystem is simulated with a plain directory: the files the kernel would create are
+	// written by hand.
+	parent := t.TempDir()
+	writeFile := func(path, contents string) {
+		t.Helper()
+		test.That(t, os.WriteFile(path, []byte(contents), 0o644), test.ShouldBeNil)
+	}
+	readFile := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		return string(data)
+	}
+
+	t.Run("missing controllers", func(t *testing.T) {
+		writeFile(filepath.Join(parent, "cgroup.subtree_control"), "memory")
+		_, err := createModuleCgroup(parent, "mod", config.ModuleResourceLimits{CPUs: 1})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "cpu")
+	})
+
+	writeFile(filepath.Join(parent, "cgroup.subtree_control"), "cpu memory pids")
+	limits := config.ModuleResourceLimits{CPUs: 1.5, MemoryMB: 256, MaxProcesses: 32}
+	cgroup, err := createModuleCgroup(parent, "mod", limits)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readFile(filepath.Join(cgroup.path, "cpu.max")), test.ShouldEqual, "150000 100000")
+	test.That(t, readFile(filepath.Join(cgroup.path, "memory.max")), test.ShouldEqual, "268435456")
+	test.That(t, readFile(filepath.Join(cgroup.path, "pids.max")), test.ShouldEqual, "32")
+	// memory.swap.max is only written when the kernel provides it
+	_, err = os.Stat(filepath.Join(cgroup.path, "memory.swap.max"))
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+
+	test.That(t, cgroup.addProcess(1234), test.ShouldBeNil)
+	test.That(t, readFile(filepath.Join(cgroup.path, "cgroup.procs")), test.ShouldEqual, "1234")
+
+	writeFile(filepath.Join(cgroup.path, "cpu.stat"),
+		"usage_usec 2500000\nuser_usec 2000000\nnr_periods 40\nnr_throttled 3\nthrottled_usec 500000\n")
+	writeFile(filepath.Join(cgroup.path, "memory.events"), "low 0\nhigh 0\nmax 4\noom 1\noom_kill 1\n")
+	writeFile(filepath.Join(cgroup.path, "memory.current"), "104857600\n")
+	writeFile(filepath.Join(cgroup.path, "pids.current"), "7\n")
+	test.That(t, cgroup.stats(), test.ShouldResemble, ResourceLimitStats{
+		CPUSecs:          2.5,
+		ThrottledPeriods: 3,
+		ThrottledSecs:    0.5,
+		MemoryMB:         100,
+		OOMKills:         1,
+		Processes:        7,
+	})
+
+	t.Run("health", func(t *testing.T) {
+		mod := &module{
+			cfg:    config.Module{Name: "mod", ResourceLimits: &limits},
+			cgroup: cgroup,
+			logger: logging.NewTestLogger(t),
+		}
+		mod.sampleThrottling()
+		// reading the health doesn't change it
+		for range 2 {
+			stats, reasons := mod.resourceLimitHealth()
+			test.That(t, stats.OOMKills, test.ShouldEqual, 1)
+			test.That(t, reasons, test.ShouldHaveLength, 2)
+		}
+
+		// throttling is only reported when it happened since the previous sample
+		mod.sampleThrottling()
+		_, reasons := mod.resourceLimitHealth()
+		test.That(t, reasons, test.ShouldHaveLength, 1)
+		test.That(t, reasons[0], test.ShouldContainSubstring, "memory limit")
+	})
+}
+
+func TestStartInCgroup(t *testing.T) {
+	// The test binary joins the cgroup in init, like viam-server does, and executes a shell in its
+	// place. The cgroup is a plain directory, so joining it only writes the pid of the process.
+	cgroup := t.TempDir()
+	self, err := os.Executable()
+	test.That(t, err, test.ShouldBeNil)
+	cmd := exec.Command(self, "/bin/sh", "-c", "echo $$; env")
+	cmd.Env = append(os.Environ(), cgroupEnvVar+"="+cgroup)
+	out, err := cmd.Output()
+	test.That(t, err, test.ShouldBeNil)
+
+	pid, env, _ := strings.Cut(string(out), "\n")
+	data, err := os.ReadFile(filepath.Join(cgroup, "cgroup.procs"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(data), test.ShouldEqual, pid)
+	test.That(t, env, test.ShouldNotContainSubstring, cgroupEnvVar)
+}
+
+func TestOwnProcesses(t *testing.T) {
+	// A module viam-server started, which started a process of its own.
+	cmd := exec.Command("/bin/sh", "-c", "sleep 10 & echo $!; wait")
+	stdout, err := cmd.StdoutPipe()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cmd.Start(), test.ShouldBeNil)
+	defer func() {
+		test.That(t, cmd.Process.Kill(), test.ShouldBeNil)
+		//nolint:errcheck
+		cmd.Wait()
+	}()
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	test.That(t, err, test.ShouldBeNil)
+	grandchild, err := strconv.Atoi(strings.TrimSpace(line))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		//nolint:errcheck
+		syscall.Kill(grandchild, syscall.SIGKILL)
+	}()
+
+	// Processes which are not viam-server's, like init, are left where they are.
+	dir := t.TempDir()
+	self := os.Getpid()
+	procs := fmt.Sprintf("1\n%d\n%d\n%d\n", self, cmd.Process.Pid, grandchild)
+	test.That(t, os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(procs), 0o644), test.ShouldBeNil)
+	pids, err := ownProcesses(dir, self)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pids, test.ShouldResemble, []int{self, cmd.Process.Pid, grandchild})
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package modmanager
+
+import "go.viam.com/rdk/config"
+
+// moduleCgroup is unsupported outside of Linux.
+type moduleCgroup struct {
+	path string
+}
+
+func newModuleCgroup(name string, limits config.ModuleResourceLimits) (*moduleCgroup, error) {
+	return nil, errResourceLimitsUnsupported
+}
+
+func (cg *moduleCgroup) addProcess(pid int) error {
+	return errResourceLimitsUnsupported
+}
+
+func (cg *moduleCgroup) remove() error {
+	return nil
+}
+
+func (cg *moduleCgroup) stats() ResourceLimitStats {
+	return ResourceLimitStats{}
+}
@@ -0,0 +1,247 @@
+package modmanager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.viam.com/utils"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+)
+
+const (
+	defaultHealthCheckInterval         = 10 * time.Second
+	defaultHealthCheckTimeout          = 5 * time.Second
+	defaultHealthCheckFailureThreshold = 3
+	defaultRestartWindow               = 10 * time.Minute
+	// restartHistoryLength is the number of restart attempts kept for reporting, in addition to
+	// those within the restart policy's window.
+	restartHistoryLength = 10
+)
+
+// ModuleHealthStatus is the overall health of a module.
+type ModuleHealthStatus string
+
+const (
+	// ModuleHealthy modules are running normally.
+	ModuleHealthy ModuleHealthStatus = "healthy"
+	// ModuleDegraded modules are running, but have reached their resource limits or are running
+	// without them.
+	ModuleDegraded ModuleHealthStatus = "degraded"
+	// ModuleFailed modules have crashed or failed to start and are not running.
+	ModuleFailed ModuleHealthStatus = "failed"
+)
+
+// ModuleHealth describes the health of a module.
+type ModuleHealth struct {
+	Status ModuleHealthStatus
+	// Reasons explains why the module is not healthy.
+	Reasons []string
+	// ResourceLimits are the module's resource usage statistics. It is nil when the module has no
+	// enforced resource limits.
+	ResourceLimits *ResourceLimitStats
+	// Restarts are the module's most recent restart attempts, oldest first.
+	Restarts []ModuleRestart
+}
+
+// ModuleRestart is an attempt to restart a module.
+type ModuleRestart struct {
+	Time time.Time
+	// Reason is why the module needed restarting, such as its exit code or a failed health check.
+	Reason    string
+	Succeeded bool
+}
+
+// ModuleHealth returns the health of every module, keyed by module name. Reaching a CPU limit is
+// reported for modules which were throttled during the last throttle sample.
+func (mgr *Manager) ModuleHealth() map[string]ModuleHealth {
+	health := map[string]ModuleHealth{}
+	mgr.modules.Range(func(name string, mod *module) bool {
+		stats, reasons := mod.resourceLimitHealth()
+		status := ModuleHealthy
+		if len(reasons) > 0 {
+			status = ModuleDegraded
+		}
+		restarts, gaveUp := mod.restartHistory()
+		if gaveUp {
+			status = ModuleFailed
+			reasons = append([]string{"reached the restart limit of its restart policy"}, reasons...)
+		}
+		health[name] = ModuleHealth{Status: status, Reasons: reasons, ResourceLimits: stats, Restarts: restarts}
+		return true
+	})
+	for _, name := range mgr.FailedModules() {
+		h := health[name]
+		if h.Status != ModuleFailed {
+			h.Status = ModuleFailed
+			h.Reasons = append([]string{"module crashed or failed to start"}, h.Reasons...)
+		}
+		health[name] = h
+	}
+	return health
+}
+
+// startHealthChecks probes the module's process until stopHealthChecks is called. If the process
+// fails too many probes in a row it is killed, so that it is restarted like any other crashed module.
+// Modules which do not serve the gRPC health service pass every probe they respond to.
+func (m *module) startHealthChecks() {
+	if m.cfg.HealthCheck == nil {
+		return
+	}
+	interval := m.cfg.HealthCheck.Interval.Unwrap()
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	timeout := m.cfg.HealthCheck.Timeout.Unwrap()
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	threshold := m.cfg.HealthCheck.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultHealthCheckFailureThreshold
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.healthCancel = cancel
+	client := healthpb.NewHealthClient(m.sharedConn.GrpcConn())
+	process := m.process
+	m.healthWorkers.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer m.healthWorkers.Done()
+		failures := 0
+		for utils.SelectContextOrWait(ctx, interval) {
+			probeCtx, probeCancel := context.WithTimeout(ctx, timeout)
+			resp, err := client.Check(probeCtx, &healthpb.HealthCheckRequest{})
+			probeCancel()
+			if ctx.Err() != nil {
+				return
+			}
+			switch {
+			case status.Code(err) == codes.Unimplemented:
+				err = nil
+			case err == nil && resp.GetStatus() != healthpb.HealthCheckResponse_SERVING:
+				err = fmt.Errorf("module reported status %v", resp.GetStatus())
+			}
+			if err == nil {
+				failures = 0
+				continue
+			}
+
+			failures++
+			m.logger.Warnw("Module failed health check", "module", m.cfg.Name, "failures", failures, "err", err)
+			if failures < threshold {
+				continue
+			}
+			pid, err := process.UnixPid()
+			if err != nil {
+				m.logger.Errorw("Cannot kill unhealthy module", "module", m.cfg.Name, "err", err)
+				return
+			}
+			m.logger.Errorw("Module is unresponsive, killing it so it is restarted", "module", m.cfg.Name, "failures", failures)
+			m.killedUnhealthy.Store(true)
+			if err := killProcessGroup(pid); err != nil {
+				m.logger.Errorw("Cannot kill unhealthy module", "module", m.cfg.Name, "err", err)
+			}
+			return
+		}
+	})
+}
+
+// stopHealthChecks stops probing the module's process and waits for the probing goroutine to exit.
+func (m *module) stopHealthChecks() {
+	if m.healthCancel != nil {
+		m.healthCancel()
+		m.healthCancel = nil
+	}
+	m.healthWorkers.Wait()
+}
+
+// exitReason describes why the module's process exited unexpectedly.
+func (m *module) exitReason(exitCode int) string {
+	if m.killedUnhealthy.Swap(false) {
+		return "failed health check"
+	}
+	return fmt.Sprintf("exited with code %d", exitCode)
+}
+
+// restartPolicy returns the module's restart policy with defaults filled in.
+func (m *module) restartPolicy() config.ModuleRestartPolicy {
+	var policy config.ModuleRestartPolicy
+	if m.cfg.RestartPolicy != nil {
+		policy = *m.cfg.RestartPolicy
+	}
+	if policy.Window <= 0 {
+		policy.Window = utils.Duration(defaultRestartWindow)
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = utils.Duration(oueRestartInterval)
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	if policy.BackoffMultiplier < 1 {
+		policy.BackoffMultiplier = 1
+	}
+	return policy
+}
+
+// restartBackoff returns how long to wait after the given number of failed restart attempts in a row.
+func (m *module) restartBackoff(failures int) time.Duration {
+	policy := m.restartPolicy()
+	backoff := float64(policy.InitialBackoff) * math.Pow(policy.BackoffMultiplier, float64(failures-1))
+	return time.Duration(math.Min(backoff, float64(policy.MaxBackoff)))
+}
+
+// mayRestart returns whether the module's restart policy allows another restart attempt. Once it
+// does not, the module is given up on and is not restarted again.
+func (m *module) mayRestart(now time.Time) bool {
+	policy := m.restartPolicy()
+	m.restartsMu.Lock()
+	defer m.restartsMu.Unlock()
+	if m.gaveUp {
+		return false
+	}
+	if policy.MaxRestarts == 0 {
+		return true
+	}
+	attempts := 0
+	for _, restart := range m.restarts {
+		if now.Sub(restart.Time) < policy.Window.Unwrap() {
+			attempts++
+		}
+	}
+	m.gaveUp = attempts >= policy.MaxRestarts
+	return !m.gaveUp
+}
+
+// recordRestart adds a restart attempt to the module's history, dropping attempts which are both
+// outside of the restart policy's window and not among the most recent.
+func (m *module) recordRestart(restart ModuleRestart) {
+	window := m.restartPolicy().Window.Unwrap()
+	m.restartsMu.Lock()
+	defer m.restartsMu.Unlock()
+	m.restarts = append(m.restarts, restart)
+	kept := m.restarts[:0]
+	for i, r := range m.restarts {
+		if restart.Time.Sub(r.Time) < window || i >= len(m.restarts)-restartHistoryLength {
+			kept = append(kept, r)
+		}
+	}
+	m.restarts = kept
+}
+
+// restartHistory returns the module's most recent restart attempts and whether it was given up on.
+func (m *module) restartHistory() ([]ModuleRestart, bool) {
+	m.restartsMu.Lock()
+	defer m.restartsMu.Unlock()
+	restarts := m.restarts
+	if len(restarts) > restartHistoryLength {
+		restarts = restarts[len(restarts)-restartHistoryLength:]
+	}
+	return append([]ModuleRestart(nil), restarts...), m.gaveUp
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package modmanager
+
+import "syscall"
+
+// killProcessGroup kills the process group led by pid, which includes any processes a module's
+// entrypoint started.
+func killProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package modmanager
+
+import "os"
+
+// killProcessGroup kills the process. Windows has no process groups to kill.
+func killProcessGroup(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
@@ -399,6 +399,7 @@ func (mgr *Manager) startModule(ctx context.Context, mod *module) error {
 	}
 
 	mod.registerResourceModels(mgr)
+	mod.startHealthChecks()
 	mgr.modules.Store(mod.cfg.Name, mod)
 	mod.logger.Infow("Module successfully added", "module", mod.cfg.Name)
 	success = true
@@ -522,6 +523,7 @@ func (mgr *Manager) closeModule(mod *module, reconfigure bool) error {
 	if err := mod.stopProcess(); err != nil {
 		return errors.WithMessage(err, "error while stopping module "+mod.cfg.Name)
 	}
+	mod.removeResourceLimits()
 
 	if mgr.modPeerConnTracker != nil {
 		mgr.modPeerConnTracker.Remove(mod.cfg.Name)
@@ -891,8 +893,8 @@ func (mgr *Manager) CleanModuleDataDirectory() error {
 }
 
 // oueRestartInterval is the interval of time at which an OnUnexpectedExit
-// function can attempt to restart the module process. Multiple restart
-// attempts will use basic backoff.
+// function can attempt to restart the module process. It is the default
+// backoff of a module's restart policy.
 var oueRestartInterval = 5 * time.Second
 
 // newOnUnexpectedExitHandler returns the appropriate OnUnexpectedExit function
@@ -903,6 +905,8 @@ func (mgr *Manager) newOnUnexpectedExitHandler(ctx context.Context, mod *module)
 		mod.logger.Errorw(
 			"Module has unexpectedly exited.", "module", mod.cfg.Name, "exit_code", exitCode,
 		)
+		mod.logOOMKill()
+		reason := mod.exitReason(exitCode)
 
 		// Add to failedModules when crash is detected
 		mgr.AddToFailedModules(mod.cfg.Name)
@@ -931,14 +935,15 @@ func (mgr *Manager) newOnUnexpectedExitHandler(ctx context.Context, mod *module)
 		}
 		defer unlock()
 
-		// Enter a loop trying to restart the module every 5 seconds. If the
-		// restart succeeds we return, this goroutine ends, and the management
-		// goroutine started by the new module managedProcess handles any future
-		// crashes. If the startup fails we kill the new process, its management
-		// goroutine returns without doing anything, and we continue to loop until
-		// we succeed or our context is cancelled.
+		// Enter a loop trying to restart the module, backing off between attempts
+		// according to its restart policy. If the restart succeeds we return, this
+		// goroutine ends, and the management goroutine started by the new module
+		// managedProcess handles any future crashes. If the startup fails we kill
+		// the new process, its management goroutine returns without doing anything,
+		// and we continue to loop until we succeed, our context is cancelled, or
+		// the restart policy gives up on the module.
 		cleanupPerformed := false
-		for {
+		for failures := 1; ; failures++ {
 			lock()
 			// It's possible the module has been removed or replaced while we were
 			// waiting on the lock. Check for a context cancellation to avoid double
@@ -957,7 +962,14 @@ func (mgr *Manager) newOnUnexpectedExitHandler(ctx context.Context, mod *module)
 				cleanupPerformed = true
 			}
 
+			if !mod.mayRestart(time.Now()) {
+				mod.logger.Errorw("Module reached the restart limit of its restart policy and will not be restarted",
+					"module", mod.cfg.Name)
+				return
+			}
+			attempted := time.Now()
 			err := mgr.attemptRestart(ctx, mod)
+			mod.recordRestart(ModuleRestart{Time: attempted, Reason: reason, Succeeded: err == nil})
 			if err == nil {
 				// restart successful, remove module from failedModules
 				mgr.deleteFromFailedModules(mod.cfg.Name)
@@ -966,7 +978,7 @@ func (mgr *Manager) newOnUnexpectedExitHandler(ctx context.Context, mod *module)
 			// could not restart crashed module, add it to failedModules
 			mgr.AddToFailedModules(mod.cfg.Name)
 			unlock()
-			utils.SelectContextOrWait(ctx, oueRestartInterval)
+			utils.SelectContextOrWait(ctx, mod.restartBackoff(failures))
 		}
 
 		// If a handleOrphanedResources function is provided, we defer all re-adding to it.
@@ -1072,6 +1084,7 @@ func (mgr *Manager) attemptRestart(ctx context.Context, mod *module) error {
 		mgr.modPeerConnTracker.Add(mod.cfg.Name, pc)
 	}
 	mod.registerResourceModels(mgr)
+	mod.startHealthChecks()
 	success = true
 	return nil
 }
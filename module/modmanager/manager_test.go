@@ -1946,3 +1946,71 @@ func TestGetAutomaticPort(t *testing.T) {
 		test.That(t, lis.Close(), test.ShouldBeNil)
 	}
 }
+
+func TestModuleHealthCheck(t *testing.T) {
+	t.Run("restart backoff", func(t *testing.T) {
+		mod := &module{cfg: config.Module{RestartPolicy: &config.ModuleRestartPolicy{
+			InitialBackoff:    utils.Duration(time.Second),
+			MaxBackoff:        utils.Duration(5 * time.Second),
+			BackoffMultiplier: 2,
+		}}}
+		for failures, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+			test.That(t, mod.restartBackoff(failures+1), test.ShouldEqual, expected)
+		}
+
+		// without a policy, restarts are retried at a fixed interval
+		mod = &module{}
+		test.That(t, mod.restartBackoff(3), test.ShouldEqual, oueRestartInterval)
+	})
+
+	if runtime.GOOS == "windows" {
+		t.Skip("hung modules are simulated with SIGSTOP")
+	}
+
+	ctx := context.Background()
+	logger, logs := logging.NewObservedTestLogger(t)
+	mgr := setupModManager(t, ctx, setupSocketWithRobot(t), logger, modmanageroptions.Options{})
+	modCfg := config.Module{
+		Name:    "test-module",
+		ExePath: rtestutils.BuildTempModule(t, "module/testmodule"),
+		HealthCheck: &config.ModuleHealthCheck{
+			Interval:         utils.Duration(50 * time.Millisecond),
+			Timeout:          utils.Duration(50 * time.Millisecond),
+			FailureThreshold: 2,
+		},
+		RestartPolicy: &config.ModuleRestartPolicy{MaxRestarts: 1, InitialBackoff: utils.Duration(10 * time.Millisecond)},
+	}
+	test.That(t, mgr.Add(ctx, modCfg), test.ShouldBeNil)
+	test.That(t, mgr.ModuleHealth()[modCfg.Name].Status, test.ShouldEqual, ModuleHealthy)
+
+	// Freeze the module process so it stops responding without exiting.
+	freeze := func() {
+		t.Helper()
+		mod, ok := mgr.modules.Load(modCfg.Name)
+		test.That(t, ok, test.ShouldBeTrue)
+		pid, err := mod.process.UnixPid()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, syscall.Kill(pid, syscall.SIGSTOP), test.ShouldBeNil)
+	}
+
+	freeze()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, mgr.ModuleHealth()[modCfg.Name].Restarts, test.ShouldHaveLength, 1)
+	})
+	restart := mgr.ModuleHealth()[modCfg.Name].Restarts[0]
+	test.That(t, restart.Reason, test.ShouldEqual, "failed health check")
+	test.That(t, restart.Succeeded, test.ShouldBeTrue)
+	test.That(t, logs.FilterMessageSnippet("Module is unresponsive").Len(), test.ShouldEqual, 1)
+	test.That(t, mgr.ModuleHealth()[modCfg.Name].Status, test.ShouldEqual, ModuleHealthy)
+
+	// The restart policy allows only one restart, so the module is not restarted again.
+	freeze()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, logs.FilterMessageSnippet("reached the restart limit").Len(), test.ShouldEqual, 1)
+	})
+	health := mgr.ModuleHealth()[modCfg.Name]
+	test.That(t, health.Status, test.ShouldEqual, ModuleFailed)
+	test.That(t, health.Restarts, test.ShouldHaveLength, 1)
+}
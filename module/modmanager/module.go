@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
@@ -66,6 +67,29 @@ type module struct {
 
 	logger logging.Logger
 	ftdc   *ftdc.FTDC
+
+	// limitsMu guards the fields used to enforce and report on the module's resource limits.
+	limitsMu       sync.Mutex
+	cgroup         *moduleCgroup
+	limitsErr      error
+	oomKillsLogged uint64
+	// throttled is whether the module reached its CPU limit during the last throttle sample, and
+	// throttledPeriodsSampled is the number of periods it had been throttled for as of that sample.
+	throttledPeriodsSampled uint64
+	throttled               bool
+	samplerCancel           context.CancelFunc
+	samplerWorkers          sync.WaitGroup
+
+	healthCancel  context.CancelFunc
+	healthWorkers sync.WaitGroup
+	// killedUnhealthy is set when the module's process is killed for failing its health check.
+	killedUnhealthy atomic.Bool
+
+	// restartsMu guards the module's restart history.
+	restartsMu sync.Mutex
+	restarts   []ModuleRestart
+	// gaveUp is set once the module's restart policy no longer allows it to be restarted.
+	gaveUp bool
 }
 
 // dial will Dial the module and replace the underlying connection (if it exists) in m.conn.
@@ -130,10 +154,15 @@ func (m *module) checkReady(ctx context.Context, parentAddr string) error {
 	req := &pb.ReadyRequest{ParentAddress: parentAddr}
 
 	// Wait for gathering to complete. Pass the entire SDP as an offer to the `ReadyRequest`.
+	// Sandboxed modules without network access have no interfaces a PeerConnection could use.
 	var err error
-	req.WebrtcOffer, err = m.sharedConn.GenerateEncodedOffer()
-	if err != nil {
-		m.logger.CWarnw(ctx, "Unable to generate offer for module PeerConnection. Ignoring.", "err", err)
+	if m.isNetworkSandboxed() {
+		m.sharedConn.DisablePeerConn()
+	} else {
+		req.WebrtcOffer, err = m.sharedConn.GenerateEncodedOffer()
+		if err != nil {
+			m.logger.CWarnw(ctx, "Unable to generate offer for module PeerConnection. Ignoring.", "err", err)
+		}
 	}
 
 	for {
@@ -188,9 +217,11 @@ func (m *module) checkReady(ctx context.Context, parentAddr string) error {
 			continue
 		}
 
-		err = m.sharedConn.ProcessEncodedAnswer(resp.WebrtcAnswer)
-		if err != nil {
-			m.logger.CWarnw(ctx, "Unable to create PeerConnection with module. Ignoring.", "err", err)
+		if req.WebrtcOffer != "" {
+			err = m.sharedConn.ProcessEncodedAnswer(resp.WebrtcAnswer)
+			if err != nil {
+				m.logger.CWarnw(ctx, "Unable to create PeerConnection with module. Ignoring.", "err", err)
+			}
 		}
 
 		// The `ReadyRespones` also includes the Viam `API`s and `Model`s the module provides. This
@@ -214,6 +245,18 @@ func (m *module) isRunningInTCPMode() bool {
 	return rutils.TCPRegex.MatchString(m.addr)
 }
 
+// removeSocket removes the module's socket file if the module did not remove it already, along with
+// the socket directory of a sandboxed module.
+func (m *module) removeSocket() {
+	if m.addr == "" || m.isRunningInTCPMode() {
+		return
+	}
+	rutils.RemoveFileNoError(m.addr)
+	if m.cfg.Sandbox != nil {
+		rutils.RemoveFileNoError(filepath.Dir(m.addr))
+	}
+}
+
 func (m *module) startProcess(
 	ctx context.Context,
 	parentAddr string,
@@ -233,8 +276,13 @@ func (m *module) startProcess(
 	} else {
 		// append a random alpha string to the module name while creating a socket address to avoid conflicts
 		// with old versions of the module.
-		if m.addr, err = modlib.CreateSocketAddress(
-			filepath.Dir(parentAddr), fmt.Sprintf("%s-%s", m.cfg.Name, utils.RandomAlphaString(5))); err != nil {
+		socketDir, socketName := filepath.Dir(parentAddr), fmt.Sprintf("%s-%s", m.cfg.Name, utils.RandomAlphaString(5))
+		if m.cfg.Sandbox != nil {
+			if socketDir, err = sandboxSocketDir(socketDir, socketName); err != nil {
+				return err
+			}
+		}
+		if m.addr, err = modlib.CreateSocketAddress(socketDir, socketName); err != nil {
 			return err
 		}
 		m.addr, err = rutils.CleanWindowsSocketPath(runtime.GOOS, m.addr)
@@ -289,6 +337,13 @@ func (m *module) startProcess(
 	if tcpMode {
 		pconf.Args = append(pconf.Args, "--tcp-mode")
 	}
+	if m.cfg.Sandbox != nil {
+		if err := m.sandboxProcess(&pconf); err != nil {
+			return err
+		}
+	} else {
+		m.cgroupProcess(&pconf)
+	}
 
 	m.prevProcess = m.process
 	m.process = pexec.NewManagedProcess(pconf, m.logger)
@@ -297,6 +352,8 @@ func (m *module) startProcess(
 		return errors.WithMessage(err, "module startup failed")
 	}
 
+	m.applyResourceLimits()
+
 	// Turn on process cpu/memory diagnostics for the module process. If there's an error, we
 	// continue normally, just without FTDC.
 	m.registerProcessWithFTDC()
@@ -304,7 +361,7 @@ func (m *module) startProcess(
 	checkTicker := time.NewTicker(100 * time.Millisecond)
 	defer checkTicker.Stop()
 
-	m.logger.CInfow(ctx, "Starting up module", "module", m.cfg.Name, "tcp_mode", tcpMode)
+	m.logger.CInfow(ctx, "Starting up module", "module", m.cfg.Name, "tcp_mode", tcpMode, "sandboxed", m.cfg.Sandbox != nil)
 	rutils.LogViamEnvVariables("Starting module with following Viam environment variables", moduleEnvironment, m.logger)
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, rutils.GetModuleStartupTimeout(m.logger))
@@ -346,6 +403,7 @@ func (m *module) stopProcess() error {
 	}
 
 	m.logger.Infof("Stopping module: %s process", m.cfg.Name)
+	m.stopHealthChecks()
 
 	// Make sure the restart handler won't try to keep the process alive.
 	if m.restartCancel != nil {
@@ -355,7 +413,7 @@ func (m *module) stopProcess() error {
 	// Attempt to remove module's .sock file if module did not remove it
 	// already.
 	defer func() {
-		rutils.RemoveFileNoError(m.addr)
+		m.removeSocket()
 
 		// The system metrics "statser" is resilient to the process dying under the hood. An empty set
 		// of metrics will be reported. Therefore it is safe to continue monitoring the module process
@@ -458,15 +516,17 @@ func (m *module) cleanupAfterStartupFailure() {
 		msg := "Error while stopping process of module that failed to start"
 		m.logger.Errorw(msg, "module", m.cfg.Name, "error", err)
 	}
+	m.removeResourceLimits()
 	utils.UncheckedError(m.sharedConn.Close())
 }
 
 func (m *module) cleanupAfterCrash(mgr *Manager) {
+	m.stopHealthChecks()
 	m.deregisterResourceModels()
 	if err := m.sharedConn.Close(); err != nil {
 		m.logger.Warnw("Error closing connection to crashed module", "error", err)
 	}
-	rutils.RemoveFileNoError(m.addr)
+	m.removeSocket()
 	if mgr.ftdc != nil {
 		mgr.ftdc.Remove(m.getFTDCName())
 	}
@@ -0,0 +1,87 @@
+package modmanager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/pexec"
+)
+
+// sandboxEnvVar holds the sandboxSpec of a sandboxed module. viam-server starts sandboxed modules by
+// running itself with this variable set, and the init function of this package sets up the sandbox
+// and executes the module in its place.
+const sandboxEnvVar = "VIAM_MODULE_SANDBOX"
+
+var errSandboxUnsupported = errors.New("module sandboxing requires Linux with unprivileged user namespaces")
+
+// sandboxSpec describes the sandbox a module runs in.
+type sandboxSpec struct {
+	// Exe is the module executable, which is run with the arguments viam-server was run with.
+	Exe string `json:"exe"`
+	Dir string `json:"dir"`
+	// Writable are the directories the module may write to.
+	Writable []string `json:"writable"`
+	Devices  []string `json:"devices"`
+	Network  bool     `json:"network"`
+	// Cgroup is the cgroup the sandbox joins before starting the module, so that the module is
+	// limited from its first instruction.
+	Cgroup string `json:"cgroup,omitempty"`
+	// InNamespaces is set once the namespaces of the sandbox have been created.
+	InNamespaces bool `json:"in_namespaces,omitempty"`
+}
+
+// sandboxProcess changes the process config of a sandboxed module to run it in its sandbox.
+func (m *module) sandboxProcess(pconf *pexec.ProcessConfig) error {
+	if !sandboxSupported {
+		return errSandboxUnsupported
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "cannot find the viam-server executable to start the module sandbox")
+	}
+	spec := sandboxSpec{
+		Exe:     pconf.Name,
+		Dir:     pconf.CWD,
+		Devices: m.cfg.Sandbox.Devices,
+		Network: !m.isNetworkSandboxed(),
+		Cgroup:  m.cgroupPath(),
+	}
+	if m.dataDir != "" {
+		spec.Writable = append(spec.Writable, m.dataDir)
+	}
+	if !m.isRunningInTCPMode() {
+		// the module creates its socket in a directory of its own, see sandboxSocketDir
+		spec.Writable = append(spec.Writable, filepath.Dir(m.addr))
+	}
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	pconf.Name = self
+	environment := map[string]string{sandboxEnvVar: string(encoded)}
+	for key, value := range pconf.Environment {
+		environment[key] = value
+	}
+	pconf.Environment = environment
+	return nil
+}
+
+// sandboxSocketDir creates the directory a sandboxed module creates its socket in. The directory
+// viam-server's socket is in holds the sockets of every module, so a sandboxed module gets a directory
+// of its own within it rather than being allowed to write to it.
+func sandboxSocketDir(parentDir, name string) (string, error) {
+	dir := filepath.Join(parentDir, name)
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		return "", errors.Wrap(err, "cannot create the socket directory of the module sandbox")
+	}
+	return dir, nil
+}
+
+// isNetworkSandboxed returns whether the module runs in a sandbox without network access. Modules in
+// TCP mode always have network access, since they connect to viam-server over it.
+func (m *module) isNetworkSandboxed() bool {
+	return m.cfg.Sandbox != nil && !m.cfg.Sandbox.AllowNetwork && !m.isRunningInTCPMode()
+}
@@ -0,0 +1,377 @@
+//go:build linux
+
+package modmanager
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// sandboxSupported is whether modules can be sandboxed on this platform.
+const sandboxSupported = true
+
+// sandboxDevices are the pseudo devices every sandboxed module may use.
+var sandboxDevices = []string{"/dev/null", "/dev/zero", "/dev/full", "/dev/random", "/dev/urandom"}
+
+// Sandboxed modules are started by running viam-server with sandboxEnvVar set. This happens in init,
+// rather than main, so the sandbox works in every binary that can start modules and so nothing else
+// runs before the module.
+func init() {
+	encoded, ok := os.LookupEnv(sandboxEnvVar)
+	if !ok {
+		return
+	}
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(encoded), &spec); err != nil {
+		sandboxFatal(errors.Wrap(err, "invalid sandbox spec"))
+	}
+	if !spec.InNamespaces {
+		os.Exit(runSandbox(spec))
+	}
+	code, err := enterSandbox(spec)
+	if err != nil {
+		sandboxFatal(err)
+	}
+	os.Exit(code)
+}
+
+func sandboxFatal(err error) {
+	fmt.Fprintf(os.Stderr, "cannot sandbox module: %v\n", err)
+	os.Exit(1)
+}
+
+// runSandbox runs viam-server again in new user, mount, PID, IPC, UTS and, unless the module may use
+// the network, network namespaces, and returns its exit code. It stays the parent of the sandbox so that
+// viam-server can stop and kill it like any other module process.
+func runSandbox(spec sandboxSpec) int {
+	if spec.Cgroup != "" {
+		// Failures are reported by viam-server, which moves this process into the cgroup as well.
+		//nolint:errcheck,gosec
+		os.WriteFile(filepath.Join(spec.Cgroup, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0o644)
+	}
+
+	spec.InNamespaces = true
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		sandboxFatal(err)
+	}
+	//nolint:gosec
+	cmd := exec.Command("/proc/self/exe", os.Args[1:]...)
+	cmd.Env = append(os.Environ(), sandboxEnvVar+"="+string(encoded))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cloneFlags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+		syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS)
+	if !spec.Network {
+		cloneFlags |= syscall.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: cloneFlags,
+		// The module is root within its user namespace, which only gives it the permissions of the
+		// user running viam-server outside of it.
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+		Pdeathsig:                  syscall.SIGKILL,
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGHUP)
+	if err := cmd.Start(); err != nil {
+		sandboxFatal(errors.Wrap(err, "cannot create namespaces"))
+	}
+	go func() {
+		for sig := range signals {
+			//nolint:errcheck,gosec
+			cmd.Process.Signal(sig)
+		}
+	}()
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return exitCode(status)
+		}
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		sandboxFatal(err)
+	}
+	return 0
+}
+
+// exitCode is the exit code of a process, as a shell reports it.
+func exitCode(status syscall.WaitStatus) int {
+	if status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return status.ExitStatus()
+}
+
+// enterSandbox makes the filesystem read-only except for the writable directories and devices of
+// the sandbox, drops every capability and runs the module, returning its exit code.
+func enterSandbox(spec sandboxSpec) (int, error) {
+	// No new privileges and the capability bounding set apply to the calling thread, which is the
+	// one that starts the module.
+	runtime.LockOSThread()
+
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return 0, errors.Wrap(err, "cannot make mounts private")
+	}
+	if !spec.Network {
+		if err := bringUpLoopback(); err != nil {
+			return 0, errors.Wrap(err, "cannot bring up the loopback interface")
+		}
+	}
+
+	// Everything bound into the sandbox is opened first, since mounting over /dev hides the devices.
+	devices := append(append([]string{}, sandboxDevices...), spec.Devices...)
+	deviceFDs := make([]int, len(devices))
+	for i, device := range devices {
+		fd, err := unix.Open(device, unix.O_PATH|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot open device %s", device)
+		}
+		deviceFDs[i] = fd
+	}
+
+	var created []string
+	if err := unix.Mount("tmpfs", "/dev", "tmpfs", unix.MS_NOSUID|unix.MS_NOEXEC, "mode=0755"); err != nil {
+		return 0, errors.Wrap(err, "cannot mount /dev")
+	}
+	created = append(created, "/dev")
+	if err := os.Mkdir("/dev/shm", 0o1777); err != nil {
+		return 0, err
+	}
+	if err := unix.Mount("tmpfs", "/dev/shm", "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777"); err != nil {
+		return 0, errors.Wrap(err, "cannot mount /dev/shm")
+	}
+	created = append(created, "/dev/shm")
+	for link, target := range map[string]string{
+		"/dev/fd":     "/proc/self/fd",
+		"/dev/stdin":  "/proc/self/fd/0",
+		"/dev/stdout": "/proc/self/fd/1",
+		"/dev/stderr": "/proc/self/fd/2",
+	} {
+		if err := os.Symlink(target, link); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, device := range devices {
+		if err := bindFD(deviceFDs[i], device); err != nil {
+			return 0, errors.Wrapf(err, "cannot bind device %s", device)
+		}
+		created = append(created, device)
+	}
+	for _, dir := range spec.Writable {
+		if err := unix.Mount(dir, dir, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return 0, errors.Wrapf(err, "cannot bind %s", dir)
+		}
+		created = append(created, dir)
+	}
+
+	if err := remountReadOnly(created); err != nil {
+		return 0, err
+	}
+	// The /proc of the host shows every process of the host, so it's replaced by one which only
+	// shows the processes of the sandbox.
+	procFlags := uintptr(unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC | unix.MS_RDONLY)
+	if err := unix.Mount("proc", "/proc", "proc", procFlags, ""); err != nil {
+		return 0, errors.Wrap(err, "cannot mount /proc")
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return 0, errors.Wrap(err, "cannot set no new privileges")
+	}
+	for capability := 0; ; capability++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(capability), 0, 0, 0); err != nil {
+			if errors.Is(err, unix.EINVAL) {
+				// past the last capability the kernel knows of
+				break
+			}
+			return 0, errors.Wrap(err, "cannot drop capabilities")
+		}
+	}
+
+	if err := os.Chdir(spec.Dir); err != nil {
+		return 0, err
+	}
+	var env []string
+	for _, variable := range os.Environ() {
+		if !strings.HasPrefix(variable, sandboxEnvVar+"=") {
+			env = append(env, variable)
+		}
+	}
+	return superviseModule(spec, env)
+}
+
+// superviseModule runs the module and waits for it to exit. This process is the init process of
+// the PID namespace of the sandbox, which ignores signals it doesn't handle and inherits orphaned
+// processes, so it stays around to pass signals on to the module and to reap the orphans. Once it
+// exits, the kernel kills everything left in the sandbox.
+func superviseModule(spec sandboxSpec, env []string) (int, error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGHUP)
+	//nolint:gosec
+	pid, err := syscall.ForkExec(spec.Exe, append([]string{spec.Exe}, os.Args[1:]...), &syscall.ProcAttr{
+		Env:   env,
+		Files: []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()},
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot start module")
+	}
+	go func() {
+		for sig := range signals {
+			//nolint:errcheck,gosec
+			syscall.Kill(pid, sig.(syscall.Signal))
+		}
+	}()
+
+	for {
+		var status syscall.WaitStatus
+		exited, err := syscall.Wait4(-1, &status, 0, nil)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if exited == pid {
+			return exitCode(status), nil
+		}
+	}
+}
+
+// bringUpLoopback brings up the loopback interface of a new network namespace, which starts down.
+// Modules expect to be able to reach themselves even without a network.
+func bringUpLoopback() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer unix.Close(fd)
+	ifreq, err := unix.NewIfreq("lo")
+	if err != nil {
+		return err
+	}
+	if err := unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifreq); err != nil {
+		return err
+	}
+	ifreq.SetUint16(ifreq.Uint16() | unix.IFF_UP)
+	return unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifreq)
+}
+
+// bindFD bind mounts the file or directory opened as fd onto path, creating path if needed.
+func bindFD(fd int, path string) error {
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return err
+	}
+	if stat.Mode&unix.S_IFMT == unix.S_IFDIR {
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		// the path exists already when the device is outside of /dev
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			if err := os.WriteFile(path, nil, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return unix.Mount(fmt.Sprintf("/proc/self/fd/%d", fd), path, "", unix.MS_BIND|unix.MS_REC, "")
+}
+
+// remountReadOnly makes every mount read-only, except those mounted on the given paths.
+func remountReadOnly(except []string) error {
+	skip := map[string]bool{}
+	for _, path := range except {
+		skip[filepath.Clean(path)] = true
+	}
+	mountInfo, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer mountInfo.Close()
+
+	var mountPoints []string
+	scanner := bufio.NewScanner(mountInfo)
+	for scanner.Scan() {
+		// the mount point is the fifth field
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		mountPoint := unescapeMountPoint(fields[4])
+		if !skip[mountPoint] {
+			mountPoints = append(mountPoints, mountPoint)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, mountPoint := range mountPoints {
+		var stat unix.Statfs_t
+		if err := unix.Statfs(mountPoint, &stat); err != nil {
+			// mounts hidden by other mounts cannot be reached from within the sandbox
+			if errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EACCES) || errors.Is(err, unix.ENOTDIR) {
+				continue
+			}
+			return errors.Wrapf(err, "cannot read mount %s", mountPoint)
+		}
+		// Flags a mount had when the sandbox was created cannot be cleared, so they are preserved.
+		flags := uintptr(unix.MS_REMOUNT | unix.MS_BIND | unix.MS_RDONLY)
+		for statFlag, mountFlag := range map[uint64]uintptr{
+			unix.ST_NOSUID:     unix.MS_NOSUID,
+			unix.ST_NODEV:      unix.MS_NODEV,
+			unix.ST_NOEXEC:     unix.MS_NOEXEC,
+			unix.ST_NOATIME:    unix.MS_NOATIME,
+			unix.ST_NODIRATIME: unix.MS_NODIRATIME,
+			unix.ST_RELATIME:   unix.MS_RELATIME,
+		} {
+			if uint64(stat.Flags)&statFlag != 0 {
+				flags |= mountFlag
+			}
+		}
+		if err := unix.Mount("", mountPoint, "", flags, ""); err != nil {
+			return errors.Wrapf(err, "cannot make %s read-only", mountPoint)
+		}
+	}
+	return nil
+}
+
+// unescapeMountPoint decodes the octal escapes /proc/self/mountinfo uses for whitespace and
+// backslashes in paths.
+func unescapeMountPoint(path string) string {
+	var unescaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				unescaped.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		unescaped.WriteByte(path[i])
+	}
+	return filepath.Clean(unescaped.String())
+}
@@ -0,0 +1,165 @@
+//go:build linux
+
+package modmanager
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	modmanageroptions "go.viam.com/rdk/module/modmanager/options"
+	"go.viam.com/rdk/resource"
+	rtestutils "go.viam.com/rdk/testutils"
+)
+
+func TestSandbox(t *testing.T) {
+	probe := exec.Command("/bin/true")
+	probe.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+	}
+	if err := probe.Run(); err != nil {
+		t.Skipf("user namespaces are unavailable: %v", err)
+	}
+
+	writable := t.TempDir()
+	readOnly := t.TempDir()
+	self, err := os.Executable()
+	test.That(t, err, test.ShouldBeNil)
+
+	// The test binary sets up the sandbox in init, like viam-server does, and runs a shell in it.
+	runSandboxed := func(t *testing.T, spec sandboxSpec, script string) (string, error) {
+		t.Helper()
+		spec.Exe = "/bin/sh"
+		spec.Dir = writable
+		spec.Writable = []string{writable}
+		encoded, err := json.Marshal(spec)
+		test.That(t, err, test.ShouldBeNil)
+		cmd := exec.Command(self, "-c", script)
+		cmd.Env = append(os.Environ(), sandboxEnvVar+"="+string(encoded))
+		out, err := cmd.CombinedOutput()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	t.Run("filesystem", func(t *testing.T) {
+		out, err := runSandboxed(t, sandboxSpec{}, `
+			echo data > file
+			touch `+filepath.Join(readOnly, "file")+` 2>/dev/null && echo wrote read-only dir
+			mount -o remount,rw / 2>/dev/null && echo remounted
+			ls /dev
+			env | grep `+sandboxEnvVar+`
+			true`)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, out, test.ShouldNotContainSubstring, "wrote read-only dir")
+		test.That(t, out, test.ShouldNotContainSubstring, "remounted")
+		test.That(t, out, test.ShouldNotContainSubstring, sandboxEnvVar)
+		test.That(t, strings.Fields(out), test.ShouldContain, "null")
+		test.That(t, strings.Fields(out), test.ShouldNotContain, "tty")
+
+		data, err := os.ReadFile(filepath.Join(writable, "file"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(data), test.ShouldEqual, "data\n")
+		_, err = os.Stat(filepath.Join(readOnly, "file"))
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	t.Run("devices", func(t *testing.T) {
+		out, err := runSandboxed(t, sandboxSpec{Devices: []string{"/dev/tty"}}, "ls /dev")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, strings.Fields(out), test.ShouldContain, "tty")
+	})
+
+	t.Run("network", func(t *testing.T) {
+		// /proc/net/dev lists the interfaces of the network namespace, plus two header lines
+		out, err := runSandboxed(t, sandboxSpec{}, "cat /proc/net/dev")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, strings.Split(out, "\n"), test.ShouldHaveLength, 3)
+		test.That(t, out, test.ShouldContainSubstring, "lo:")
+
+		out, err = runSandboxed(t, sandboxSpec{Network: true}, "cat /proc/net/dev")
+		test.That(t, err, test.ShouldBeNil)
+		hostInterfaces, err := os.ReadFile("/proc/net/dev")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, strings.Split(out, "\n"), test.ShouldHaveLength, len(strings.Split(strings.TrimSpace(string(hostInterfaces)), "\n")))
+	})
+
+	t.Run("processes", func(t *testing.T) {
+		// The module only sees the processes of its sandbox, such as the init process of the
+		// sandbox, the shell, and the ls it runs. How many of these are still alive when /proc is
+		// listed depends on timing, so only check that the host's processes are not listed.
+		out, err := runSandboxed(t, sandboxSpec{}, `ls /proc`)
+		test.That(t, err, test.ShouldBeNil)
+		var pids []int
+		for _, name := range strings.Fields(out) {
+			if pid, err := strconv.Atoi(name); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+		test.That(t, pids, test.ShouldNotBeEmpty)
+		test.That(t, len(pids), test.ShouldBeLessThanOrEqualTo, 5)
+		if hostPID := os.Getpid(); hostPID > 5 {
+			test.That(t, pids, test.ShouldNotContain, hostPID)
+		}
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		_, err := runSandboxed(t, sandboxSpec{}, "exit 3")
+		var exitErr *exec.ExitError
+		test.That(t, err, test.ShouldHaveSameTypeAs, exitErr)
+		test.That(t, err.(*exec.ExitError).ExitCode(), test.ShouldEqual, 3)
+	})
+
+	t.Run("module", func(t *testing.T) {
+		ctx := context.Background()
+		mgr := setupModManager(t, ctx, setupSocketWithRobot(t), logging.NewTestLogger(t), modmanageroptions.Options{
+			ViamHomeDir: t.TempDir(),
+		})
+		modCfg := config.Module{
+			Name:    "test-module",
+			ExePath: rtestutils.BuildTempModule(t, "module/testmodule"),
+			Sandbox: &config.ModuleSandbox{},
+		}
+		test.That(t, mgr.Add(ctx, modCfg), test.ShouldBeNil)
+
+		cfg := resource.Config{Name: "myhelper", API: generic.API, Model: resource.NewModel("rdk", "test", "helper")}
+		_, _, err := cfg.Validate("test", resource.APITypeComponentName)
+		test.That(t, err, test.ShouldBeNil)
+		h, err := mgr.AddResource(ctx, cfg, nil)
+		test.That(t, err, test.ShouldBeNil)
+
+		// the module may write to its data directory, but not next to it
+		resp, err := h.DoCommand(ctx, map[string]interface{}{
+			"command": "write_data_file", "filename": "data.txt", "contents": "hello",
+		})
+		test.That(t, err, test.ShouldBeNil)
+		data, err := os.ReadFile(resp["fullpath"].(string))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(data), test.ShouldEqual, "hello")
+
+		_, err = h.DoCommand(ctx, map[string]interface{}{
+			"command": "write_data_file", "filename": "../outside.txt", "contents": "hello",
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "read-only file system")
+
+		// the module's socket is in a directory of its own, since it may not write next to viam-server's
+		mod, ok := mgr.modules.Load("test-module")
+		test.That(t, ok, test.ShouldBeTrue)
+		socketDir := filepath.Dir(mod.addr)
+		test.That(t, filepath.Dir(socketDir), test.ShouldEqual, filepath.Dir(mgr.parentAddrs.UnixAddr))
+		test.That(t, mgr.closeModule(mod, false), test.ShouldBeNil)
+		_, err = os.Stat(socketDir)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package modmanager
+
+// sandboxSupported is whether modules can be sandboxed on this platform.
+const sandboxSupported = false
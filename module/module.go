@@ -26,6 +26,8 @@ import (
 	"go.viam.com/utils/rpc"
 	"go.viam.com/utils/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"go.viam.com/rdk/components/camera/rtppassthrough"
 	// Register component APIs.
@@ -229,6 +231,10 @@ func NewModule(ctx context.Context, address string, logger logging.Logger) (*Mod
 	if err := m.server.RegisterServiceServer(ctx, &robotpb.RobotService_ServiceDesc, m); err != nil {
 		return nil, err
 	}
+	// The gRPC health service lets viam-server probe whether the module is responsive.
+	if err := m.server.RegisterServiceServer(ctx, &healthpb.Health_ServiceDesc, health.NewServer()); err != nil {
+		return nil, err
+	}
 
 	// attempt to construct a PeerConnection
 	pc, err := rgrpc.NewLocalPeerConnection(logger)
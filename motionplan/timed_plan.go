@@ -0,0 +1,505 @@
+package motionplan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+const (
+	// defaultMaxJointVelRads is the joint velocity limit used for rotational DoF when none is provided.
+	defaultMaxJointVelRads = math.Pi / 2
+	// defaultMaxJointAccRads is the joint acceleration limit used for rotational DoF when none is provided.
+	defaultMaxJointAccRads = math.Pi
+	// defaultMaxJointVelMM is the velocity limit used for translational DoF when none is provided.
+	defaultMaxJointVelMM = 200.
+	// defaultMaxJointAccMM is the acceleration limit used for translational DoF when none is provided.
+	defaultMaxJointAccMM = 400.
+
+	// timeParameterizationMaxIterations bounds the number of smoothing passes made over a trajectory.
+	timeParameterizationMaxIterations = 100
+	// minSegmentDuration is the shortest duration, in seconds, that any trajectory segment may have.
+	minSegmentDuration = 1e-3
+	// limitTolerance is the relative amount by which a limit may be exceeded before a segment is stretched.
+	limitTolerance = 1e-6
+)
+
+// DynamicLimits describes the velocity, acceleration and jerk limits of a single degree of freedom.
+// Units are radians for rotational DoF and millimeters for translational DoF, per second, second^2 and second^3.
+// A Jerk of zero means that jerk is not limited.
+type DynamicLimits struct {
+	Velocity     float64
+	Acceleration float64
+	Jerk         float64
+}
+
+func (dl DynamicLimits) validate() error {
+	if dl.Velocity <= 0 {
+		return errors.New("velocity limit must be positive")
+	}
+	if dl.Acceleration <= 0 {
+		return errors.New("acceleration limit must be positive")
+	}
+	if dl.Jerk < 0 {
+		return errors.New("jerk limit may not be negative")
+	}
+	return nil
+}
+
+// TimingOptions describes the limits used to time-parameterize a Trajectory.
+// The velocity and acceleration limits of each joint of a kinematic model are taken from the model's config. Rotational
+// and Translational are applied where the model gives none, to every DoF of the corresponding kind, as reported by the
+// Limit.IsRotational of each frame's DoF. FrameLimits may be used to override the limits of every DoF of a specific frame.
+type TimingOptions struct {
+	Rotational    DynamicLimits
+	Translational DynamicLimits
+	FrameLimits   map[string][]DynamicLimits
+}
+
+// NewDefaultTimingOptions returns conservative timing options suitable for most arms.
+func NewDefaultTimingOptions() *TimingOptions {
+	return &TimingOptions{
+		Rotational:    DynamicLimits{Velocity: defaultMaxJointVelRads, Acceleration: defaultMaxJointAccRads},
+		Translational: DynamicLimits{Velocity: defaultMaxJointVelMM, Acceleration: defaultMaxJointAccMM},
+	}
+}
+
+// limitsForFrame returns the dynamic limits of every DoF of the given frame.
+func (opts *TimingOptions) limitsForFrame(frame referenceframe.Frame) ([]DynamicLimits, error) {
+	dof := frame.DoF()
+	if limits, ok := opts.FrameLimits[frame.Name()]; ok {
+		if len(limits) != len(dof) {
+			return nil, fmt.Errorf("frame %q has %d DoF but %d dynamic limits were provided", frame.Name(), len(dof), len(limits))
+		}
+		for _, l := range limits {
+			if err := l.validate(); err != nil {
+				return nil, fmt.Errorf("invalid dynamic limits for frame %q: %w", frame.Name(), err)
+			}
+		}
+		return limits, nil
+	}
+	limits := make([]DynamicLimits, 0, len(dof))
+	for _, l := range dof {
+		if l.IsRotational() {
+			limits = append(limits, opts.Rotational)
+		} else {
+			limits = append(limits, opts.Translational)
+		}
+	}
+	if model, ok := frame.(*referenceframe.SimpleModel); ok {
+		velocities, accelerations := model.JointLimits()
+		for i := range limits {
+			if velocities[i] > 0 {
+				limits[i].Velocity = velocities[i]
+			}
+			if accelerations[i] > 0 {
+				limits[i].Acceleration = accelerations[i]
+			}
+		}
+	}
+	for _, l := range limits {
+		if err := l.validate(); err != nil {
+			return nil, fmt.Errorf("invalid dynamic limits for frame %q: %w", frame.Name(), err)
+		}
+	}
+	return limits, nil
+}
+
+// TimedWaypoint is a single step of a TimedTrajectory. Time is measured from the start of the trajectory, and
+// Velocities and Accelerations map a frame's name to the per-DoF velocity and acceleration at that waypoint.
+type TimedWaypoint struct {
+	Time          time.Duration
+	Inputs        referenceframe.FrameSystemInputs
+	Velocities    referenceframe.FrameSystemInputs
+	Accelerations referenceframe.FrameSystemInputs
+}
+
+// TimedTrajectory is a Trajectory in which every step is annotated with the time at which it should be reached and the
+// velocity the robot should have when it passes through it.
+type TimedTrajectory []TimedWaypoint
+
+// Duration returns the total time taken to execute the TimedTrajectory.
+func (tt TimedTrajectory) Duration() time.Duration {
+	if len(tt) == 0 {
+		return 0
+	}
+	return tt[len(tt)-1].Time
+}
+
+// Trajectory returns the untimed Trajectory underlying the TimedTrajectory.
+func (tt TimedTrajectory) Trajectory() Trajectory {
+	traj := make(Trajectory, 0, len(tt))
+	for _, wp := range tt {
+		traj = append(traj, wp.Inputs)
+	}
+	return traj
+}
+
+// GetFrameInputs returns the waypoints, times and velocities of a single frame from the TimedTrajectory.
+func (tt TimedTrajectory) GetFrameInputs(frameName string) ([][]referenceframe.Input, []time.Duration, [][]float64, error) {
+	positions := make([][]referenceframe.Input, 0, len(tt))
+	times := make([]time.Duration, 0, len(tt))
+	velocities := make([][]float64, 0, len(tt))
+	for _, wp := range tt {
+		inputs, ok := wp.Inputs[frameName]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("frame named %s not found in timed trajectory", frameName)
+		}
+		positions = append(positions, inputs)
+		times = append(times, wp.Time)
+		velocities = append(velocities, wp.Velocities[frameName])
+	}
+	return positions, times, velocities, nil
+}
+
+// TimedPlan is an implementation of Plan whose Trajectory has been time-parameterized to respect the velocity,
+// acceleration and jerk limits of every moving frame.
+type TimedPlan struct {
+	*SimplePlan
+	timed TimedTrajectory
+}
+
+// NewTimedPlan time-parameterizes the Trajectory of the given Plan. Every frame that appears in the Trajectory must be
+// present in the frame system. If opts is nil, NewDefaultTimingOptions is used.
+func NewTimedPlan(plan Plan, fs *referenceframe.FrameSystem, opts *TimingOptions) (*TimedPlan, error) {
+	timed, err := TimeParameterize(plan.Trajectory(), fs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &TimedPlan{SimplePlan: NewSimplePlan(plan.Path(), plan.Trajectory()), timed: timed}, nil
+}
+
+// TimedTrajectory returns the TimedTrajectory associated with the Plan.
+func (plan *TimedPlan) TimedTrajectory() TimedTrajectory {
+	return plan.timed
+}
+
+// Duration returns the total time taken to execute the Plan.
+func (plan *TimedPlan) Duration() time.Duration {
+	return plan.timed.Duration()
+}
+
+// trajectoryDoF is a single degree of freedom of a flattened trajectory.
+type trajectoryDoF struct {
+	frame  string
+	index  int
+	limits DynamicLimits
+}
+
+// TimeParameterize assigns a time and velocity to every step of the given Trajectory such that, when the robot moves
+// along it, no DoF exceeds its velocity, acceleration or jerk limit. The robot is assumed to be at rest at the first and
+// last steps of the Trajectory.
+//
+// This is an iterative time parameterization: every segment is first given the shortest duration allowed by the velocity
+// limits, a velocity is chosen for every waypoint, and segments are then repeatedly stretched wherever the motion between
+// the velocities of neighbouring waypoints violates a velocity, acceleration or jerk limit, until every limit is respected.
+func TimeParameterize(traj Trajectory, fs *referenceframe.FrameSystem, opts *TimingOptions) (TimedTrajectory, error) {
+	if opts == nil {
+		opts = NewDefaultTimingOptions()
+	}
+	if len(traj) == 0 {
+		return TimedTrajectory{}, nil
+	}
+
+	dofs, err := trajectoryDoFs(traj, fs, opts)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := flattenTrajectory(traj, dofs)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := initialSegmentDurations(positions, dofs)
+	var velocities [][]float64
+	var motion segmentMotion
+	converged := false
+	for range timeParameterizationMaxIterations {
+		velocities = waypointVelocities(positions, durations, dofs)
+		motion = newSegmentMotion(positions, durations, velocities)
+		if !stretchSegments(durations, motion, dofs) {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return nil, fmt.Errorf("time parameterization did not converge after %d iterations", timeParameterizationMaxIterations)
+	}
+
+	return newTimedTrajectory(traj, 0, durations, velocities, motion.waypointAccelerations(len(dofs)), dofs), nil
+}
+
+// NewTimedTrajectory annotates every step of the given Trajectory with the time at which it should be reached, in
+// times, and the velocity and acceleration the robot has there. Like TimeParameterize, the robot is assumed to be at
+// rest at the first and last steps. It fails if any DoF would exceed its velocity, acceleration or jerk limit.
+func NewTimedTrajectory(
+	traj Trajectory, times []time.Duration, fs *referenceframe.FrameSystem, opts *TimingOptions,
+) (TimedTrajectory, error) {
+	if opts == nil {
+		opts = NewDefaultTimingOptions()
+	}
+	if len(times) != len(traj) {
+		return nil, fmt.Errorf("trajectory has %d steps but %d times were provided", len(traj), len(times))
+	}
+	if len(traj) == 0 {
+		return TimedTrajectory{}, nil
+	}
+
+	dofs, err := trajectoryDoFs(traj, fs, opts)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := flattenTrajectory(traj, dofs)
+	if err != nil {
+		return nil, err
+	}
+	durations := make([]float64, len(traj)-1)
+	for i := range durations {
+		durations[i] = (times[i+1] - times[i]).Seconds()
+		if durations[i] <= 0 {
+			return nil, fmt.Errorf("the time of step %d of the trajectory is not after the step before it", i+1)
+		}
+	}
+
+	velocities := waypointVelocities(positions, durations, dofs)
+	motion := newSegmentMotion(positions, durations, velocities)
+	for i := range durations {
+		for j, d := range dofs {
+			if v := math.Abs(motion.peaks[i][j]); v > d.limits.Velocity*(1+limitTolerance) {
+				return nil, fmt.Errorf(
+					"DoF %d of frame %q would move at %.3f per second between steps %d and %d, faster than its limit of %.3f",
+					d.index, d.frame, v, i, i+1, d.limits.Velocity)
+			}
+		}
+	}
+	for i := range durations {
+		for j, d := range dofs {
+			a := math.Max(math.Abs(motion.leaving[i][j]), math.Abs(motion.arriving[i][j]))
+			if a > d.limits.Acceleration*(1+limitTolerance) {
+				return nil, fmt.Errorf(
+					"DoF %d of frame %q would accelerate at %.3f per second squared between steps %d and %d, "+
+						"faster than its limit of %.3f",
+					d.index, d.frame, a, i, i+1, d.limits.Acceleration)
+			}
+		}
+	}
+	for i := range durations {
+		for j, d := range dofs {
+			if d.limits.Jerk == 0 {
+				continue
+			}
+			if jerk := math.Abs(motion.jerk(i, j, durations)); jerk > d.limits.Jerk*(1+limitTolerance) {
+				return nil, fmt.Errorf(
+					"DoF %d of frame %q would jerk at %.3f per second cubed between steps %d and %d, more than its limit of %.3f",
+					d.index, d.frame, jerk, i, i+1, d.limits.Jerk)
+			}
+		}
+	}
+	return newTimedTrajectory(traj, times[0], durations, velocities, motion.waypointAccelerations(len(dofs)), dofs), nil
+}
+
+// newTimedTrajectory annotates the steps of the trajectory with their times, starting at start and separated by the
+// durations, and with their velocities and accelerations.
+func newTimedTrajectory(
+	traj Trajectory, start time.Duration, durations []float64, velocities, accelerations [][]float64, dofs []trajectoryDoF,
+) TimedTrajectory {
+	timed := make(TimedTrajectory, 0, len(traj))
+	var elapsed float64
+	for i, step := range traj {
+		if i > 0 {
+			elapsed += durations[i-1]
+		}
+		timed = append(timed, TimedWaypoint{
+			Time:          start + time.Duration(elapsed*float64(time.Second)),
+			Inputs:        step,
+			Velocities:    unflatten(velocities[i], dofs),
+			Accelerations: unflatten(accelerations[i], dofs),
+		})
+	}
+	return timed
+}
+
+// trajectoryDoFs returns every moving DoF in the trajectory, in a deterministic order.
+func trajectoryDoFs(traj Trajectory, fs *referenceframe.FrameSystem, opts *TimingOptions) ([]trajectoryDoF, error) {
+	names := make([]string, 0, len(traj[0]))
+	for name := range traj[0] {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	dofs := []trajectoryDoF{}
+	for _, name := range names {
+		frame := fs.Frame(name)
+		if frame == nil {
+			return nil, referenceframe.NewFrameMissingError(name)
+		}
+		limits, err := opts.limitsForFrame(frame)
+		if err != nil {
+			return nil, err
+		}
+		for i, l := range limits {
+			dofs = append(dofs, trajectoryDoF{frame: name, index: i, limits: l})
+		}
+	}
+	return dofs, nil
+}
+
+// flattenTrajectory converts each step of the trajectory into a slice of floats ordered as dofs.
+func flattenTrajectory(traj Trajectory, dofs []trajectoryDoF) ([][]float64, error) {
+	positions := make([][]float64, 0, len(traj))
+	for i, step := range traj {
+		flat := make([]float64, 0, len(dofs))
+		for _, d := range dofs {
+			inputs, ok := step[d.frame]
+			if !ok {
+				return nil, fmt.Errorf("frame named %s not found in step %d of trajectory", d.frame, i)
+			}
+			if d.index >= len(inputs) {
+				return nil, referenceframe.NewIncorrectDoFError(len(inputs), d.index+1)
+			}
+			flat = append(flat, inputs[d.index])
+		}
+		positions = append(positions, flat)
+	}
+	return positions, nil
+}
+
+func unflatten(values []float64, dofs []trajectoryDoF) referenceframe.FrameSystemInputs {
+	out := referenceframe.FrameSystemInputs{}
+	for i, d := range dofs {
+		out[d.frame] = append(out[d.frame], values[i])
+	}
+	return out
+}
+
+// initialSegmentDurations returns, for each segment, the shortest duration that respects every velocity limit.
+func initialSegmentDurations(positions [][]float64, dofs []trajectoryDoF) []float64 {
+	durations := make([]float64, len(positions)-1)
+	for i := range durations {
+		t := minSegmentDuration
+		for j, d := range dofs {
+			t = math.Max(t, math.Abs(positions[i+1][j]-positions[i][j])/d.limits.Velocity)
+		}
+		durations[i] = t
+	}
+	return durations
+}
+
+// waypointVelocities estimates the velocity of every DoF at every waypoint. The robot is at rest at either end of the
+// trajectory and at any waypoint where a DoF reverses direction.
+func waypointVelocities(positions [][]float64, durations []float64, dofs []trajectoryDoF) [][]float64 {
+	velocities := make([][]float64, len(positions))
+	for i := range positions {
+		velocities[i] = make([]float64, len(dofs))
+		if i == 0 || i == len(positions)-1 {
+			continue
+		}
+		for j, d := range dofs {
+			before := (positions[i][j] - positions[i-1][j]) / durations[i-1]
+			after := (positions[i+1][j] - positions[i][j]) / durations[i]
+			if before*after <= 0 {
+				continue
+			}
+			v := (before + after) / 2
+			velocities[i][j] = math.Copysign(math.Min(math.Abs(v), d.limits.Velocity), v)
+		}
+	}
+	return velocities
+}
+
+// segmentMotion describes how every DoF moves over every segment of a trajectory. The velocity of a DoF changes linearly
+// from its velocity at the waypoint starting a segment to a peak halfway through the segment, and then linearly to its
+// velocity at the waypoint ending the segment, with the peak chosen so that the DoF covers the distance of the segment.
+type segmentMotion struct {
+	// peaks holds the velocity of every DoF halfway through every segment.
+	peaks [][]float64
+	// leaving and arriving hold the acceleration of every DoF over the first and second half of every segment.
+	leaving  [][]float64
+	arriving [][]float64
+}
+
+// newSegmentMotion works out the motion of every segment from the segment durations and the velocities at the waypoints.
+// The accelerations follow from the differences between the velocities at the waypoints and the peak velocities
+// between them, so the waypoint velocities always agree with the accelerations needed to reach them.
+func newSegmentMotion(positions [][]float64, durations []float64, velocities [][]float64) segmentMotion {
+	motion := segmentMotion{
+		peaks:    make([][]float64, len(durations)),
+		leaving:  make([][]float64, len(durations)),
+		arriving: make([][]float64, len(durations)),
+	}
+	for i, t := range durations {
+		n := len(velocities[i])
+		motion.peaks[i] = make([]float64, n)
+		motion.leaving[i] = make([]float64, n)
+		motion.arriving[i] = make([]float64, n)
+		for j := range n {
+			// The distance covered is the area under the velocity, t/4 * (v0 + 2*peak + v1).
+			peak := 2*(positions[i+1][j]-positions[i][j])/t - (velocities[i][j]+velocities[i+1][j])/2
+			motion.peaks[i][j] = peak
+			motion.leaving[i][j] = 2 * (peak - velocities[i][j]) / t
+			motion.arriving[i][j] = 2 * (velocities[i+1][j] - peak) / t
+		}
+	}
+	return motion
+}
+
+// jerk returns the rate at which the acceleration of DoF j changes over segment i.
+func (motion segmentMotion) jerk(i, j int, durations []float64) float64 {
+	return (motion.arriving[i][j] - motion.leaving[i][j]) / durations[i]
+}
+
+// waypointAccelerations returns the acceleration of every DoF as it leaves every waypoint, or, at the last waypoint, as
+// it arrives there.
+func (motion segmentMotion) waypointAccelerations(numDoF int) [][]float64 {
+	if len(motion.leaving) == 0 {
+		return [][]float64{make([]float64, numDoF)}
+	}
+	accelerations := make([][]float64, 0, len(motion.leaving)+1)
+	accelerations = append(accelerations, motion.leaving...)
+	return append(accelerations, motion.arriving[len(motion.arriving)-1])
+}
+
+// stretchSegments lengthens every segment over which a velocity, acceleration or jerk limit is exceeded. It returns whether
+// any segment was changed.
+func stretchSegments(durations []float64, motion segmentMotion, dofs []trajectoryDoF) bool {
+	changed := false
+	stretch := func(segment int, factor float64) {
+		if segment < 0 || segment >= len(durations) || factor <= 1+limitTolerance {
+			return
+		}
+		durations[segment] *= factor
+		changed = true
+	}
+
+	for i := range durations {
+		// Velocities scale with the inverse of time and accelerations with the inverse square of time, so the violation
+		// ratio and its square root are the amounts by which the segment must be slowed down.
+		factor := 1.
+		for j, d := range dofs {
+			factor = math.Max(factor, math.Abs(motion.peaks[i][j])/d.limits.Velocity)
+			factor = math.Max(factor, math.Sqrt(math.Abs(motion.leaving[i][j])/d.limits.Acceleration))
+			factor = math.Max(factor, math.Sqrt(math.Abs(motion.arriving[i][j])/d.limits.Acceleration))
+		}
+		stretch(i, factor)
+	}
+	if changed {
+		return true
+	}
+
+	for i := range durations {
+		// Jerk scales with the inverse cube of time.
+		factor := 1.
+		for j, d := range dofs {
+			if d.limits.Jerk == 0 {
+				continue
+			}
+			factor = math.Max(factor, math.Cbrt(math.Abs(motion.jerk(i, j, durations))/d.limits.Jerk))
+		}
+		stretch(i, factor)
+	}
+	return changed
+}
@@ -0,0 +1,179 @@
+package motionplan
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/utils"
+)
+
+func TestTimeParameterize(t *testing.T) {
+	m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("test")
+	test.That(t, fs.AddFrame(m, fs.World()), test.ShouldBeNil)
+
+	traj := Trajectory{}
+	for i := range 20 {
+		inputs := make([]referenceframe.Input, len(m.DoF()))
+		for j := range inputs {
+			inputs[j] = math.Sin(float64(i)/5) * float64(j+1) / 10
+		}
+		traj = append(traj, referenceframe.FrameSystemInputs{m.Name(): inputs})
+	}
+
+	opts := NewDefaultTimingOptions()
+	opts.Rotational.Jerk = 10
+	timed, err := TimeParameterize(traj, fs, opts)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(timed), test.ShouldEqual, len(traj))
+	test.That(t, timed[0].Time, test.ShouldEqual, 0)
+	test.That(t, timed.Duration(), test.ShouldBeGreaterThan, 0)
+	test.That(t, timed.Trajectory(), test.ShouldResemble, traj)
+
+	for i, wp := range timed {
+		if i > 0 {
+			test.That(t, wp.Time, test.ShouldBeGreaterThan, timed[i-1].Time)
+		}
+		for j, v := range wp.Velocities[m.Name()] {
+			test.That(t, math.Abs(v), test.ShouldBeLessThanOrEqualTo, opts.Rotational.Velocity)
+			a := wp.Accelerations[m.Name()][j]
+			test.That(t, math.Abs(a), test.ShouldBeLessThanOrEqualTo, opts.Rotational.Acceleration*(1+1e-3))
+		}
+	}
+	// The robot starts and ends at rest.
+	for _, v := range timed[0].Velocities[m.Name()] {
+		test.That(t, v, test.ShouldEqual, 0)
+	}
+	for _, v := range timed[len(timed)-1].Velocities[m.Name()] {
+		test.That(t, v, test.ShouldEqual, 0)
+	}
+
+	positions, times, velocities, err := timed.GetFrameInputs(m.Name())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(positions), test.ShouldEqual, len(traj))
+	test.That(t, len(times), test.ShouldEqual, len(traj))
+	test.That(t, len(velocities), test.ShouldEqual, len(traj))
+
+	t.Run("slower limits take longer", func(t *testing.T) {
+		slow := NewDefaultTimingOptions()
+		slow.Rotational.Velocity /= 4
+		slow.Rotational.Acceleration /= 4
+		slowTimed, err := TimeParameterize(traj, fs, slow)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, slowTimed.Duration(), test.ShouldBeGreaterThan, timed.Duration())
+	})
+
+	t.Run("timed plan", func(t *testing.T) {
+		plan, err := NewTimedPlan(NewSimplePlan(nil, traj), fs, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, plan.Trajectory(), test.ShouldResemble, traj)
+		test.That(t, len(plan.TimedTrajectory()), test.ShouldEqual, len(traj))
+		test.That(t, plan.Duration(), test.ShouldBeGreaterThan, 0)
+	})
+
+	t.Run("joint limits of the model", func(t *testing.T) {
+		// The joints of the ur5e can move at 180 degrees per second, faster than the default limit, so
+		// half a turn takes two seconds when the joint speeds up to its limit halfway and slows down again.
+		ur5e, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/sim/kinematics/ur5e.json"), "")
+		test.That(t, err, test.ShouldBeNil)
+		ur5eFS := referenceframe.NewEmptyFrameSystem("test")
+		test.That(t, ur5eFS.AddFrame(ur5e, ur5eFS.World()), test.ShouldBeNil)
+		move := Trajectory{
+			{ur5e.Name(): make([]referenceframe.Input, 6)},
+			{ur5e.Name(): {math.Pi, 0, 0, 0, 0, 0}},
+		}
+
+		opts := NewDefaultTimingOptions()
+		opts.Rotational.Acceleration = 1000
+		timed, err := TimeParameterize(move, ur5eFS, opts)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, timed.Duration().Seconds(), test.ShouldAlmostEqual, 2, 1e-3)
+	})
+
+	t.Run("accelerations agree with velocities", func(t *testing.T) {
+		// A joint moving quickly, then briefly slowing down before moving quickly again must shed and
+		// regain its speed within its acceleration limit.
+		corner := Trajectory{}
+		for _, position := range []float64{0, 1, 2, 2.05, 2.1, 3, 4} {
+			corner = append(corner, referenceframe.FrameSystemInputs{m.Name(): {position, 0, 0, 0, 0, 0}})
+		}
+		limit := opts.Rotational.Acceleration * (1 + 1e-3)
+		for _, tr := range []Trajectory{traj, corner} {
+			timed, err := TimeParameterize(tr, fs, opts)
+			test.That(t, err, test.ShouldBeNil)
+			for i := 1; i < len(timed); i++ {
+				segment := (timed[i].Time - timed[i-1].Time).Seconds()
+				for j, v := range timed[i].Velocities[m.Name()] {
+					start := timed[i-1].Velocities[m.Name()][j]
+					test.That(t, math.Abs(v-start)/segment, test.ShouldBeLessThanOrEqualTo, limit)
+					test.That(t, math.Abs(timed[i].Accelerations[m.Name()][j]), test.ShouldBeLessThanOrEqualTo, limit)
+
+					// Going from one velocity to the next within the acceleration limit, the joint can
+					// only cover so much or so little distance in the time between the waypoints.
+					distance := timed[i].Inputs[m.Name()][j] - timed[i-1].Inputs[m.Name()][j]
+					test.That(t, distance, test.ShouldBeBetweenOrEqual,
+						extremeDistance(start, v, segment, -limit)-1e-9, extremeDistance(start, v, segment, limit)+1e-9)
+				}
+			}
+		}
+	})
+
+	t.Run("given times", func(t *testing.T) {
+		// The times TimeParameterize found are within the limits.
+		times := make([]time.Duration, 0, len(timed))
+		for _, wp := range timed {
+			times = append(times, wp.Time)
+		}
+		given, err := NewTimedTrajectory(traj, times, fs, opts)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, given.Duration().Seconds(), test.ShouldAlmostEqual, timed.Duration().Seconds(), 1e-6)
+		for j, v := range given[1].Velocities[m.Name()] {
+			test.That(t, v, test.ShouldAlmostEqual, timed[1].Velocities[m.Name()][j], 1e-6)
+		}
+
+		// Moving a joint half a radian in 0.7 seconds is within its velocity limit, but starting and
+		// stopping that quickly is not within its acceleration limit.
+		move := Trajectory{{m.Name(): make([]referenceframe.Input, 6)}, {m.Name(): {0.5, 0, 0, 0, 0, 0}}}
+		_, err = NewTimedTrajectory(move, []time.Duration{0, 700 * time.Millisecond}, fs, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "accelerate")
+		_, err = NewTimedTrajectory(move, []time.Duration{0, 100 * time.Millisecond}, fs, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "move at")
+		_, err = NewTimedTrajectory(move, []time.Duration{0, 2 * time.Second}, fs, nil)
+		test.That(t, err, test.ShouldBeNil)
+
+		_, err = NewTimedTrajectory(move, []time.Duration{0}, fs, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = NewTimedTrajectory(move, []time.Duration{time.Second, time.Second}, fs, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := TimeParameterize(Trajectory{{"missing": {0}}}, fs, nil)
+		test.That(t, err, test.ShouldBeError, referenceframe.NewFrameMissingError("missing"))
+
+		bad := NewDefaultTimingOptions()
+		bad.FrameLimits = map[string][]DynamicLimits{m.Name(): {{Velocity: 1, Acceleration: 1}}}
+		_, err = TimeParameterize(traj, fs, bad)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		bad.FrameLimits = nil
+		bad.Rotational.Velocity = 0
+		_, err = TimeParameterize(traj, fs, bad)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+// extremeDistance returns how far a DoF moves over a duration when it changes from the start to
+// the end velocity by accelerating at the given rate, then decelerating at the same rate.
+func extremeDistance(start, end, duration, acceleration float64) float64 {
+	peak := (acceleration*duration + start + end) / 2
+	rampUp := (peak - start) / acceleration
+	return rampUp*(start+peak)/2 + (duration-rampUp)*(peak+end)/2
+}
@@ -0,0 +1,248 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultHistorySize is the number of completed operations a Manager remembers.
+	DefaultHistorySize = 1000
+
+	// maxArgumentsLength bounds the summary of the arguments of an operation kept in its record.
+	maxArgumentsLength = 256
+
+	// maxHistoryFileSize is the size past which a history file is moved aside to "<path>.1" and
+	// started over.
+	maxHistoryFileSize = 10 << 20
+)
+
+// A Record describes a completed operation.
+type Record struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id,omitempty"`
+	// Entity is the authenticated entity of the client that started the operation, if any.
+	Entity string `json:"entity,omitempty"`
+	Method string `json:"method"`
+	// Resource is the name of the resource the request of the operation named, if any, as the
+	// request named it.
+	Resource string `json:"resource,omitempty"`
+	// Arguments summarizes the request of the operation. Long requests are truncated. Requests of
+	// queries, which are only recorded when they are canceled, are not summarized.
+	Arguments string    `json:"arguments,omitempty"`
+	Started   time.Time `json:"started"`
+	Ended     time.Time `json:"ended"`
+	Error     string    `json:"error,omitempty"`
+	// CancelCause says who or what canceled the operation, if it was canceled.
+	CancelCause string `json:"cancel_cause,omitempty"`
+}
+
+// A HistoryQuery selects records from the history of a Manager. Zero fields match every record.
+type HistoryQuery struct {
+	// Method matches records whose method contains it, e.g. "MoveToPosition".
+	Method string
+	// Resource matches records of requests naming the resource. It is either the name of the
+	// resource or its full name.
+	Resource  string
+	SessionID uuid.UUID
+	// Since matches records of operations that ended at or after it.
+	Since time.Time
+	// Limit bounds the number of records returned, keeping the most recent ones.
+	Limit int
+}
+
+func (q HistoryQuery) matches(r Record) bool {
+	if q.Method != "" && !strings.Contains(r.Method, q.Method) {
+		return false
+	}
+	if q.Resource != "" && (r.Resource == "" || (r.Resource != q.Resource && !strings.HasSuffix(q.Resource, "/"+r.Resource))) {
+		return false
+	}
+	if q.SessionID != uuid.Nil && r.SessionID != q.SessionID.String() {
+		return false
+	}
+	return q.Since.IsZero() || !r.Ended.Before(q.Since)
+}
+
+// history is a bounded, oldest first, record of completed operations which may be appended to a
+// file as JSON lines.
+type history struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+	file    *os.File
+	path    string
+}
+
+func newHistory(size int) *history {
+	if size < 1 {
+		size = DefaultHistorySize
+	}
+	return &history{records: make([]Record, size)}
+}
+
+func (h *history) add(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.insert(r)
+	if h.file == nil {
+		return nil
+	}
+	return h.persist(r)
+}
+
+func (h *history) insert(r Record) {
+	h.records[h.next] = r
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *history) persist(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if info, err := h.file.Stat(); err == nil && info.Size()+int64(len(line)) > maxHistoryFileSize {
+		if err := h.rotate(); err != nil {
+			return err
+		}
+	}
+	_, err = h.file.Write(append(line, '\n'))
+	return err
+}
+
+func (h *history) rotate() error {
+	if err := h.file.Close(); err != nil {
+		return err
+	}
+	h.file = nil
+	if err := os.Rename(h.path, h.path+".1"); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	h.file = file
+	return nil
+}
+
+func (h *history) query(q HistoryQuery) []Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var ordered []Record
+	if h.full {
+		ordered = append(ordered, h.records[h.next:]...)
+	}
+	ordered = append(ordered, h.records[:h.next]...)
+
+	matched := []Record{}
+	for _, r := range ordered {
+		if q.matches(r) {
+			matched = append(matched, r)
+		}
+	}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[len(matched)-q.Limit:]
+	}
+	return matched
+}
+
+// open loads the records already in the file at path and appends records to it from then on.
+func (h *history) open(path string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file != nil {
+		if err := h.file.Close(); err != nil {
+			return err
+		}
+		h.file = nil
+	}
+	truncated, err := h.load(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if truncated {
+		// end the line cut short so that the next record starts a line of its own.
+		if _, err := file.Write([]byte{'\n'}); err != nil {
+			return multierr.Combine(err, file.Close())
+		}
+	}
+	h.file = file
+	h.path = path
+	return nil
+}
+
+// load inserts the records in the file at path, and returns whether its last line was cut short.
+func (h *history) load(path string) (truncated bool, err error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to read operation history from %q", path)
+	}
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		var r Record
+		// A line cut short by a crash should not cost the rest of the history.
+		if json.Unmarshal(line, &r) == nil {
+			h.insert(r)
+		}
+	}
+	return len(data) > 0 && data[len(data)-1] != '\n', nil
+}
+
+func (h *history) close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
+
+// summarizeArguments describes a request briefly enough to be kept for every operation.
+func summarizeArguments(req interface{}) string {
+	msg, ok := req.(proto.Message)
+	if !ok || msg == nil {
+		return ""
+	}
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+	// protojson output is not stable, so compact it to keep summaries comparable.
+	summary := strings.Join(strings.Fields(string(encoded)), " ")
+	if len(summary) > maxArgumentsLength {
+		summary = summary[:maxArgumentsLength] + "..."
+	}
+	return summary
+}
+
+// requestResource returns the name of the resource a request names, if any.
+func requestResource(req interface{}) string {
+	if named, ok := req.(interface{ GetName() string }); ok {
+		return named.GetName()
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/session"
+)
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	m := NewManager(logger)
+
+	sess := session.New(ctx, "someone", 0, nil)
+	_, cleanup := m.Create(session.ToContext(ctx, sess), "/viam.component.arm.v1.ArmService/MoveToPosition", nil)
+	cleanup()
+	_, cleanup = m.Create(ctx, "/viam.component.arm.v1.ArmService/GetEndPosition", nil)
+	cleanup()
+	_, cleanup = m.Create(ctx, "/viam.component.motor.v1.MotorService/SetPower", nil)
+	cleanup()
+	_, cleanup = m.Create(ctx, "/viam.robot.v1.RobotService/SendSessionHeartbeat", nil)
+	cleanup()
+	_, cleanup = m.Create(ctx, "/viam.robot.v1.RobotService/Log", nil)
+	cleanup()
+
+	records := m.History(HistoryQuery{})
+	test.That(t, records, test.ShouldHaveLength, 2)
+	test.That(t, records[0].Method, test.ShouldEqual, "/viam.component.arm.v1.ArmService/MoveToPosition")
+	test.That(t, records[0].SessionID, test.ShouldEqual, sess.ID().String())
+	test.That(t, records[0].Ended, test.ShouldHappenOnOrAfter, records[0].Started)
+	test.That(t, records[1].Method, test.ShouldEqual, "/viam.component.motor.v1.MotorService/SetPower")
+	test.That(t, records[1].SessionID, test.ShouldBeEmpty)
+
+	records = m.History(HistoryQuery{Method: "MoveToPosition"})
+	test.That(t, records, test.ShouldHaveLength, 1)
+	records = m.History(HistoryQuery{SessionID: sess.ID()})
+	test.That(t, records, test.ShouldHaveLength, 1)
+	records = m.History(HistoryQuery{Limit: 1})
+	test.That(t, records, test.ShouldHaveLength, 1)
+	test.That(t, records[0].Method, test.ShouldEqual, "/viam.component.motor.v1.MotorService/SetPower")
+	records = m.History(HistoryQuery{Since: time.Now().Add(time.Minute)})
+	test.That(t, records, test.ShouldBeEmpty)
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	h := newHistory(3)
+	for _, method := range []string{"a", "b", "c", "d", "e"} {
+		test.That(t, h.add(Record{Method: method}), test.ShouldBeNil)
+	}
+	records := h.query(HistoryQuery{})
+	test.That(t, records, test.ShouldHaveLength, 3)
+	test.That(t, records[0].Method, test.ShouldEqual, "c")
+	test.That(t, records[2].Method, test.ShouldEqual, "e")
+}
+
+func TestHistoryCancelCause(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	m := NewManager(logger)
+
+	ctx1, cleanup1 := m.Create(ctx, "first", nil)
+	CancelOtherWithLabel(ctx1, "motor1")
+	ctx2, cleanup2 := m.Create(ctx, "second", nil)
+	CancelOtherWithLabel(ctx2, "motor1")
+	test.That(t, ctx1.Err(), test.ShouldNotBeNil)
+	cleanup1()
+
+	ctx3, cleanup3 := m.Create(ctx, "third", nil)
+	Get(ctx3).Cancel()
+	cleanup3()
+	cleanup2()
+
+	records := m.History(HistoryQuery{})
+	test.That(t, records, test.ShouldHaveLength, 3)
+	test.That(t, records[0].Method, test.ShouldEqual, "first")
+	test.That(t, records[0].CancelCause, test.ShouldContainSubstring, `superseded on "motor1"`)
+	test.That(t, records[0].CancelCause, test.ShouldContainSubstring, Get(ctx2).ID.String())
+	test.That(t, records[1].Method, test.ShouldEqual, "third")
+	test.That(t, records[1].CancelCause, test.ShouldEqual, "canceled")
+	test.That(t, records[2].Method, test.ShouldEqual, "second")
+	test.That(t, records[2].CancelCause, test.ShouldBeEmpty)
+}
+
+func TestHistoryFromInterceptor(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	m := NewManager(logger)
+
+	req := &pb.MoveToPositionRequest{Name: "arm1"}
+	info := &grpc.UnaryServerInfo{FullMethod: "/viam.component.arm.v1.ArmService/MoveToPosition"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+	_, err := m.UnaryServerInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		return nil, errors.New("out of reach")
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	records := m.History(HistoryQuery{Resource: "rdk:component:arm/arm1"})
+	test.That(t, records, test.ShouldHaveLength, 1)
+	test.That(t, records[0].Error, test.ShouldEqual, "out of reach")
+	test.That(t, records[0].Arguments, test.ShouldContainSubstring, "arm1")
+	test.That(t, m.History(HistoryQuery{Resource: "arm2"}), test.ShouldBeEmpty)
+
+	// queries are only recorded when canceled, and their requests are not summarized
+	query := &pb.GetEndPositionRequest{Name: "arm1"}
+	info = &grpc.UnaryServerInfo{FullMethod: "/viam.component.arm.v1.ArmService/GetEndPosition"}
+	_, err = m.UnaryServerInterceptor(ctx, query, info, func(ctx context.Context, req any) (any, error) {
+		Get(ctx).Cancel()
+		return nil, ctx.Err()
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+	records = m.History(HistoryQuery{Resource: "rdk:component:arm/arm1"})
+	test.That(t, records, test.ShouldHaveLength, 2)
+	test.That(t, records[1].Method, test.ShouldEqual, info.FullMethod)
+	test.That(t, records[1].Arguments, test.ShouldBeEmpty)
+}
+
+func TestPersistHistory(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	path := filepath.Join(t.TempDir(), "operations.jsonl")
+
+	m := NewManager(logger)
+	test.That(t, m.PersistHistory(path), test.ShouldBeNil)
+	_, cleanup := m.Create(ctx, "Stop", nil)
+	cleanup()
+	test.That(t, m.Close(), test.ShouldBeNil)
+
+	// a line cut short by a crash is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = f.WriteString(`{"id":"`)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+
+	m = NewManager(logger)
+	test.That(t, m.PersistHistory(path), test.ShouldBeNil)
+	defer func() {
+		test.That(t, m.Close(), test.ShouldBeNil)
+	}()
+	records := m.History(HistoryQuery{})
+	test.That(t, records, test.ShouldHaveLength, 1)
+	test.That(t, records[0].Method, test.ShouldEqual, "Stop")
+
+	_, cleanup = m.Create(ctx, "SetPower", nil)
+	cleanup()
+	test.That(t, m.Close(), test.ShouldBeNil)
+	m = NewManager(logger)
+	test.That(t, m.PersistHistory(path), test.ShouldBeNil)
+	records = m.History(HistoryQuery{})
+	test.That(t, records, test.ShouldHaveLength, 2)
+	test.That(t, records[1].Method, test.ShouldEqual, "SetPower")
+}
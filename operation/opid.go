@@ -3,11 +3,13 @@ package operation
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.viam.com/utils/rpc"
 
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/session"
@@ -23,10 +25,36 @@ var methodPrefixesToFilter = [...]string{
 	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
 }
 
+// queryMethodPrefixes are the prefixes of the names of methods that only read the state of a
+// robot. Clients poll them many times a second, so they are left out of the operation history
+// unless they are canceled, lest they crowd out the operations that command the robot.
+var queryMethodPrefixes = [...]string{"Get", "Is", "List", "Read", "Stream"}
+
+// housekeepingMethods are the methods clients and modules call to keep their connection to a robot
+// going rather than to use it. They are sent several times a second and are never recorded in the
+// operation history.
+var housekeepingMethods = map[string]bool{
+	"/viam.robot.v1.RobotService/SendSessionHeartbeat": true,
+	"/viam.robot.v1.RobotService/Log":                  true,
+	"/viam.robot.v1.RobotService/ResourceNames":        true,
+}
+
+func isQueryMethod(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range queryMethodPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Operation is an operation happening on the server.
 type Operation struct {
 	ID        uuid.UUID
 	SessionID uuid.UUID
+	// Entity is the authenticated entity of the client that started the operation, if any.
+	Entity    string
 	Method    string
 	Arguments interface{}
 	Started   time.Time
@@ -35,10 +63,29 @@ type Operation struct {
 	cancel     context.CancelFunc
 	labelsLock sync.Mutex
 	labels     []string
+
+	// recordLock guards what is only known about the operation once it runs, and is kept in its
+	// Record.
+	recordLock  sync.Mutex
+	request     string
+	resource    string
+	err         error
+	cancelCause string
 }
 
 // Cancel cancel the context associated with an operation.
 func (o *Operation) Cancel() {
+	o.CancelWithCause("canceled")
+}
+
+// CancelWithCause cancels the context associated with an operation and records why in the
+// history of its manager. Only the first cause of an operation is kept.
+func (o *Operation) CancelWithCause(cause string) {
+	o.recordLock.Lock()
+	if o.cancelCause == "" {
+		o.cancelCause = cause
+	}
+	o.recordLock.Unlock()
 	o.cancel()
 }
 
@@ -57,12 +104,13 @@ func (o *Operation) HasLabel(label string) bool {
 // CancelOtherWithLabel will cancel all operations besides this one with this label.
 func (o *Operation) CancelOtherWithLabel(label string) {
 	all := o.myManager.All()
+	cause := fmt.Sprintf("superseded on %q by operation %s (%s)", label, o.ID, o.Method)
 	for _, op := range all {
 		if op == nil || op == o {
 			continue
 		}
 		if op.HasLabel(label) {
-			op.Cancel()
+			op.CancelWithCause(cause)
 		}
 	}
 
@@ -71,21 +119,94 @@ func (o *Operation) CancelOtherWithLabel(label string) {
 	o.labels = append(o.labels, label)
 }
 
+// describeRequest keeps a summary of the request of the operation for its record.
+// Queries are only recorded when they are canceled and housekeeping is never recorded, so their
+// requests are not summarized.
+func (o *Operation) describeRequest(req interface{}) {
+	var request string
+	if !isQueryMethod(o.Method) && !housekeepingMethods[o.Method] {
+		request = summarizeArguments(req)
+	}
+	o.recordLock.Lock()
+	defer o.recordLock.Unlock()
+	o.request = request
+	o.resource = requestResource(req)
+}
+
+// setResult keeps the error the operation ended with for its record.
+func (o *Operation) setResult(err error) {
+	o.recordLock.Lock()
+	defer o.recordLock.Unlock()
+	o.err = err
+}
+
+// record describes the operation as it ended.
+func (o *Operation) record(ended time.Time) Record {
+	o.recordLock.Lock()
+	defer o.recordLock.Unlock()
+	r := Record{
+		ID:          o.ID.String(),
+		Entity:      o.Entity,
+		Method:      o.Method,
+		Resource:    o.resource,
+		Arguments:   o.request,
+		Started:     o.Started,
+		Ended:       ended,
+		CancelCause: o.cancelCause,
+	}
+	if o.SessionID != uuid.Nil {
+		r.SessionID = o.SessionID.String()
+	}
+	if o.err != nil {
+		r.Error = o.err.Error()
+	}
+	return r
+}
+
 func (o *Operation) cleanup() {
 	o.myManager.remove(o.ID)
+	if housekeepingMethods[o.Method] {
+		return
+	}
+	r := o.record(time.Now())
+	if isQueryMethod(r.Method) && r.CancelCause == "" {
+		return
+	}
+	if err := o.myManager.history.add(r); err != nil {
+		o.myManager.logger.Warnw("failed to persist operation history", "error", err)
+	}
 }
 
-// NewManager creates a new manager for holding Operations.
+// NewManager creates a new manager for holding Operations. It remembers the last
+// DefaultHistorySize operations that completed.
 func NewManager(logger logging.Logger) *Manager {
 	opLogger := logger.Sublogger("operation_manager")
-	return &Manager{ops: map[string]*Operation{}, logger: opLogger}
+	return &Manager{ops: map[string]*Operation{}, history: newHistory(DefaultHistorySize), logger: opLogger}
 }
 
 // Manager holds Operations.
 type Manager struct {
-	ops    map[string]*Operation
-	lock   sync.Mutex
-	logger logging.Logger
+	ops     map[string]*Operation
+	lock    sync.Mutex
+	history *history
+	logger  logging.Logger
+}
+
+// History returns the completed operations matching a query, oldest first.
+func (m *Manager) History(query HistoryQuery) []Record {
+	return m.history.query(query)
+}
+
+// PersistHistory loads the operation history kept in the file at path, and appends every
+// operation that completes from now on to it as a line of JSON. Once the file grows past 10MiB,
+// it is moved to "<path>.1" and started over.
+func (m *Manager) PersistHistory(path string) error {
+	return m.history.open(path)
+}
+
+// Close stops persisting the operation history.
+func (m *Manager) Close() error {
+	return m.history.close()
 }
 
 func (m *Manager) remove(id uuid.UUID) {
@@ -131,13 +252,20 @@ func (m *Manager) Create(ctx context.Context, method string, args interface{}) (
 }
 
 func (m *Manager) createWithID(ctx context.Context, id uuid.UUID, method string, args interface{}) (context.Context, func()) {
+	ctx, _, cleanup := m.create(ctx, id, method, args)
+	return ctx, cleanup
+}
+
+// create is createWithID but also returns the operation it created, or nil if it did not create
+// one.
+func (m *Manager) create(ctx context.Context, id uuid.UUID, method string, args interface{}) (context.Context, *Operation, func()) {
 	if ctx.Value(opidKey) != nil {
 		panic("operations cannot be nested")
 	}
 
 	for _, val := range methodPrefixesToFilter {
 		if strings.HasPrefix(method, val) {
-			return ctx, func() {}
+			return ctx, nil, func() {}
 		}
 	}
 
@@ -158,7 +286,7 @@ func (m *Manager) createWithID(ctx context.Context, id uuid.UUID, method string,
 			method,
 		)
 		ctx = context.WithValue(ctx, opidKey, o)
-		return ctx, func() {}
+		return ctx, nil, func() {}
 	}
 
 	op := &Operation{
@@ -171,11 +299,14 @@ func (m *Manager) createWithID(ctx context.Context, id uuid.UUID, method string,
 	if sess, ok := session.FromContext(ctx); ok {
 		op.SessionID = sess.ID()
 	}
+	if authEntity, ok := rpc.ContextAuthEntity(ctx); ok {
+		op.Entity = authEntity.Entity
+	}
 	ctx = context.WithValue(ctx, opidKey, op)
 	ctx, op.cancel = context.WithCancel(ctx)
 	m.add(op)
 
-	return ctx, func() { op.cleanup() }
+	return ctx, op, func() { op.cleanup() }
 }
 
 // Get returns the current Operation. This can be nil.
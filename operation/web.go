@@ -53,7 +53,7 @@ func (m *Manager) UnaryServerInterceptor(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
-	ctx, done := m.CreateFromIncomingContext(ctx, info.FullMethod)
+	ctx, created, done := m.createFromIncomingContext(ctx, info.FullMethod)
 	defer done()
 	if op := Get(ctx); op != nil && op.ID.String() != "" {
 		// SetHeader will occasionally error because of a data race if the request has been cancelled from client side.
@@ -67,7 +67,13 @@ func (m *Manager) UnaryServerInterceptor(
 			m.logger.CDebugw(ctx, "error while setting header", "err", err)
 		}
 	}
-	return handler(ctx, req)
+	if created == nil {
+		return handler(ctx, req)
+	}
+	created.describeRequest(req)
+	resp, err := handler(ctx, req)
+	created.setResult(err)
+	return resp, err
 }
 
 // StreamServerInterceptor creates a new operation in the current context before passing
@@ -79,27 +85,38 @@ func (m *Manager) StreamServerInterceptor(
 	info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler,
 ) error {
-	ctx, done := m.CreateFromIncomingContext(ss.Context(), info.FullMethod)
+	ctx, created, done := m.createFromIncomingContext(ss.Context(), info.FullMethod)
 	defer done()
 	if op := Get(ctx); op != nil && op.ID.String() != "" {
 		utils.UncheckedError(ss.SetHeader(metadata.MD{opidMetadataKey: []string{op.ID.String()}}))
 	}
-	return handler(srv, &ssStreamContextWrapper{ss, ctx})
+	err := handler(srv, &ssStreamContextWrapper{ss, ctx})
+	if created != nil {
+		created.setResult(err)
+	}
+	return err
 }
 
 // CreateFromIncomingContext creates a new operation from an incoming context.
 func (m *Manager) CreateFromIncomingContext(ctx context.Context, method string) (context.Context, func()) {
+	ctx, _, done := m.createFromIncomingContext(ctx, method)
+	return ctx, done
+}
+
+// createFromIncomingContext is CreateFromIncomingContext but also returns the operation it
+// created, which is nil if the incoming context belongs to an operation already running.
+func (m *Manager) createFromIncomingContext(ctx context.Context, method string) (context.Context, *Operation, func()) {
 	meta, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		m.logger.CWarnw(ctx, "failed to pull metadata from context", "method", method)
-		return m.Create(ctx, method, nil)
+		return m.create(ctx, uuid.New(), method, nil)
 	}
 	opid, err := GetOrCreateFromMetadata(meta)
 	if err != nil {
 		m.logger.CWarnw(ctx, "failed to create operation id from metadata", "error", err)
-		return m.Create(ctx, method, nil)
+		return m.create(ctx, uuid.New(), method, nil)
 	}
-	return m.createWithID(ctx, opid, method, nil)
+	return m.create(ctx, opid, method, nil)
 }
 
 // GetOrCreateFromMetadata returns an operation id from metadata, or generates a random
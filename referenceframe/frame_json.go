@@ -56,6 +56,10 @@ type JointConfig struct {
 	Min      float64                 `json:"min"`                // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"` // only valid for prismatic/translational joints
 	Mimic    *MimicConfig            `json:"mimic,omitempty"`
+	// MaxVelocity and MaxAcceleration are how fast the joint can move and speed up, in mm or degs
+	// per second and per second squared. Zero means unknown.
+	MaxVelocity     float64 `json:"max_velocity,omitempty"`
+	MaxAcceleration float64 `json:"max_acceleration,omitempty"`
 }
 
 // DHParamConfig is a revolute and static frame combined in a set of Denavit Hartenberg parameters.
@@ -68,6 +72,10 @@ type DHParamConfig struct {
 	Max      float64                 `json:"max"` // in mm or degs
 	Min      float64                 `json:"min"` // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"`
+	// MaxVelocity and MaxAcceleration are how fast the joint can move and speed up, in degs per
+	// second and per second squared. Zero means unknown.
+	MaxVelocity     float64 `json:"max_velocity,omitempty"`
+	MaxAcceleration float64 `json:"max_acceleration,omitempty"`
 }
 
 // NewLinkConfig constructs a config from a Frame.
@@ -15,6 +15,7 @@ import (
 	"gonum.org/v1/gonum/num/quat"
 
 	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
 )
 
 // A Model represents a frame that can change its name, and can return itself as a ModelConfig struct.
@@ -375,6 +376,39 @@ func (m *SimpleModel) ModelConfig() *ModelConfigJSON {
 	return m.modelConfig
 }
 
+// JointLimits returns how fast each DoF of the model can move and speed up, in radians or mm per
+// second and per second squared, as given by the joints of its config. Limits the config does not
+// give are zero.
+func (m *SimpleModel) JointLimits() (velocities, accelerations []float64) {
+	velocities = make([]float64, len(m.DoF()))
+	accelerations = make([]float64, len(m.DoF()))
+	names := m.MoveableFrameNames()
+	if m.modelConfig == nil || len(names) != len(velocities) {
+		return velocities, accelerations
+	}
+
+	type limits struct {
+		velocity, acceleration float64
+		prismatic              bool
+	}
+	byName := make(map[string]limits)
+	for _, joint := range m.modelConfig.Joints {
+		byName[joint.ID] = limits{joint.MaxVelocity, joint.MaxAcceleration, joint.Type == PrismaticJoint}
+	}
+	for _, dh := range m.modelConfig.DHParams {
+		byName[dh.ID+"_j"] = limits{velocity: dh.MaxVelocity, acceleration: dh.MaxAcceleration}
+	}
+	for i, name := range names {
+		joint := byName[name]
+		if joint.prismatic {
+			velocities[i], accelerations[i] = joint.velocity, joint.acceleration
+		} else {
+			velocities[i], accelerations[i] = utils.DegToRad(joint.velocity), utils.DegToRad(joint.acceleration)
+		}
+	}
+	return velocities, accelerations
+}
+
 // Hash returns a hash value for this simple model.
 func (m *SimpleModel) Hash() int {
 	h := m.hash()
@@ -179,6 +179,13 @@ func UnmarshalModelXML(
 			default:
 				return nil, err
 			}
+			if jointElem.Limit != nil {
+				if jointElem.Type == PrismaticJoint {
+					thisJoint.MaxVelocity = utils.MetersToMM(jointElem.Limit.Velocity)
+				} else {
+					thisJoint.MaxVelocity = utils.RadToDeg(jointElem.Limit.Velocity)
+				}
+			}
 			if jointElem.Mimic != nil {
 				thisJoint.Mimic = &MimicConfig{
 					Joint:           jointElem.Mimic.Joint,
@@ -113,6 +113,10 @@ func TestParseURDFFile(t *testing.T) {
 	modelGeo, err := model.Geometries(make([]Input, len(model.DoF())))
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, len(modelGeo.Geometries()), test.ShouldEqual, 5) // notably we only have 5 geometries for this model
+	// velocity limits are read in degrees per second
+	for _, joint := range model.ModelConfig().Joints {
+		test.That(t, joint.MaxVelocity, test.ShouldAlmostEqual, 180, 1e-3)
+	}
 
 	// Test naming of a URDF to something other than the robot's name element
 	u, err = ParseModelXMLFile(utils.ResolveFile("referenceframe/testfiles/ur5e.urdf"), "foo", nil)
@@ -0,0 +1,139 @@
+package resource
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// NodeInspection describes a node of the resource graph, for finding out why a resource is not
+// ready and what is waiting on it. Resource names are in their string form.
+type NodeInspection struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// Error is the last error of the node. It is only set while the node is unhealthy.
+	Error    string `json:"error,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	// TransitionedAt is when the node entered its current state.
+	TransitionedAt time.Time `json:"transitioned_at"`
+	// LastReconfigured is when the resource was constructed or last reconfigured. It is nil if the
+	// resource was never configured.
+	LastReconfigured *time.Time `json:"last_reconfigured,omitempty"`
+	// UpdatedAt is the value of the graph's logical clock when the resource last changed.
+	UpdatedAt    int64    `json:"updated_at"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Dependents   []string `json:"dependents,omitempty"`
+	// UnresolvedDependencies are dependencies in the resource's config which do not match any
+	// resource of the graph yet.
+	UnresolvedDependencies []string `json:"unresolved_dependencies,omitempty"`
+}
+
+// GraphInspection is the state of every node of a resource graph.
+type GraphInspection struct {
+	LogicalClock int64            `json:"logical_clock"`
+	Nodes        []NodeInspection `json:"nodes"`
+}
+
+// FailureChain explains why a resource is not ready, and what is blocked on it.
+type FailureChain struct {
+	Resource NodeInspection `json:"resource"`
+	// Causes are the transitive dependencies of the resource which are not ready, nearest first.
+	// The last of them are the root causes.
+	Causes []NodeInspection `json:"causes,omitempty"`
+	// Blocked are the transitive dependents of the resource which are not ready, nearest first.
+	Blocked []NodeInspection `json:"blocked,omitempty"`
+}
+
+// Inspect returns the state of every node of the graph, sorted by name.
+func (g *Graph) Inspect() GraphInspection {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	inspection := GraphInspection{LogicalClock: g.logicalClock.Load()}
+	for _, nn := range nodesSortedByName(g.nodes) {
+		inspection.Nodes = append(inspection.Nodes, g.inspectNode(nn.Name, nn.Node))
+	}
+	return inspection
+}
+
+// InspectFailureChain returns the nodes of the graph which keep the named resource from being
+// ready, and those which are not ready because of it.
+func (g *Graph) InspectFailureChain(name Name) (FailureChain, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	node, ok := g.nodes.Get(name)
+	if !ok {
+		return FailureChain{}, NewNotFoundError(name)
+	}
+	return FailureChain{
+		Resource: g.inspectNode(name, node),
+		Causes:   g.inspectNotReady(name, g.children),
+		Blocked:  g.inspectNotReady(name, g.parents),
+	}, nil
+}
+
+// inspectNotReady walks the graph breadth first from the named node along the given edges, and
+// inspects the nodes it reaches which are not ready.
+func (g *Graph) inspectNotReady(name Name, edges resourceDependencies) []NodeInspection {
+	var notReady []NodeInspection
+	visited := map[Name]bool{name: true}
+	queue := []Name{name}
+	for len(queue) > 0 {
+		next := sortedNames(edges[queue[0]])
+		queue = queue[1:]
+		for _, neighbor := range next {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			queue = append(queue, neighbor)
+			node, ok := g.nodes.Get(neighbor)
+			if !ok {
+				continue
+			}
+			if inspection := g.inspectNode(neighbor, node); inspection.State != NodeStateReady.String() {
+				notReady = append(notReady, inspection)
+			}
+		}
+	}
+	return notReady
+}
+
+func (g *Graph) inspectNode(name Name, node *GraphNode) NodeInspection {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+
+	inspection := NodeInspection{
+		Name:                   name.String(),
+		State:                  node.state.String(),
+		Revision:               node.revision,
+		TransitionedAt:         node.transitionedAt,
+		LastReconfigured:       node.lastReconfigured,
+		UpdatedAt:              node.updatedAt,
+		Dependencies:           NamesToStrings(sortedNames(g.children[name])),
+		Dependents:             NamesToStrings(sortedNames(g.parents[name])),
+		UnresolvedDependencies: slices.Clone(node.unresolvedDependencies),
+	}
+	if node.state == NodeStateUnhealthy && node.lastErr != nil {
+		inspection.Error = node.lastErr.Error()
+	}
+	if node.currentModel != (Model{}) {
+		inspection.Model = node.currentModel.String()
+	} else if node.config.Model != (Model{}) {
+		inspection.Model = node.config.Model.String()
+	}
+	return inspection
+}
+
+func sortedNames(nodes graphNodes) []Name {
+	names := make([]Name, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b Name) int {
+		return cmp.Compare(a.String(), b.String())
+	})
+	return names
+}
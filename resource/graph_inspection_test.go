@@ -0,0 +1,80 @@
+package resource
+
+import (
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestGraphInspection(t *testing.T) {
+	g := NewGraph(logging.NewTestLogger(t))
+	model := DefaultModelFamily.WithModel("fake")
+
+	// board is broken, so motor and, through it, arm cannot be configured. camera is unrelated.
+	board := NewName(apiA, "board")
+	boardNode := NewConfiguredGraphNode(Config{Name: "board", Model: model}, &someResource{Named: board.AsNamed()}, model)
+	test.That(t, g.AddNode(board, boardNode), test.ShouldBeNil)
+	boardNode.LogAndSetLastError(errors.New("i2c bus not found"))
+
+	motor := NewName(apiA, "motor")
+	test.That(t, g.AddNode(motor, NewUnconfiguredGraphNode(Config{Name: "motor", Model: model}, []string{"board"})), test.ShouldBeNil)
+	test.That(t, g.AddChild(board, motor), test.ShouldBeNil)
+
+	arm := NewName(apiA, "arm")
+	armNode := NewUnconfiguredGraphNode(Config{Name: "arm", Model: model}, []string{"motor", "gripper"})
+	armNode.setUnresolvedDependencies("gripper")
+	test.That(t, g.AddNode(arm, armNode), test.ShouldBeNil)
+	test.That(t, g.AddChild(motor, arm), test.ShouldBeNil)
+
+	camera := NewName(apiA, "camera")
+	test.That(t, g.AddNode(camera, NewConfiguredGraphNode(Config{Name: "camera"}, &someResource{Named: camera.AsNamed()}, model)),
+		test.ShouldBeNil)
+
+	inspection := g.Inspect()
+	test.That(t, inspection.LogicalClock, test.ShouldEqual, g.CurrLogicalClockValue())
+	test.That(t, inspection.Nodes, test.ShouldHaveLength, 4)
+	names := make([]string, 0, len(inspection.Nodes))
+	for _, node := range inspection.Nodes {
+		names = append(names, node.Name)
+	}
+	test.That(t, names, test.ShouldResemble, []string{arm.String(), board.String(), camera.String(), motor.String()})
+
+	boardInspection := inspection.Nodes[1]
+	test.That(t, boardInspection.State, test.ShouldEqual, NodeStateUnhealthy.String())
+	test.That(t, boardInspection.Error, test.ShouldEqual, "i2c bus not found")
+	test.That(t, boardInspection.Model, test.ShouldEqual, model.String())
+	test.That(t, boardInspection.LastReconfigured, test.ShouldNotBeNil)
+	test.That(t, boardInspection.Dependents, test.ShouldResemble, []string{motor.String()})
+	test.That(t, inspection.Nodes[0].UnresolvedDependencies, test.ShouldResemble, []string{"gripper"})
+	test.That(t, inspection.Nodes[2].State, test.ShouldEqual, NodeStateReady.String())
+	test.That(t, inspection.Nodes[2].Error, test.ShouldBeEmpty)
+
+	chain, err := g.InspectFailureChain(motor)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chain.Resource.Name, test.ShouldEqual, motor.String())
+	test.That(t, chain.Resource.State, test.ShouldEqual, NodeStateConfiguring.String())
+	test.That(t, chain.Resource.Dependencies, test.ShouldResemble, []string{board.String()})
+	test.That(t, chain.Causes, test.ShouldHaveLength, 1)
+	test.That(t, chain.Causes[0].Error, test.ShouldEqual, "i2c bus not found")
+	test.That(t, chain.Blocked, test.ShouldHaveLength, 1)
+	test.That(t, chain.Blocked[0].Name, test.ShouldEqual, arm.String())
+
+	// causes are ordered from the resource to the root cause
+	chain, err = g.InspectFailureChain(arm)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chain.Causes, test.ShouldHaveLength, 2)
+	test.That(t, chain.Causes[0].Name, test.ShouldEqual, motor.String())
+	test.That(t, chain.Causes[1].Name, test.ShouldEqual, board.String())
+	test.That(t, chain.Blocked, test.ShouldBeEmpty)
+
+	chain, err = g.InspectFailureChain(camera)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chain.Causes, test.ShouldBeEmpty)
+	test.That(t, chain.Blocked, test.ShouldBeEmpty)
+
+	_, err = g.InspectFailureChain(NewName(apiA, "missing"))
+	test.That(t, IsNotFoundError(err), test.ShouldBeTrue)
+}
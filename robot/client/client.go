@@ -3,9 +3,11 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,6 +15,7 @@ import (
 	"time"
 
 	"github.com/fullstorydev/grpcurl"
+	"github.com/google/uuid"
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/grpcreflect"
@@ -1264,6 +1267,22 @@ func (rc *RobotClient) MachineStatus(ctx context.Context) (robot.MachineStatus,
 			CloudMetadata: rprotoutils.MetadataFromProto(pbResStatus.CloudMetadata),
 		}
 
+		if resStatus.Name.API == robot.ModuleStatusAPI {
+			if mStatus.ModuleStatuses == nil {
+				mStatus.ModuleStatuses = make(map[string]robot.ModuleStatus)
+			}
+			modStatus := robot.ModuleStatus{State: robot.ModuleStateHealthy}
+			if pbResStatus.State != pb.ResourceStatus_STATE_READY {
+				state, reasons, _ := strings.Cut(pbResStatus.Error, ": ")
+				modStatus.State = state
+				if reasons != "" {
+					modStatus.Reasons = strings.Split(reasons, "; ")
+				}
+			}
+			mStatus.ModuleStatuses[resStatus.Name.Name] = modStatus
+			continue
+		}
+
 		switch pbResStatus.State {
 		case pb.ResourceStatus_STATE_UNSPECIFIED:
 			rc.logger.CErrorw(ctx, "received resource in an unspecified state", "resource", resStatus.Name.String())
@@ -1300,8 +1319,27 @@ func (rc *RobotClient) MachineStatus(ctx context.Context) (robot.MachineStatus,
 		tspbToTime := func(tspb *timestamppb.Timestamp, _ int) time.Time {
 			return tspb.AsTime()
 		}
-		mStatus.JobStatuses = make(map[string]robot.JobStatus, len(resp.GetJobStatuses()))
 		for _, js := range resp.GetJobStatuses() {
+			if name, err := resource.NewFromString(js.GetJobName()); err == nil && name.API == robot.ModuleStatusAPI {
+				modStatus := mStatus.ModuleStatuses[name.Name]
+				for _, tspb := range js.GetRecentSuccessfulRuns() {
+					modStatus.RecentRestarts = append(modStatus.RecentRestarts, robot.ModuleRestart{Time: tspb.AsTime(), Succeeded: true})
+				}
+				for _, tspb := range js.GetRecentFailedRuns() {
+					modStatus.RecentRestarts = append(modStatus.RecentRestarts, robot.ModuleRestart{Time: tspb.AsTime()})
+				}
+				sort.Slice(modStatus.RecentRestarts, func(i, j int) bool {
+					return modStatus.RecentRestarts[i].Time.Before(modStatus.RecentRestarts[j].Time)
+				})
+				if mStatus.ModuleStatuses == nil {
+					mStatus.ModuleStatuses = make(map[string]robot.ModuleStatus)
+				}
+				mStatus.ModuleStatuses[name.Name] = modStatus
+				continue
+			}
+			if mStatus.JobStatuses == nil {
+				mStatus.JobStatuses = make(map[string]robot.JobStatus, len(resp.GetJobStatuses()))
+			}
 			mStatus.JobStatuses[js.GetJobName()] = robot.JobStatus{
 				RecentSuccessfulRuns: lo.Map(js.GetRecentSuccessfulRuns(), tspbToTime),
 				RecentFailedRuns:     lo.Map(js.GetRecentFailedRuns(), tspbToTime),
@@ -1312,6 +1350,167 @@ func (rc *RobotClient) MachineStatus(ctx context.Context) (robot.MachineStatus,
 	return mStatus, nil
 }
 
+const resourceGraphFeature = "resource graph inspection"
+
+// InspectResourceGraph returns the state of every node of the machine's resource graph.
+func (rc *RobotClient) InspectResourceGraph(ctx context.Context) (resource.GraphInspection, error) {
+	var inspection resource.GraphInspection
+	err := rc.invokeStructService(ctx, robot.ResourceGraphServiceName, "GetResourceGraph", resourceGraphFeature, &structpb.Struct{}, &inspection)
+	return inspection, err
+}
+
+// ResourceFailureChain returns what keeps a resource of the machine from being ready, and what is
+// blocked on it. The name is either a full resource name or a name matching a single resource.
+func (rc *RobotClient) ResourceFailureChain(ctx context.Context, name string) (resource.FailureChain, error) {
+	var chain resource.FailureChain
+	req, err := structpb.NewStruct(map[string]any{"name": name})
+	if err != nil {
+		return chain, err
+	}
+	err = rc.invokeStructService(ctx, robot.ResourceGraphServiceName, "GetFailureChain", resourceGraphFeature, req, &chain)
+	return chain, err
+}
+
+// invokeStructService calls a method of a service whose requests and responses are
+// google.protobuf.Struct, and decodes its JSON response into resp. feature describes the service
+// to users of machines which do not serve it.
+func (rc *RobotClient) invokeStructService(
+	ctx context.Context,
+	service, method, feature string,
+	req *structpb.Struct,
+	resp any,
+) error {
+	var out structpb.Struct
+	if err := rc.conn.Invoke(ctx, fmt.Sprintf("/%s/%s", service, method), req, &out); err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return fmt.Errorf("the machine does not support %s, it may need a newer viam-server", feature)
+		}
+		return err
+	}
+	if resp == nil {
+		return nil
+	}
+	encoded, err := out.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, resp)
+}
+
+// AcquireLease leases a resource to the session of this client, so that other clients cannot use
+// its safety monitored methods until the lease is released, is not renewed within ttl, or the
+// session ends. A ttl of zero leases the resource for as long as the session lasts. The holder
+// identifies this client to the clients the lease blocks, who get a *session.ResourceLeasedError;
+// it defaults to the entity the client authenticated as. Acquiring a lease this client already
+// holds renews it.
+func (rc *RobotClient) AcquireLease(
+	ctx context.Context,
+	name resource.Name,
+	holder string,
+	ttl time.Duration,
+) (session.LeaseInfo, error) {
+	var lease session.LeaseInfo
+	req, err := structpb.NewStruct(map[string]any{"name": name.String(), "holder": holder, "ttl_seconds": ttl.Seconds()})
+	if err != nil {
+		return lease, err
+	}
+	err = rc.invokeLeaseService(ctx, "AcquireLease", req, &lease)
+	return lease, err
+}
+
+// RenewLease extends a lease held by this client by ttl from now.
+func (rc *RobotClient) RenewLease(ctx context.Context, name resource.Name, ttl time.Duration) (session.LeaseInfo, error) {
+	var lease session.LeaseInfo
+	req, err := structpb.NewStruct(map[string]any{"name": name.String(), "ttl_seconds": ttl.Seconds()})
+	if err != nil {
+		return lease, err
+	}
+	err = rc.invokeLeaseService(ctx, "RenewLease", req, &lease)
+	return lease, err
+}
+
+// ReleaseLease ends a lease held by this client.
+func (rc *RobotClient) ReleaseLease(ctx context.Context, name resource.Name) error {
+	req, err := structpb.NewStruct(map[string]any{"name": name.String()})
+	if err != nil {
+		return err
+	}
+	return rc.invokeLeaseService(ctx, "ReleaseLease", req, nil)
+}
+
+// Leases returns every active lease on the machine's resources.
+func (rc *RobotClient) Leases(ctx context.Context) ([]session.LeaseInfo, error) {
+	var resp struct {
+		Leases []session.LeaseInfo `json:"leases"`
+	}
+	err := rc.invokeLeaseService(ctx, "ListLeases", &structpb.Struct{}, &resp)
+	return resp.Leases, err
+}
+
+func (rc *RobotClient) invokeLeaseService(ctx context.Context, method string, req *structpb.Struct, resp any) error {
+	return rc.invokeStructService(ctx, robot.LeaseServiceName, method, "resource leases", req, resp)
+}
+
+// OperationHistory returns the operations the machine completed that match the query, oldest
+// first.
+func (rc *RobotClient) OperationHistory(ctx context.Context, query operation.HistoryQuery) ([]operation.Record, error) {
+	fields := map[string]any{}
+	if query.Method != "" {
+		fields["method"] = query.Method
+	}
+	if query.Resource != "" {
+		fields["resource"] = query.Resource
+	}
+	if query.SessionID != uuid.Nil {
+		fields["session_id"] = query.SessionID.String()
+	}
+	if !query.Since.IsZero() {
+		fields["since"] = query.Since.Format(time.RFC3339Nano)
+	}
+	if query.Limit > 0 {
+		fields["limit"] = query.Limit
+	}
+	req, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Operations []operation.Record `json:"operations"`
+	}
+	err = rc.invokeStructService(ctx, robot.OperationHistoryServiceName, "GetOperationHistory", "operation history", req, &resp)
+	return resp.Operations, err
+}
+
+// QueryLogs returns the log entries matching the query that the machine stored on its disk, oldest
+// first. Unlike the logs uploaded to the cloud, these can be read while the machine is offline.
+func (rc *RobotClient) QueryLogs(ctx context.Context, query logging.LogQuery) ([]logging.StoredLogEntry, error) {
+	fields := map[string]any{"min_level": query.MinLevel.String()}
+	if query.Logger != "" {
+		fields["logger"] = query.Logger
+	}
+	if !query.Since.IsZero() {
+		fields["since"] = query.Since.Format(time.RFC3339Nano)
+	}
+	if !query.Until.IsZero() {
+		fields["until"] = query.Until.Format(time.RFC3339Nano)
+	}
+	if query.Contains != "" {
+		fields["contains"] = query.Contains
+	}
+	if query.Limit > 0 {
+		fields["limit"] = query.Limit
+	}
+	req, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Entries []logging.StoredLogEntry `json:"entries"`
+	}
+	err = rc.invokeStructService(ctx, robot.LogServiceName, "QueryLogs", "stored logs", req, &resp)
+	return resp.Entries, err
+}
+
 // Version returns version information about the machine.
 func (rc *RobotClient) Version(ctx context.Context) (robot.VersionResponse, error) {
 	mVersion := robot.VersionResponse{}
@@ -154,7 +154,8 @@ func (rc *RobotClient) safetyMonitorFromHeaders(ctx context.Context, hdr metadat
 }
 
 func (rc *RobotClient) useSessionInRequest(ctx context.Context, method string) bool {
-	return !rc.sessionsDisabled && ctx.Value(ctxKeyInSessionMDReq) == nil && robot.IsSafetyHeartbeatMonitored(method)
+	return !rc.sessionsDisabled && ctx.Value(ctxKeyInSessionMDReq) == nil &&
+		(robot.IsSafetyHeartbeatMonitored(method) || robot.IsLeaseServiceMethod(method))
 }
 
 func (rc *RobotClient) sessionUnaryClientInterceptor(
@@ -176,7 +177,11 @@ func (rc *RobotClient) sessionUnaryClientInterceptor(
 		if err != nil {
 			return err
 		}
-		return invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&hdr))...)
+		err = invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&hdr))...)
+		if leasedErr, ok := session.ResourceLeasedErrorFromStatus(err); ok {
+			return leasedErr
+		}
+		return err
 	}
 	if !rc.useSessionInRequest(ctx, method) {
 		// we won't retry but we will pass along any metadata we get from the remote to our parent(s).
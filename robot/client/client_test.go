@@ -3,6 +3,7 @@ package client
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
@@ -63,6 +64,7 @@ import (
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/robot/framesystem"
 	"go.viam.com/rdk/robot/server"
+	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/testutils"
 	"go.viam.com/rdk/testutils/inject"
@@ -2242,6 +2244,32 @@ func TestMachineStatus(t *testing.T) {
 			},
 			0,
 		},
+		{
+			"module statuses",
+			robot.MachineStatus{
+				Config:    config.Revision{Revision: "rev1"},
+				Resources: []resource.Status{},
+				State:     robot.StateRunning,
+				JobStatuses: map[string]robot.JobStatus{
+					"job1": {
+						RecentSuccessfulRuns: []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+						RecentFailedRuns:     []time.Time{},
+					},
+				},
+				ModuleStatuses: map[string]robot.ModuleStatus{
+					"healthy-module": {State: robot.ModuleStateHealthy},
+					"failed-module": {
+						State:   "failed",
+						Reasons: []string{"module exited", "restart limit reached"},
+						RecentRestarts: []robot.ModuleRestart{
+							{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Succeeded: true},
+							{Time: time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)},
+						},
+					},
+				},
+			},
+			0,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			logger, logs := logging.NewObservedTestLogger(t)
@@ -2318,6 +2346,79 @@ func TestVersion(t *testing.T) {
 	test.That(t, md, test.ShouldResemble, version)
 }
 
+func TestInspectResourceGraph(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer := grpc.NewServer()
+
+	armName := arm.Named("arm1")
+	reconfigured := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	armNode := resource.NodeInspection{
+		Name:             armName.String(),
+		State:            resource.NodeStateUnhealthy.String(),
+		Error:            "cannot reach arm",
+		TransitionedAt:   reconfigured,
+		LastReconfigured: &reconfigured,
+		UpdatedAt:        2,
+	}
+	injectRobot := &inject.Robot{
+		ResourceNamesFunc:   func() []resource.Name { return nil },
+		ResourceRPCAPIsFunc: func() []resource.RPCAPI { return nil },
+		InspectResourceGraphFunc: func() resource.GraphInspection {
+			return resource.GraphInspection{LogicalClock: 2, Nodes: []resource.NodeInspection{armNode}}
+		},
+		ResourceFailureChainFunc: func(name resource.Name) (resource.FailureChain, error) {
+			return resource.FailureChain{Resource: armNode}, nil
+		},
+		MachineStatusFunc: func(ctx context.Context) (robot.MachineStatus, error) {
+			return robot.MachineStatus{State: robot.StateRunning}, nil
+		},
+	}
+
+	pb.RegisterRobotServiceServer(gServer, server.New(injectRobot))
+
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	client, err := New(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	// servers without the resource graph service are reported as such
+	_, err = client.InspectResourceGraph(context.Background())
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "newer viam-server")
+
+	gServer.Stop()
+	listener, err = net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer = grpc.NewServer()
+	pb.RegisterRobotServiceServer(gServer, server.New(injectRobot))
+	gServer.RegisterService(&server.ResourceGraphServiceDesc, server.NewResourceGraphServer(injectRobot))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	client2, err := New(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client2.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	inspection, err := client2.InspectResourceGraph(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, inspection.LogicalClock, test.ShouldEqual, 2)
+	test.That(t, inspection.Nodes, test.ShouldHaveLength, 1)
+	test.That(t, inspection.Nodes[0], test.ShouldResemble, armNode)
+
+	chain, err := client2.ResourceFailureChain(context.Background(), "arm1")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chain.Resource, test.ShouldResemble, armNode)
+	test.That(t, chain.Causes, test.ShouldBeEmpty)
+}
+
 func TestListTunnels(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 	listener, err := net.Listen("tcp", "localhost:0")
@@ -2363,3 +2464,96 @@ func TestListTunnels(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, ttes, test.ShouldResemble, expectedTTEs)
 }
+
+func TestOperationHistory(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer := grpc.NewServer()
+
+	injectRobot := &inject.Robot{
+		ResourceNamesFunc:   func() []resource.Name { return nil },
+		ResourceRPCAPIsFunc: func() []resource.RPCAPI { return nil },
+		MachineStatusFunc: func(ctx context.Context) (robot.MachineStatus, error) {
+			return robot.MachineStatus{State: robot.StateRunning}, nil
+		},
+		LoggerFunc: func() logging.Logger { return logger },
+	}
+	pb.RegisterRobotServiceServer(gServer, server.New(injectRobot))
+	gServer.RegisterService(&server.OperationHistoryServiceDesc, server.NewOperationHistoryServer(injectRobot))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	client, err := New(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	sess := session.New(context.Background(), "owner1", time.Minute, nil)
+	_, done := injectRobot.OperationManager().Create(
+		session.ToContext(context.Background(), sess), "/viam.component.arm.v1.ArmService/MoveToPosition", nil)
+	done()
+	_, done = injectRobot.OperationManager().Create(context.Background(), "/viam.component.arm.v1.ArmService/Stop", nil)
+	done()
+
+	records, err := client.OperationHistory(context.Background(), operation.HistoryQuery{SessionID: sess.ID()})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, records, test.ShouldHaveLength, 1)
+	test.That(t, records[0].Method, test.ShouldEqual, "/viam.component.arm.v1.ArmService/MoveToPosition")
+	test.That(t, records[0].SessionID, test.ShouldEqual, sess.ID().String())
+	test.That(t, records[0].Ended.IsZero(), test.ShouldBeFalse)
+
+	records, err = client.OperationHistory(context.Background(), operation.HistoryQuery{Limit: 1})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, records, test.ShouldHaveLength, 1)
+	test.That(t, records[0].Method, test.ShouldEqual, "/viam.component.arm.v1.ArmService/Stop")
+}
+
+func TestQueryLogs(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer := grpc.NewServer()
+
+	logged := time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)
+	stored := logging.StoredLogEntry{
+		Time:    logged,
+		Level:   logging.ERROR,
+		Logger:  "rdk.networking",
+		Message: "failed to reach app",
+		Fields:  json.RawMessage(`{"attempt":3}`),
+	}
+	var queried logging.LogQuery
+	injectRobot := &inject.Robot{
+		ResourceNamesFunc:   func() []resource.Name { return nil },
+		ResourceRPCAPIsFunc: func() []resource.RPCAPI { return nil },
+		MachineStatusFunc: func(ctx context.Context) (robot.MachineStatus, error) {
+			return robot.MachineStatus{State: robot.StateRunning}, nil
+		},
+		QueryLogsFunc: func(query logging.LogQuery) ([]logging.StoredLogEntry, error) {
+			queried = query
+			return []logging.StoredLogEntry{stored}, nil
+		},
+	}
+	pb.RegisterRobotServiceServer(gServer, server.New(injectRobot))
+	gServer.RegisterService(&server.LogServiceDesc, server.NewLogServer(injectRobot))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	client, err := New(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	query := logging.LogQuery{MinLevel: logging.DEBUG, Logger: "rdk.networking*", Since: logged.Add(-time.Hour), Limit: 5}
+	entries, err := client.QueryLogs(context.Background(), query)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, queried, test.ShouldResemble, query)
+	test.That(t, entries, test.ShouldHaveLength, 1)
+	test.That(t, entries[0].Time.Equal(logged), test.ShouldBeTrue)
+	test.That(t, entries[0].Level, test.ShouldEqual, logging.ERROR)
+	test.That(t, entries[0].Message, test.ShouldEqual, stored.Message)
+	test.That(t, string(entries[0].Fields), test.ShouldEqual, `{"attempt":3}`)
+}
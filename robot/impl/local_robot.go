@@ -114,6 +114,8 @@ type localRobot struct {
 	configTicker               *time.Ticker
 	revealSensitiveConfigDiffs bool
 	shutdownCallback           func()
+	// structuredLogFile is where the robot's logs are stored for QueryLogs, if anywhere.
+	structuredLogFile string
 
 	// lastWeakAndOptionalDependentsRound stores the value of the resource graph's
 	// logical clock when updateWeakAndOptionalDependents was called.
@@ -152,6 +154,24 @@ func (r *localRobot) ExportResourcesAsDot(index int) (resource.GetSnapshotInfo,
 	return r.manager.ExportDot(index)
 }
 
+// InspectResourceGraph returns the state of every node of the resource graph.
+func (r *localRobot) InspectResourceGraph() resource.GraphInspection {
+	return r.manager.resources.Inspect()
+}
+
+// ResourceFailureChain returns what keeps a resource from being ready, and what is blocked on it.
+func (r *localRobot) ResourceFailureChain(name resource.Name) (resource.FailureChain, error) {
+	return r.manager.resources.InspectFailureChain(name)
+}
+
+// QueryLogs returns the log entries matching the query that the robot stored on disk, oldest first.
+func (r *localRobot) QueryLogs(query logging.LogQuery) ([]logging.StoredLogEntry, error) {
+	if r.structuredLogFile == "" {
+		return nil, errLogsNotStored
+	}
+	return logging.QueryLogFiles(r.structuredLogFile, query)
+}
+
 // RemoteByName returns a remote robot by name. If it does not exist
 // nil is returned.
 func (r *localRobot) RemoteByName(name string) (robot.Robot, bool) {
@@ -266,6 +286,9 @@ func (r *localRobot) Close(ctx context.Context) error {
 	if r.ftdc != nil {
 		r.ftdc.StopAndJoin(ctx)
 	}
+	if r.operations != nil {
+		err = multierr.Combine(err, r.operations.Close())
+	}
 
 	err = multierr.Combine(err, trace.Shutdown(ctx))
 
@@ -282,7 +305,7 @@ func (r *localRobot) Kill() {
 func (r *localRobot) StopAll(ctx context.Context, extra map[resource.Name]map[string]interface{}) error {
 	// Stop all operations
 	for _, op := range r.OperationManager().All() {
-		op.Cancel()
+		op.CancelWithCause("canceled by StopAll")
 	}
 
 	// Stop all stoppable resources
@@ -466,6 +489,9 @@ func newWithResources(
 		if statser, err := sys.NewNetUsageStatser(); err == nil {
 			ftdcWorker.Add("net", statser)
 		}
+		if addr := os.Getenv(utils.ViamFTDCOpenMetricsAddrEnvVar); addr != "" {
+			ftdcWorker.EnableOpenMetrics(addr)
+		}
 	}
 
 	homeDir := utils.ViamDotDir
@@ -478,12 +504,13 @@ func newWithResources(
 		homeDir: homeDir,
 		manager: newResourceManager(
 			resourceManagerOptions{
-				debug:              cfg.Debug,
-				fromCommand:        cfg.FromCommand,
-				allowInsecureCreds: cfg.AllowInsecureCreds,
-				untrustedEnv:       cfg.UntrustedEnv,
-				tlsConfig:          cfg.Network.TLSConfig,
-				ftdc:               ftdcWorker,
+				debug:                    cfg.Debug,
+				fromCommand:              cfg.FromCommand,
+				allowInsecureCreds:       cfg.AllowInsecureCreds,
+				untrustedEnv:             cfg.UntrustedEnv,
+				tlsConfig:                cfg.Network.TLSConfig,
+				ftdc:                     ftdcWorker,
+				configurationConcurrency: rOpts.resourceConfigurationConcurrency,
 			},
 			logger,
 		),
@@ -498,6 +525,7 @@ func newWithResources(
 		revealSensitiveConfigDiffs: rOpts.revealSensitiveConfigDiffs,
 		cloudConnSvc:               icloud.NewCloudConnectionService(cfg.Cloud, conn, logger),
 		shutdownCallback:           rOpts.shutdownCallback,
+		structuredLogFile:          rOpts.structuredLogFile,
 		localModuleVersions:        make(map[string]semver.Version),
 		ftdc:                       ftdcWorker,
 	}
@@ -521,6 +549,17 @@ func newWithResources(
 		}
 	}()
 
+	historyFile := rOpts.operationHistoryFile
+	if historyFile == "" {
+		historyFile = os.Getenv(utils.OperationHistoryFileEnvVar)
+	}
+	if historyFile != "" {
+		if err := r.operations.PersistHistory(historyFile); err != nil {
+			logger.CErrorw(ctx, "failed to persist operation history, keeping it in memory only",
+				"path", historyFile, "error", err)
+		}
+	}
+
 	packageLogger := logger.Sublogger("package_manager")
 
 	if cfg.Cloud != nil && cfg.Cloud.AppAddress != "" {
@@ -548,6 +587,9 @@ func newWithResources(
 	r.webSvc = web.New(r, logger, rOpts.webOptions...)
 	if r.ftdc != nil {
 		r.ftdc.Add("web", r.webSvc.RequestCounter())
+		if rOpts.loggerRegistry != nil {
+			r.ftdc.Add("logging", rOpts.loggerRegistry)
+		}
 	}
 	r.frameSvc, err = framesystem.New(ctx, resource.Dependencies{}, logger.Sublogger("framesystem"))
 	if err != nil {
@@ -1842,6 +1884,8 @@ func (r *localRobot) checkMaxInstance(api resource.API, max int) error { //nolin
 
 var errNoCloudMetadata = errors.New("cloud metadata not available")
 
+var errLogsNotStored = errors.New("logs are not stored on this machine, start viam-server with --structured-log-file")
+
 // CloudMetadata returns app-related information about the robot.
 func (r *localRobot) CloudMetadata(ctx context.Context) (cloud.Metadata, error) {
 	md := cloud.Metadata{}
@@ -1990,6 +2034,26 @@ func (r *localRobot) MachineStatus(ctx context.Context) (robot.MachineStatus, er
 		}
 	}
 
+	r.manager.modManagerLock.Lock()
+	moduleManager := r.manager.moduleManager
+	r.manager.modManagerLock.Unlock()
+	if moduleManager != nil {
+		for name, health := range moduleManager.ModuleHealth() {
+			if result.ModuleStatuses == nil {
+				result.ModuleStatuses = make(map[string]robot.ModuleStatus)
+			}
+			status := robot.ModuleStatus{State: string(health.Status), Reasons: health.Reasons}
+			for _, restart := range health.Restarts {
+				status.RecentRestarts = append(status.RecentRestarts, robot.ModuleRestart{
+					Time:      restart.Time,
+					Reason:    restart.Reason,
+					Succeeded: restart.Succeeded,
+				})
+			}
+			result.ModuleStatuses[name] = status
+		}
+	}
+
 	return result, nil
 }
 
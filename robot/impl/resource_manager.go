@@ -1,12 +1,14 @@
 package robotimpl
 
 import (
+	"cmp"
 	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -65,6 +67,7 @@ type moduleManager interface {
 	ValidateConfig(ctx context.Context, conf resource.Config) ([]string, []string, error)
 	FailedModules() []string
 	ClearFailedModules()
+	ModuleHealth() map[string]modmanager.ModuleHealth
 	AddToFailedModules(moduleName string)
 }
 
@@ -88,6 +91,9 @@ type resourceManagerOptions struct {
 	untrustedEnv       bool
 	tlsConfig          *tls.Config
 	ftdc               *ftdc.FTDC
+	// configurationConcurrency is the number of resources of a level of the graph which are
+	// (re)configured at the same time. If it is not positive, it is read from the environment.
+	configurationConcurrency int
 }
 
 // newResourceManager returns a properly initialized set of parts.
@@ -700,6 +706,10 @@ func (manager *resourceManager) completeConfig(
 	// order.
 	levels := manager.resources.ReverseTopologicalSortInLevels()
 	timeout := rutils.GetResourceConfigurationTimeout(manager.logger)
+	concurrency := manager.opts.configurationConcurrency
+	if concurrency <= 0 {
+		concurrency = rutils.GetResourceConfigurationConcurrency(manager.logger)
+	}
 	for _, resourceNames := range levels {
 		// At the start of every reconfiguration level, run updateWeakAndOptionalDependents.
 		// value.
@@ -727,160 +737,238 @@ func (manager *resourceManager) completeConfig(
 			lr.updateWeakAndOptionalDependents(ctx)
 			break
 		}
-		// we use an errgroup here instead of a normal waitgroup to conveniently bubble
-		// up errors in resource processing goroutinues that warrant an early exit.
-		var levelErrG errgroup.Group
-		// Add resources in batches instead of all at once. We've observed this to be more
-		// reliable when there are a large number of resources to add (e.g. hundreds).
-		levelErrG.SetLimit(10)
-		for _, resName := range resourceNames {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-			}
-			// processResource is intended to be run concurrently for each resource
-			// within a topological sort level. if any processResource function returns a
-			// non-nil error then the entire `completeConfig` function will exit early.
-			//
-			// currently only a top-level context cancellation will result in an early
-			// exist - individual resource processing failures will not.
-			processResource := func() error {
-				resChan := make(chan struct{}, 1)
-				ctxWithTimeout, timeoutCancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
-				defer timeoutCancel()
-
-				stopSlowLogger := rutils.SlowLogger(
-					ctx, "Waiting for resource to complete (re)configuration", "resource", resName.String(), manager.logger)
-
-				lr.reconfigureWorkers.Add(1)
-				goutils.PanicCapturingGo(func() {
-					defer func() {
-						stopSlowLogger()
-						resChan <- struct{}{}
-						lr.reconfigureWorkers.Done()
-					}()
-					gNode, ok := manager.resources.Node(resName)
-					if !ok || !gNode.NeedsReconfigure() {
-						return
-					}
-					if !(resName.API.IsComponent() || resName.API.IsService()) {
-						return
-					}
+		if !manager.completeConfigLevel(ctx, lr, resourceNames, forceSync, timeout, concurrency) {
+			return
+		}
+	} // for-each level
+}
 
-					verb := "construct"
-					conf := gNode.Config()
-					if gNode.IsUninitialized() {
-						gNode.InitializeLogger(
-							manager.logger, resName.String(),
-						)
-					} else {
-						verb = "reconfigur"
-					}
-					manager.logger.CInfow(ctx, fmt.Sprintf("Now %ving resource", verb), "resource", resName, "model", conf.Model)
-
-					// The config was already validated, but we must check again before attempting
-					// to add.
-					if _, _, err := conf.Validate("", resName.API.Type.Name); err != nil {
-						gNode.LogAndSetLastError(
-							fmt.Errorf("resource config validation error: %w", err),
-							"resource", conf.ResourceName(),
-							"model", conf.Model)
-						return
-					}
-					if manager.moduleManager.Provides(conf) {
-						if _, _, err := manager.moduleManager.ValidateConfig(ctxWithTimeout, conf); err != nil {
-							gNode.LogAndSetLastError(
-								fmt.Errorf("modular resource config validation error: %w", err),
-								"resource", conf.ResourceName(),
-								"model", conf.Model)
-							return
-						}
-					}
+// completeConfigLevel builds or reconfigures the resources of one topological level, at most
+// concurrency at a time. The failures of the level are reported once every resource of it is done
+// or has timed out, in the order of the resources' names, so that the logs and errors of a
+// reconfiguration do not depend on which resource happened to finish first. It returns false if
+// the context was cancelled.
+func (manager *resourceManager) completeConfigLevel(
+	ctx context.Context,
+	lr *localRobot,
+	resourceNames []resource.Name,
+	forceSync bool,
+	timeout time.Duration,
+	concurrency int,
+) bool {
+	resourceNames = slices.Clone(resourceNames)
+	slices.SortFunc(resourceNames, func(a, b resource.Name) int {
+		return cmp.Compare(a.String(), b.String())
+	})
+	outcomes := make([]configOutcome, len(resourceNames))
+	defer func() {
+		for i := range outcomes {
+			outcomes[i].report(ctx, lr.logger)
+		}
+	}()
 
-					switch {
-					case resName.API.IsComponent(), resName.API.IsService():
-
-						newRes, newlyBuilt, err := manager.processResource(ctxWithTimeout, conf, gNode, lr)
-						if newlyBuilt || err != nil {
-							if err := manager.markChildrenForUpdate(resName); err != nil {
-								manager.logger.CErrorw(ctx,
-									"failed to mark children of resource for update",
-									"resource", resName,
-									"reason", err)
-							}
-						}
-
-						if err != nil {
-							gNode.LogAndSetLastError(
-								fmt.Errorf("resource build error: %v", err.Error()),
-								"resource", conf.ResourceName(),
-								"model", conf.Model)
-							return
-						}
-
-						// if the ctxWithTimeout fails with DeadlineExceeded, then that means that
-						// resource generation is running async, and we don't currently have good
-						// validation around how this might affect the resource graph. So, we avoid
-						// updating the graph to be safe.
-						if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
-							manager.logger.CErrorw(
-								ctx, "error building resource", "resource", conf.ResourceName(), "model", conf.Model, "error", ctxWithTimeout.Err())
-						} else {
-							gNode.SwapResource(newRes, conf.Model, manager.opts.ftdc)
-							manager.logger.CInfow(ctx, fmt.Sprintf("Successfully %ved resource", verb), "resource", resName, "model", conf.Model)
-						}
-
-					default:
-						err := errors.New("config is not for a component or service")
-						gNode.LogAndSetLastError(err, "resource", resName)
-					}
-				})
+	// we use an errgroup here instead of a normal waitgroup to conveniently bubble
+	// up errors in resource processing goroutinues that warrant an early exit.
+	var levelErrG errgroup.Group
+	// Add resources in batches instead of all at once. We've observed this to be more
+	// reliable when there are a large number of resources to add (e.g. hundreds).
+	levelErrG.SetLimit(concurrency)
+	for i, resName := range resourceNames {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+		}
+		outcome := &outcomes[i]
+		outcome.name = resName
+		// processResource is intended to be run concurrently for each resource
+		// within a topological sort level. if any processResource function returns a
+		// non-nil error then the entire `completeConfig` function will exit early.
+		//
+		// currently only a top-level context cancellation will result in an early
+		// exist - individual resource processing failures will not.
+		processResource := func() error {
+			resChan := make(chan struct{}, 1)
+			ctxWithTimeout, timeoutCancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+			defer timeoutCancel()
+
+			stopSlowLogger := rutils.SlowLogger(
+				ctx, "Waiting for resource to complete (re)configuration", "resource", resName.String(), manager.logger)
+
+			lr.reconfigureWorkers.Add(1)
+			goutils.PanicCapturingGo(func() {
+				defer func() {
+					stopSlowLogger()
+					resChan <- struct{}{}
+					lr.reconfigureWorkers.Done()
+				}()
+				manager.configureResource(ctx, ctxWithTimeout, lr, resName, outcome)
+			})
 
-				select {
-				case <-resChan:
-				case <-ctxWithTimeout.Done():
-					// this resource is taking too long to process, so we give up but
-					// continue processing other resources. we do not wait for this
-					// resource to finish processing since it may be running outside code
-					// and have unexpected behavior.
-					if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
-						lr.logger.CWarn(ctx, rutils.NewBuildTimeoutError(resName.String(), lr.logger))
-					}
-				case <-ctx.Done():
-					return ctx.Err()
+			select {
+			case <-resChan:
+			case <-ctxWithTimeout.Done():
+				// this resource is taking too long to process, so we give up but
+				// continue processing other resources. we do not wait for this
+				// resource to finish processing since it may be running outside code
+				// and have unexpected behavior.
+				if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
+					outcome.timeout()
 				}
-				return nil
+			case <-ctx.Done():
+				return ctx.Err()
 			}
+			return nil
+		}
 
-			syncRes := forceSync
-			if !syncRes {
-				// TODO(RSDK-6925): support concurrent processing of resources of
-				// APIs with a maximum instance limit. Currently this limit is
-				// validated later in the resource creation flow and assumes that
-				// each resource is created synchronously to have an accurate
-				// creation count.
-				if c, ok := resource.LookupGenericAPIRegistration(resName.API); ok && c.MaxInstance != 0 {
-					syncRes = true
-				}
+		syncRes := forceSync
+		if !syncRes {
+			// TODO(RSDK-6925): support concurrent processing of resources of
+			// APIs with a maximum instance limit. Currently this limit is
+			// validated later in the resource creation flow and assumes that
+			// each resource is created synchronously to have an accurate
+			// creation count.
+			if c, ok := resource.LookupGenericAPIRegistration(resName.API); ok && c.MaxInstance != 0 {
+				syncRes = true
 			}
+		}
 
-			if syncRes {
-				if err := processResource(); err != nil {
-					return
-				}
-			} else {
-				lr.reconfigureWorkers.Add(1)
-				levelErrG.Go(func() error {
-					defer lr.reconfigureWorkers.Done()
-					return processResource()
-				})
+		if syncRes {
+			if err := processResource(); err != nil {
+				return false
 			}
-		} // for-each resource name
-		if err := levelErrG.Wait(); err != nil {
+		} else {
+			lr.reconfigureWorkers.Add(1)
+			levelErrG.Go(func() error {
+				defer lr.reconfigureWorkers.Done()
+				return processResource()
+			})
+		}
+	} // for-each resource name
+	return levelErrG.Wait() == nil
+}
+
+// configureResource builds or reconfigures a resource if it needs to be, and records its failure
+// in outcome.
+func (manager *resourceManager) configureResource(
+	ctx, ctxWithTimeout context.Context,
+	lr *localRobot,
+	resName resource.Name,
+	outcome *configOutcome,
+) {
+	gNode, ok := manager.resources.Node(resName)
+	if !ok || !gNode.NeedsReconfigure() {
+		return
+	}
+	if !(resName.API.IsComponent() || resName.API.IsService()) {
+		return
+	}
+
+	verb := "construct"
+	conf := gNode.Config()
+	if gNode.IsUninitialized() {
+		gNode.InitializeLogger(
+			manager.logger, resName.String(),
+		)
+	} else {
+		verb = "reconfigur"
+	}
+	manager.logger.CInfow(ctx, fmt.Sprintf("Now %ving resource", verb), "resource", resName, "model", conf.Model)
+
+	// The config was already validated, but we must check again before attempting
+	// to add.
+	if _, _, err := conf.Validate("", resName.API.Type.Name); err != nil {
+		outcome.fail(gNode,
+			fmt.Errorf("resource config validation error: %w", err),
+			"resource", conf.ResourceName(),
+			"model", conf.Model)
+		return
+	}
+	if manager.moduleManager.Provides(conf) {
+		if _, _, err := manager.moduleManager.ValidateConfig(ctxWithTimeout, conf); err != nil {
+			outcome.fail(gNode,
+				fmt.Errorf("modular resource config validation error: %w", err),
+				"resource", conf.ResourceName(),
+				"model", conf.Model)
 			return
 		}
-	} // for-each level
+	}
+
+	newRes, newlyBuilt, err := manager.processResource(ctxWithTimeout, conf, gNode, lr)
+	if newlyBuilt || err != nil {
+		if err := manager.markChildrenForUpdate(resName); err != nil {
+			manager.logger.CErrorw(ctx,
+				"failed to mark children of resource for update",
+				"resource", resName,
+				"reason", err)
+		}
+	}
+
+	if err != nil {
+		outcome.fail(gNode,
+			fmt.Errorf("resource build error: %v", err.Error()),
+			"resource", conf.ResourceName(),
+			"model", conf.Model)
+		return
+	}
+
+	// if the ctxWithTimeout fails with DeadlineExceeded, then that means that
+	// resource generation is running async, and we don't currently have good
+	// validation around how this might affect the resource graph. So, we avoid
+	// updating the graph to be safe.
+	if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
+		manager.logger.CErrorw(
+			ctx, "error building resource", "resource", conf.ResourceName(), "model", conf.Model, "error", ctxWithTimeout.Err())
+	} else {
+		gNode.SwapResource(newRes, conf.Model, manager.opts.ftdc)
+		manager.logger.CInfow(ctx, fmt.Sprintf("Successfully %ved resource", verb), "resource", resName, "model", conf.Model)
+	}
+}
+
+// configOutcome holds the failure of a resource until the failures of its level are reported.
+type configOutcome struct {
+	mu       sync.Mutex
+	name     resource.Name
+	node     *resource.GraphNode
+	err      error
+	errArgs  []any
+	timedOut bool
+	reported bool
+}
+
+// fail records that the resource failed to (re)configure. If the failures of its level were
+// already reported, which happens when the resource finishes after timing out, it is reported
+// right away.
+func (o *configOutcome) fail(node *resource.GraphNode, err error, args ...any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.reported {
+		node.LogAndSetLastError(err, args...)
+		return
+	}
+	o.node, o.err, o.errArgs = node, err, args
+}
+
+// timeout records that the resource took longer than the resource configuration timeout.
+func (o *configOutcome) timeout() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.timedOut = true
+}
+
+func (o *configOutcome) report(ctx context.Context, logger logging.Logger) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.reported {
+		return
+	}
+	o.reported = true
+	if o.timedOut {
+		logger.CWarn(ctx, rutils.NewBuildTimeoutError(o.name.String(), logger))
+	}
+	if o.err != nil {
+		o.node.LogAndSetLastError(o.err, o.errArgs...)
+	}
 }
 
 func (manager *resourceManager) completeConfigForRemotes(ctx context.Context, lr *localRobot) {
@@ -460,6 +460,10 @@ func (m *dummyModMan) FailedModules() []string {
 func (m *dummyModMan) ClearFailedModules() {
 }
 
+func (m *dummyModMan) ModuleHealth() map[string]modmanager.ModuleHealth {
+	return nil
+}
+
 func TestTwoModulesSameName(t *testing.T) {
 	ctx := context.Background()
 	logger := logging.NewTestLogger(t)
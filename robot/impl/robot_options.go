@@ -1,6 +1,7 @@
 package robotimpl
 
 import (
+	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/robot/web"
 )
 
@@ -24,6 +25,21 @@ type options struct {
 
 	// disableCompleteConfigWorker starts the robot without the complete config worker - should only be used for tests.
 	disableCompleteConfigWorker bool
+
+	// resourceConfigurationConcurrency is the number of independent resources that are (re)configured
+	// at the same time. It defaults to utils.GetResourceConfigurationConcurrency.
+	resourceConfigurationConcurrency int
+
+	// operationHistoryFile is the file the history of completed operations is persisted to. It
+	// defaults to the value of the VIAM_OPERATION_HISTORY_FILE environment variable.
+	operationHistoryFile string
+
+	// structuredLogFile is the file a logging.StructuredFileAppender stores the logs of the robot in.
+	structuredLogFile string
+
+	// loggerRegistry is the registry of the robot's loggers, whose rate limit stats are reported
+	// to FTDC.
+	loggerRegistry *logging.Registry
 }
 
 // Option configures how we set up the web service.
@@ -93,3 +109,36 @@ func WithDisableCompleteConfigWorker() Option {
 		o.disableCompleteConfigWorker = true
 	})
 }
+
+// WithResourceConfigurationConcurrency returns an Option which sets the number of independent
+// resources that are constructed or reconfigured at the same time, overriding the
+// VIAM_RESOURCE_CONFIGURATION_CONCURRENCY environment variable.
+func WithResourceConfigurationConcurrency(concurrency int) Option {
+	return newFuncOption(func(o *options) {
+		o.resourceConfigurationConcurrency = concurrency
+	})
+}
+
+// WithOperationHistoryFile returns an Option which persists the history of the operations the robot
+// completes to a file, overriding the VIAM_OPERATION_HISTORY_FILE environment variable.
+func WithOperationHistoryFile(path string) Option {
+	return newFuncOption(func(o *options) {
+		o.operationHistoryFile = path
+	})
+}
+
+// WithStructuredLogFile returns an Option which tells the robot the file a
+// logging.StructuredFileAppender stores its logs in, so that they can be queried through the robot.
+func WithStructuredLogFile(path string) Option {
+	return newFuncOption(func(o *options) {
+		o.structuredLogFile = path
+	})
+}
+
+// WithLoggerRegistry returns an Option which reports the number of log entries the loggers in the
+// registry suppressed due to their rate limits to FTDC.
+func WithLoggerRegistry(registry *logging.Registry) Option {
+	return newFuncOption(func(o *options) {
+		o.loggerRegistry = registry
+	})
+}
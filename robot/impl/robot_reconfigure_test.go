@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -3421,6 +3422,62 @@ func TestResourceConstructTimeout(t *testing.T) {
 	})
 }
 
+func TestResourceConstructConcurrency(t *testing.T) {
+	logger, logs := logging.NewObservedTestLogger(t)
+
+	var running, maxRunning atomic.Int32
+	model := registerMockComponent(
+		t,
+		resource.Registration[resource.Resource, resource.NoNativeConfig]{
+			Constructor: func(
+				ctx context.Context,
+				deps resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (resource.Resource, error) {
+				now := running.Add(1)
+				defer running.Add(-1)
+				for {
+					most := maxRunning.Load()
+					if now <= most || maxRunning.CompareAndSwap(most, now) {
+						break
+					}
+				}
+				time.Sleep(50 * time.Millisecond)
+				if conf.Attributes.Bool("fail", false) {
+					return nil, errors.Errorf("cannot build %s", conf.Name)
+				}
+				return &mockFake{Named: conf.ResourceName().AsNamed()}, nil
+			},
+		})
+
+	// resources are listed out of order, and the failing ones will finish in different orders
+	cfg := &config.Config{}
+	for _, name := range []string{"f", "b", "e", "a", "d", "c"} {
+		cfg.Components = append(cfg.Components, resource.Config{
+			Name:       name,
+			Model:      model,
+			API:        mockAPI,
+			Attributes: rutils.AttributeMap{"fail": name != "a" && name != "d"},
+		})
+	}
+	r := setupLocalRobot(t, context.Background(), cfg, logger, WithResourceConfigurationConcurrency(2))
+
+	test.That(t, maxRunning.Load(), test.ShouldEqual, 2)
+	_, err := r.ResourceByName(mockNamed("a"))
+	test.That(t, err, test.ShouldBeNil)
+
+	// failures of a level are reported in the order of the resources' names
+	var failed []string
+	for _, entry := range logs.FilterMessageSnippet("resource build error").All() {
+		failed = append(failed, strings.TrimPrefix(entry.Message, "resource build error: cannot build "))
+	}
+	test.That(t, failed, test.ShouldResemble, []string{"b", "c", "e", "f"})
+	_, err = r.ResourceByName(mockNamed("c"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot build c")
+}
+
 // tests that on context cancellation, the resource re/configuration loop never gets inside the resource constructor.
 func TestResourceConstructCtxCancel(t *testing.T) {
 	logger := logging.NewTestLogger(t)
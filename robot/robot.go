@@ -181,6 +181,15 @@ type LocalRobot interface {
 	// DOT reference: https://graphviz.org/doc/info/lang.html
 	ExportResourcesAsDot(index int) (resource.GetSnapshotInfo, error)
 
+	// InspectResourceGraph returns the state of every node of the resource graph.
+	InspectResourceGraph() resource.GraphInspection
+
+	// ResourceFailureChain returns what keeps a resource from being ready, and what is blocked on it.
+	ResourceFailureChain(name resource.Name) (resource.FailureChain, error)
+
+	// QueryLogs returns the log entries matching the query that the robot stored on disk, oldest first.
+	QueryLogs(query logging.LogQuery) ([]logging.StoredLogEntry, error)
+
 	// RestartAllowed returns whether the robot can safely be restarted.
 	RestartAllowed() bool
 
@@ -352,14 +361,41 @@ type MachineStatus struct {
 	Config      config.Revision
 	State       MachineState
 	JobStatuses map[string]JobStatus
+	// ModuleStatuses are keyed by module name.
+	ModuleStatuses map[string]ModuleStatus
 }
 
+// ModuleStatusAPI names the module statuses in a GetMachineStatus response, which has no field of
+// its own for them. Each module is sent as a resource status with this API, ready when the module
+// is healthy and unhealthy otherwise, and its restarts as a job status named after that resource.
+// The reasons for individual restarts are not sent.
+var ModuleStatusAPI = resource.APINamespaceRDKInternal.WithType("module").WithSubtype("status")
+
 // JobStatus encapsulates status information about a single JobManager job.
 type JobStatus struct {
 	RecentSuccessfulRuns []time.Time
 	RecentFailedRuns     []time.Time
 }
 
+// ModuleStatus encapsulates health and restart information about a single module.
+type ModuleStatus struct {
+	// State is one of ModuleStateHealthy, "degraded" or "failed".
+	State string
+	// Reasons explains why the module is not healthy.
+	Reasons        []string
+	RecentRestarts []ModuleRestart
+}
+
+// ModuleStateHealthy is the state of a module that is running normally.
+const ModuleStateHealthy = "healthy"
+
+// ModuleRestart is an attempt to restart a module after it exited or failed its health check.
+type ModuleRestart struct {
+	Time      time.Time
+	Reason    string
+	Succeeded bool
+}
+
 // VersionResponse encapsulates the version info of the robot.
 type VersionResponse struct {
 	Platform   string
@@ -402,3 +438,23 @@ func version() (VersionResponse, error) {
 
 	return result, nil
 }
+
+// ResourceGraphServiceName is the gRPC service robots serve resource graph inspections on. It is not
+// part of the robot API: its requests and responses are JSON objects carried as
+// google.protobuf.Struct.
+const ResourceGraphServiceName = "rdk.robot.v1.ResourceGraphService"
+
+// LeaseServiceName is the gRPC service robots serve resource leases on. Like
+// ResourceGraphServiceName, its requests and responses are JSON objects carried as
+// google.protobuf.Struct. Its requests are bound to the session of the client.
+const LeaseServiceName = "rdk.robot.v1.LeaseService"
+
+// OperationHistoryServiceName is the gRPC service robots serve the history of their completed
+// operations on. Like ResourceGraphServiceName, its requests and responses are JSON objects
+// carried as google.protobuf.Struct.
+const OperationHistoryServiceName = "rdk.robot.v1.OperationHistoryService"
+
+// LogServiceName is the gRPC service robots serve the logs they stored on disk on, so that they
+// can be read while the robot cannot reach the cloud. Like ResourceGraphServiceName, its requests
+// and responses are JSON objects carried as google.protobuf.Struct.
+const LogServiceName = "rdk.robot.v1.LogService"
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/session"
+)
+
+// LeaseServiceServer serves exclusive resource leases to the sessions of clients. The "name" field
+// of requests is either a full resource name or a name matching a single resource, and leases are
+// returned as session.LeaseInfo.
+type LeaseServiceServer interface {
+	// AcquireLease leases the resource to the session of the request for "ttl_seconds", or for as
+	// long as the session lasts if it is not set. "holder" identifies the client to those the lease
+	// blocks and defaults to the authenticated entity of the client.
+	AcquireLease(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	// RenewLease extends a lease held by the session of the request by "ttl_seconds".
+	RenewLease(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	// ReleaseLease ends a lease held by the session of the request.
+	ReleaseLease(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	// ListLeases returns every active lease in its "leases" field.
+	ListLeases(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// LeaseServiceDesc describes the service robot.LeaseServiceName for an rpc.Server.
+var LeaseServiceDesc = grpc.ServiceDesc{
+	ServiceName: robot.LeaseServiceName,
+	HandlerType: (*LeaseServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AcquireLease",
+			Handler:    structServiceHandler(robot.LeaseServiceName, "AcquireLease", LeaseServiceServer.AcquireLease),
+		},
+		{
+			MethodName: "RenewLease",
+			Handler:    structServiceHandler(robot.LeaseServiceName, "RenewLease", LeaseServiceServer.RenewLease),
+		},
+		{
+			MethodName: "ReleaseLease",
+			Handler:    structServiceHandler(robot.LeaseServiceName, "ReleaseLease", LeaseServiceServer.ReleaseLease),
+		},
+		{
+			MethodName: "ListLeases",
+			Handler:    structServiceHandler(robot.LeaseServiceName, "ListLeases", LeaseServiceServer.ListLeases),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+var errLeaseNeedsSession = errors.New("resource leases are held by sessions, and the request has none")
+
+type leaseServer struct {
+	robot robot.Robot
+}
+
+// NewLeaseServer constructs a LeaseServiceServer for a robot.
+func NewLeaseServer(robot robot.Robot) LeaseServiceServer {
+	return &leaseServer{robot: robot}
+}
+
+func (s *leaseServer) AcquireLease(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	manager, sess, name, err := s.leaseRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	holder := req.GetFields()["holder"].GetStringValue()
+	if holder == "" {
+		if authEntity, ok := rpc.ContextAuthEntity(ctx); ok {
+			holder = authEntity.Entity
+		}
+	}
+	lease, err := manager.AcquireLease(ctx, sess.ID(), name, holder, leaseTTL(req))
+	if err != nil {
+		return nil, err
+	}
+	return jsonToStruct(lease.Info(sess.ID()))
+}
+
+func (s *leaseServer) RenewLease(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	manager, sess, name, err := s.leaseRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lease, err := manager.RenewLease(ctx, sess.ID(), name, leaseTTL(req))
+	if err != nil {
+		return nil, err
+	}
+	return jsonToStruct(lease.Info(sess.ID()))
+}
+
+func (s *leaseServer) ReleaseLease(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	manager, sess, name, err := s.leaseRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := manager.ReleaseLease(ctx, sess.ID(), name); err != nil {
+		return nil, err
+	}
+	return &structpb.Struct{}, nil
+}
+
+func (s *leaseServer) ListLeases(ctx context.Context, _ *structpb.Struct) (*structpb.Struct, error) {
+	manager, err := s.leaseManager()
+	if err != nil {
+		return nil, err
+	}
+	var caller uuid.UUID
+	if sess, ok := session.FromContext(ctx); ok {
+		caller = sess.ID()
+	}
+	leases := []session.LeaseInfo{}
+	for _, lease := range manager.Leases() {
+		leases = append(leases, lease.Info(caller))
+	}
+	return jsonToStruct(map[string]any{"leases": leases})
+}
+
+func (s *leaseServer) leaseManager() (session.LeaseManager, error) {
+	manager, ok := s.robot.SessionManager().(session.LeaseManager)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the robot does not support resource leases")
+	}
+	return manager, nil
+}
+
+// leaseRequest returns what every request about a single lease needs: the lease manager, the
+// session of the request and the resource the request names.
+func (s *leaseServer) leaseRequest(
+	ctx context.Context,
+	req *structpb.Struct,
+) (session.LeaseManager, *session.Session, resource.Name, error) {
+	manager, err := s.leaseManager()
+	if err != nil {
+		return nil, nil, resource.Name{}, err
+	}
+	sess, ok := session.FromContext(ctx)
+	if !ok {
+		return nil, nil, resource.Name{}, errLeaseNeedsSession
+	}
+	name, err := resolveResourceName(req.GetFields()["name"].GetStringValue(), resource.NamesToStrings(s.robot.ResourceNames()))
+	if err != nil {
+		return nil, nil, resource.Name{}, err
+	}
+	return manager, sess, name, nil
+}
+
+func leaseTTL(req *structpb.Struct) time.Duration {
+	return time.Duration(req.GetFields()["ttl_seconds"].GetNumberValue() * float64(time.Second))
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot"
+)
+
+// LogServiceServer serves the logs a robot stored on disk.
+type LogServiceServer interface {
+	// QueryLogs returns the stored log entries matching the request, oldest first, as
+	// logging.StoredLogEntry in its "entries" field. The request may filter on "min_level"
+	// (defaulting to info), "logger" (a logger name pattern), "since" and "until" (RFC 3339) and
+	// "contains", and bound the number of entries with "limit".
+	QueryLogs(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// LogServiceDesc describes the service robot.LogServiceName for an rpc.Server.
+var LogServiceDesc = grpc.ServiceDesc{
+	ServiceName: robot.LogServiceName,
+	HandlerType: (*LogServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "QueryLogs",
+			Handler:    structServiceHandler(robot.LogServiceName, "QueryLogs", LogServiceServer.QueryLogs),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+type logServer struct {
+	robot robot.LocalRobot
+}
+
+// NewLogServer constructs a LogServiceServer for a robot.
+func NewLogServer(robot robot.LocalRobot) LogServiceServer {
+	return &logServer{robot: robot}
+}
+
+func (s *logServer) QueryLogs(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	query, err := logQueryFromStruct(req)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.robot.QueryLogs(query)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToStruct(map[string]any{"entries": entries})
+}
+
+func logQueryFromStruct(req *structpb.Struct) (logging.LogQuery, error) {
+	fields := req.GetFields()
+	query := logging.LogQuery{
+		Logger:   fields["logger"].GetStringValue(),
+		Contains: fields["contains"].GetStringValue(),
+		Limit:    int(fields["limit"].GetNumberValue()),
+	}
+	if minLevel := fields["min_level"].GetStringValue(); minLevel != "" {
+		level, err := logging.LevelFromString(minLevel)
+		if err != nil {
+			return logging.LogQuery{}, err
+		}
+		query.MinLevel = level
+	}
+	for key, bound := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		value := fields[key].GetStringValue()
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return logging.LogQuery{}, errors.Wrapf(err, "invalid %s", key)
+		}
+		*bound = t
+	}
+	return query, nil
+}
@@ -0,0 +1,73 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot/server"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestLogServer(t *testing.T) {
+	logged := time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)
+	var queried logging.LogQuery
+	injectRobot := &inject.Robot{}
+	injectRobot.QueryLogsFunc = func(query logging.LogQuery) ([]logging.StoredLogEntry, error) {
+		queried = query
+		return []logging.StoredLogEntry{{
+			Time:    logged,
+			Level:   logging.WARN,
+			Logger:  "rdk.resource_manager",
+			Message: "resource build error",
+			Fields:  json.RawMessage(`{"resource":"arm1"}`),
+		}}, nil
+	}
+	srv := server.NewLogServer(injectRobot)
+
+	req, err := structpb.NewStruct(map[string]any{
+		"min_level": "warn",
+		"logger":    "rdk.*",
+		"since":     "2025-01-15T13:00:00Z",
+		"until":     "2025-01-15T15:00:00Z",
+		"contains":  "arm",
+		"limit":     10,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	resp, err := srv.QueryLogs(context.Background(), req)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, queried, test.ShouldResemble, logging.LogQuery{
+		MinLevel: logging.WARN,
+		Logger:   "rdk.*",
+		Since:    logged.Add(-time.Hour),
+		Until:    logged.Add(time.Hour),
+		Contains: "arm",
+		Limit:    10,
+	})
+	entries := resp.Fields["entries"].GetListValue().GetValues()
+	test.That(t, entries, test.ShouldHaveLength, 1)
+	entry := entries[0].GetStructValue().GetFields()
+	test.That(t, entry["level"].GetStringValue(), test.ShouldEqual, "Warn")
+	test.That(t, entry["fields"].GetStructValue().GetFields()["resource"].GetStringValue(), test.ShouldEqual, "arm1")
+
+	// the level defaults to info
+	_, err = srv.QueryLogs(context.Background(), &structpb.Struct{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, queried, test.ShouldResemble, logging.LogQuery{})
+
+	req, err = structpb.NewStruct(map[string]any{"min_level": "loud"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = srv.QueryLogs(context.Background(), req)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	req, err = structpb.NewStruct(map[string]any{"until": "yesterday"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = srv.QueryLogs(context.Background(), req)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid until")
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/robot"
+)
+
+// OperationHistoryServiceServer serves the history of the operations a robot completed, so that
+// operators can tell who commanded a resource and when.
+type OperationHistoryServiceServer interface {
+	// GetOperationHistory returns the completed operations matching the request, oldest first, as
+	// operation.Record in its "operations" field. The request may filter on "method", "resource",
+	// "session_id" and "since" (RFC 3339), and bound the number of operations with "limit".
+	GetOperationHistory(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// OperationHistoryServiceDesc describes the service robot.OperationHistoryServiceName for an
+// rpc.Server.
+var OperationHistoryServiceDesc = grpc.ServiceDesc{
+	ServiceName: robot.OperationHistoryServiceName,
+	HandlerType: (*OperationHistoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetOperationHistory",
+			Handler: structServiceHandler(
+				robot.OperationHistoryServiceName, "GetOperationHistory", OperationHistoryServiceServer.GetOperationHistory),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+type operationHistoryServer struct {
+	robot robot.Robot
+}
+
+// NewOperationHistoryServer constructs an OperationHistoryServiceServer for a robot.
+func NewOperationHistoryServer(robot robot.Robot) OperationHistoryServiceServer {
+	return &operationHistoryServer{robot: robot}
+}
+
+func (s *operationHistoryServer) GetOperationHistory(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	manager := s.robot.OperationManager()
+	if manager == nil {
+		return nil, status.Error(codes.Unimplemented, "the robot does not keep an operation history")
+	}
+	query, err := historyQueryFromStruct(req)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToStruct(map[string]any{"operations": manager.History(query)})
+}
+
+func historyQueryFromStruct(req *structpb.Struct) (operation.HistoryQuery, error) {
+	fields := req.GetFields()
+	query := operation.HistoryQuery{
+		Method:   fields["method"].GetStringValue(),
+		Resource: fields["resource"].GetStringValue(),
+		Limit:    int(fields["limit"].GetNumberValue()),
+	}
+	if sessionID := fields["session_id"].GetStringValue(); sessionID != "" {
+		id, err := uuid.Parse(sessionID)
+		if err != nil {
+			return operation.HistoryQuery{}, errors.Wrap(err, "invalid session_id")
+		}
+		query.SessionID = id
+	}
+	if since := fields["since"].GetStringValue(); since != "" {
+		t, err := time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return operation.HistoryQuery{}, errors.Wrap(err, "invalid since")
+		}
+		query.Since = t
+	}
+	return query, nil
+}
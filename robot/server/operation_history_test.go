@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/robot/server"
+	"go.viam.com/rdk/session"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestOperationHistoryServer(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	injectRobot := &inject.Robot{}
+	injectRobot.LoggerFunc = func() logging.Logger {
+		return logger
+	}
+	robotServer := server.New(injectRobot)
+	srv := server.NewOperationHistoryServer(injectRobot)
+
+	history := func(fields map[string]any) []*structpb.Value {
+		t.Helper()
+		req, err := structpb.NewStruct(fields)
+		test.That(t, err, test.ShouldBeNil)
+		resp, err := srv.GetOperationHistory(context.Background(), req)
+		test.That(t, err, test.ShouldBeNil)
+		return resp.Fields["operations"].GetListValue().GetValues()
+	}
+
+	test.That(t, history(nil), test.ShouldBeEmpty)
+
+	sess := session.New(context.Background(), "owner1", time.Minute, nil)
+	moveCtx, moveDone := injectRobot.OperationManager().Create(
+		session.ToContext(context.Background(), sess), "/viam.component.arm.v1.ArmService/MoveToPosition", nil)
+	_, stopDone := injectRobot.OperationManager().Create(context.Background(), "/viam.component.arm.v1.ArmService/Stop", nil)
+	stopDone()
+
+	_, err := robotServer.CancelOperation(context.Background(), &pb.CancelOperationRequest{Id: operation.Get(moveCtx).ID.String()})
+	test.That(t, err, test.ShouldBeNil)
+	moveDone()
+
+	ops := history(nil)
+	test.That(t, ops, test.ShouldHaveLength, 2)
+	stop := ops[0].GetStructValue().GetFields()
+	test.That(t, stop["method"].GetStringValue(), test.ShouldEqual, "/viam.component.arm.v1.ArmService/Stop")
+	move := ops[1].GetStructValue().GetFields()
+	test.That(t, move["session_id"].GetStringValue(), test.ShouldEqual, sess.ID().String())
+	test.That(t, move["cancel_cause"].GetStringValue(), test.ShouldEqual, "canceled by CancelOperation")
+
+	ops = history(map[string]any{"session_id": sess.ID().String()})
+	test.That(t, ops, test.ShouldHaveLength, 1)
+	ops = history(map[string]any{"method": "Stop", "limit": 5})
+	test.That(t, ops, test.ShouldHaveLength, 1)
+	ops = history(map[string]any{"since": time.Now().Add(time.Minute).Format(time.RFC3339)})
+	test.That(t, ops, test.ShouldBeEmpty)
+
+	req, err := structpb.NewStruct(map[string]any{"session_id": "nope"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = srv.GetOperationHistory(context.Background(), req)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid session_id")
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/robot"
+)
+
+// ResourceGraphServiceServer serves inspections of a robot's resource graph.
+type ResourceGraphServiceServer interface {
+	// GetResourceGraph returns a resource.GraphInspection.
+	GetResourceGraph(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	// GetFailureChain returns the resource.FailureChain of the resource named by the "name" field of
+	// the request, which is either a full resource name or a name matching a single resource.
+	GetFailureChain(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// ResourceGraphServiceDesc describes the service robot.ResourceGraphServiceName for an rpc.Server.
+var ResourceGraphServiceDesc = grpc.ServiceDesc{
+	ServiceName: robot.ResourceGraphServiceName,
+	HandlerType: (*ResourceGraphServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetResourceGraph",
+			Handler:    structServiceHandler(robot.ResourceGraphServiceName, "GetResourceGraph", ResourceGraphServiceServer.GetResourceGraph),
+		},
+		{
+			MethodName: "GetFailureChain",
+			Handler:    structServiceHandler(robot.ResourceGraphServiceName, "GetFailureChain", ResourceGraphServiceServer.GetFailureChain),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+type resourceGraphServer struct {
+	robot robot.LocalRobot
+}
+
+// NewResourceGraphServer constructs a ResourceGraphServiceServer for a robot.
+func NewResourceGraphServer(robot robot.LocalRobot) ResourceGraphServiceServer {
+	return &resourceGraphServer{robot: robot}
+}
+
+func (s *resourceGraphServer) GetResourceGraph(_ context.Context, _ *structpb.Struct) (*structpb.Struct, error) {
+	return jsonToStruct(s.robot.InspectResourceGraph())
+}
+
+func (s *resourceGraphServer) GetFailureChain(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	inspection := s.robot.InspectResourceGraph()
+	names := make([]string, 0, len(inspection.Nodes))
+	for _, node := range inspection.Nodes {
+		names = append(names, node.Name)
+	}
+	name, err := resolveResourceName(req.GetFields()["name"].GetStringValue(), names)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := s.robot.ResourceFailureChain(name)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToStruct(chain)
+}
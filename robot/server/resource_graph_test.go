@@ -0,0 +1,98 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/server"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestResourceGraphServer(t *testing.T) {
+	armName := arm.Named("arm1")
+	motorName := motor.Named("motor1")
+	otherMotorName := motor.Named("arm1")
+	inspection := resource.GraphInspection{
+		LogicalClock: 3,
+		Nodes: []resource.NodeInspection{
+			{Name: armName.String(), State: resource.NodeStateConfiguring.String(), Dependencies: []string{motorName.String()}},
+			{Name: otherMotorName.String(), State: resource.NodeStateReady.String()},
+			{Name: motorName.String(), State: resource.NodeStateUnhealthy.String(), Error: "stalled"},
+		},
+	}
+
+	injectRobot := &inject.Robot{}
+	injectRobot.InspectResourceGraphFunc = func() resource.GraphInspection {
+		return inspection
+	}
+	var chainRequested resource.Name
+	injectRobot.ResourceFailureChainFunc = func(name resource.Name) (resource.FailureChain, error) {
+		chainRequested = name
+		if name == armName {
+			return resource.FailureChain{Resource: inspection.Nodes[0], Causes: inspection.Nodes[2:]}, nil
+		}
+		return resource.FailureChain{}, resource.NewNotFoundError(name)
+	}
+	srv := server.NewResourceGraphServer(injectRobot)
+
+	failureChain := func(name string) (*structpb.Struct, error) {
+		req, err := structpb.NewStruct(map[string]any{"name": name})
+		test.That(t, err, test.ShouldBeNil)
+		return srv.GetFailureChain(context.Background(), req)
+	}
+
+	t.Run("graph", func(t *testing.T) {
+		resp, err := srv.GetResourceGraph(context.Background(), &structpb.Struct{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Fields["logical_clock"].GetNumberValue(), test.ShouldEqual, 3)
+		nodes := resp.Fields["nodes"].GetListValue().GetValues()
+		test.That(t, nodes, test.ShouldHaveLength, 3)
+		motorNode := nodes[2].GetStructValue().GetFields()
+		test.That(t, motorNode["name"].GetStringValue(), test.ShouldEqual, motorName.String())
+		test.That(t, motorNode["state"].GetStringValue(), test.ShouldEqual, resource.NodeStateUnhealthy.String())
+		test.That(t, motorNode["error"].GetStringValue(), test.ShouldEqual, "stalled")
+	})
+
+	t.Run("failure chain by full name", func(t *testing.T) {
+		resp, err := failureChain(armName.String())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, chainRequested, test.ShouldResemble, armName)
+		test.That(t, resp.Fields["resource"].GetStructValue().GetFields()["name"].GetStringValue(), test.ShouldEqual, armName.String())
+		causes := resp.Fields["causes"].GetListValue().GetValues()
+		test.That(t, causes, test.ShouldHaveLength, 1)
+		test.That(t, causes[0].GetStructValue().GetFields()["error"].GetStringValue(), test.ShouldEqual, "stalled")
+	})
+
+	t.Run("failure chain by short name", func(t *testing.T) {
+		_, err := failureChain("motor1")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, resource.IsNotFoundError(err), test.ShouldBeTrue)
+		test.That(t, chainRequested, test.ShouldResemble, motorName)
+	})
+
+	t.Run("ambiguous name", func(t *testing.T) {
+		_, err := failureChain("arm1")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "matches more than one resource")
+		test.That(t, err.Error(), test.ShouldContainSubstring, armName.String())
+		test.That(t, err.Error(), test.ShouldContainSubstring, otherMotorName.String())
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		_, err := failureChain("gripper1")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `no resource named "gripper1"`)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := srv.GetFailureChain(context.Background(), &structpb.Struct{})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "resource name is required")
+	})
+}
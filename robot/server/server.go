@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -205,7 +206,11 @@ func convertInterfaceToStruct(i interface{}) (*structpb.Struct, error) {
 func (s *Server) CancelOperation(ctx context.Context, req *pb.CancelOperationRequest) (*pb.CancelOperationResponse, error) {
 	op := s.robot.OperationManager().FindString(req.Id)
 	if op != nil {
-		op.Cancel()
+		cause := "canceled by CancelOperation"
+		if canceler := operation.Get(ctx); canceler != nil {
+			cause += " in operation " + canceler.ID.String()
+		}
+		op.CancelWithCause(cause)
 	}
 	return &pb.CancelOperationResponse{}, nil
 }
@@ -580,6 +585,33 @@ func (s *Server) GetMachineStatus(ctx context.Context, _ *pb.GetMachineStatusReq
 		}
 	}
 
+	for moduleName, modStatus := range mStatus.ModuleStatuses {
+		resName := resource.NewName(robot.ModuleStatusAPI, moduleName)
+		pbResStatus := &pb.ResourceStatus{
+			Name:     protoutils.ResourceNameToProto(resName),
+			State:    pb.ResourceStatus_STATE_READY,
+			Revision: mStatus.Config.Revision,
+		}
+		if modStatus.State != robot.ModuleStateHealthy {
+			pbResStatus.State = pb.ResourceStatus_STATE_UNHEALTHY
+			pbResStatus.Error = modStatus.State
+			if len(modStatus.Reasons) > 0 {
+				pbResStatus.Error += ": " + strings.Join(modStatus.Reasons, "; ")
+			}
+		}
+		restarts := &pb.JobStatus{JobName: resName.String()}
+		for _, restart := range modStatus.RecentRestarts {
+			if restart.Succeeded {
+				restarts.RecentSuccessfulRuns = append(restarts.RecentSuccessfulRuns, timestamppb.New(restart.Time))
+			} else {
+				restarts.RecentFailedRuns = append(restarts.RecentFailedRuns, timestamppb.New(restart.Time))
+			}
+			pbResStatus.LastUpdated = timestamppb.New(restart.Time)
+		}
+		result.Resources = append(result.Resources, pbResStatus)
+		result.JobStatuses = append(result.JobStatuses, restarts)
+	}
+
 	return &result, nil
 }
 
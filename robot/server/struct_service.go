@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/resource"
+)
+
+// structServiceHandler returns the handler of a method of a service whose requests and responses
+// are google.protobuf.Struct.
+func structServiceHandler[S any](
+	service, method string,
+	call func(S, context.Context, *structpb.Struct) (*structpb.Struct, error),
+) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(structpb.Struct)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return call(srv.(S), ctx, in)
+		}
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: fmt.Sprintf("/%s/%s", service, method),
+		}
+		return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+			return call(srv.(S), ctx, req.(*structpb.Struct))
+		})
+	}
+}
+
+// jsonToStruct converts a value to a google.protobuf.Struct through its JSON encoding.
+func jsonToStruct(v any) (*structpb.Struct, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
+
+// resolveResourceName finds the resource a query names among the full names of the resources of
+// a robot. Full names match exactly, and otherwise a query matches by the name alone so that
+// operators do not need to know a resource's API.
+func resolveResourceName(query string, names []string) (resource.Name, error) {
+	if query == "" {
+		return resource.Name{}, errors.New("a resource name is required")
+	}
+	var matches []string
+	for _, fullName := range names {
+		if fullName == query {
+			matches = []string{fullName}
+			break
+		}
+		if name, err := resource.NewFromString(fullName); err == nil && (name.ShortName() == query || name.Name == query) {
+			matches = append(matches, fullName)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return resource.Name{}, errors.Errorf("no resource named %q", query)
+	case 1:
+	default:
+		return resource.Name{}, errors.Errorf("%q matches more than one resource, use one of: %s", query, strings.Join(matches, ", "))
+	}
+	return resource.NewFromString(matches[0])
+}
@@ -1,7 +1,9 @@
 package robot
 
 import (
+	"cmp"
 	"context"
+	"slices"
 	"sync"
 	"time"
 
@@ -22,6 +24,7 @@ func NewSessionManager(robot Robot, heartbeatWindow time.Duration) *SessionManag
 		logger:            robot.Logger().Sublogger("networking.session_manager"),
 		sessions:          map[uuid.UUID]*session.Session{},
 		resourceToSession: map[resource.Name]uuid.UUID{},
+		leases:            map[resource.Name]session.Lease{},
 	}
 	m.workers = utils.NewBackgroundStoppableWorkers(m.expireLoop)
 	return m
@@ -38,6 +41,7 @@ type SessionManager struct {
 	sessions          map[uuid.UUID]*session.Session
 
 	resourceToSession map[resource.Name]uuid.UUID
+	leases            map[resource.Name]session.Lease
 
 	workers *utils.StoppableWorkers
 }
@@ -88,6 +92,11 @@ func (m *SessionManager) expireLoop(ctx context.Context) {
 			for id := range toDelete {
 				delete(m.sessions, id)
 			}
+			for resName, lease := range m.leases {
+				if _, ok := toDelete[lease.SessionID]; ok || !lease.Active(now) {
+					delete(m.leases, resName)
+				}
+			}
 
 			if len(toStop) == 0 {
 				return
@@ -189,6 +198,123 @@ func (m *SessionManager) AssociateResource(id uuid.UUID, resourceName resource.N
 	m.sessionResourceMu.Unlock()
 }
 
+// AcquireLease leases a resource to a session for ttl, or for as long as the session lasts if ttl
+// is zero. Acquiring a lease the session already holds renews it.
+func (m *SessionManager) AcquireLease(
+	ctx context.Context,
+	id uuid.UUID,
+	resourceName resource.Name,
+	holder string,
+	ttl time.Duration,
+) (session.Lease, error) {
+	if ttl < 0 {
+		return session.Lease{}, errors.Errorf("lease duration must not be negative, got %v", ttl)
+	}
+	m.sessionResourceMu.Lock()
+	defer m.sessionResourceMu.Unlock()
+
+	now := time.Now()
+	if sess, ok := m.sessions[id]; !ok || !sess.Active(now) {
+		return session.Lease{}, session.ErrNoSession
+	}
+	if lease, ok := m.leases[resourceName]; ok && lease.SessionID != id && lease.Active(now) {
+		return session.Lease{}, session.NewResourceLeasedError(lease)
+	}
+	lease := session.Lease{Resource: resourceName, SessionID: id, Holder: holder}
+	if ttl != 0 {
+		lease.Expires = now.Add(ttl)
+	}
+	m.leases[resourceName] = lease
+	m.logger.CDebugw(ctx, "resource leased", "resource", resourceName, "holder", holder, "expires", lease.Expires)
+	return lease, nil
+}
+
+// RenewLease extends a lease held by a session by ttl from now, or for as long as the session
+// lasts if ttl is zero.
+func (m *SessionManager) RenewLease(
+	ctx context.Context,
+	id uuid.UUID,
+	resourceName resource.Name,
+	ttl time.Duration,
+) (session.Lease, error) {
+	if ttl < 0 {
+		return session.Lease{}, errors.Errorf("lease duration must not be negative, got %v", ttl)
+	}
+	m.sessionResourceMu.Lock()
+	defer m.sessionResourceMu.Unlock()
+
+	now := time.Now()
+	lease, err := m.heldLease(id, resourceName, now)
+	if err != nil {
+		return session.Lease{}, err
+	}
+	lease.Expires = time.Time{}
+	if ttl != 0 {
+		lease.Expires = now.Add(ttl)
+	}
+	m.leases[resourceName] = lease
+	return lease, nil
+}
+
+// ReleaseLease ends a lease held by a session. Releasing a resource which is not leased has no
+// effect.
+func (m *SessionManager) ReleaseLease(ctx context.Context, id uuid.UUID, resourceName resource.Name) error {
+	m.sessionResourceMu.Lock()
+	defer m.sessionResourceMu.Unlock()
+
+	lease, ok := m.leases[resourceName]
+	if !ok || !lease.Active(time.Now()) {
+		return nil
+	}
+	if lease.SessionID != id {
+		return session.NewResourceLeasedError(lease)
+	}
+	delete(m.leases, resourceName)
+	m.logger.CDebugw(ctx, "resource lease released", "resource", resourceName, "holder", lease.Holder)
+	return nil
+}
+
+// heldLease returns the active lease of the resource if the session holds it.
+func (m *SessionManager) heldLease(id uuid.UUID, resourceName resource.Name, now time.Time) (session.Lease, error) {
+	lease, ok := m.leases[resourceName]
+	if !ok || !lease.Active(now) {
+		return session.Lease{}, errors.Errorf("resource %q is not leased", resourceName.String())
+	}
+	if lease.SessionID != id {
+		return session.Lease{}, session.NewResourceLeasedError(lease)
+	}
+	return lease, nil
+}
+
+// Leases returns every active lease, sorted by resource name.
+func (m *SessionManager) Leases() []session.Lease {
+	m.sessionResourceMu.RLock()
+	defer m.sessionResourceMu.RUnlock()
+	now := time.Now()
+	leases := make([]session.Lease, 0, len(m.leases))
+	for _, lease := range m.leases {
+		if lease.Active(now) {
+			leases = append(leases, lease)
+		}
+	}
+	slices.SortFunc(leases, func(a, b session.Lease) int {
+		return cmp.Compare(a.Resource.String(), b.Resource.String())
+	})
+	return leases
+}
+
+// CheckLease returns a *session.ResourceLeasedError if the resource is leased by a session other
+// than the one given. The session interceptors call it for safety monitored methods of the
+// resource named in a request, so resources called in process bypass leases.
+func (m *SessionManager) CheckLease(id uuid.UUID, resourceName resource.Name) error {
+	m.sessionResourceMu.RLock()
+	defer m.sessionResourceMu.RUnlock()
+	if lease, ok := m.leases[resourceName]; ok && lease.SessionID != id && lease.Active(time.Now()) {
+		return session.NewResourceLeasedError(lease)
+	}
+	return nil
+}
+
 // Close stops the session manager but will not explicitly expire any sessions.
 func (m *SessionManager) Close() {
 	m.workers.Stop()
@@ -2,14 +2,17 @@ package robot_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"go.viam.com/test"
 	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/testutils/inject"
@@ -87,3 +90,83 @@ func TestSessionManagerExpiredSessions(t *testing.T) {
 			test.ShouldEqual, 1)
 	})
 }
+
+func TestSessionManagerLeases(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	r := &inject.Robot{}
+	r.LoggerFunc = func() logging.Logger {
+		return logger
+	}
+
+	sm := robot.NewSessionManager(r, config.DefaultSessionHeartbeatWindow)
+	defer sm.Close()
+
+	fooSess, err := sm.Start(ctx, "foo")
+	test.That(t, err, test.ShouldBeNil)
+	barSess, err := sm.Start(ctx, "bar")
+	test.That(t, err, test.ShouldBeNil)
+
+	arm1 := resource.NewName(resource.APINamespaceRDK.WithComponentType("arm"), "arm1")
+	lease, err := sm.AcquireLease(ctx, fooSess.ID(), arm1, "foo", 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lease.Expires.IsZero(), test.ShouldBeTrue)
+	test.That(t, sm.CheckLease(fooSess.ID(), arm1), test.ShouldBeNil)
+
+	// everyone else, including requests without a session, is refused
+	var leasedErr *session.ResourceLeasedError
+	test.That(t, errors.As(sm.CheckLease(barSess.ID(), arm1), &leasedErr), test.ShouldBeTrue)
+	test.That(t, leasedErr.Holder, test.ShouldEqual, "foo")
+	test.That(t, sm.CheckLease(uuid.Nil, arm1), test.ShouldNotBeNil)
+	_, err = sm.AcquireLease(ctx, barSess.ID(), arm1, "bar", 0)
+	test.That(t, errors.As(err, &leasedErr), test.ShouldBeTrue)
+	_, err = sm.RenewLease(ctx, barSess.ID(), arm1, time.Minute)
+	test.That(t, errors.As(err, &leasedErr), test.ShouldBeTrue)
+	test.That(t, errors.As(sm.ReleaseLease(ctx, barSess.ID(), arm1), &leasedErr), test.ShouldBeTrue)
+
+	_, err = sm.AcquireLease(ctx, uuid.New(), arm1, "unknown", 0)
+	test.That(t, err, test.ShouldBeError, session.ErrNoSession)
+	_, err = sm.AcquireLease(ctx, fooSess.ID(), arm1, "foo", -time.Second)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a lease which is not renewed in time ends
+	lease, err = sm.RenewLease(ctx, fooSess.ID(), arm1, 50*time.Millisecond)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lease.Expires.IsZero(), test.ShouldBeFalse)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, sm.Leases(), test.ShouldBeEmpty)
+	})
+	test.That(t, sm.CheckLease(barSess.ID(), arm1), test.ShouldBeNil)
+	_, err = sm.RenewLease(ctx, fooSess.ID(), arm1, time.Minute)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = sm.AcquireLease(ctx, barSess.ID(), arm1, "bar", 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sm.ReleaseLease(ctx, barSess.ID(), arm1), test.ShouldBeNil)
+	test.That(t, sm.ReleaseLease(ctx, barSess.ID(), arm1), test.ShouldBeNil)
+	test.That(t, sm.Leases(), test.ShouldBeEmpty)
+}
+
+func TestSessionManagerLeasesEndWithSession(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	r := &inject.Robot{}
+	r.LoggerFunc = func() logging.Logger {
+		return logger
+	}
+
+	sm := robot.NewSessionManager(r, 100*time.Millisecond)
+	defer sm.Close()
+
+	sess, err := sm.Start(ctx, "foo")
+	test.That(t, err, test.ShouldBeNil)
+	arm1 := resource.NewName(resource.APINamespaceRDK.WithComponentType("arm"), "arm1")
+	_, err = sm.AcquireLease(ctx, sess.ID(), arm1, "foo", time.Hour)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sm.Leases(), test.ShouldHaveLength, 1)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, sm.Leases(), test.ShouldBeEmpty)
+	})
+	test.That(t, sm.CheckLease(uuid.Nil, arm1), test.ShouldBeNil)
+}
@@ -626,6 +626,100 @@ func TestSessionsMixedClients(t *testing.T) {
 	test.That(t, r.Close(ctx), test.ShouldBeNil)
 }
 
+func TestSessionsResourceLeases(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	motor1Name := motor.Named("motor1")
+	dummyMotor1 := dummyMotor{Named: motor1Name.AsNamed(), stopCh: make(chan struct{}, 10)}
+	resource.RegisterComponent(
+		motor.API,
+		model,
+		resource.Registration[motor.Motor, resource.NoNativeConfig]{
+			Constructor: func(
+				ctx context.Context,
+				deps resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (motor.Motor, error) {
+				return &dummyMotor1, nil
+			},
+		})
+	defer resource.Deregister(motor.API, model)
+
+	cfg := &config.Config{
+		Components: []resource.Config{{Name: "motor1", API: motor.API, Model: model}},
+	}
+	ctx := context.Background()
+	r, err := robotimpl.New(ctx, cfg, nil, logger.Sublogger("main"))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, r.Close(ctx), test.ShouldBeNil)
+	}()
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	// a scheduler and a teleop client share the motor
+	scheduler, err := client.New(ctx, addr, logger.Sublogger("scheduler"))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, scheduler.Close(ctx), test.ShouldBeNil)
+	}()
+	teleop, err := client.New(ctx, addr, logger.Sublogger("teleop"))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, teleop.Close(ctx), test.ShouldBeNil)
+	}()
+	motorScheduler, err := motor.FromProvider(scheduler, "motor1")
+	test.That(t, err, test.ShouldBeNil)
+	motorTeleop, err := motor.FromProvider(teleop, "motor1")
+	test.That(t, err, test.ShouldBeNil)
+
+	lease, err := scheduler.AcquireLease(ctx, motor1Name, "scheduler", time.Minute)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lease.Resource, test.ShouldEqual, motor1Name.String())
+	test.That(t, lease.Holder, test.ShouldEqual, "scheduler")
+	test.That(t, lease.HeldByCaller, test.ShouldBeTrue)
+	test.That(t, lease.Expires, test.ShouldNotBeNil)
+
+	test.That(t, motorScheduler.SetPower(ctx, 50, nil), test.ShouldBeNil)
+
+	// the teleop client can neither command the motor nor take the lease, but can still stop it
+	err = motorTeleop.SetPower(ctx, 20, nil)
+	var leasedErr *session.ResourceLeasedError
+	test.That(t, errors.As(err, &leasedErr), test.ShouldBeTrue)
+	test.That(t, leasedErr.Resource, test.ShouldResemble, motor1Name)
+	test.That(t, leasedErr.Holder, test.ShouldEqual, "scheduler")
+	test.That(t, leasedErr.Expires.Equal(*lease.Expires), test.ShouldBeTrue)
+
+	_, err = teleop.AcquireLease(ctx, motor1Name, "teleop", 0)
+	test.That(t, errors.As(err, &leasedErr), test.ShouldBeTrue)
+	test.That(t, teleop.ReleaseLease(ctx, motor1Name), test.ShouldNotBeNil)
+	test.That(t, motorTeleop.Stop(ctx, nil), test.ShouldBeNil)
+
+	leases, err := teleop.Leases(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, leases, test.ShouldHaveLength, 1)
+	test.That(t, leases[0].Holder, test.ShouldEqual, "scheduler")
+	test.That(t, leases[0].HeldByCaller, test.ShouldBeFalse)
+
+	renewed, err := scheduler.RenewLease(ctx, motor1Name, 2*time.Minute)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, renewed.Expires.After(*lease.Expires), test.ShouldBeTrue)
+
+	// once released, the teleop client takes over
+	test.That(t, scheduler.ReleaseLease(ctx, motor1Name), test.ShouldBeNil)
+	test.That(t, motorTeleop.SetPower(ctx, 20, nil), test.ShouldBeNil)
+	_, err = teleop.AcquireLease(ctx, motor1Name, "", 0)
+	test.That(t, err, test.ShouldBeNil)
+	err = motorScheduler.SetPower(ctx, 50, nil)
+	test.That(t, errors.As(err, &leasedErr), test.ShouldBeTrue)
+	// without a holder, the lease is held by the entity the client authenticated as
+	test.That(t, leasedErr.Holder, test.ShouldNotBeEmpty)
+	test.That(t, leasedErr.Expires.IsZero(), test.ShouldBeTrue)
+}
+
 func TestSessionsMixedOwnersNoAuth(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 	stopChMotor1 := make(chan struct{})
@@ -62,6 +62,12 @@ func IsSafetyHeartbeatMonitored(method string) bool {
 	return false
 }
 
+// IsLeaseServiceMethod returns whether the gRPC method is one of LeaseServiceName, whose requests
+// are bound to the session of the client.
+func IsLeaseServiceMethod(method string) bool {
+	return strings.HasPrefix(method, "/"+LeaseServiceName+"/")
+}
+
 func (m *SessionManager) safetyMonitoredResourceFromUnary(req interface{}, method string) resource.Name {
 	subType, _, ok := m.safetyMonitoredTypeAndMethod(method)
 	if !ok {
@@ -153,6 +159,14 @@ func (m *SessionManager) UnaryServerInterceptor(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	if IsLeaseServiceMethod(info.FullMethod) {
+		// leases are held by the session of the request
+		ctx, err := associateSession(ctx, m, resource.Name{}, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
 	if _, _, isMonitored := m.safetyMonitoredTypeAndMethod(info.FullMethod); !isMonitored {
 		return handler(ctx, req)
 	}
@@ -202,6 +216,11 @@ func associateSession(
 		// a resource with a monitored method as long as no error happened.
 		defer func() {
 			if err == nil {
+				// only the session leasing a resource, if any, may use its monitored methods
+				if err = m.CheckLease(sessID, safetyMonitoredResourceName); err != nil {
+					nextCtx = nil
+					return
+				}
 				m.AssociateResource(sessID, safetyMonitoredResourceName)
 			}
 		}()
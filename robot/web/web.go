@@ -491,6 +491,38 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		return err
 	}
 
+	if err := svc.rpcServer.RegisterServiceServer(
+		ctx,
+		&grpcserver.ResourceGraphServiceDesc,
+		grpcserver.NewResourceGraphServer(svc.r),
+	); err != nil {
+		return err
+	}
+
+	if err := svc.rpcServer.RegisterServiceServer(
+		ctx,
+		&grpcserver.LeaseServiceDesc,
+		grpcserver.NewLeaseServer(svc.r),
+	); err != nil {
+		return err
+	}
+
+	if err := svc.rpcServer.RegisterServiceServer(
+		ctx,
+		&grpcserver.OperationHistoryServiceDesc,
+		grpcserver.NewOperationHistoryServer(svc.r),
+	); err != nil {
+		return err
+	}
+
+	if err := svc.rpcServer.RegisterServiceServer(
+		ctx,
+		&grpcserver.LogServiceDesc,
+		grpcserver.NewLogServer(svc.r),
+	); err != nil {
+		return err
+	}
+
 	if err := svc.initAPIResourceCollections(ctx, svc.rpcServer); err != nil {
 		return err
 	}
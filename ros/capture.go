@@ -0,0 +1,281 @@
+package ros
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/common/v1"
+	pb "go.viam.com/api/component/movementsensor/v1"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+// defaultMaxCaptureFileSize matches the data manager's default maximum_capture_file_size_bytes.
+const defaultMaxCaptureFileSize = 256 * 1024
+
+// CaptureConversionConfig configures how ConvertMCAPToCaptureFiles turns the topics of a ROS 2 bag
+// into data capture files.
+type CaptureConversionConfig struct {
+	// CaptureDir is the directory the capture files are written under, laid out like the capture
+	// directory of the data manager so that it syncs them.
+	CaptureDir string
+	// Topics limits the conversion to these topics. Empty converts every topic of a supported type.
+	Topics []string
+	// ComponentNames maps topics to the names of the components their messages are captured from.
+	// Other topics are named after the topic, e.g. "/imu/data" becomes "imu_data". Topics of
+	// movement sensor messages mapped to the same name are captured from one movement sensor.
+	ComponentNames map[string]string
+	// Tags are added to every capture file.
+	Tags []string
+	// MaxCaptureFileSize is the size in bytes tabular capture files grow to before a new one is
+	// started. Defaults to the data manager's default.
+	MaxCaptureFileSize int64
+}
+
+// ConvertMCAPToCaptureFiles converts the messages of a ROS 2 bag stored in MCAP to the capture
+// files the data manager writes, so that a replaypcd camera or replay movement sensor can play the
+// bag back once they are synced:
+//   - sensor_msgs/PointCloud2 becomes NextPointCloud captures of a camera.
+//   - sensor_msgs/Image becomes ReadImage captures of a camera, as PNG.
+//   - sensor_msgs/Imu, sensor_msgs/NavSatFix and nav_msgs/Odometry become the Position,
+//     LinearVelocity, AngularVelocity, LinearAcceleration and Orientation captures of a movement
+//     sensor.
+//
+// Data is captured at the time in the header of each message. It returns the number of messages
+// converted for each topic. Topics of other types are skipped.
+func ConvertMCAPToCaptureFiles(filename string, cfg CaptureConversionConfig, logger logging.Logger) (map[string]int, error) {
+	if cfg.CaptureDir == "" {
+		return nil, errors.New("a capture directory is required")
+	}
+	if cfg.MaxCaptureFileSize <= 0 {
+		cfg.MaxCaptureFileSize = defaultMaxCaptureFileSize
+	}
+	c := &captureConverter{
+		cfg:      cfg,
+		logger:   logger,
+		decoders: make(map[uint16]*ROS2Decoder),
+		skipped:  make(map[uint16]bool),
+		buffers:  make(map[string]*data.CaptureBuffer),
+		counts:   make(map[string]int),
+	}
+	err := ReadMCAPFile(filename, c.convert)
+	for _, buffer := range c.buffers {
+		if flushErr := buffer.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.counts, nil
+}
+
+type captureConverter struct {
+	cfg    CaptureConversionConfig
+	logger logging.Logger
+	// decoders holds the decoders of channels being converted and skipped the channels which are
+	// not, by channel ID.
+	decoders map[uint16]*ROS2Decoder
+	skipped  map[uint16]bool
+	// buffers holds the capture buffers by capture directory.
+	buffers map[string]*data.CaptureBuffer
+	counts  map[string]int
+}
+
+func (c *captureConverter) decoder(channel *MCAPChannel) (*ROS2Decoder, error) {
+	if decoder, ok := c.decoders[channel.ID]; ok {
+		return decoder, nil
+	}
+	if c.skipped[channel.ID] {
+		return nil, nil
+	}
+	skip := func(reason string) (*ROS2Decoder, error) {
+		c.logger.Infow("Skipping topic", "topic", channel.Topic, "reason", reason)
+		c.skipped[channel.ID] = true
+		return nil, nil
+	}
+	if len(c.cfg.Topics) > 0 && !slices.Contains(c.cfg.Topics, channel.Topic) {
+		c.skipped[channel.ID] = true
+		return nil, nil
+	}
+	if channel.Schema == nil || channel.Schema.Encoding != "ros2msg" || channel.MessageEncoding != "cdr" {
+		return skip("not a ROS 2 message topic")
+	}
+	switch channel.Schema.Name {
+	case ROS2ImageType, ROS2PointCloud2Type, ROS2ImuType, ROS2NavSatFixType, ROS2OdometryType:
+	default:
+		return skip("unsupported message type " + channel.Schema.Name)
+	}
+	decoder, err := NewROS2Decoder(channel.Schema.Name, channel.Schema.Data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "topic %s", channel.Topic)
+	}
+	c.decoders[channel.ID] = decoder
+	return decoder, nil
+}
+
+func (c *captureConverter) componentName(topic string) string {
+	if name, ok := c.cfg.ComponentNames[topic]; ok {
+		return name
+	}
+	return strings.ReplaceAll(strings.Trim(topic, "/"), "/", "_")
+}
+
+func (c *captureConverter) convert(msg *MCAPMessage) error {
+	decoder, err := c.decoder(msg.Channel)
+	if err != nil || decoder == nil {
+		return err
+	}
+	decoded, err := decoder.Decode(msg.Data)
+	if err != nil {
+		return errors.Wrapf(err, "topic %s", msg.Channel.Topic)
+	}
+	capturedAt := ROS2HeaderTime(decoded)
+	if capturedAt.IsZero() || capturedAt.Unix() == 0 {
+		capturedAt = msg.LogTime
+	}
+	ts := data.Timestamps{TimeRequested: capturedAt, TimeReceived: capturedAt}
+	name := c.componentName(msg.Channel.Topic)
+
+	switch msg.Channel.Schema.Name {
+	case ROS2PointCloud2Type:
+		pc, err := PointCloudFromROS2(decoded)
+		if err != nil {
+			return errors.Wrapf(err, "topic %s", msg.Channel.Topic)
+		}
+		var buf bytes.Buffer
+		if err := pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary); err != nil {
+			return err
+		}
+		if err := c.writeBinary(camera.API, name, "NextPointCloud", utils.MimeTypePCD, ts, buf.Bytes()); err != nil {
+			return err
+		}
+	case ROS2ImageType:
+		img, err := ImageFromROS2(decoded)
+		if err != nil {
+			return errors.Wrapf(err, "topic %s", msg.Channel.Topic)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		if err := c.writeBinary(camera.API, name, "ReadImage", utils.MimeTypePNG, ts, buf.Bytes()); err != nil {
+			return err
+		}
+	default:
+		readings, err := MovementReadingsFromROS2(msg.Channel.Schema.Name, decoded)
+		if err != nil {
+			return err
+		}
+		if err := c.writeMovementReadings(name, ts, readings); err != nil {
+			return err
+		}
+	}
+	c.counts[msg.Channel.Topic]++
+	return nil
+}
+
+func (c *captureConverter) buffer(
+	api resource.API, name, method string, additionalParams map[string]interface{},
+) (*data.CaptureBuffer, error) {
+	dir := data.CaptureFilePathWithReplacedReservedChars(filepath.Join(c.cfg.CaptureDir, api.String(), name, method))
+	if buffer, ok := c.buffers[dir]; ok {
+		return buffer, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create capture directory %s", dir)
+	}
+	md, _ := data.BuildCaptureMetadata(api, name, method, additionalParams, nil, c.cfg.Tags)
+	buffer := data.NewCaptureBuffer(dir, md, c.cfg.MaxCaptureFileSize)
+	c.buffers[dir] = buffer
+	return buffer, nil
+}
+
+func (c *captureConverter) writeBinary(
+	api resource.API, name, method, mimeType string, ts data.Timestamps, payload []byte,
+) error {
+	buffer, err := c.buffer(api, name, method, map[string]interface{}{"mime_type": mimeType})
+	if err != nil {
+		return err
+	}
+	result := data.NewBinaryCaptureResult(ts, []data.Binary{{Payload: payload, MimeType: mimeType}})
+	for _, item := range result.ToProto() {
+		if err := buffer.WriteBinary(item, mimeType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *captureConverter) writeTabular(name, method string, ts data.Timestamps, response interface{}) error {
+	buffer, err := c.buffer(movementsensor.API, name, method, nil)
+	if err != nil {
+		return err
+	}
+	result, err := data.NewTabularCaptureResult(ts, response)
+	if err != nil {
+		return err
+	}
+	for _, item := range result.ToProto() {
+		if err := buffer.WriteTabular(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMovementReadings captures readings the way the data manager captures the methods of a
+// movement sensor.
+func (c *captureConverter) writeMovementReadings(name string, ts data.Timestamps, readings MovementReadings) error {
+	if readings.Position != nil {
+		if err := c.writeTabular(name, "Position", ts, pb.GetPositionResponse{
+			Coordinate: &v1.GeoPoint{Latitude: readings.Position.Lat(), Longitude: readings.Position.Lng()},
+			AltitudeM:  float32(readings.AltitudeM),
+		}); err != nil {
+			return err
+		}
+	}
+	if readings.LinearVelocity != nil {
+		if err := c.writeTabular(name, "LinearVelocity", ts, pb.GetLinearVelocityResponse{
+			LinearVelocity: &v1.Vector3{X: readings.LinearVelocity.X, Y: readings.LinearVelocity.Y, Z: readings.LinearVelocity.Z},
+		}); err != nil {
+			return err
+		}
+	}
+	if readings.AngularVelocity != nil {
+		if err := c.writeTabular(name, "AngularVelocity", ts, pb.GetAngularVelocityResponse{
+			AngularVelocity: &v1.Vector3{X: readings.AngularVelocity.X, Y: readings.AngularVelocity.Y, Z: readings.AngularVelocity.Z},
+		}); err != nil {
+			return err
+		}
+	}
+	if readings.LinearAcceleration != nil {
+		if err := c.writeTabular(name, "LinearAcceleration", ts, pb.GetLinearAccelerationResponse{
+			LinearAcceleration: &v1.Vector3{
+				X: readings.LinearAcceleration.X, Y: readings.LinearAcceleration.Y, Z: readings.LinearAcceleration.Z,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	if readings.Orientation != nil {
+		ov := readings.Orientation.OrientationVectorDegrees()
+		if err := c.writeTabular(name, "Orientation", ts, pb.GetOrientationResponse{
+			Orientation: &v1.Orientation{OX: ov.OX, OY: ov.OY, OZ: ov.OZ, Theta: ov.Theta},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package ros
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// mcapMagic starts and ends every MCAP file.
+var mcapMagic = []byte{0x89, 'M', 'C', 'A', 'P', '0', '\r', '\n'}
+
+// maxMCAPChunkPreallocation bounds the memory allocated up front to decompress a chunk into.
+const maxMCAPChunkPreallocation = 64 << 20
+
+// MCAP record opcodes, see https://mcap.dev/spec.
+const (
+	mcapOpFooter  = 0x02
+	mcapOpSchema  = 0x03
+	mcapOpChannel = 0x04
+	mcapOpMessage = 0x05
+	mcapOpChunk   = 0x06
+)
+
+// MCAPSchema describes the type of the messages of a channel.
+type MCAPSchema struct {
+	ID uint16
+	// Name is the message type, e.g. "sensor_msgs/msg/Imu".
+	Name string
+	// Encoding is the format of Data, e.g. "ros2msg".
+	Encoding string
+	Data     []byte
+}
+
+// MCAPChannel is a stream of messages, which is a topic in a ROS 2 bag.
+type MCAPChannel struct {
+	ID    uint16
+	Topic string
+	// MessageEncoding is the format of the messages, e.g. "cdr".
+	MessageEncoding string
+	Metadata        map[string]string
+	// Schema is nil for channels of schemaless messages.
+	Schema *MCAPSchema
+}
+
+// MCAPMessage is a message recorded in an MCAP file.
+type MCAPMessage struct {
+	Channel     *MCAPChannel
+	Sequence    uint32
+	LogTime     time.Time
+	PublishTime time.Time
+	Data        []byte
+}
+
+// ReadMCAPFile calls visit with every message of the MCAP file in the order they were written,
+// which is how ROS 2 bags are stored since Iron. It stops at the first error visit returns.
+func ReadMCAPFile(filename string, visit func(*MCAPMessage) error) error {
+	//nolint:gosec
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrapf(err, "unable to open input file")
+	}
+	defer utils.UncheckedErrorFunc(f.Close)
+	return ReadMCAP(bufio.NewReader(f), visit)
+}
+
+// ReadMCAP calls visit with every message read from r in the order they were written.
+func ReadMCAP(r io.Reader, visit func(*MCAPMessage) error) error {
+	magic := make([]byte, len(mcapMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, mcapMagic) {
+		return errors.New("not an MCAP file")
+	}
+	mr := &mcapReader{
+		schemas:  make(map[uint16]*MCAPSchema),
+		channels: make(map[uint16]*MCAPChannel),
+		visit:    visit,
+	}
+	for {
+		op, record, err := readMCAPRecord(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				// files cut short by a crash have no footer, keep what was read.
+				return nil
+			}
+			return err
+		}
+		if op == mcapOpFooter {
+			return nil
+		}
+		if err := mr.handle(op, record); err != nil {
+			return err
+		}
+	}
+}
+
+type mcapReader struct {
+	schemas  map[uint16]*MCAPSchema
+	channels map[uint16]*MCAPChannel
+	visit    func(*MCAPMessage) error
+}
+
+func readMCAPRecord(r io.Reader) (byte, []byte, error) {
+	var prefix [9]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, io.EOF
+		}
+		return 0, nil, err
+	}
+	length := binary.LittleEndian.Uint64(prefix[1:])
+	// read through a LimitReader rather than allocating length up front, which a corrupt record
+	// could make arbitrarily large.
+	record, err := io.ReadAll(io.LimitReader(r, int64(length)))
+	if err != nil {
+		return 0, nil, err
+	}
+	if uint64(len(record)) != length {
+		return 0, nil, io.EOF
+	}
+	return prefix[0], record, nil
+}
+
+func (mr *mcapReader) handle(op byte, record []byte) error {
+	rec := &mcapRecordReader{data: record}
+	switch op {
+	case mcapOpSchema:
+		schema := &MCAPSchema{ID: rec.uint16()}
+		schema.Name = rec.string()
+		schema.Encoding = rec.string()
+		schema.Data = rec.bytes(int(rec.uint32()))
+		if rec.err != nil {
+			return errors.Wrap(rec.err, "invalid MCAP schema record")
+		}
+		mr.schemas[schema.ID] = schema
+	case mcapOpChannel:
+		channel := &MCAPChannel{ID: rec.uint16()}
+		schemaID := rec.uint16()
+		channel.Topic = rec.string()
+		channel.MessageEncoding = rec.string()
+		channel.Metadata = rec.stringMap()
+		if rec.err != nil {
+			return errors.Wrap(rec.err, "invalid MCAP channel record")
+		}
+		if schemaID != 0 {
+			schema, ok := mr.schemas[schemaID]
+			if !ok {
+				return errors.Errorf("MCAP channel %q refers to unknown schema %d", channel.Topic, schemaID)
+			}
+			channel.Schema = schema
+		}
+		mr.channels[channel.ID] = channel
+	case mcapOpMessage:
+		channelID := rec.uint16()
+		msg := &MCAPMessage{Sequence: rec.uint32()}
+		msg.LogTime = time.Unix(0, int64(rec.uint64()))
+		msg.PublishTime = time.Unix(0, int64(rec.uint64()))
+		msg.Data = rec.rest()
+		if rec.err != nil {
+			return errors.Wrap(rec.err, "invalid MCAP message record")
+		}
+		channel, ok := mr.channels[channelID]
+		if !ok {
+			return errors.Errorf("MCAP message refers to unknown channel %d", channelID)
+		}
+		msg.Channel = channel
+		return mr.visit(msg)
+	case mcapOpChunk:
+		rec.uint64() // message start time
+		rec.uint64() // message end time
+		uncompressedSize := rec.uint64()
+		rec.uint32() // uncompressed crc
+		compression := rec.string()
+		records := rec.bytes(int(rec.uint64()))
+		if rec.err != nil {
+			return errors.Wrap(rec.err, "invalid MCAP chunk record")
+		}
+		decompressed, err := decompressMCAPChunk(compression, records, uncompressedSize)
+		if err != nil {
+			return err
+		}
+		chunk := bytes.NewReader(decompressed)
+		for chunk.Len() > 0 {
+			op, record, err := readMCAPRecord(chunk)
+			if err != nil {
+				return errors.Wrap(err, "invalid MCAP chunk")
+			}
+			if err := mr.handle(op, record); err != nil {
+				return err
+			}
+		}
+	default:
+		// headers, indexes, attachments, metadata and statistics are not needed to read messages.
+	}
+	return nil
+}
+
+func decompressMCAPChunk(compression string, records []byte, uncompressedSize uint64) ([]byte, error) {
+	var reader io.Reader
+	switch compression {
+	case "":
+		return records, nil
+	case "zstd":
+		decoder, err := zstd.NewReader(bytes.NewReader(records))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		reader = decoder
+	case "lz4":
+		reader = lz4.NewReader(bytes.NewReader(records))
+	default:
+		return nil, errors.Errorf("unsupported MCAP chunk compression %q", compression)
+	}
+	decompressed := bytes.NewBuffer(make([]byte, 0, min(uncompressedSize, maxMCAPChunkPreallocation)))
+	if _, err := io.Copy(decompressed, reader); err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress %s MCAP chunk", compression)
+	}
+	return decompressed.Bytes(), nil
+}
+
+// mcapRecordReader reads the little endian fields of an MCAP record. The first read past the end
+// of the record sets err, and reads after that return zero values.
+type mcapRecordReader struct {
+	data []byte
+	err  error
+}
+
+func (r *mcapRecordReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	ret := r.data[:n]
+	r.data = r.data[n:]
+	return ret
+}
+
+func (r *mcapRecordReader) rest() []byte {
+	return r.bytes(len(r.data))
+}
+
+func (r *mcapRecordReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *mcapRecordReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *mcapRecordReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *mcapRecordReader) string() string {
+	return string(r.bytes(int(r.uint32())))
+}
+
+func (r *mcapRecordReader) stringMap() map[string]string {
+	entries := &mcapRecordReader{data: r.bytes(int(r.uint32()))}
+	ret := make(map[string]string)
+	for r.err == nil && len(entries.data) > 0 {
+		key := entries.string()
+		ret[key] = entries.string()
+		r.err = entries.err
+	}
+	return ret
+}
@@ -0,0 +1,65 @@
+// Package main converts ROS 2 bags stored in MCAP to data capture files.
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/ros"
+)
+
+var logger = logging.NewLogger("mcap_converter")
+
+// Arguments for the MCAP converter.
+type Arguments struct {
+	MCAPFile   string `flag:"0,required,usage=ROS 2 bag in MCAP format"`
+	CaptureDir string `flag:"capture-dir,required,usage=directory to write capture files under such as ~/.viam/capture"`
+	Topics     string `flag:"topics,usage=comma separated topics to convert, all supported topics if unset"`
+	Components string `flag:"components,usage=comma separated topic=component pairs naming the component of a topic"`
+	Tags       string `flag:"tags,usage=comma separated tags to add to the capture files"`
+}
+
+func main() {
+	goutils.ContextualMain(mainWithArgs, logger)
+}
+
+func mainWithArgs(ctx context.Context, args []string, logger logging.Logger) error {
+	var argsParsed Arguments
+	if err := goutils.ParseFlags(args, &argsParsed); err != nil {
+		return err
+	}
+
+	cfg := ros.CaptureConversionConfig{
+		CaptureDir:     argsParsed.CaptureDir,
+		Topics:         splitList(argsParsed.Topics),
+		Tags:           splitList(argsParsed.Tags),
+		ComponentNames: make(map[string]string),
+	}
+	for _, pair := range splitList(argsParsed.Components) {
+		topic, name, ok := strings.Cut(pair, "=")
+		if !ok {
+			return errors.Errorf("invalid topic=component pair %q", pair)
+		}
+		cfg.ComponentNames[topic] = name
+	}
+
+	counts, err := ros.ConvertMCAPToCaptureFiles(argsParsed.MCAPFile, cfg, logger)
+	if err != nil {
+		return err
+	}
+	for topic, count := range counts {
+		logger.Infow("Converted topic", "topic", topic, "messages", count)
+	}
+	return nil
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
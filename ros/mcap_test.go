@@ -0,0 +1,361 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+)
+
+const (
+	testHeaderDefinition = `
+================================================================================
+MSG: std_msgs/Header
+# the stamp is left to the builtin definition of builtin_interfaces/Time.
+builtin_interfaces/Time stamp
+string frame_id`
+
+	testImuDefinition = `std_msgs/Header header
+geometry_msgs/Quaternion orientation
+float64[9] orientation_covariance # Row major about x, y, z axes
+geometry_msgs/Vector3 angular_velocity
+float64[9] angular_velocity_covariance
+geometry_msgs/Vector3 linear_acceleration
+float64[9] linear_acceleration_covariance
+================================================================================
+MSG: geometry_msgs/Quaternion
+float64 x 0
+float64 y 0
+float64 z 0
+float64 w 1
+================================================================================
+MSG: geometry_msgs/Vector3
+float64 x
+float64 y
+float64 z` + testHeaderDefinition
+
+	testPointCloud2Definition = `std_msgs/Header header
+uint32 height
+uint32 width
+PointField[] fields
+bool    is_bigendian
+uint32  point_step
+uint32  row_step
+uint8[] data
+bool is_dense
+================================================================================
+MSG: sensor_msgs/PointField
+uint8 INT8    = 1
+uint8 FLOAT32 = 7
+string name
+uint32 offset
+uint8  datatype
+uint32 count` + testHeaderDefinition
+
+	testNavSatFixDefinition = `std_msgs/Header header
+NavSatStatus status
+float64 latitude
+float64 longitude
+float64 altitude
+float64[9] position_covariance
+uint8 position_covariance_type
+================================================================================
+MSG: sensor_msgs/NavSatStatus
+int8 STATUS_NO_FIX =  -1
+int8 status
+uint16 service` + testHeaderDefinition
+)
+
+// cdrWriter serializes messages the way ROS 2 does, as little endian CDR.
+type cdrWriter struct {
+	buf []byte
+}
+
+func (w *cdrWriter) align(n int) {
+	for len(w.buf)%n != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *cdrWriter) uint8(v uint8) {
+	w.buf = append(w.buf, v)
+}
+
+func (w *cdrWriter) uint16(v uint16) {
+	w.align(2)
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, v)
+}
+
+func (w *cdrWriter) uint32(v uint32) {
+	w.align(4)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+func (w *cdrWriter) float64(v float64) {
+	w.align(8)
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(v))
+}
+
+func (w *cdrWriter) string(v string) {
+	w.uint32(uint32(len(v) + 1))
+	w.buf = append(append(w.buf, v...), 0)
+}
+
+func (w *cdrWriter) header(sec uint32) {
+	w.uint32(sec)
+	w.uint32(500)
+	w.string("base_link")
+}
+
+func (w *cdrWriter) message() []byte {
+	return append([]byte{0x00, 0x01, 0x00, 0x00}, w.buf...)
+}
+
+// mcapWriter writes MCAP records, optionally into a chunk.
+type mcapWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *mcapWriter) record(op byte, fields ...any) {
+	var content []byte
+	for _, field := range fields {
+		switch f := field.(type) {
+		case uint16:
+			content = binary.LittleEndian.AppendUint16(content, f)
+		case uint32:
+			content = binary.LittleEndian.AppendUint32(content, f)
+		case uint64:
+			content = binary.LittleEndian.AppendUint64(content, f)
+		case string:
+			content = binary.LittleEndian.AppendUint32(content, uint32(len(f)))
+			content = append(content, f...)
+		case []byte:
+			content = append(content, f...)
+		}
+	}
+	w.buf.WriteByte(op)
+	w.buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(content))))
+	w.buf.Write(content)
+}
+
+func (w *mcapWriter) channel(id uint16, topic, typeName, definition string) {
+	w.record(mcapOpSchema, id, typeName, "ros2msg", uint32(len(definition)), []byte(definition))
+	// channels have no metadata.
+	w.record(mcapOpChannel, id, id, topic, "cdr", uint32(0))
+}
+
+func (w *mcapWriter) message(channelID uint16, sequence uint32, data []byte) {
+	w.record(mcapOpMessage, channelID, sequence, uint64(sequence), uint64(sequence), data)
+}
+
+func writeTestMCAP(t *testing.T) string {
+	t.Helper()
+	var file mcapWriter
+	file.buf.Write(mcapMagic)
+	file.record(0x01, "ros2", "test")
+
+	// the IMU is recorded in a compressed chunk, the others outside of chunks.
+	var chunk mcapWriter
+	chunk.channel(1, "/imu/data", ROS2ImuType, testImuDefinition)
+	for i := range 3 {
+		var w cdrWriter
+		w.header(uint32(1700000000 + i))
+		for _, v := range []float64{0, 0, 0, 1} {
+			w.float64(v)
+		}
+		for range 9 {
+			w.float64(0)
+		}
+		for _, v := range []float64{math.Pi, 0, 0} {
+			w.float64(v)
+		}
+		for range 9 {
+			w.float64(0)
+		}
+		for _, v := range []float64{0, 0, 9.8} {
+			w.float64(v)
+		}
+		// the IMU has no acceleration estimate.
+		w.float64(-1)
+		for range 8 {
+			w.float64(0)
+		}
+		chunk.message(1, uint32(i), w.message())
+	}
+	encoder, err := zstd.NewWriter(nil)
+	test.That(t, err, test.ShouldBeNil)
+	records := chunk.buf.Bytes()
+	compressed := encoder.EncodeAll(records, nil)
+	test.That(t, encoder.Close(), test.ShouldBeNil)
+	file.record(mcapOpChunk, uint64(0), uint64(2), uint64(len(records)), uint32(0), "zstd", uint64(len(compressed)), compressed)
+
+	file.channel(2, "/lidar/points", ROS2PointCloud2Type, testPointCloud2Definition)
+	var w cdrWriter
+	w.header(1700000001)
+	w.uint32(1) // height
+	w.uint32(2) // width
+	w.uint32(3) // fields
+	for i, name := range []string{"x", "y", "z"} {
+		w.string(name)
+		w.uint32(uint32(4 * i))
+		w.uint8(pointFieldFloat32)
+		w.uint32(1)
+	}
+	w.uint8(0)   // is_bigendian
+	w.uint32(12) // point_step
+	w.uint32(24) // row_step
+	w.uint32(24) // data
+	for _, v := range []float32{1, 2, 3, float32(math.NaN()), 0, 0} {
+		w.buf = binary.LittleEndian.AppendUint32(w.buf, math.Float32bits(v))
+	}
+	w.uint8(0) // is_dense
+	file.message(2, 0, w.message())
+
+	file.channel(3, "/gps/fix", ROS2NavSatFixType, testNavSatFixDefinition)
+	for i, status := range []uint8{0, 0xff} {
+		var w cdrWriter
+		w.header(uint32(1700000000 + i))
+		w.uint8(status)
+		w.uint16(1)
+		w.float64(40.7)
+		w.float64(-74)
+		w.float64(10)
+		for range 9 {
+			w.float64(0)
+		}
+		w.uint8(0)
+		file.message(3, uint32(i), w.message())
+	}
+
+	file.channel(4, "/rosout", "rcl_interfaces/msg/Log", "uint8 level")
+	file.message(4, 0, []byte{0x00, 0x01, 0x00, 0x00, 20})
+
+	file.record(mcapOpFooter, uint64(0), uint64(0), uint32(0))
+	file.buf.Write(mcapMagic)
+
+	filename := filepath.Join(t.TempDir(), "bag.mcap")
+	test.That(t, os.WriteFile(filename, file.buf.Bytes(), 0o600), test.ShouldBeNil)
+	return filename
+}
+
+func TestReadMCAP(t *testing.T) {
+	filename := writeTestMCAP(t)
+
+	topics := map[string]int{}
+	var imu map[string]any
+	err := ReadMCAPFile(filename, func(msg *MCAPMessage) error {
+		topics[msg.Channel.Topic]++
+		if msg.Channel.Topic == "/imu/data" && imu == nil {
+			decoder, err := NewROS2Decoder(msg.Channel.Schema.Name, msg.Channel.Schema.Data)
+			if err != nil {
+				return err
+			}
+			imu, err = decoder.Decode(msg.Data)
+			return err
+		}
+		return nil
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, topics, test.ShouldResemble, map[string]int{"/imu/data": 3, "/lidar/points": 1, "/gps/fix": 2, "/rosout": 1})
+
+	test.That(t, ROS2HeaderTime(imu).Unix(), test.ShouldEqual, 1700000000)
+	test.That(t, ros2Struct(imu, "header")["frame_id"], test.ShouldEqual, "base_link")
+	test.That(t, ros2Struct(imu, "angular_velocity")["x"], test.ShouldEqual, math.Pi)
+	test.That(t, imu["orientation_covariance"], test.ShouldHaveLength, 9)
+
+	err = ReadMCAP(bytes.NewReader([]byte("not a bag")), func(*MCAPMessage) error { return nil })
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestROS2Decoder(t *testing.T) {
+	_, err := NewROS2Decoder("sensor_msgs/msg/Imu", []byte("std_msgs/Header header"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "missing type std_msgs/Header")
+
+	decoder, err := NewROS2Decoder("test_msgs/msg/Bounded", []byte("string<=4 name\nint32[<=3] values\nuint8 LIMIT=3"))
+	test.That(t, err, test.ShouldBeNil)
+	var w cdrWriter
+	w.string("abc")
+	w.uint32(2)
+	w.uint32(7)
+	w.uint32(0xffffffff)
+	msg, err := decoder.Decode(w.message())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, msg, test.ShouldResemble, map[string]any{"name": "abc", "values": []any{int32(7), int32(-1)}})
+
+	// a sequence longer than the message is rejected rather than allocated.
+	w = cdrWriter{}
+	w.string("abc")
+	w.uint32(1 << 30)
+	_, err = decoder.Decode(w.message())
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestConvertMCAPToCaptureFiles(t *testing.T) {
+	filename := writeTestMCAP(t)
+	captureDir := t.TempDir()
+	counts, err := ConvertMCAPToCaptureFiles(filename, CaptureConversionConfig{
+		CaptureDir:     captureDir,
+		ComponentNames: map[string]string{"/gps/fix": "imu_data"},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, counts, test.ShouldResemble, map[string]int{"/imu/data": 3, "/lidar/points": 1, "/gps/fix": 2})
+
+	captures := func(component, method string) []*v1.SensorData {
+		t.Helper()
+		paths, err := filepath.Glob(filepath.Join(captureDir, "*", component, method, "*"+data.CompletedCaptureFileExt))
+		test.That(t, err, test.ShouldBeNil)
+		var ret []*v1.SensorData
+		for _, path := range paths {
+			sensorData, err := data.SensorDataFromCaptureFilePath(path)
+			test.That(t, err, test.ShouldBeNil)
+			ret = append(ret, sensorData...)
+		}
+		return ret
+	}
+	// tabular payloads are compared as messages since their JSON encoding is not stable.
+	shouldEqualStruct := func(actual *structpb.Struct, expected map[string]any) {
+		t.Helper()
+		expectedStruct, err := structpb.NewStruct(expected)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, proto.Equal(actual, expectedStruct), test.ShouldBeTrue)
+	}
+
+	angularVelocity := captures("imu_data", "AngularVelocity")
+	test.That(t, angularVelocity, test.ShouldHaveLength, 3)
+	shouldEqualStruct(angularVelocity[0].GetStruct(), map[string]any{
+		"angular_velocity": map[string]any{"x": 180, "y": 0, "z": 0},
+	})
+	test.That(t, captures("imu_data", "Orientation"), test.ShouldHaveLength, 3)
+	test.That(t, captures("imu_data", "LinearAcceleration"), test.ShouldBeEmpty)
+	// the GPS has no fix in its second message.
+	position := captures("imu_data", "Position")
+	test.That(t, position, test.ShouldHaveLength, 1)
+	shouldEqualStruct(position[0].GetStruct(), map[string]any{
+		"altitude_m": 10,
+		"coordinate": map[string]any{"latitude": 40.7, "longitude": -74},
+	})
+
+	pcds := captures("lidar_points", "NextPointCloud")
+	test.That(t, pcds, test.ShouldHaveLength, 1)
+	pc, err := pointcloud.ReadPCD(bytes.NewReader(pcds[0].GetBinary()), pointcloud.BasicType)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 1)
+	_, ok := pc.At(1000, 2000, 3000)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	_, err = ConvertMCAPToCaptureFiles(filename, CaptureConversionConfig{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,335 @@
+package ros
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ros2MsgSeparator separates the definitions of the types a ROS 2 message depends on in a
+// "ros2msg" schema.
+const ros2MsgSeparator = "================================================================================"
+
+// builtinROS2Definitions are used when a schema leaves out the definition of a type every ROS 2
+// installation has.
+var builtinROS2Definitions = map[string]string{
+	"builtin_interfaces/Time":     "int32 sec\nuint32 nanosec",
+	"builtin_interfaces/Duration": "int32 sec\nuint32 nanosec",
+}
+
+// primitiveROS2Types are the types of ROS 2 fields which are not messages, other than strings.
+var primitiveROS2Types = map[string]struct{}{
+	"bool": {}, "byte": {}, "char": {},
+	"int8": {}, "uint8": {}, "int16": {}, "uint16": {}, "int32": {}, "uint32": {}, "int64": {}, "uint64": {},
+	"float32": {}, "float64": {},
+}
+
+type ros2Field struct {
+	name string
+	// typeName is a primitive type or the full name of a message type, e.g. "std_msgs/Header".
+	typeName string
+	isArray  bool
+	// arrayLen is the length of fixed size arrays, and zero for sequences.
+	arrayLen int
+}
+
+// ROS2Decoder decodes CDR serialized ROS 2 messages of one type, as recorded in ROS 2 bags, into
+// maps of field names to values. Integers and floats keep the width of their ROS type, byte
+// arrays become []byte, other arrays []any and nested messages map[string]any.
+type ROS2Decoder struct {
+	typeName string
+	types    map[string][]ros2Field
+}
+
+// NewROS2Decoder returns a decoder for messages of the named type, e.g. "sensor_msgs/msg/Imu",
+// given the definition of the type and the types it depends on in the "ros2msg" format.
+func NewROS2Decoder(typeName string, definition []byte) (*ROS2Decoder, error) {
+	d := &ROS2Decoder{typeName: normalizeROS2TypeName(typeName), types: make(map[string][]ros2Field)}
+
+	currentType := d.typeName
+	var section []string
+	addSection := func() error {
+		fields, err := parseROS2Fields(currentType, section)
+		if err != nil {
+			return err
+		}
+		d.types[currentType] = fields
+		return nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(definition))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == ros2MsgSeparator {
+			if err := addSection(); err != nil {
+				return nil, err
+			}
+			section = nil
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "MSG:"); ok {
+			currentType = normalizeROS2TypeName(strings.TrimSpace(name))
+			continue
+		}
+		section = append(section, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if _, ok := d.types[currentType]; !ok || len(section) > 0 {
+		if err := addSection(); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, definition := range builtinROS2Definitions {
+		if _, ok := d.types[name]; !ok {
+			fields, err := parseROS2Fields(name, strings.Split(definition, "\n"))
+			if err != nil {
+				return nil, err
+			}
+			d.types[name] = fields
+		}
+	}
+	for name, fields := range d.types {
+		for _, field := range fields {
+			if _, ok := primitiveROS2Types[field.typeName]; ok || field.typeName == "string" {
+				continue
+			}
+			if _, ok := d.types[field.typeName]; !ok {
+				return nil, errors.Errorf("definition of %s is missing type %s of field %s", name, field.typeName, field.name)
+			}
+		}
+	}
+	return d, nil
+}
+
+// normalizeROS2TypeName turns "sensor_msgs/msg/Imu" into "sensor_msgs/Imu", the form used by the
+// fields of definitions.
+func normalizeROS2TypeName(name string) string {
+	return strings.Replace(name, "/msg/", "/", 1)
+}
+
+func parseROS2Fields(typeName string, lines []string) ([]ros2Field, error) {
+	pkg, _, _ := strings.Cut(typeName, "/")
+	var fields []ros2Field
+	for _, line := range lines {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		tokens := strings.Fields(line)
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(tokens) < 2 {
+			return nil, errors.Errorf("invalid field %q in definition of %s", line, typeName)
+		}
+		// constants, e.g. "uint8 STATUS_FIX=0", are not part of serialized messages.
+		if strings.Contains(tokens[1], "=") || (len(tokens) > 2 && strings.HasPrefix(tokens[2], "=")) {
+			continue
+		}
+
+		field := ros2Field{name: tokens[1]}
+		fieldType := tokens[0]
+		if i := strings.IndexByte(fieldType, '['); i >= 0 {
+			field.isArray = true
+			bound := strings.TrimSuffix(fieldType[i+1:], "]")
+			fieldType = fieldType[:i]
+			// bounded sequences, e.g. "int32[<=5]", are serialized like unbounded ones.
+			if bound != "" && !strings.HasPrefix(bound, "<=") {
+				n, err := strconv.Atoi(bound)
+				if err != nil {
+					return nil, errors.Errorf("invalid array length in field %q of %s", line, typeName)
+				}
+				field.arrayLen = n
+			}
+		}
+		// bounded strings, e.g. "string<=10", are serialized like unbounded ones.
+		fieldType, _, _ = strings.Cut(fieldType, "<=")
+
+		switch _, primitive := primitiveROS2Types[fieldType]; {
+		case primitive || fieldType == "string":
+		case fieldType == "wstring":
+			return nil, errors.Errorf("field %s of %s: wstring is not supported", field.name, typeName)
+		case fieldType == "Header":
+			// ROS 1 style definitions leave out the package of the header.
+			fieldType = "std_msgs/Header"
+		case !strings.Contains(fieldType, "/"):
+			// types of the same package leave it out.
+			fieldType = pkg + "/" + fieldType
+		default:
+			fieldType = normalizeROS2TypeName(fieldType)
+		}
+		field.typeName = fieldType
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Decode decodes a CDR serialized message.
+func (d *ROS2Decoder) Decode(data []byte) (map[string]any, error) {
+	if len(data) < 4 {
+		return nil, errors.New("CDR message is too short")
+	}
+	r := &cdrReader{data: data[4:], order: binary.BigEndian, maxAlign: 8}
+	// the encapsulation header says whether the message is little endian and uses plain CDR or
+	// plain XCDR2, which aligns 8 byte values to 4 bytes.
+	switch data[1] {
+	case 0x00:
+	case 0x01:
+		r.order = binary.LittleEndian
+	case 0x06:
+		r.maxAlign = 4
+	case 0x07:
+		r.order = binary.LittleEndian
+		r.maxAlign = 4
+	default:
+		return nil, errors.Errorf("unsupported CDR encapsulation %#x", data[1])
+	}
+	msg := d.decodeMessage(r, d.typeName)
+	if r.err != nil {
+		return nil, errors.Wrapf(r.err, "failed to decode %s message", d.typeName)
+	}
+	return msg, nil
+}
+
+func (d *ROS2Decoder) decodeMessage(r *cdrReader, typeName string) map[string]any {
+	fields := d.types[typeName]
+	msg := make(map[string]any, len(fields))
+	if len(fields) == 0 {
+		// messages without fields are serialized with a single placeholder byte.
+		r.uint8()
+		return msg
+	}
+	for _, field := range fields {
+		if !field.isArray {
+			msg[field.name] = d.decodeValue(r, field.typeName)
+			continue
+		}
+		n := field.arrayLen
+		if n == 0 {
+			n = int(r.uint32())
+		}
+		// every element takes at least a byte, which bounds what a corrupt length can allocate.
+		if n > len(r.data)-r.pos {
+			r.fail()
+		}
+		if r.err != nil {
+			return msg
+		}
+		switch field.typeName {
+		case "uint8", "byte", "char":
+			msg[field.name] = bytes.Clone(r.bytes(n))
+		default:
+			values := make([]any, n)
+			for i := range values {
+				values[i] = d.decodeValue(r, field.typeName)
+			}
+			msg[field.name] = values
+		}
+	}
+	return msg
+}
+
+func (d *ROS2Decoder) decodeValue(r *cdrReader, typeName string) any {
+	switch typeName {
+	case "bool":
+		return r.uint8() != 0
+	case "byte", "char", "uint8":
+		return r.uint8()
+	case "int8":
+		return int8(r.uint8())
+	case "int16":
+		return int16(r.uint16())
+	case "uint16":
+		return r.uint16()
+	case "int32":
+		return int32(r.uint32())
+	case "uint32":
+		return r.uint32()
+	case "int64":
+		return int64(r.uint64())
+	case "uint64":
+		return r.uint64()
+	case "float32":
+		return math.Float32frombits(r.uint32())
+	case "float64":
+		return math.Float64frombits(r.uint64())
+	case "string":
+		n := int(r.uint32())
+		return strings.TrimSuffix(string(r.bytes(n)), "\x00")
+	default:
+		return d.decodeMessage(r, typeName)
+	}
+}
+
+// cdrReader reads CDR serialized values. The first read past the end of the data sets err, and
+// reads after that return zero values.
+type cdrReader struct {
+	data     []byte
+	pos      int
+	order    binary.ByteOrder
+	maxAlign int
+	err      error
+}
+
+func (r *cdrReader) fail() {
+	if r.err == nil {
+		r.err = errors.New("CDR message is too short")
+	}
+}
+
+// alignedBytes returns the next n bytes after moving past the padding that aligns them to
+// `align` bytes.
+func (r *cdrReader) alignedBytes(n, align int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	align = min(align, r.maxAlign)
+	if rem := r.pos % align; rem != 0 {
+		r.pos += align - rem
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.fail()
+		return nil
+	}
+	ret := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return ret
+}
+
+func (r *cdrReader) bytes(n int) []byte {
+	return r.alignedBytes(n, 1)
+}
+
+func (r *cdrReader) uint8() uint8 {
+	if b := r.alignedBytes(1, 1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *cdrReader) uint16() uint16 {
+	if b := r.alignedBytes(2, 2); b != nil {
+		return r.order.Uint16(b)
+	}
+	return 0
+}
+
+func (r *cdrReader) uint32() uint32 {
+	if b := r.alignedBytes(4, 4); b != nil {
+		return r.order.Uint32(b)
+	}
+	return 0
+}
+
+func (r *cdrReader) uint64() uint64 {
+	if b := r.alignedBytes(8, 8); b != nil {
+		return r.order.Uint64(b)
+	}
+	return 0
+}
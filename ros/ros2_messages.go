@@ -0,0 +1,351 @@
+package ros
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The ROS 2 message types with conversions to rdk types, named as in MCAP schemas.
+const (
+	ROS2ImageType       = "sensor_msgs/msg/Image"
+	ROS2PointCloud2Type = "sensor_msgs/msg/PointCloud2"
+	ROS2ImuType         = "sensor_msgs/msg/Imu"
+	ROS2NavSatFixType   = "sensor_msgs/msg/NavSatFix"
+	ROS2OdometryType    = "nav_msgs/msg/Odometry"
+)
+
+// sensor_msgs/PointField datatypes.
+const (
+	pointFieldInt8    = 1
+	pointFieldUint8   = 2
+	pointFieldInt16   = 3
+	pointFieldUint16  = 4
+	pointFieldInt32   = 5
+	pointFieldUint32  = 6
+	pointFieldFloat32 = 7
+	pointFieldFloat64 = 8
+)
+
+var pointFieldSizes = map[int]int{
+	pointFieldInt8: 1, pointFieldUint8: 1, pointFieldInt16: 2, pointFieldUint16: 2,
+	pointFieldInt32: 4, pointFieldUint32: 4, pointFieldFloat32: 4, pointFieldFloat64: 8,
+}
+
+// ROS2HeaderTime returns the time stamped in the header of a decoded message, or the zero time
+// if it has no header.
+func ROS2HeaderTime(msg map[string]any) time.Time {
+	stamp := ros2Struct(msg, "header", "stamp")
+	if stamp == nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(ros2Number(stamp["sec"])), int64(ros2Number(stamp["nanosec"])))
+}
+
+// ImageFromROS2 converts a decoded sensor_msgs/Image. Depth images become 16 bit grayscale images
+// of millimeters.
+func ImageFromROS2(msg map[string]any) (image.Image, error) {
+	width := int(ros2Number(msg["width"]))
+	height := int(ros2Number(msg["height"]))
+	step := int(ros2Number(msg["step"]))
+	encoding, _ := msg["encoding"].(string)
+	data, _ := msg["data"].([]byte)
+	if len(data) < step*height {
+		return nil, errors.Errorf("image data is %d bytes, expected %d", len(data), step*height)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if ros2Number(msg["is_bigendian"]) != 0 {
+		order = binary.BigEndian
+	}
+
+	rect := image.Rect(0, 0, width, height)
+	pixelSize := map[string]int{
+		"rgb8": 3, "bgr8": 3, "rgba8": 4, "bgra8": 4, "mono8": 1, "8UC1": 1, "mono16": 2, "16UC1": 2, "32FC1": 4,
+	}[encoding]
+	if pixelSize == 0 {
+		return nil, errors.Errorf("unsupported image encoding %q", encoding)
+	}
+	if width*pixelSize > step {
+		return nil, errors.Errorf("image step %d is too small for %d %s pixels", step, width, encoding)
+	}
+	pixel := func(x, y int) []byte {
+		offset := y*step + x*pixelSize
+		return data[offset : offset+pixelSize]
+	}
+
+	switch encoding {
+	case "mono8", "8UC1":
+		img := image.NewGray(rect)
+		for y := 0; y < height; y++ {
+			copy(img.Pix[y*img.Stride:], data[y*step:y*step+width])
+		}
+		return img, nil
+	case "mono16", "16UC1":
+		img := image.NewGray16(rect)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				img.SetGray16(x, y, color.Gray16{Y: order.Uint16(pixel(x, y))})
+			}
+		}
+		return img, nil
+	case "32FC1":
+		img := image.NewGray16(rect)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				meters := math.Float32frombits(order.Uint32(pixel(x, y)))
+				// missing depth is NaN in ROS and zero in rdk.
+				if mm := float64(meters) * 1000; mm > 0 && mm <= math.MaxUint16 {
+					img.SetGray16(x, y, color.Gray16{Y: uint16(mm)})
+				}
+			}
+		}
+		return img, nil
+	default:
+		img := image.NewNRGBA(rect)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				p := pixel(x, y)
+				c := color.NRGBA{R: p[0], G: p[1], B: p[2], A: 255}
+				if encoding == "bgr8" || encoding == "bgra8" {
+					c.R, c.B = c.B, c.R
+				}
+				if pixelSize == 4 {
+					c.A = p[3]
+				}
+				img.SetNRGBA(x, y, c)
+			}
+		}
+		return img, nil
+	}
+}
+
+// PointCloudFromROS2 converts a decoded sensor_msgs/PointCloud2 whose points have x, y and z
+// fields and optionally an rgb or rgba field. Points are converted from meters to millimeters,
+// and invalid points left out.
+func PointCloudFromROS2(msg map[string]any) (pointcloud.PointCloud, error) {
+	width := int(ros2Number(msg["width"]))
+	height := int(ros2Number(msg["height"]))
+	pointStep := int(ros2Number(msg["point_step"]))
+	rowStep := int(ros2Number(msg["row_step"]))
+	data, _ := msg["data"].([]byte)
+	var order binary.ByteOrder = binary.LittleEndian
+	if b, _ := msg["is_bigendian"].(bool); b {
+		order = binary.BigEndian
+	}
+
+	type pointField struct {
+		offset   int
+		datatype int
+	}
+	fields := make(map[string]pointField)
+	fieldList, _ := msg["fields"].([]any)
+	for _, f := range fieldList {
+		field, _ := f.(map[string]any)
+		name, _ := field["name"].(string)
+		fields[name] = pointField{offset: int(ros2Number(field["offset"])), datatype: int(ros2Number(field["datatype"]))}
+	}
+	for _, name := range []string{"x", "y", "z"} {
+		if _, ok := fields[name]; !ok {
+			return nil, errors.Errorf("point cloud has no %s field", name)
+		}
+	}
+	colorField, hasColor := fields["rgb"]
+	if !hasColor {
+		colorField, hasColor = fields["rgba"]
+	}
+	for name, field := range fields {
+		size := pointFieldSizes[field.datatype]
+		if name == "rgb" || name == "rgba" {
+			size = 4
+		}
+		if field.offset < 0 || field.offset+size > pointStep {
+			return nil, errors.Errorf("point field %s does not fit in a %d byte point", name, pointStep)
+		}
+	}
+	if len(data) < (height-1)*rowStep+width*pointStep {
+		return nil, errors.Errorf("point cloud data is %d bytes, too short for %dx%d points", len(data), width, height)
+	}
+
+	read := func(point []byte, field pointField) (float64, bool) {
+		b := point[field.offset:]
+		switch field.datatype {
+		case pointFieldInt8:
+			return float64(int8(b[0])), true
+		case pointFieldUint8:
+			return float64(b[0]), true
+		case pointFieldInt16:
+			return float64(int16(order.Uint16(b))), true
+		case pointFieldUint16:
+			return float64(order.Uint16(b)), true
+		case pointFieldInt32:
+			return float64(int32(order.Uint32(b))), true
+		case pointFieldUint32:
+			return float64(order.Uint32(b)), true
+		case pointFieldFloat32:
+			return float64(math.Float32frombits(order.Uint32(b))), true
+		case pointFieldFloat64:
+			return math.Float64frombits(order.Uint64(b)), true
+		default:
+			return 0, false
+		}
+	}
+
+	pc := pointcloud.NewBasicPointCloud(width * height)
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			start := row*rowStep + col*pointStep
+			point := data[start : start+pointStep]
+			var coords [3]float64
+			valid := true
+			for i, name := range []string{"x", "y", "z"} {
+				v, ok := read(point, fields[name])
+				if !ok {
+					return nil, errors.Errorf("unsupported point field datatype %d", fields[name].datatype)
+				}
+				valid = valid && !math.IsNaN(v) && !math.IsInf(v, 0)
+				coords[i] = v * 1000
+			}
+			if !valid {
+				continue
+			}
+			pointData := pointcloud.NewBasicData()
+			if hasColor {
+				// colors are packed into the lower three bytes of a 4 byte field, whatever its type.
+				packed := order.Uint32(point[colorField.offset:])
+				pointData = pointcloud.NewColoredData(color.NRGBA{
+					R: uint8(packed >> 16), G: uint8(packed >> 8), B: uint8(packed), A: 255,
+				})
+			}
+			if err := pc.Set(r3.Vector{X: coords[0], Y: coords[1], Z: coords[2]}, pointData); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pc, nil
+}
+
+// MovementReadings holds what a movement sensor reports as found in a ROS 2 message. Fields the
+// message does not have are nil.
+type MovementReadings struct {
+	Position *geo.Point
+	// AltitudeM is the altitude of Position in meters.
+	AltitudeM float64
+	// LinearVelocity is in meters per second.
+	LinearVelocity *r3.Vector
+	// AngularVelocity is in degrees per second.
+	AngularVelocity *spatialmath.AngularVelocity
+	// LinearAcceleration is in meters per second per second.
+	LinearAcceleration *r3.Vector
+	Orientation        spatialmath.Orientation
+}
+
+// MovementReadingsFromROS2 converts a decoded sensor_msgs/Imu, sensor_msgs/NavSatFix or
+// nav_msgs/Odometry message of the given type.
+func MovementReadingsFromROS2(typeName string, msg map[string]any) (MovementReadings, error) {
+	var readings MovementReadings
+	switch normalizeROS2TypeName(typeName) {
+	case normalizeROS2TypeName(ROS2ImuType):
+		// the first element of a covariance is -1 when the message has no such estimate.
+		known := func(covariance string) bool {
+			values, _ := msg[covariance].([]any)
+			return len(values) == 0 || ros2Number(values[0]) != -1
+		}
+		if known("orientation_covariance") {
+			readings.Orientation = quaternionFromROS2(ros2Struct(msg, "orientation"))
+		}
+		if known("angular_velocity_covariance") {
+			readings.AngularVelocity = angularVelocityFromROS2(ros2Struct(msg, "angular_velocity"))
+		}
+		if known("linear_acceleration_covariance") {
+			readings.LinearAcceleration = vectorFromROS2(ros2Struct(msg, "linear_acceleration"))
+		}
+	case normalizeROS2TypeName(ROS2NavSatFixType):
+		// a status below zero means there was no fix.
+		if ros2Number(ros2Struct(msg, "status")["status"]) >= 0 {
+			readings.Position = geo.NewPoint(ros2Number(msg["latitude"]), ros2Number(msg["longitude"]))
+			readings.AltitudeM = ros2Number(msg["altitude"])
+		}
+	case normalizeROS2TypeName(ROS2OdometryType):
+		readings.Orientation = quaternionFromROS2(ros2Struct(msg, "pose", "pose", "orientation"))
+		readings.LinearVelocity = vectorFromROS2(ros2Struct(msg, "twist", "twist", "linear"))
+		readings.AngularVelocity = angularVelocityFromROS2(ros2Struct(msg, "twist", "twist", "angular"))
+	default:
+		return readings, errors.Errorf("%s messages have no movement readings", typeName)
+	}
+	return readings, nil
+}
+
+func vectorFromROS2(msg map[string]any) *r3.Vector {
+	if msg == nil {
+		return nil
+	}
+	return &r3.Vector{X: ros2Number(msg["x"]), Y: ros2Number(msg["y"]), Z: ros2Number(msg["z"])}
+}
+
+func angularVelocityFromROS2(msg map[string]any) *spatialmath.AngularVelocity {
+	radians := vectorFromROS2(msg)
+	if radians == nil {
+		return nil
+	}
+	degrees := spatialmath.AngularVelocity(radians.Mul(180 / math.Pi))
+	return &degrees
+}
+
+func quaternionFromROS2(msg map[string]any) spatialmath.Orientation {
+	if msg == nil {
+		return nil
+	}
+	q := quat.Number{Real: ros2Number(msg["w"]), Imag: ros2Number(msg["x"]), Jmag: ros2Number(msg["y"]), Kmag: ros2Number(msg["z"])}
+	norm := quat.Abs(q)
+	if norm == 0 || math.IsNaN(norm) {
+		return nil
+	}
+	normalized := spatialmath.Quaternion(quat.Scale(1/norm, q))
+	return &normalized
+}
+
+// ros2Struct returns the nested message at path in a decoded message, or nil if there is none.
+func ros2Struct(msg map[string]any, path ...string) map[string]any {
+	for _, name := range path {
+		msg, _ = msg[name].(map[string]any)
+	}
+	return msg
+}
+
+// ros2Number returns a decoded numeric field as a float64, and zero for missing fields.
+func ros2Number(v any) float64 {
+	switch n := v.(type) {
+	case int8:
+		return float64(n)
+	case uint8:
+		return float64(n)
+	case int16:
+		return float64(n)
+	case uint16:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
@@ -159,6 +159,33 @@ func (b *builtIn) Sync(ctx context.Context, extra map[string]interface{}) error
 	return b.sync.Sync(ctx, extra)
 }
 
+// TriggerRingBufferCommand is the DoCommand key which triggers ring buffer ("black box") collectors.
+// Its value may be a map with optional "resource_name" and "method" keys to restrict which
+// collectors are triggered. The response contains the number of collectors triggered under
+// "triggered".
+const TriggerRingBufferCommand = "trigger_ring_buffer"
+
+// DoCommand handles the TriggerRingBufferCommand.
+func (b *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	rawArgs, ok := cmd[TriggerRingBufferCommand]
+	if !ok {
+		return nil, resource.ErrDoUnimplemented
+	}
+	var resourceName, method string
+	if args, ok := rawArgs.(map[string]interface{}); ok {
+		resourceName, _ = args["resource_name"].(string)
+		method, _ = args["method"].(string)
+	}
+
+	// b.mu is not held: b.capture is never replaced and triggering can write up to a ring buffer's
+	// MaxBytes, which must not block Sync, Reconfigure or Close.
+	triggered, err := b.capture.TriggerRingBuffers(resourceName, method)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"triggered": triggered}, nil
+}
+
 // Reconfigure updates the data manager service when the config has changed.
 // At time of writing Reconfigure only returns an error in one of the following unrecoverable error cases:
 //  1. There is some static (aka compile time) error which we currently are only able to detected at runtime:
@@ -202,6 +229,7 @@ func (b *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies, c
 		// If this error occurs it's a resource graph error
 		return err
 	}
+	captureConfig.TriggerSensors = triggerSensorsFromDeps(collectorConfigsByResource, deps, b.logger)
 	if err := os.MkdirAll(captureConfig.CaptureDir, 0o700); err != nil {
 		b.logger.Warnf("failed to create capture directory: %s", captureConfig.CaptureDir)
 	}
@@ -362,6 +390,43 @@ func syncSensorFromDeps(name string, deps resource.Dependencies, logger logging.
 	return syncSensor, true
 }
 
+// triggerSensorsFromDeps looks up the sensors referenced by burst capture rules and ring buffer
+// triggers. Missing sensors are logged and omitted, which causes the affected collectors to report
+// an error instead of triggering.
+func triggerSensorsFromDeps(
+	collectorConfigsByResource capture.CollectorConfigsByResource,
+	deps resource.Dependencies,
+	logger logging.Logger,
+) map[string]sensor.Sensor {
+	var triggerSensors map[string]sensor.Sensor
+	for _, collectorConfigs := range collectorConfigsByResource {
+		for _, collectorConfig := range collectorConfigs {
+			var names []string
+			if rules := collectorConfig.CaptureRules; rules != nil && rules.Burst != nil {
+				names = append(names, rules.Burst.Sensor)
+			}
+			if ringBuffer := collectorConfig.RingBuffer; ringBuffer != nil && ringBuffer.TriggerSensor != "" {
+				names = append(names, ringBuffer.TriggerSensor)
+			}
+			for _, name := range names {
+				if _, ok := triggerSensors[name]; ok {
+					continue
+				}
+				triggerSensor, err := sensor.FromProvider(deps, name)
+				if err != nil {
+					logger.Errorw("unable to find capture trigger sensor", "sensor", name, "error", err.Error())
+					continue
+				}
+				if triggerSensors == nil {
+					triggerSensors = map[string]sensor.Sensor{}
+				}
+				triggerSensors[name] = triggerSensor
+			}
+		}
+	}
+	return triggerSensors
+}
+
 // Lookup the collector configs associated with the data manager service.
 func lookupCollectorConfigsByResource(
 	deps resource.Dependencies,
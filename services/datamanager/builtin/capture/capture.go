@@ -12,8 +12,10 @@ import (
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/multierr"
 	goutils "go.viam.com/utils"
 
+	"go.viam.com/rdk/components/sensor"
 	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/protoutils"
@@ -65,6 +67,11 @@ type Capture struct {
 	mongoMU            sync.Mutex
 	mongo              captureMongo
 
+	// triggerSensors are looked up by burst triggers on every poll so that collectors keep working
+	// when a trigger sensor is rebuilt without their own config changing.
+	triggerSensorsMu sync.Mutex
+	triggerSensors   map[string]sensor.Sensor
+
 	// defaultCollectorConfigs are the default as specified in the machine config.
 	// These are stored in order to be compared to any capture override readings.
 	defaultCollectorConfigs CollectorConfigsByResource
@@ -230,6 +237,10 @@ func (c *Capture) Reconfigure(
 		c.logger.Infof("maximum_capture_file_size_bytes old: %d, new: %d", c.maxCaptureFileSize, config.MaximumCaptureFileSizeBytes)
 	}
 
+	c.triggerSensorsMu.Lock()
+	c.triggerSensors = config.TriggerSensors
+	c.triggerSensorsMu.Unlock()
+
 	collection := c.mongoReconfigure(ctx, config.MongoConfig)
 	newCollectors := c.newCollectors(collectorConfigsByResource, config, collection)
 	// If a component/method has been removed from the config, close the collector.
@@ -360,10 +371,14 @@ func (c *Capture) buildCollector(
 		MethodParams:    methodParams,
 		Target:          data.NewCaptureBuffer(targetDir, captureMetadata, maxCaptureFileSize),
 		// Set queue size to defaultCaptureQueueSize if it was not set in the config.
-		QueueSize:  queueSize,
-		BufferSize: bufferSize,
-		Logger:     c.logger,
-		Clock:      c.clk,
+		QueueSize:         queueSize,
+		BufferSize:        bufferSize,
+		Logger:            c.logger,
+		Clock:             c.clk,
+		Rules:             collectorConfig.CaptureRules,
+		BurstTrigger:      c.burstTrigger(collectorConfig.CaptureRules),
+		RingBuffer:        collectorConfig.RingBuffer,
+		RingBufferTrigger: c.ringBufferTrigger(collectorConfig.RingBuffer),
 	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "constructor for collector %s failed with config: %s",
@@ -377,6 +392,65 @@ func (c *Capture) buildCollector(
 	return &collectorAndConfig{res, collector, collectorConfig}, nil
 }
 
+// burstTrigger returns a function which reads the burst trigger sensor of rules, or nil if rules
+// has no burst rule.
+func (c *Capture) burstTrigger(rules *data.CaptureRules) data.TriggerSensorFunc {
+	if rules == nil || rules.Burst == nil {
+		return nil
+	}
+	return c.triggerSensorReadings(rules.Burst.Sensor)
+}
+
+// ringBufferTrigger returns a function which reads the trigger sensor of config, or nil if config
+// has no trigger sensor.
+func (c *Capture) ringBufferTrigger(config *data.RingBufferConfig) data.TriggerSensorFunc {
+	if config == nil || config.TriggerSensor == "" {
+		return nil
+	}
+	return c.triggerSensorReadings(config.TriggerSensor)
+}
+
+func (c *Capture) triggerSensorReadings(name string) data.TriggerSensorFunc {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		c.triggerSensorsMu.Lock()
+		triggerSensor, ok := c.triggerSensors[name]
+		c.triggerSensorsMu.Unlock()
+		if !ok {
+			return nil, errors.Errorf("sensor %q not found", name)
+		}
+		return triggerSensor.Readings(ctx, nil)
+	}
+}
+
+// TriggerRingBuffers triggers the ring buffers of collectors capturing from resourceName and
+// method, where empty strings match every resource or method. It returns the number of collectors
+// which were triggered.
+func (c *Capture) TriggerRingBuffers(resourceName, method string) (int, error) {
+	c.collectorsMu.Lock()
+	var toTrigger []data.TriggerableCollector
+	for _, collAndConfig := range c.collectors {
+		if collAndConfig.Config.RingBuffer == nil {
+			continue
+		}
+		if resourceName != "" && collAndConfig.Config.Name.ShortName() != resourceName {
+			continue
+		}
+		if method != "" && collAndConfig.Config.Method != method {
+			continue
+		}
+		if triggerable, ok := collAndConfig.Collector.(data.TriggerableCollector); ok {
+			toTrigger = append(toTrigger, triggerable)
+		}
+	}
+	c.collectorsMu.Unlock()
+
+	var errs error
+	for _, collector := range toTrigger {
+		errs = multierr.Combine(errs, collector.Trigger())
+	}
+	return len(toTrigger), errs
+}
+
 func collectorConfigDescription(
 	collectorConfig datamanager.DataCaptureConfig,
 	targetDir string,
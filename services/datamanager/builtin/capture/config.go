@@ -1,5 +1,7 @@
 package capture
 
+import "go.viam.com/rdk/components/sensor"
+
 // MongoConfig is the optional data capture mongo config.
 type MongoConfig struct {
 	URI        string `json:"uri"`
@@ -26,4 +28,6 @@ type Config struct {
 	MaximumCaptureFileSizeBytes int64
 
 	MongoConfig *MongoConfig
+	// TriggerSensors are the sensors referenced by capture_rules.burst, keyed by name.
+	TriggerSensors map[string]sensor.Sensor
 }
@@ -72,6 +72,15 @@ type Config struct {
 	ScheduledSyncDisabled  bool     `json:"sync_disabled"`
 	SelectiveSyncerName    string   `json:"selective_syncer_name"`
 	SyncIntervalMins       float64  `json:"sync_interval_mins"`
+	// SyncDestination when set ships files to an S3 compatible object store, a local directory
+	// or an HTTP endpoint instead of the Viam cloud.
+	SyncDestination *datasync.DestinationConfig `json:"sync_destination,omitempty"`
+	// SyncPriorities orders which files are synced first. Files matching no entry have priority 0.
+	SyncPriorities []datasync.PriorityRule `json:"sync_priorities,omitempty"`
+	// MaxUploadBytesPerSec when positive limits the average upload rate of sync.
+	MaxUploadBytesPerSec int64 `json:"max_upload_bytes_per_sec,omitempty"`
+	// SyncWindows when set restricts scheduled sync to the given local times of day.
+	SyncWindows []datasync.SyncWindow `json:"sync_windows,omitempty"`
 	// CaptureControlSensor when set specifies a sensor to poll for dynamic
 	// capture configurations.
 	CaptureControlSensor *CaptureControlSensorConfig `json:"capture_control_sensor,omitempty"`
@@ -100,6 +109,24 @@ func (c *Config) Validate(path string) ([]string, []string, error) {
 	if c.CaptureDirDeletionThreshold < 0 {
 		return nil, nil, errors.New("capture_dir_deletion_threshold can't be negative")
 	}
+	if c.SyncDestination != nil {
+		if err := c.SyncDestination.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, rule := range c.SyncPriorities {
+		if err := rule.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if c.MaxUploadBytesPerSec < 0 {
+		return nil, nil, errors.New("max_upload_bytes_per_sec can't be negative")
+	}
+	for _, window := range c.SyncWindows {
+		if err := window.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
 	return []string{cloud.InternalServiceName.String()}, nil, nil
 }
 
@@ -186,5 +213,9 @@ func (c *Config) syncConfig(syncSensor sensor.Sensor, syncSensorEnabled bool, lo
 		SyncIntervalMins:            syncIntervalMins,
 		SelectiveSyncSensor:         syncSensor,
 		SelectiveSyncSensorEnabled:  syncSensorEnabled,
+		Destination:                 c.SyncDestination,
+		Priorities:                  c.SyncPriorities,
+		MaxUploadBytesPerSec:        c.MaxUploadBytesPerSec,
+		Windows:                     c.SyncWindows,
 	}
 }
@@ -95,6 +95,16 @@ type Config struct {
 	// unil the Readings method of the SelectiveSyncSensor (when called on the SyncIntervalMins interval) returns
 	// the a key of datamanager.ShouldSyncKey and a value of `true`
 	SelectiveSyncSensor sensor.Sensor
+	// Destination, when non nil, causes files to be shipped to an S3 compatible object store,
+	// a local directory or an HTTP endpoint instead of being uploaded to the Viam cloud.
+	Destination *DestinationConfig
+	// Priorities orders which files are synced first, e.g. fault logs before camera frames.
+	Priorities []PriorityRule
+	// MaxUploadBytesPerSec, when positive, limits the average rate at which files are uploaded.
+	MaxUploadBytesPerSec int64
+	// Windows, when non empty, restricts scheduled sync to the given times of day. Manual syncs
+	// ignore the windows.
+	Windows []SyncWindow
 }
 
 // SchedulerEnabled returns true if the sync scheduler should be running.
@@ -121,7 +131,11 @@ func (c Config) Equal(o Config) bool {
 		c.SyncIntervalMins == o.SyncIntervalMins &&
 		reflect.DeepEqual(c.Tags, o.Tags) &&
 		c.SelectiveSyncSensorEnabled == o.SelectiveSyncSensorEnabled &&
-		c.SelectiveSyncSensor == o.SelectiveSyncSensor
+		c.SelectiveSyncSensor == o.SelectiveSyncSensor &&
+		c.Destination.Equal(o.Destination) &&
+		reflect.DeepEqual(c.Priorities, o.Priorities) &&
+		c.MaxUploadBytesPerSec == o.MaxUploadBytesPerSec &&
+		reflect.DeepEqual(c.Windows, o.Windows)
 }
 
 func (c *Config) logDiff(o Config, logger logging.Logger) {
@@ -188,6 +202,22 @@ func (c *Config) logDiff(o Config, logger logging.Logger) {
 		}
 		logger.Infof("SelectiveSyncSensor: old: %s, new: %s", oldName, newName)
 	}
+
+	if !c.Destination.Equal(o.Destination) {
+		logger.Infof("sync_destination: old: %s, new: %s", c.Destination.describe(), o.Destination.describe())
+	}
+
+	if !reflect.DeepEqual(c.Priorities, o.Priorities) {
+		logger.Infof("sync_priorities: old: %v, new: %v", c.Priorities, o.Priorities)
+	}
+
+	if c.MaxUploadBytesPerSec != o.MaxUploadBytesPerSec {
+		logger.Infof("max_upload_bytes_per_sec: old: %d, new: %d", c.MaxUploadBytesPerSec, o.MaxUploadBytesPerSec)
+	}
+
+	if !reflect.DeepEqual(c.Windows, o.Windows) {
+		logger.Infof("sync_windows: old: %v, new: %v", c.Windows, o.Windows)
+	}
 }
 
 // SyncPaths returns the capture directory and additional sync paths as a slice.
@@ -0,0 +1,352 @@
+package sync
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// DestinationType identifies where sync ships files to when not uploading to the Viam cloud.
+type DestinationType string
+
+const (
+	// DestinationTypeS3 uploads files to an S3 compatible object store, e.g. AWS S3 or MinIO.
+	DestinationTypeS3 DestinationType = "s3"
+	// DestinationTypeLocal copies files into a directory, e.g. a mounted NFS share.
+	DestinationTypeLocal DestinationType = "local"
+	// DestinationTypeHTTP PUTs files to an HTTP endpoint.
+	DestinationTypeHTTP DestinationType = "http"
+)
+
+// httpDestinationTimeout bounds a single HTTP upload attempt.
+const httpDestinationTimeout = 5 * time.Minute
+
+// errDestinationRejectedFile is returned when a destination rejects a file in a way that retrying
+// will never fix, e.g. an HTTP 400 or 413. Such files are moved to the failed directory.
+var errDestinationRejectedFile = errors.New("destination rejected file")
+
+// DestinationConfig configures a sync destination other than the Viam cloud. When set, capture files
+// and arbitrary files are shipped as is (capture files are not decoded) to the destination and
+// deleted locally once they have been uploaded.
+type DestinationConfig struct {
+	Type DestinationType `json:"type"`
+	// Prefix is prepended to the key (or relative path) of every uploaded file.
+	Prefix string `json:"prefix,omitempty"`
+
+	// S3 options. Endpoint may be empty when uploading to AWS.
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+
+	// Local options.
+	Path string `json:"path,omitempty"`
+
+	// HTTP options. Files are PUT to URL joined with the file's key.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Validate returns an error if the config is missing options required by its type.
+func (c *DestinationConfig) Validate() error {
+	switch c.Type {
+	case DestinationTypeS3:
+		if c.Bucket == "" {
+			return errors.New("sync_destination.bucket is required for s3 destinations")
+		}
+		if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+			return errors.New("sync_destination.access_key_id and sync_destination.secret_access_key must be set together")
+		}
+	case DestinationTypeLocal:
+		if c.Path == "" {
+			return errors.New("sync_destination.path is required for local destinations")
+		}
+	case DestinationTypeHTTP:
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.Errorf("sync_destination.url must be an http or https url, got %q", c.URL)
+		}
+	default:
+		return errors.Errorf("unknown sync_destination.type %q, expected one of %q, %q or %q",
+			c.Type, DestinationTypeS3, DestinationTypeLocal, DestinationTypeHTTP)
+	}
+	return nil
+}
+
+// Equal returns true when both DestinationConfigs are equal.
+func (c *DestinationConfig) Equal(o *DestinationConfig) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+	if len(c.Headers) != len(o.Headers) {
+		return false
+	}
+	for k, v := range c.Headers {
+		if ov, ok := o.Headers[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return c.Type == o.Type &&
+		c.Prefix == o.Prefix &&
+		c.Endpoint == o.Endpoint &&
+		c.Region == o.Region &&
+		c.Bucket == o.Bucket &&
+		c.AccessKeyID == o.AccessKeyID &&
+		c.SecretAccessKey == o.SecretAccessKey &&
+		c.Path == o.Path &&
+		c.URL == o.URL
+}
+
+// Destination uploads files that sync would otherwise upload to the Viam cloud.
+type Destination interface {
+	// Upload uploads the file at localPath under key, a slash separated path relative to the
+	// destination root. It returns the number of bytes uploaded.
+	Upload(ctx context.Context, localPath, key string) (uint64, error)
+}
+
+// NewDestination returns the Destination described by config.
+func NewDestination(config DestinationConfig) (Destination, error) {
+	return newDestination(config, nil)
+}
+
+// newDestination returns the Destination described by config, which reads the files it uploads no
+// faster than bandwidth allows.
+func newDestination(config DestinationConfig, bandwidth *bandwidthLimiter) (Destination, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	switch config.Type {
+	case DestinationTypeS3:
+		return newS3Destination(config, bandwidth)
+	case DestinationTypeLocal:
+		return &localDestination{root: config.Path, prefix: config.Prefix, bandwidth: bandwidth}, nil
+	case DestinationTypeHTTP:
+		return &httpDestination{
+			url:       strings.TrimSuffix(config.URL, "/"),
+			prefix:    config.Prefix,
+			headers:   config.Headers,
+			client:    &http.Client{Timeout: httpDestinationTimeout},
+			bandwidth: bandwidth,
+		}, nil
+	default:
+		// unreachable, Validate rejects unknown types
+		return nil, errors.Errorf("unknown sync destination type %q", config.Type)
+	}
+}
+
+type s3Destination struct {
+	client    *s3.S3
+	bucket    string
+	prefix    string
+	bandwidth *bandwidthLimiter
+}
+
+func newS3Destination(config DestinationConfig, bandwidth *bandwidthLimiter) (Destination, error) {
+	region := config.Region
+	if region == "" {
+		// MinIO and most other S3 compatible stores ignore the region, but the signer requires one.
+		region = "us-east-1"
+	}
+	awsConfig := &aws.Config{Region: aws.String(region)}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+		// Self hosted stores are generally not set up for virtual host style bucket addressing.
+		awsConfig.S3ForcePathStyle = aws.Bool(true)
+	}
+	if config.AccessKeyID != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(config.AccessKeyID, config.SecretAccessKey, "")
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create s3 session")
+	}
+	return &s3Destination{client: s3.New(sess), bucket: config.Bucket, prefix: config.Prefix, bandwidth: bandwidth}, nil
+}
+
+func (d *s3Destination) Upload(ctx context.Context, localPath, key string) (uint64, error) {
+	//nolint:gosec
+	f, err := os.Open(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() //nolint:errcheck
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	// The SDK reads the body to hash it before sending it unless it is given the hashes. Hash the file
+	// directly so that only the read which sends the file waits for, and counts against, the bandwidth.
+	md5Hash, sha256Hash := md5.New(), sha256.New() //nolint:gosec
+	if _, err := io.Copy(io.MultiWriter(md5Hash, sha256Hash), f); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	_, err = d.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:     aws.String(d.bucket),
+		Key:        aws.String(path.Join(d.prefix, key)),
+		Body:       d.bandwidth.reader(ctx, f),
+		ContentMD5: aws.String(base64.StdEncoding.EncodeToString(md5Hash.Sum(nil))),
+	}, func(r *request.Request) {
+		r.HTTPRequest.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Hash.Sum(nil)))
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && isRejectedStatus(reqErr.StatusCode()) {
+			return 0, errors.Wrap(errDestinationRejectedFile, err.Error())
+		}
+		return 0, err
+	}
+	return uint64(info.Size()), nil
+}
+
+type localDestination struct {
+	root      string
+	prefix    string
+	bandwidth *bandwidthLimiter
+}
+
+// Upload copies the file to a temporary file next to its destination and renames it into place so
+// that readers of the destination directory never observe a partially written file.
+func (d *localDestination) Upload(ctx context.Context, localPath, key string) (uint64, error) {
+	dst := filepath.Join(d.root, filepath.FromSlash(path.Join(d.prefix, key)))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return 0, err
+	}
+
+	//nolint:gosec
+	src, err := os.Open(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close() //nolint:errcheck
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(tmp, d.bandwidth.reader(ctx, src))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), dst)
+	}
+	if err != nil {
+		//nolint:errcheck
+		os.Remove(tmp.Name())
+		return 0, err
+	}
+	return uint64(n), nil
+}
+
+type httpDestination struct {
+	url       string
+	prefix    string
+	headers   map[string]string
+	client    *http.Client
+	bandwidth *bandwidthLimiter
+}
+
+func (d *httpDestination) Upload(ctx context.Context, localPath, key string) (uint64, error) {
+	//nolint:gosec
+	f, err := os.Open(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() //nolint:errcheck
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	segments := strings.Split(path.Join(d.prefix, key), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.url+"/"+strings.Join(segments, "/"), d.bandwidth.reader(ctx, f))
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	for k, v := range d.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	//nolint:errcheck
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return uint64(info.Size()), nil
+	}
+	statusErr := fmt.Errorf("%s responded with %s", req.URL.Redacted(), resp.Status)
+	if isRejectedStatus(resp.StatusCode) {
+		return 0, errors.Wrap(errDestinationRejectedFile, statusErr.Error())
+	}
+	return 0, statusErr
+}
+
+// isRejectedStatus returns true for HTTP statuses that indicate the file itself is unacceptable.
+// Auth and server errors are retried, as they are usually fixed by changes outside of the file.
+func isRejectedStatus(code int) bool {
+	switch code {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
+
+// describe returns a loggable summary of the config which omits credentials and headers.
+func (c *DestinationConfig) describe() string {
+	if c == nil {
+		return "viam cloud"
+	}
+	switch c.Type {
+	case DestinationTypeS3:
+		return fmt.Sprintf("s3 endpoint: %q bucket: %q prefix: %q", c.Endpoint, c.Bucket, c.Prefix)
+	case DestinationTypeLocal:
+		return fmt.Sprintf("local path: %q prefix: %q", c.Path, c.Prefix)
+	case DestinationTypeHTTP:
+		return fmt.Sprintf("http url: %q prefix: %q", c.URL, c.Prefix)
+	default:
+		return string(c.Type)
+	}
+}
@@ -0,0 +1,234 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+func TestDestinationConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config DestinationConfig
+		errMsg string
+	}{
+		{name: "s3", config: DestinationConfig{Type: DestinationTypeS3, Bucket: "b"}},
+		{name: "s3 without bucket", config: DestinationConfig{Type: DestinationTypeS3}, errMsg: "bucket is required"},
+		{
+			name:   "s3 with partial credentials",
+			config: DestinationConfig{Type: DestinationTypeS3, Bucket: "b", AccessKeyID: "id"},
+			errMsg: "must be set together",
+		},
+		{name: "local", config: DestinationConfig{Type: DestinationTypeLocal, Path: "/mnt/nfs"}},
+		{name: "local without path", config: DestinationConfig{Type: DestinationTypeLocal}, errMsg: "path is required"},
+		{name: "http", config: DestinationConfig{Type: DestinationTypeHTTP, URL: "https://example.com/upload"}},
+		{name: "http without scheme", config: DestinationConfig{Type: DestinationTypeHTTP, URL: "example.com"}, errMsg: "http or https"},
+		{name: "unknown type", config: DestinationConfig{Type: "ftp"}, errMsg: "unknown sync_destination.type"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.errMsg == "" {
+				test.That(t, err, test.ShouldBeNil)
+			} else {
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestDestinationKey(t *testing.T) {
+	config := Config{CaptureDir: "/capture", AdditionalSyncPaths: []string{"/extra/photos"}}
+	test.That(t, destinationKey(config, "/capture/rdk:component:camera/cam/ReadImage/a.capture"),
+		test.ShouldEqual, "rdk:component:camera/cam/ReadImage/a.capture")
+	test.That(t, destinationKey(config, "/extra/photos/2024/a.jpg"), test.ShouldEqual, "photos/2024/a.jpg")
+	test.That(t, destinationKey(config, "/elsewhere/a.jpg"), test.ShouldEqual, "a.jpg")
+}
+
+func TestLocalDestination(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.txt")
+	test.That(t, os.WriteFile(src, []byte("hello"), 0o600), test.ShouldBeNil)
+
+	root := t.TempDir()
+	dest, err := NewDestination(DestinationConfig{Type: DestinationTypeLocal, Path: root, Prefix: "robot1"})
+	test.That(t, err, test.ShouldBeNil)
+
+	n, err := dest.Upload(context.Background(), src, "a/b/file.txt")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 5)
+
+	contents, err := os.ReadFile(filepath.Join(root, "robot1", "a", "b", "file.txt"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(contents), test.ShouldEqual, "hello")
+
+	// no temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Join(root, "robot1", "a", "b"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 1)
+}
+
+func TestHTTPDestination(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.txt")
+	test.That(t, os.WriteFile(src, []byte("hello"), 0o600), test.ShouldBeNil)
+
+	var mu sync.Mutex
+	var gotPath, gotAuth, gotBody string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		gotPath, gotAuth, gotBody = r.URL.Path, r.Header.Get("Authorization"), string(body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	dest, err := NewDestination(DestinationConfig{
+		Type:    DestinationTypeHTTP,
+		URL:     server.URL + "/upload/",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	n, err := dest.Upload(context.Background(), src, "cam/file.txt")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 5)
+	mu.Lock()
+	test.That(t, gotPath, test.ShouldEqual, "/upload/cam/file.txt")
+	test.That(t, gotAuth, test.ShouldEqual, "Bearer token")
+	test.That(t, gotBody, test.ShouldEqual, "hello")
+	status = http.StatusRequestEntityTooLarge
+	mu.Unlock()
+
+	_, err = dest.Upload(context.Background(), src, "cam/file.txt")
+	test.That(t, terminalError(err), test.ShouldBeTrue)
+
+	mu.Lock()
+	status = http.StatusServiceUnavailable
+	mu.Unlock()
+	_, err = dest.Upload(context.Background(), src, "cam/file.txt")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, terminalError(err), test.ShouldBeFalse)
+}
+
+func TestS3Destination(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.txt")
+	test.That(t, os.WriteFile(src, []byte("hello"), 0o600), test.ShouldBeNil)
+
+	var gotPath, gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotAuth, gotBody = r.URL.Path, r.Header.Get("Authorization"), string(body)
+	}))
+	defer server.Close()
+
+	dest, err := NewDestination(DestinationConfig{
+		Type:            DestinationTypeS3,
+		Endpoint:        server.URL,
+		Bucket:          "captures",
+		Prefix:          "robot1",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio123",
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	n, err := dest.Upload(context.Background(), src, "cam/file.txt")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 5)
+	test.That(t, gotPath, test.ShouldEqual, "/captures/robot1/cam/file.txt")
+	test.That(t, gotAuth, test.ShouldStartWith, "AWS4-HMAC-SHA256 Credential=minio/")
+	test.That(t, gotBody, test.ShouldEqual, "hello")
+
+	t.Run("bandwidth limit", func(t *testing.T) {
+		// The limit lets the file through straight away but would make a second read of it wait for
+		// a second, longer than the upload may take.
+		clk := clock.NewMock()
+		bandwidth := newBandwidthLimiter(5, clk)
+		dest, err := newDestination(DestinationConfig{
+			Type:            DestinationTypeS3,
+			Endpoint:        server.URL,
+			Bucket:          "captures",
+			AccessKeyID:     "minio",
+			SecretAccessKey: "minio123",
+		}, bandwidth)
+		test.That(t, err, test.ShouldBeNil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		n, err := dest.Upload(ctx, src, "cam/file.txt")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, n, test.ShouldEqual, 5)
+		test.That(t, gotBody, test.ShouldEqual, "hello")
+		test.That(t, bandwidth.next, test.ShouldEqual, clk.Now().Add(time.Second))
+	})
+}
+
+func TestSyncFileToDestination(t *testing.T) {
+	captureDir := t.TempDir()
+	destDir := t.TempDir()
+	logger := logging.NewTestLogger(t)
+	s := New(nil, func() {}, clock.New(), logger)
+	defer s.Close()
+
+	config := Config{
+		CaptureDir:  captureDir,
+		Destination: &DestinationConfig{Type: DestinationTypeLocal, Path: destDir},
+	}
+	dest, err := NewDestination(*config.Destination)
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("capture files are shipped as is and deleted", func(t *testing.T) {
+		dir := filepath.Join(captureDir, "rdk_component_sensor", "s1", "Readings")
+		test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+		captureFile, err := data.NewCaptureFile(dir, &v1.DataCaptureMetadata{
+			Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		reading, err := structpb.NewStruct(map[string]any{"a": 1})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, captureFile.WriteNext(&v1.SensorData{Data: &v1.SensorData_Struct{Struct: reading}}), test.ShouldBeNil)
+		test.That(t, captureFile.Close(), test.ShouldBeNil)
+		path := strings.TrimSuffix(captureFile.GetPath(), data.InProgressCaptureFileExt) + data.CompletedCaptureFileExt
+		expected, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+
+		s.syncFileToDestination(dest, config, path)
+
+		_, err = os.Stat(path)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+		rel, err := filepath.Rel(captureDir, path)
+		test.That(t, err, test.ShouldBeNil)
+		got, err := os.ReadFile(filepath.Join(destDir, rel))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, got, test.ShouldResemble, expected)
+		stats := s.GetStats().Upload
+		test.That(t, stats.TabularSensorUploadedFileCount, test.ShouldEqual, 1)
+		test.That(t, stats.TabularSensorCompletedUploadBytes, test.ShouldEqual, len(expected))
+	})
+
+	t.Run("unreadable capture files are moved to the failed directory", func(t *testing.T) {
+		path := filepath.Join(captureDir, "corrupt.capture")
+		test.That(t, os.WriteFile(path, []byte("not a capture file"), 0o600), test.ShouldBeNil)
+
+		s.syncFileToDestination(dest, config, path)
+
+		_, err := os.Stat(filepath.Join(captureDir, FailedDir, "corrupt.capture"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, s.GetStats().Upload.TabularSensorUploadFailedFileCount, test.ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,546 @@
+package sync
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"go.viam.com/rdk/data"
+)
+
+// noMinPriority is the minimum priority used when every file may be synced.
+const noMinPriority = math.MinInt
+
+// PriorityRule assigns a priority to the files it matches. Files with a higher priority are synced
+// before files with a lower one. Files which match no rule have priority 0. When several rules match
+// a file, the highest priority wins.
+type PriorityRule struct {
+	// Dir is a slash separated glob matched against the directory of a file relative to the sync
+	// path it was found in, e.g. "rdk_component_camera/*" or "fault_logs". Files in subdirectories
+	// of a matched directory also match.
+	Dir string `json:"dir,omitempty"`
+	// Tag matches capture files which were captured with the tag, and arbitrary files when the tag
+	// is one of the tags configured for the data manager.
+	Tag      string `json:"tag,omitempty"`
+	Priority int    `json:"priority"`
+}
+
+// SyncWindow is a time of day, in the machine's local time, during which scheduled sync may run.
+type SyncWindow struct {
+	// Start and End are formatted as "15:04". A window whose End is before its Start ends on the
+	// following day. A window whose Start and End are equal lasts all day.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Days restricts the window to the given days of the week, e.g. "mon". Every day when empty.
+	Days []string `json:"days,omitempty"`
+	// MinPriority, when set, only syncs files whose priority is at least MinPriority during the window.
+	MinPriority *int `json:"min_priority,omitempty"`
+}
+
+// Validate returns an error if the rule is invalid.
+func (r PriorityRule) Validate() error {
+	if r.Dir == "" && r.Tag == "" {
+		return errors.New("sync_priorities entries require a dir or tag")
+	}
+	if r.Dir != "" {
+		if _, err := path.Match(r.Dir, ""); err != nil {
+			return errors.Wrapf(err, "invalid sync_priorities dir %q", r.Dir)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the window is invalid.
+func (w SyncWindow) Validate() error {
+	if _, err := parseTimeOfDay(w.Start); err != nil {
+		return errors.Wrap(err, "invalid sync_windows start")
+	}
+	if _, err := parseTimeOfDay(w.End); err != nil {
+		return errors.Wrap(err, "invalid sync_windows end")
+	}
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return errors.Errorf("invalid sync_windows day %q", day)
+		}
+	}
+	return nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseTimeOfDay returns the duration since midnight of a "15:04" formatted time.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// activeMinPriority returns whether now falls within one of the windows and, if so, the lowest
+// priority that may be synced. Every file may be synced when no windows are configured.
+func activeMinPriority(windows []SyncWindow, now time.Time) (int, bool) {
+	if len(windows) == 0 {
+		return noMinPriority, true
+	}
+	minPriority, active := 0, false
+	for _, w := range windows {
+		if !w.contains(now) {
+			continue
+		}
+		windowMin := noMinPriority
+		if w.MinPriority != nil {
+			windowMin = *w.MinPriority
+		}
+		if !active || windowMin < minPriority {
+			minPriority = windowMin
+		}
+		active = true
+	}
+	return minPriority, active
+}
+
+func (w SyncWindow) contains(now time.Time) bool {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	sinceMidnight := now.Sub(midnight)
+
+	// day is the day the window containing now started on.
+	day := now.Weekday()
+	switch {
+	case start == end:
+	case start < end:
+		if sinceMidnight < start || sinceMidnight >= end {
+			return false
+		}
+	case sinceMidnight >= start:
+	case sinceMidnight < end:
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+
+	if len(w.Days) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(w.Days, func(d string) bool {
+		return weekdays[strings.ToLower(d)] == day
+	})
+}
+
+// filePriority returns the priority of the file at filePath, which was found in syncPath. The tags of
+// capture files are read once per directory and kept in dirTags, since the capture files in a
+// directory are written by the same collector.
+func filePriority(config Config, syncPath, filePath string, dirTags map[string][]string) int {
+	if len(config.Priorities) == 0 {
+		return 0
+	}
+
+	dir := "."
+	if rel, err := filepath.Rel(syncPath, filepath.Dir(filePath)); err == nil {
+		dir = filepath.ToSlash(rel)
+	}
+	var tags []string
+	tagsRead := false
+
+	priority, matched := 0, false
+	for _, rule := range config.Priorities {
+		if matched && rule.Priority <= priority {
+			continue
+		}
+		if rule.Dir != "" && !dirMatches(rule.Dir, dir) {
+			continue
+		}
+		if rule.Tag != "" {
+			if !tagsRead {
+				tags, tagsRead = fileTags(config, filePath, dirTags), true
+			}
+			if !slices.Contains(tags, rule.Tag) {
+				continue
+			}
+		}
+		priority, matched = rule.Priority, true
+	}
+	return priority
+}
+
+// dirMatches returns whether dir or one of its parents matches pattern.
+func dirMatches(pattern, dir string) bool {
+	for {
+		if ok, err := path.Match(pattern, dir); err == nil && ok {
+			return true
+		}
+		parent := path.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// fileTags returns the tags a capture file was captured with, or the configured tags for arbitrary files.
+func fileTags(config Config, filePath string, dirTags map[string][]string) []string {
+	if !isCompletedCaptureFile(filePath) {
+		return config.Tags
+	}
+	if tags, ok := dirTags[filepath.Dir(filePath)]; ok {
+		return tags
+	}
+	//nolint:gosec
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close() //nolint:errcheck
+	captureFile, err := data.ReadCaptureFile(f)
+	if err != nil {
+		return nil
+	}
+	tags := captureFile.ReadMetadata().GetTags()
+	dirTags[filepath.Dir(filePath)] = tags
+	return tags
+}
+
+// maxQueuedFiles bounds the number of files a walk of the sync paths holds in memory to sort by
+// priority. When it finds more, the highest priority files are kept and the rest are left for a
+// later walk.
+var maxQueuedFiles = 10000
+
+// queuedFile is a file which has been found by a walk of the sync paths and is waiting to be synced.
+type queuedFile struct {
+	path     string
+	priority int
+	size     int64
+	// order is the position of the file in the walk.
+	order int
+}
+
+// fileQueue holds the files found by a walk, with the file that would be synced last on top.
+// It implements heap.Interface.
+type fileQueue []queuedFile
+
+func (q fileQueue) Len() int { return len(q) }
+
+func (q fileQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].order > q[j].order
+}
+
+func (q fileQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *fileQueue) Push(x any) { *q = append(*q, x.(queuedFile)) }
+
+func (q *fileQueue) Pop() any {
+	old := *q
+	f := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return f
+}
+
+// add queues f, dropping the file that would be synced last once maxQueuedFiles are queued. It
+// returns false if a file was dropped. f must have been walked after every queued file.
+func (q *fileQueue) add(f queuedFile) bool {
+	if q.Len() < maxQueuedFiles {
+		heap.Push(q, f)
+		return true
+	}
+	if (*q)[0].priority < f.priority {
+		(*q)[0] = f
+		heap.Fix(q, 0)
+	}
+	return false
+}
+
+// sorted returns the queued files in the order they should be synced: by priority, with files of
+// equal priority in the order they were walked in.
+func (q fileQueue) sorted() []queuedFile {
+	files := slices.Clone(q)
+	slices.SortFunc(files, func(a, b queuedFile) int {
+		if a.priority != b.priority {
+			return cmp.Compare(b.priority, a.priority)
+		}
+		return cmp.Compare(a.order, b.order)
+	})
+	return files
+}
+
+// priorityQueueStats tracks the files queued for sync with a given priority.
+type priorityQueueStats struct {
+	queuedFileCount   atomic.Int64
+	queuedBytes       atomic.Int64
+	deferredFileCount atomic.Int64
+}
+
+// FTDCPriorityStats represents the sync queue of a priority for a given moment.
+type FTDCPriorityStats struct {
+	// QueuedFileCount and QueuedBytes count files which are waiting to be synced or are being synced.
+	QueuedFileCount int64
+	QueuedBytes     int64
+	// DeferredFileCount counts files which the last scheduled sync skipped because they were below
+	// the minimum priority of the active sync window.
+	DeferredFileCount int64
+}
+
+// priorityStats tracks the sync queue per priority. Priorities which are not mentioned in the config
+// are tracked as they are seen.
+type priorityStats struct {
+	mu         sync.Mutex
+	priorities map[int]*priorityQueueStats
+}
+
+func newPriorityStats(config Config) *priorityStats {
+	ps := &priorityStats{priorities: map[int]*priorityQueueStats{0: {}}}
+	for _, rule := range config.Priorities {
+		ps.priorities[rule.Priority] = &priorityQueueStats{}
+	}
+	return ps
+}
+
+func (ps *priorityStats) get(priority int) *priorityQueueStats {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	stats, ok := ps.priorities[priority]
+	if !ok {
+		stats = &priorityQueueStats{}
+		ps.priorities[priority] = stats
+	}
+	return stats
+}
+
+func (ps *priorityStats) enqueue(f queuedFile) {
+	stats := ps.get(f.priority)
+	stats.queuedFileCount.Add(1)
+	stats.queuedBytes.Add(f.size)
+}
+
+func (ps *priorityStats) dequeue(f queuedFile) {
+	stats := ps.get(f.priority)
+	stats.queuedFileCount.Add(-1)
+	stats.queuedBytes.Add(-f.size)
+}
+
+func (ps *priorityStats) setDeferred(deferred map[int]int64) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for priority, stats := range ps.priorities {
+		stats.deferredFileCount.Store(deferred[priority])
+	}
+	for priority, count := range deferred {
+		if _, ok := ps.priorities[priority]; !ok {
+			stats := &priorityQueueStats{}
+			stats.deferredFileCount.Store(count)
+			ps.priorities[priority] = stats
+		}
+	}
+}
+
+func (ps *priorityStats) ftdc() map[string]FTDCPriorityStats {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	result := make(map[string]FTDCPriorityStats, len(ps.priorities))
+	for priority, stats := range ps.priorities {
+		result[strconv.Itoa(priority)] = FTDCPriorityStats{
+			QueuedFileCount:   stats.queuedFileCount.Load(),
+			QueuedBytes:       stats.queuedBytes.Load(),
+			DeferredFileCount: stats.deferredFileCount.Load(),
+		}
+	}
+	return result
+}
+
+// bandwidthLimiter paces uploads so that, averaged over time, no more than bytesPerSec bytes are
+// uploaded per second. Uploads wait for each chunk before sending it, so a large file is sent at
+// the limit rather than as fast as the link allows.
+type bandwidthLimiter struct {
+	clock       clock.Clock
+	bytesPerSec float64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newBandwidthLimiter returns nil, which never waits, when bytesPerSec is not positive.
+func newBandwidthLimiter(bytesPerSec int64, clk clock.Clock) *bandwidthLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &bandwidthLimiter{clock: clk, bytesPerSec: float64(bytesPerSec)}
+}
+
+// wait blocks until a chunk of size bytes may be sent or ctx is done.
+func (l *bandwidthLimiter) wait(ctx context.Context, size int64) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := l.clock.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(time.Duration(float64(size) / l.bytesPerSec * float64(time.Second)))
+	l.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := l.clock.Timer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// client returns a client whose uploads wait for each message they send. It returns client itself
+// when there is no limit.
+func (l *bandwidthLimiter) client(client v1.DataSyncServiceClient) v1.DataSyncServiceClient {
+	if l == nil {
+		return client
+	}
+	return &throttledClient{DataSyncServiceClient: client, bandwidth: l}
+}
+
+// reader returns a reader which reads from r at most UploadChunkSize bytes at a time, waiting for
+// each chunk. It also stops reading once ctx is cancelled so that large copies do not block shutdown.
+func (l *bandwidthLimiter) reader(ctx context.Context, r io.ReadSeeker) io.ReadSeeker {
+	return &throttledReader{ctx: ctx, r: r, bandwidth: l}
+}
+
+type throttledClient struct {
+	v1.DataSyncServiceClient
+	bandwidth *bandwidthLimiter
+}
+
+func (c *throttledClient) DataCaptureUpload(
+	ctx context.Context, in *v1.DataCaptureUploadRequest, opts ...grpc.CallOption,
+) (*v1.DataCaptureUploadResponse, error) {
+	if err := c.bandwidth.wait(ctx, int64(proto.Size(in))); err != nil {
+		return nil, err
+	}
+	return c.DataSyncServiceClient.DataCaptureUpload(ctx, in, opts...)
+}
+
+func (c *throttledClient) FileUpload(ctx context.Context, opts ...grpc.CallOption) (v1.DataSyncService_FileUploadClient, error) {
+	stream, err := c.DataSyncServiceClient.FileUpload(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &throttledFileUploadClient{DataSyncService_FileUploadClient: stream, ctx: ctx, bandwidth: c.bandwidth}, nil
+}
+
+func (c *throttledClient) StreamingDataCaptureUpload(
+	ctx context.Context, opts ...grpc.CallOption,
+) (v1.DataSyncService_StreamingDataCaptureUploadClient, error) {
+	stream, err := c.DataSyncServiceClient.StreamingDataCaptureUpload(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &throttledStreamingDataCaptureUploadClient{
+		DataSyncService_StreamingDataCaptureUploadClient: stream, ctx: ctx, bandwidth: c.bandwidth,
+	}, nil
+}
+
+type throttledFileUploadClient struct {
+	v1.DataSyncService_FileUploadClient
+	ctx       context.Context
+	bandwidth *bandwidthLimiter
+}
+
+func (c *throttledFileUploadClient) Send(req *v1.FileUploadRequest) error {
+	if err := c.bandwidth.wait(c.ctx, int64(proto.Size(req))); err != nil {
+		return err
+	}
+	return c.DataSyncService_FileUploadClient.Send(req)
+}
+
+type throttledStreamingDataCaptureUploadClient struct {
+	v1.DataSyncService_StreamingDataCaptureUploadClient
+	ctx       context.Context
+	bandwidth *bandwidthLimiter
+}
+
+func (c *throttledStreamingDataCaptureUploadClient) Send(req *v1.StreamingDataCaptureUploadRequest) error {
+	if err := c.bandwidth.wait(c.ctx, int64(proto.Size(req))); err != nil {
+		return err
+	}
+	return c.DataSyncService_StreamingDataCaptureUploadClient.Send(req)
+}
+
+type throttledReader struct {
+	ctx       context.Context
+	r         io.ReadSeeker
+	bandwidth *bandwidthLimiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(p) > UploadChunkSize {
+		p = p[:UploadChunkSize]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.bandwidth.wait(r.ctx, int64(n)); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *throttledReader) Seek(offset int64, whence int) (int64, error) {
+	return r.r.Seek(offset, whence)
+}
+
+// String is used when logging config diffs.
+func (r PriorityRule) String() string {
+	return fmt.Sprintf("{dir: %q, tag: %q, priority: %d}", r.Dir, r.Tag, r.Priority)
+}
+
+// String is used when logging config diffs.
+func (w SyncWindow) String() string {
+	minPriority := "none"
+	if w.MinPriority != nil {
+		minPriority = strconv.Itoa(*w.MinPriority)
+	}
+	return fmt.Sprintf("{start: %s, end: %s, days: %v, min_priority: %s}", w.Start, w.End, w.Days, minPriority)
+}
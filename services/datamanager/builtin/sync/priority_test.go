@@ -0,0 +1,280 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+)
+
+func TestFilePriority(t *testing.T) {
+	captureDir := t.TempDir()
+	dir := filepath.Join(captureDir, "rdk_component_sensor", "faults", "Readings")
+	test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+	captureFile, err := data.NewCaptureFile(dir, &v1.DataCaptureMetadata{
+		Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+		Tags: []string{"critical"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, captureFile.Close(), test.ShouldBeNil)
+	tagged := captureFile.GetPath()[:len(captureFile.GetPath())-len(data.InProgressCaptureFileExt)] + data.CompletedCaptureFileExt
+
+	config := Config{
+		CaptureDir: captureDir,
+		Tags:       []string{"robot1"},
+		Priorities: []PriorityRule{
+			{Dir: "rdk_component_camera/*", Priority: -5},
+			{Tag: "critical", Priority: 10},
+			{Dir: "rdk_component_sensor", Priority: 5},
+			{Tag: "robot1", Priority: 1},
+		},
+	}
+	dirTags := map[string][]string{}
+	test.That(t, filePriority(config, captureDir, filepath.Join(captureDir, "rdk_component_camera", "cam", "ReadImage", "a.capture"), dirTags),
+		test.ShouldEqual, -5)
+	test.That(t, filePriority(config, captureDir, filepath.Join(captureDir, "rdk_component_sensor", "s", "Readings", "a.capture"), dirTags),
+		test.ShouldEqual, 5)
+	test.That(t, filePriority(config, captureDir, tagged, dirTags), test.ShouldEqual, 10)
+	test.That(t, filePriority(config, captureDir, filepath.Join(captureDir, "other.capture"), dirTags), test.ShouldEqual, 0)
+	test.That(t, filePriority(config, "/extra", "/extra/notes.txt", dirTags), test.ShouldEqual, 1)
+	test.That(t, filePriority(Config{}, captureDir, tagged, dirTags), test.ShouldEqual, 0)
+	// tags are read from one capture file per directory
+	test.That(t, filePriority(config, captureDir, filepath.Join(dir, "later.capture"), dirTags), test.ShouldEqual, 10)
+}
+
+func TestSyncWindows(t *testing.T) {
+	low := -1
+	windows := []SyncWindow{
+		{Start: "22:00", End: "06:00"},
+		{Start: "09:00", End: "17:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}, MinPriority: &low},
+	}
+	// 2024-01-01 was a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.Local)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		now         time.Time
+		inWindow    bool
+		minPriority int
+	}{
+		{name: "before midnight", now: at(1, 23, 0), inWindow: true, minPriority: noMinPriority},
+		{name: "after midnight", now: at(2, 5, 59), inWindow: true, minPriority: noMinPriority},
+		{name: "end is exclusive", now: at(2, 6, 0)},
+		{name: "weekday", now: at(3, 12, 0), inWindow: true, minPriority: -1},
+		{name: "weekend", now: at(6, 12, 0)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			minPriority, inWindow := activeMinPriority(windows, tc.now)
+			test.That(t, inWindow, test.ShouldEqual, tc.inWindow)
+			if inWindow {
+				test.That(t, minPriority, test.ShouldEqual, tc.minPriority)
+			}
+		})
+	}
+
+	minPriority, inWindow := activeMinPriority(nil, at(6, 12, 0))
+	test.That(t, inWindow, test.ShouldBeTrue)
+	test.That(t, minPriority, test.ShouldEqual, noMinPriority)
+
+	test.That(t, SyncWindow{Start: "25:00", End: "01:00"}.Validate(), test.ShouldNotBeNil)
+	test.That(t, SyncWindow{Start: "01:00", End: "02:00", Days: []string{"someday"}}.Validate(), test.ShouldNotBeNil)
+	test.That(t, PriorityRule{Priority: 1}.Validate(), test.ShouldNotBeNil)
+	test.That(t, PriorityRule{Dir: "[", Priority: 1}.Validate(), test.ShouldNotBeNil)
+}
+
+func TestBandwidthLimiter(t *testing.T) {
+	test.That(t, newBandwidthLimiter(0, clock.New()).wait(context.Background(), 1<<30), test.ShouldBeNil)
+
+	clk := clock.NewMock()
+	limiter := newBandwidthLimiter(100, clk)
+	test.That(t, limiter.wait(context.Background(), 200), test.ShouldBeNil)
+
+	// the previous upload used the next 2 seconds of budget
+	done := make(chan error)
+	go func() { done <- limiter.wait(context.Background(), 100) }()
+	for {
+		clk.Add(500 * time.Millisecond)
+		select {
+		case err := <-done:
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, clk.Now().Sub(time.Unix(0, 0)), test.ShouldBeGreaterThanOrEqualTo, 2*time.Second)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			test.That(t, limiter.wait(ctx, 100), test.ShouldBeError, context.Canceled)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestDestinationUploadIsThrottled(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.bin")
+	contents := bytes.Repeat([]byte{1}, 3*UploadChunkSize)
+	test.That(t, os.WriteFile(src, contents, 0o600), test.ShouldBeNil)
+
+	clk := clock.NewMock()
+	root := t.TempDir()
+	dest, err := newDestination(
+		DestinationConfig{Type: DestinationTypeLocal, Path: root},
+		newBandwidthLimiter(int64(UploadChunkSize), clk),
+	)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the file is read a chunk at a time, each waiting for the second of budget used by the one before
+	done := make(chan error)
+	go func() {
+		_, err := dest.Upload(context.Background(), src, "file.bin")
+		done <- err
+	}()
+	for {
+		select {
+		case err := <-done:
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, clk.Now().Sub(time.Unix(0, 0)), test.ShouldBeGreaterThanOrEqualTo, 2*time.Second)
+			uploaded, err := os.ReadFile(filepath.Join(root, "file.bin"))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, uploaded, test.ShouldResemble, contents)
+			return
+		case <-time.After(10 * time.Millisecond):
+			clk.Add(500 * time.Millisecond)
+		}
+	}
+}
+
+func TestWalkDirsSendsFilesInPriorityOrder(t *testing.T) {
+	captureDir := t.TempDir()
+	writeFile := func(rel string) string {
+		path := filepath.Join(captureDir, rel)
+		test.That(t, os.MkdirAll(filepath.Dir(path), 0o700), test.ShouldBeNil)
+		test.That(t, os.WriteFile(path, []byte("data"), 0o600), test.ShouldBeNil)
+		return path
+	}
+	camera := writeFile("a_camera/cam/ReadImage/1.capture")
+	faults := writeFile("z_faults/fault.capture")
+	other := writeFile("m_other/1.capture")
+	deferred := writeFile("b_debug/1.capture")
+
+	config := Config{
+		CaptureDir: captureDir,
+		Priorities: []PriorityRule{
+			{Dir: "a_camera/*", Priority: -1},
+			{Dir: "b_debug", Priority: -10},
+			{Dir: "z_faults", Priority: 10},
+		},
+	}
+	s := New(nil, func() {}, clock.New(), logging.NewTestLogger(t))
+	defer s.Close()
+	s.priorityStats = newPriorityStats(config)
+
+	errCh := make(chan error, 1)
+	minPriority := -5
+	windows := []SyncWindow{{Start: "00:00", End: "00:00", MinPriority: &minPriority}}
+	go func() { errCh <- s.walkDirsAndSendFilesToSync(context.Background(), config, windows) }()
+
+	var got []string
+	for range 3 {
+		f := <-s.filesToSync
+		if len(got) == 0 {
+			// every file is queued before the first one is handed to a worker
+			stats := s.GetStats().Upload.Priorities
+			test.That(t, stats["10"].QueuedFileCount, test.ShouldEqual, 1)
+			test.That(t, stats["-1"].QueuedBytes, test.ShouldEqual, 4)
+			test.That(t, stats["-10"].DeferredFileCount, test.ShouldEqual, 1)
+		}
+		got = append(got, f.path)
+		s.priorityStats.dequeue(f)
+	}
+	test.That(t, <-errCh, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, []string{faults, other, camera})
+	test.That(t, got, test.ShouldNotContain, deferred)
+	test.That(t, s.GetStats().Upload.Priorities["0"].QueuedFileCount, test.ShouldEqual, 0)
+}
+
+func TestWalkDirsStopsSendingWhenWindowEnds(t *testing.T) {
+	captureDir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(captureDir, name+".capture")
+		test.That(t, os.WriteFile(path, []byte("data"), 0o600), test.ShouldBeNil)
+		paths = append(paths, path)
+	}
+
+	config := Config{CaptureDir: captureDir}
+	clk := clock.NewMock()
+	clk.Set(time.Date(2024, 1, 1, 10, 30, 0, 0, time.Local))
+	s := New(nil, func() {}, clk, logging.NewTestLogger(t))
+	defer s.Close()
+	s.priorityStats = newPriorityStats(config)
+
+	windows := []SyncWindow{{Start: "10:00", End: "11:00"}}
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.walkDirsAndSendFilesToSync(context.Background(), config, windows) }()
+
+	got := []string{(<-s.filesToSync).path}
+	// the window ends while the first file is uploading, the second may already have been checked
+	clk.Add(time.Hour)
+	for {
+		select {
+		case f := <-s.filesToSync:
+			got = append(got, f.path)
+			continue
+		case err := <-errCh:
+			test.That(t, err, test.ShouldBeNil)
+		}
+		break
+	}
+	test.That(t, got[0], test.ShouldEqual, paths[0])
+	test.That(t, got, test.ShouldNotContain, paths[2])
+	stats := s.GetStats().Upload.Priorities["0"]
+	test.That(t, stats.DeferredFileCount, test.ShouldEqual, 3-len(got))
+	test.That(t, stats.QueuedFileCount, test.ShouldEqual, len(got))
+}
+
+func TestWalkDirsBoundsQueue(t *testing.T) {
+	defer func(prev int) { maxQueuedFiles = prev }(maxQueuedFiles)
+	maxQueuedFiles = 2
+
+	captureDir := t.TempDir()
+	writeFile := func(rel string) string {
+		path := filepath.Join(captureDir, rel)
+		test.That(t, os.MkdirAll(filepath.Dir(path), 0o700), test.ShouldBeNil)
+		test.That(t, os.WriteFile(path, []byte("data"), 0o600), test.ShouldBeNil)
+		return path
+	}
+	low := writeFile("a_low/1.capture")
+	first := writeFile("b_other/1.capture")
+	second := writeFile("b_other/2.capture")
+	high := writeFile("c_high/1.capture")
+
+	config := Config{
+		CaptureDir: captureDir,
+		Priorities: []PriorityRule{
+			{Dir: "a_low", Priority: -1},
+			{Dir: "c_high", Priority: 1},
+		},
+	}
+	s := New(nil, func() {}, clock.New(), logging.NewTestLogger(t))
+	defer s.Close()
+	s.priorityStats = newPriorityStats(config)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.walkDirsAndSendFilesToSync(context.Background(), config, nil) }()
+
+	// the lowest priority file, then the last walked of equal priority, are left for the next walk
+	got := []string{(<-s.filesToSync).path, (<-s.filesToSync).path}
+	test.That(t, <-errCh, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, []string{high, first})
+	test.That(t, got, test.ShouldNotContain, low)
+	test.That(t, got, test.ShouldNotContain, second)
+}
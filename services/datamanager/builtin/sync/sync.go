@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -85,6 +86,9 @@ type FTDCUploadStats struct {
 	TabularSensorUploadedFileCount     uint64
 	TabularSensorCompletedUploadBytes  uint64 // bytes successfully uploaded (completed files)
 	TabularSensorUploadFailedFileCount uint64
+
+	// Sync queue per priority, keyed by the priority.
+	Priorities map[string]FTDCPriorityStats
 }
 
 // Sync manages uploading files (both written by data capture and by 3rd party applications)
@@ -102,7 +106,7 @@ type Sync struct {
 	workersWg         sync.WaitGroup
 	flushCollectors   func()
 	fileTracker       *fileTracker
-	filesToSync       chan string
+	filesToSync       chan queuedFile
 	clientConstructor func(cc grpc.ClientConnInterface) v1.DataSyncServiceClient
 	clock             clock.Clock
 	uploadStats       *uploadStats
@@ -110,6 +114,11 @@ type Sync struct {
 
 	configMu sync.Mutex
 	config   Config
+	// destination is non nil when config.Destination is set and could be constructed.
+	destination Destination
+	// bandwidth is nil when config.MaxUploadBytesPerSec is not set.
+	bandwidth     *bandwidthLimiter
+	priorityStats *priorityStats
 
 	configCtx        context.Context
 	configCancelFunc func()
@@ -140,12 +149,13 @@ func New(
 		clientConstructor:   clientConstructor,
 		logger:              logger,
 		fileTracker:         newFileTracker(),
-		filesToSync:         make(chan string),
+		filesToSync:         make(chan queuedFile),
 		flushCollectors:     flushCollectors,
 		Scheduler:           goutils.NewBackgroundStoppableWorkers(),
 		cloudConn:           cloudConn{ready: make(chan struct{})},
 		FileDeletingWorkers: goutils.NewBackgroundStoppableWorkers(),
 		uploadStats:         &uploadStats,
+		priorityStats:       newPriorityStats(Config{}),
 	}
 	return &s
 }
@@ -184,9 +194,21 @@ func (s *Sync) Reconfigure(_ context.Context, config Config, cloudConnSvc cloud.
 	// wait for workers to stop
 	s.workersWg.Wait()
 
+	bandwidth := newBandwidthLimiter(config.MaxUploadBytesPerSec, s.clock)
+	var destination Destination
+	if config.Destination != nil {
+		var err error
+		if destination, err = newDestination(*config.Destination, bandwidth); err != nil {
+			s.logger.Errorw("unable to create sync destination, files will not be synced", "error", err)
+		}
+	}
+
 	// update config
 	s.configMu.Lock()
 	s.config = config
+	s.destination = destination
+	s.bandwidth = bandwidth
+	s.priorityStats = newPriorityStats(config)
 	s.configMu.Unlock()
 	// reset config context
 	s.configCtx, s.configCancelFunc = context.WithCancel(context.Background())
@@ -230,6 +252,9 @@ func (s *Sync) Reconfigure(_ context.Context, config Config, cloudConnSvc cloud.
 
 // GetStats returns cumulative file deletion and upload metrics.
 func (s *Sync) GetStats() FTDCStats {
+	s.configMu.Lock()
+	priorityStats := s.priorityStats
+	s.configMu.Unlock()
 	return FTDCStats{
 		// File deletion metric.
 		FilesDeletedToFreeSpace: s.deletedFileCount.Load(),
@@ -251,6 +276,8 @@ func (s *Sync) GetStats() FTDCStats {
 			TabularSensorCompletedUploadBytes:  s.uploadStats.tabular.completedUploadBytes.Load(),
 			TabularSensorUploadedFileCount:     s.uploadStats.tabular.uploadedFileCount.Load(),
 			TabularSensorUploadFailedFileCount: s.uploadStats.tabular.uploadFailedFileCount.Load(),
+
+			Priorities: priorityStats.ftdc(),
 		},
 	}
 }
@@ -278,15 +305,23 @@ func (s *Sync) CloudConnReady() chan struct{} {
 // If automated sync is also enabled, calling Sync will upload the files,
 // regardless of whether or not is the scheduled time.
 func (s *Sync) Sync(ctx context.Context, _ map[string]interface{}) error {
+	s.configMu.Lock()
+	config := s.config
+	destination := s.destination
+	s.configMu.Unlock()
+	if config.Destination != nil {
+		if destination == nil {
+			return errors.New("sync destination is misconfigured")
+		}
+		return s.walkDirsAndSendFilesToSync(ctx, config, nil)
+	}
+
 	select {
 	case <-s.cloudConn.ready:
 	default:
 		return errors.New("not connected to the cloud")
 	}
-	s.configMu.Lock()
-	config := s.config
-	s.configMu.Unlock()
-	return s.walkDirsAndSendFilesToSync(ctx, config)
+	return s.walkDirsAndSendFilesToSync(ctx, config, nil)
 }
 
 type cloudConn struct {
@@ -403,8 +438,12 @@ func (s *Sync) runWorker(config Config) {
 		select {
 		case <-s.configCtx.Done():
 			return
-		case path := <-s.filesToSync:
-			s.syncFile(config, path)
+		case f := <-s.filesToSync:
+			s.syncFile(config, f.path)
+			s.configMu.Lock()
+			priorityStats := s.priorityStats
+			s.configMu.Unlock()
+			priorityStats.dequeue(f)
 		}
 	}
 }
@@ -423,6 +462,17 @@ func (s *Sync) syncFile(config Config, filePath string) {
 		return
 	}
 	defer s.fileTracker.unmarkInProgress(filePath)
+
+	if config.Destination != nil {
+		s.configMu.Lock()
+		destination := s.destination
+		s.configMu.Unlock()
+		if destination != nil {
+			s.syncFileToDestination(destination, config, filePath)
+		}
+		return
+	}
+
 	//nolint:gosec
 	f, err := os.Open(filePath)
 	if err != nil {
@@ -470,7 +520,7 @@ func (s *Sync) syncDataCaptureFile(f *os.File, captureDir string, logger logging
 	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, f.Name(), func(ctx context.Context) (uint64, error) {
 		msg := "error uploading data capture file %s, size: %s, md: %s"
 		errMetadata := fmt.Sprintf(msg, captureFile.GetPath(), data.FormatBytesI64(captureFile.Size()), captureFile.ReadMetadata())
-		bytesUploaded, err := uploadDataCaptureFile(ctx, captureFile, s.cloudConn, logger, uploadingBytesCounter)
+		bytesUploaded, err := uploadDataCaptureFile(ctx, captureFile, s.uploadConn(), logger, uploadingBytesCounter)
 		if err != nil {
 			return 0, errors.Wrap(err, errMetadata)
 		}
@@ -520,7 +570,7 @@ func (s *Sync) syncArbitraryFile(f *os.File, tags, datasetIDs []string, fileLast
 	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, f.Name(), func(ctx context.Context) (uint64, error) {
 		errMetadata := fmt.Sprintf("error uploading arbitrary file %s", f.Name())
 		bytesUploaded, err := uploadArbitraryFile(
-			ctx, f, s.cloudConn, tags, datasetIDs, fileLastModifiedMillis, s.clock, logger, &s.uploadStats.arbitrary.uploadingBytes,
+			ctx, f, s.uploadConn(), tags, datasetIDs, fileLastModifiedMillis, s.clock, logger, &s.uploadStats.arbitrary.uploadingBytes,
 		)
 		if err != nil {
 			return 0, errors.Wrap(err, errMetadata)
@@ -560,6 +610,103 @@ func (s *Sync) syncArbitraryFile(f *os.File, tags, datasetIDs []string, fileLast
 	s.uploadStats.arbitrary.completedUploadBytes.Add(bytesUploaded)
 }
 
+// syncFileToDestination ships the file at filePath, as is, to the configured non cloud destination,
+// deleting it once uploaded and moving it to the failed directory on terminal errors.
+func (s *Sync) syncFileToDestination(destination Destination, config Config, filePath string) {
+	stats := &s.uploadStats.arbitrary
+	failedParentDir := path.Dir(filePath)
+	if isCompletedCaptureFile(filePath) {
+		failedParentDir = config.CaptureDir
+		isBinary, err := captureFileIsBinary(filePath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return
+			}
+			if err := moveFailedData(filePath, failedParentDir, errors.Wrap(err, "ReadCaptureFile failed"), s.logger); err != nil {
+				s.logger.Error(err)
+			}
+			s.uploadStats.tabular.uploadFailedFileCount.Add(1)
+			return
+		}
+		stats = &s.uploadStats.tabular
+		if isBinary {
+			stats = &s.uploadStats.binary
+		}
+	}
+
+	key := destinationKey(config, filePath)
+	retry := newExponentialRetry(s.configCtx, s.clock, s.logger, filePath, func(ctx context.Context) (uint64, error) {
+		bytesUploaded, err := destination.Upload(ctx, filePath, key)
+		if err != nil {
+			return 0, errors.Wrapf(err, "error uploading file %s to sync destination", filePath)
+		}
+		return bytesUploaded, nil
+	})
+
+	bytesUploaded, err := retry.run()
+	if err != nil {
+		// if we stopped due to a cancelled context,
+		// return without deleting the file or moving it to the failed directory
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+
+		// otherwise we hit a terminal error, and we should move the file to the failed directory
+		if err := moveFailedData(filePath, failedParentDir, err, s.logger); err != nil {
+			s.logger.Error(err)
+		}
+		stats.uploadFailedFileCount.Add(1)
+		return
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		s.logger.Error(errors.Wrap(err, fmt.Sprintf("error deleting file %s", filePath)).Error())
+	}
+	stats.uploadedFileCount.Add(1)
+	stats.completedUploadBytes.Add(bytesUploaded)
+}
+
+// uploadConn returns the cloud connection, with uploads limited to config.MaxUploadBytesPerSec.
+func (s *Sync) uploadConn() cloudConn {
+	s.configMu.Lock()
+	bandwidth := s.bandwidth
+	s.configMu.Unlock()
+	conn := s.cloudConn
+	conn.client = bandwidth.client(conn.client)
+	return conn
+}
+
+func captureFileIsBinary(filePath string) (bool, error) {
+	//nolint:gosec
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close() //nolint:errcheck
+	captureFile, err := data.ReadCaptureFile(f)
+	if err != nil {
+		return false, err
+	}
+	return captureFile.ReadMetadata().GetType() == v1.DataType_DATA_TYPE_BINARY_SENSOR, nil
+}
+
+// destinationKey returns the slash separated key filePath is uploaded under. Files in the capture
+// directory keep their path relative to it. Files in additional sync paths are nested under the base
+// name of the sync path they were found in.
+func destinationKey(config Config, filePath string) string {
+	for i, dir := range config.SyncPaths() {
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if i > 0 {
+			rel = filepath.Join(filepath.Base(dir), rel)
+		}
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(filePath)
+}
+
 // UploadBinaryDataToDatasets simultaneously uploads binary data and adds it to a dataset.
 func (s *Sync) UploadBinaryDataToDatasets(ctx context.Context, binaryData []byte, datasetIDs, tags []string, mimeType v1.MimeType) error {
 	errChan := make(chan error, 1)
@@ -624,6 +771,10 @@ func moveFailedData(path, parentDir string, cause error, logger logging.Logger)
 // BEGIN sync scheudler.
 func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Config) {
 	defer tkr.Stop()
+	if config.Destination != nil {
+		s.runDestinationScheduler(ctx, tkr, config)
+		return
+	}
 	var readyLogged bool
 
 	for {
@@ -661,8 +812,35 @@ func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Confi
 				s.logger.Info("data manager: NOT syncing data to the cloud as it's selective sync sensor is not ready to sync")
 				continue
 			}
+			if _, inWindow := activeMinPriority(config.Windows, s.clock.Now()); !inWindow {
+				s.logger.Debug("data manager: NOT syncing data to the cloud as it is outside of the configured sync windows")
+				continue
+			}
 
-			if err := s.walkDirsAndSendFilesToSync(ctx, config); err != nil && !errors.Is(err, context.Canceled) {
+			if err := s.walkDirsAndSendFilesToSync(ctx, config, config.Windows); err != nil && !errors.Is(err, context.Canceled) {
+				goutils.UncheckedError(err)
+			}
+		}
+	}
+}
+
+// runDestinationScheduler is runScheduler for when a non cloud destination is configured, in which
+// case there is no cloud connection to wait for.
+func (s *Sync) runDestinationScheduler(ctx context.Context, tkr *clock.Ticker, config Config) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tkr.C:
+			if !ReadyToSyncDirectories(ctx, config, s.logger) {
+				s.logger.Info("data manager: NOT syncing data as it's selective sync sensor is not ready to sync")
+				continue
+			}
+			if _, inWindow := activeMinPriority(config.Windows, s.clock.Now()); !inWindow {
+				s.logger.Debug("data manager: NOT syncing data as it is outside of the configured sync windows")
+				continue
+			}
+			if err := s.walkDirsAndSendFilesToSync(ctx, config, config.Windows); err != nil && !errors.Is(err, context.Canceled) {
 				goutils.UncheckedError(err)
 			}
 		}
@@ -671,13 +849,21 @@ func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Confi
 
 // returns early with an error if either ctx is cancelled or if the reconfigure is called
 // while walkDirsAndSendFilesToSync.
-func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config) error {
+// Files are sent to the sync workers in order of priority. Each file is only sent while one of windows is
+// active and its priority is at least the window's minimum, so that uploads stop when the window ends.
+// Every file is sent when windows is empty.
+func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config, windows []SyncWindow) error {
 	s.flushCollectors()
+	minPriority, _ := activeMinPriority(windows, s.clock.Now())
 	var errs []error
+	var queue fileQueue
+	dropped := 0
+	deferred := map[int]int64{}
+	dirTags := map[string][]string{}
 	for _, dir := range config.SyncPaths() {
 		s.logger.Debugf("syncing from: %s", dir)
 		loggedDirPaths := map[string]bool{}
-		// Retrieve all files in capture dir and send them to the syncer
+		// Retrieve all files in capture dir and queue them for the syncer
 		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 			if err := ctx.Err(); err != nil {
 				// if the context is cancelled, bail out
@@ -709,17 +895,55 @@ func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config) er
 			// Take max(timeSinceMod, 0) to account for this.
 			timeSinceMod := max(s.clock.Since(info.ModTime()), 0)
 			if readyToSyncFile(timeSinceMod, path, info, config.FileLastModifiedMillis, s.fileTracker) {
+				priority := filePriority(config, dir, path, dirTags)
+				if priority < minPriority {
+					deferred[priority]++
+					return nil
+				}
 				dirPath := filepath.Dir(path)
 				if !loggedDirPaths[dirPath] {
 					loggedDirPaths[dirPath] = true
 					s.logger.Debugf("syncing from subdirectory: %s", dirPath)
 				}
-				s.sendToSync(ctx, path)
+				if !queue.add(queuedFile{path: path, priority: priority, size: info.Size(), order: queue.Len() + dropped}) {
+					dropped++
+				}
 			}
 			return nil
 		})
 		errs = append(errs, err)
 	}
+
+	s.configMu.Lock()
+	priorityStats := s.priorityStats
+	s.configMu.Unlock()
+	priorityStats.setDeferred(deferred)
+	if dropped > 0 {
+		s.logger.Debugf("data manager: more than %d files are waiting to be synced, leaving %d for the next sync", maxQueuedFiles, dropped)
+	}
+
+	files := queue.sorted()
+	for _, f := range files {
+		priorityStats.enqueue(f)
+	}
+	for i, f := range files {
+		// The walk and the uploads before this one may have outlasted the window. Files are sorted by
+		// priority, so none of the rest may be sent either.
+		if minPriority, inWindow := activeMinPriority(windows, s.clock.Now()); !inWindow || f.priority < minPriority {
+			for _, unsent := range files[i:] {
+				priorityStats.dequeue(unsent)
+				deferred[unsent.priority]++
+			}
+			priorityStats.setDeferred(deferred)
+			break
+		}
+		if !s.sendToSync(ctx, f) {
+			for _, unsent := range files[i:] {
+				priorityStats.dequeue(unsent)
+			}
+			break
+		}
+	}
 	errs = append(errs, ctx.Err(), s.configCtx.Err())
 	return multierr.Combine(errs...)
 }
@@ -749,14 +973,15 @@ func isNonCaptureFileThatIsNotBeingWrittenTo(timeSinceMod time.Duration, path st
 		info.Size() > 0
 }
 
-func (s *Sync) sendToSync(ctx context.Context, path string) {
+// sendToSync returns false if ctx or the config context was cancelled before a worker received f.
+func (s *Sync) sendToSync(ctx context.Context, f queuedFile) bool {
 	select {
 	case <-ctx.Done():
-		return
+		return false
 	case <-s.configCtx.Done():
-		return
-	case s.filesToSync <- path:
-		return
+		return false
+	case s.filesToSync <- f:
+		return true
 	}
 }
 
@@ -14,6 +14,7 @@ import (
 	datasyncpb "go.viam.com/api/app/datasync/v1"
 	servicepb "go.viam.com/api/service/datamanager/v1"
 
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/utils"
@@ -169,6 +170,10 @@ type DataCaptureConfig struct {
 	Disabled           bool                   `json:"disabled"`
 	Tags               []string               `json:"tags,omitempty"`
 	CaptureDirectory   string                 `json:"capture_directory"`
+	// CaptureRules, when set, decide which captures are written, e.g. only on change.
+	CaptureRules *data.CaptureRules `json:"capture_rules,omitempty"`
+	// RingBuffer, when set, holds captures in memory and only writes them when triggered.
+	RingBuffer *data.RingBufferConfig `json:"ring_buffer,omitempty"`
 }
 
 // Equals checks if one capture config is equal to another.
@@ -181,7 +186,9 @@ func (c *DataCaptureConfig) Equals(other *DataCaptureConfig) bool {
 		c.Disabled == other.Disabled &&
 		slices.Compare(c.Tags, other.Tags) == 0 &&
 		reflect.DeepEqual(c.AdditionalParams, other.AdditionalParams) &&
-		c.CaptureDirectory == other.CaptureDirectory
+		c.CaptureDirectory == other.CaptureDirectory &&
+		reflect.DeepEqual(c.CaptureRules, other.CaptureRules) &&
+		reflect.DeepEqual(c.RingBuffer, other.RingBuffer)
 }
 
 // ShouldSyncKey is a special key we use within a modular sensor to pass a boolean
@@ -3,8 +3,10 @@ package builtin
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
 	"slices"
 	"strconv"
 	"sync"
@@ -49,6 +51,7 @@ var (
 	errBoundingRegionsGeomWithTranslation = errors.New("bounding region " + geomWithTranslation)
 	errObstacleGeomParse                  = errors.New("obstacle unable to be converted from geometry config")
 	errBoundingRegionsGeomParse           = errors.New("bounding regions unable to be converted from geometry config")
+	errZonesUnsupported                   = errors.New("the navigation store does not support zones")
 )
 
 const (
@@ -75,6 +78,9 @@ const (
 
 	// frequency in milliseconds.
 	planHistoryPollFrequency = time.Millisecond * 50
+
+	// how long waypoint mode waits before checking again whether the base is back within its zones.
+	zoneViolationRetryInterval = time.Second
 )
 
 func init() {
@@ -228,6 +234,7 @@ type builtIn struct {
 	mu                      sync.RWMutex
 	store                   navigation.NavStore
 	storeType               string
+	storeConfig             map[string]interface{}
 	mode                    navigation.Mode
 	mapType                 navigation.MapType
 
@@ -246,7 +253,9 @@ type builtIn struct {
 	wholeServiceCancelFunc    func()
 	currentWaypointCancelFunc func()
 	waypointInProgress        *navigation.Waypoint
-	activeBackgroundWorkers   sync.WaitGroup
+	// zoneViolation is the last *navigation.ZoneViolationError waypoint mode paused for, or nil.
+	zoneViolation           error
+	activeBackgroundWorkers sync.WaitGroup
 }
 
 func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
@@ -366,13 +375,19 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	}
 
 	// Reconfigure the store if necessary
-	if svc.storeType != string(storeCfg.Type) {
-		newStore, err := navigation.NewStoreFromConfig(ctx, svcConfig.Store)
+	if svc.storeType != string(storeCfg.Type) || !reflect.DeepEqual(svc.storeConfig, storeCfg.Config) {
+		newStore, err := navigation.NewStoreFromConfig(ctx, svc.Name().Name, svcConfig.Store)
 		if err != nil {
 			return err
 		}
+		if svc.store != nil {
+			if err := svc.store.Close(ctx); err != nil {
+				svc.logger.CWarnw(ctx, "failed to close previous navigation store", "error", err)
+			}
+		}
 		svc.store = newStore
 		svc.storeType = string(storeCfg.Type)
+		svc.storeConfig = storeCfg.Config
 	}
 
 	// Parse obstacles from the configuration
@@ -519,6 +534,156 @@ func (svc *builtIn) RemoveWaypoint(ctx context.Context, id primitive.ObjectID, e
 	return svc.store.RemoveWaypoint(ctx, id)
 }
 
+// DoCommand supports importing and exporting waypoints as GPX or GeoJSON:
+//
+//	{"import_waypoints": {"format": "gpx", "data": "<gpx>...</gpx>"}} returns {"imported": <count>}
+//	{"export_waypoints": {"format": "geojson"}} returns {"data": "<document>"}
+//
+// Exports only include waypoints which have not been visited.
+//
+// When its store is a navigation.ZoneStore, it also manages geofences and keep out zones, see
+// navigation.Zone:
+//
+//	{"add_zone": {"name": "dig site", "kind": "keep_out", "vertices": [{"latitude": 1, "longitude": 2}, ...]}}
+//	{"remove_zone": {"name": "dig site"}}
+//	{"zones": {}} returns {"zones": [...], "violation": "<why waypoint mode is paused, if it is>"}
+//
+// Adding or removing a zone replans the route to the current waypoint.
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if args, ok := cmd[addZoneCommand]; ok {
+		var zone navigation.Zone
+		if err := jsonRoundTrip(args, &zone); err != nil {
+			return nil, errors.Wrap(err, "invalid add_zone arguments")
+		}
+		if err := zone.Validate(); err != nil {
+			return nil, err
+		}
+		zoneStore, ok := svc.store.(navigation.ZoneStore)
+		if !ok {
+			return nil, errZonesUnsupported
+		}
+		if err := zoneStore.AddZone(ctx, zone); err != nil {
+			return nil, err
+		}
+		svc.logger.CInfof(ctx, "added %s zone %q", zone.Kind, zone.Name)
+		svc.replanCurrentWaypoint()
+		return map[string]interface{}{}, nil
+	}
+	if args, ok := cmd[removeZoneCommand]; ok {
+		argsMap, ok := args.(map[string]interface{})
+		name, _ := argsMap["name"].(string)
+		if !ok || name == "" {
+			return nil, errors.New("remove_zone requires a name")
+		}
+		zoneStore, ok := svc.store.(navigation.ZoneStore)
+		if !ok {
+			return nil, errZonesUnsupported
+		}
+		if err := zoneStore.RemoveZone(ctx, name); err != nil {
+			return nil, err
+		}
+		svc.logger.CInfof(ctx, "removed zone %q", name)
+		svc.replanCurrentWaypoint()
+		return map[string]interface{}{}, nil
+	}
+	if _, ok := cmd[zonesCommand]; ok {
+		zones, err := svc.zones(ctx)
+		if err != nil {
+			return nil, err
+		}
+		encoded := []interface{}{}
+		if err := jsonRoundTrip(append([]navigation.Zone{}, zones...), &encoded); err != nil {
+			return nil, err
+		}
+		resp := map[string]interface{}{"zones": encoded}
+		svc.mu.RLock()
+		if svc.zoneViolation != nil {
+			resp["violation"] = svc.zoneViolation.Error()
+		}
+		svc.mu.RUnlock()
+		return resp, nil
+	}
+	if args, ok := cmd[importWaypointsCommand]; ok {
+		format, data, err := waypointCommandArgs(args, true)
+		if err != nil {
+			return nil, err
+		}
+		wps, err := navigation.ImportWaypoints(ctx, svc.store, []byte(data), format)
+		if err != nil {
+			return nil, importError(err, len(wps))
+		}
+		svc.logger.CInfof(ctx, "imported %d waypoints", len(wps))
+		return map[string]interface{}{"imported": len(wps)}, nil
+	}
+	if args, ok := cmd[exportWaypointsCommand]; ok {
+		format, _, err := waypointCommandArgs(args, false)
+		if err != nil {
+			return nil, err
+		}
+		wps, err := svc.store.Waypoints(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := navigation.ExportWaypoints(wps, format)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"data": string(data)}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+const (
+	importWaypointsCommand = "import_waypoints"
+	exportWaypointsCommand = "export_waypoints"
+	addZoneCommand         = "add_zone"
+	removeZoneCommand      = "remove_zone"
+	zonesCommand           = "zones"
+)
+
+// jsonRoundTrip converts between DoCommand's generic maps and typed values using their json tags.
+func jsonRoundTrip(from, to interface{}) error {
+	encoded, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, to)
+}
+
+// replanCurrentWaypoint cancels the move to the waypoint in progress, if any, so that waypoint mode
+// plans to it again.
+func (svc *builtIn) replanCurrentWaypoint() {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.waypointInProgress != nil && svc.currentWaypointCancelFunc != nil {
+		svc.currentWaypointCancelFunc()
+	}
+}
+
+func waypointCommandArgs(args interface{}, requireData bool) (navigation.WaypointFormat, string, error) {
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return "", "", errors.New("waypoint command arguments must be an object")
+	}
+	format, ok := argsMap["format"].(string)
+	if !ok {
+		return "", "", errors.New("waypoint command requires a format of gpx or geojson")
+	}
+	data, ok := argsMap["data"].(string)
+	if requireData && !ok {
+		return "", "", errors.New("import_waypoints requires the document as data")
+	}
+	return navigation.WaypointFormat(format), data, nil
+}
+
+// importError notes how many waypoints were imported before an import failed.
+func importError(err error, imported int) error {
+	if imported == 0 {
+		return err
+	}
+	return errors.Wrapf(err, "imported %d waypoints before failing", imported)
+}
+
 func (svc *builtIn) waypointReached(ctx context.Context) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
@@ -543,23 +708,44 @@ func (svc *builtIn) Close(ctx context.Context) error {
 }
 
 func (svc *builtIn) moveToWaypoint(ctx context.Context, wp navigation.Waypoint, extra map[string]interface{}) error {
+	zones, err := svc.zones(ctx)
+	if err != nil {
+		return err
+	}
+	if err := svc.checkZones(ctx, zones); err != nil {
+		return err
+	}
+	zoneObstacles, err := navigation.ZoneGeometries(zones)
+	if err != nil {
+		return err
+	}
+
 	req := motion.MoveOnGlobeReq{
 		ComponentName:      svc.base.Name().Name,
 		Destination:        wp.ToPoint(),
 		Heading:            math.NaN(),
 		MovementSensorName: svc.movementSensor.Name().Name,
-		Obstacles:          svc.obstacles,
+		Obstacles:          append(slices.Clone(svc.obstacles), zoneObstacles...),
 		MotionCfg:          svc.motionCfg,
 		BoundingRegions:    svc.boundingRegions,
 		Extra:              extra,
 	}
-	cancelCtx, cancelFn := context.WithCancel(ctx)
-	defer cancelFn()
+	cancelCtx, cancelFn := context.WithCancelCause(ctx)
+	defer cancelFn(nil)
 	executionID, err := svc.motionService.MoveOnGlobe(cancelCtx, req)
 	if err != nil {
 		return err
 	}
 
+	// the base may stray from the planned path, so its position is also checked against the zones while
+	// it moves, stopping it if it leaves them.
+	if len(zones) > 0 {
+		svc.activeBackgroundWorkers.Add(1)
+		utils.ManagedGo(func() {
+			svc.monitorZones(cancelCtx, zones, cancelFn)
+		}, svc.activeBackgroundWorkers.Done)
+	}
+
 	executionWaypoint := executionWaypoint{executionID: executionID, waypoint: wp}
 	if old := svc.activeExecutionWaypoint.Swap(executionWaypoint); old != nil && old != emptyExecutionWaypoint {
 		msg := "unexpected race condition in moveOnGlobeSync, expected " +
@@ -593,12 +779,66 @@ func (svc *builtIn) moveToWaypoint(ctx context.Context, wp navigation.Waypoint,
 		},
 	)
 	if err != nil {
+		var violation *navigation.ZoneViolationError
+		if cause := context.Cause(cancelCtx); errors.As(cause, &violation) {
+			return cause
+		}
 		return err
 	}
 
 	return svc.waypointReached(cancelCtx)
 }
 
+// zones returns the zones in the store, or none if the store does not support zones.
+func (svc *builtIn) zones(ctx context.Context) ([]navigation.Zone, error) {
+	zoneStore, ok := svc.store.(navigation.ZoneStore)
+	if !ok {
+		return nil, nil
+	}
+	return zoneStore.Zones(ctx)
+}
+
+// checkZones returns a *navigation.ZoneViolationError if the base's position violates the zones, and
+// records the result for DoCommand to report.
+func (svc *builtIn) checkZones(ctx context.Context, zones []navigation.Zone) error {
+	var violation error
+	if len(zones) > 0 {
+		position, _, err := svc.movementSensor.Position(ctx, nil)
+		if err != nil {
+			return err
+		}
+		violation = navigation.CheckZones(zones, position)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if violation != nil && (svc.zoneViolation == nil || svc.zoneViolation.Error() != violation.Error()) {
+		svc.logger.CErrorf(ctx, "pausing navigation: %s", violation)
+	}
+	if violation == nil && svc.zoneViolation != nil {
+		svc.logger.CInfo(ctx, "resuming navigation, the base is back within its zones")
+	}
+	svc.zoneViolation = violation
+	return violation
+}
+
+// monitorZones polls the base's position until ctx is done, cancelling ctx with the violation if
+// the base's position violates the zones.
+func (svc *builtIn) monitorZones(ctx context.Context, zones []navigation.Zone, cancel context.CancelCauseFunc) {
+	interval := time.Duration(float64(time.Second) / defaultPositionPollingHz)
+	if hz := svc.motionCfg.PositionPollingFreqHz; hz != nil && *hz > 0 {
+		interval = time.Duration(float64(time.Second) / *hz)
+	}
+	for utils.SelectContextOrWait(ctx, interval) {
+		err := svc.checkZones(ctx, zones)
+		var violation *navigation.ZoneViolationError
+		if errors.As(err, &violation) {
+			cancel(err)
+			return
+		}
+	}
+}
+
 func (svc *builtIn) startWaypointMode(ctx context.Context, extra map[string]interface{}) {
 	if extra == nil {
 		extra = map[string]interface{}{}
@@ -631,6 +871,12 @@ func (svc *builtIn) startWaypointMode(ctx context.Context, extra map[string]inte
 					svc.logger.CInfof(ctx, "skipping waypoint %+v since it was deleted", wp)
 					continue
 				}
+				var violation *navigation.ZoneViolationError
+				if errors.As(err, &violation) {
+					// checkZones has already logged the violation
+					utils.SelectContextOrWait(ctx, zoneViolationRetryInterval)
+					continue
+				}
 				svc.logger.CWarnf(ctx, "retrying navigation to waypoint %+v since it errored out: %s", wp, err)
 				continue
 			}
@@ -656,8 +902,16 @@ func (svc *builtIn) Obstacles(ctx context.Context, extra map[string]interface{})
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
 
-	// get static GeoGeometriess
-	geoGeometries := svc.obstacles
+	// get static GeoGeometries, including zones
+	zones, err := svc.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zoneObstacles, err := navigation.ZoneGeometries(zones)
+	if err != nil {
+		return nil, err
+	}
+	geoGeometries := append(slices.Clone(svc.obstacles), zoneObstacles...)
 
 	for _, detector := range svc.motionCfg.ObstacleDetectors {
 		// get the vision service
@@ -1660,6 +1660,94 @@ func TestStartWaypoint(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("geofences are obstacles blocking paths which leave them", func(t *testing.T) {
+		s := setupStartWaypoint(ctx, t, logger)
+		defer s.closeFunc()
+
+		start := geo.NewPoint(40.0005, -73.9995)
+		s.movementSensor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+			return start, 0, nil
+		}
+		reqs := make(chan motion.MoveOnGlobeReq, 1)
+		s.injectMS.MoveOnGlobeFunc = func(ctx context.Context, req motion.MoveOnGlobeReq) (motion.ExecutionID, error) {
+			select {
+			case reqs <- req:
+			default:
+			}
+			return uuid.Nil, errors.New("no path to the destination")
+		}
+
+		_, err := s.ns.DoCommand(ctx, map[string]interface{}{addZoneCommand: map[string]interface{}{
+			"name": "yard",
+			"kind": "geofence",
+			"vertices": []interface{}{
+				map[string]interface{}{"latitude": 40, "longitude": -74},
+				map[string]interface{}{"latitude": 40, "longitude": -73.999},
+				map[string]interface{}{"latitude": 40.001, "longitude": -73.999},
+				map[string]interface{}{"latitude": 40.001, "longitude": -74},
+			},
+		}})
+		test.That(t, err, test.ShouldBeNil)
+		outside := geo.NewPoint(40.002, -73.9995)
+		test.That(t, s.ns.AddWaypoint(ctx, outside, nil), test.ShouldBeNil)
+		test.That(t, s.ns.SetMode(ctx, navigation.ModeWaypoint, nil), test.ShouldBeNil)
+
+		var req motion.MoveOnGlobeReq
+		select {
+		case req = <-reqs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("MoveOnGlobe was not called")
+		}
+		// the geofence does not widen the area the planner may use, which would be unbounded
+		// without it
+		test.That(t, req.BoundingRegions, test.ShouldBeEmpty)
+		test.That(t, req.Obstacles, test.ShouldHaveLength, 1)
+
+		// the straight path to the waypoint crosses the edge of the geofence
+		end := spatialmath.GeoPointToPoint(outside, start)
+		pathPose := spatialmath.NewPose(
+			end.Mul(0.5), &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: math.Atan2(end.Y, end.X) * 180 / math.Pi})
+		path, err := spatialmath.NewBox(pathPose, r3.Vector{X: end.Norm(), Y: 100, Z: 100}, "path")
+		test.That(t, err, test.ShouldBeNil)
+		blocked := false
+		for _, wall := range spatialmath.GeoGeometriesToGeometries(req.Obstacles, start) {
+			collides, _, err := wall.CollidesWith(path, 0)
+			test.That(t, err, test.ShouldBeNil)
+			blocked = blocked || collides
+		}
+		test.That(t, blocked, test.ShouldBeTrue)
+	})
+}
+
+func TestZonesUnsupported(t *testing.T) {
+	ctx := context.Background()
+	// embedding the NavStore interface hides the zone methods of the memory store
+	svc := &builtIn{
+		store:     struct{ navigation.NavStore }{navigation.NewMemoryNavigationStore()},
+		motionCfg: &motion.MotionConfiguration{},
+	}
+
+	_, err := svc.DoCommand(ctx, map[string]interface{}{addZoneCommand: map[string]interface{}{
+		"name": "pit",
+		"kind": "keep_out",
+		"vertices": []interface{}{
+			map[string]interface{}{"latitude": 40, "longitude": -74},
+			map[string]interface{}{"latitude": 40, "longitude": -73.999},
+			map[string]interface{}{"latitude": 40.001, "longitude": -73.999},
+		},
+	}})
+	test.That(t, err, test.ShouldBeError, errZonesUnsupported)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{removeZoneCommand: map[string]interface{}{"name": "pit"}})
+	test.That(t, err, test.ShouldBeError, errZonesUnsupported)
+
+	// without zones, there are no zones to report or plan around
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{zonesCommand: map[string]interface{}{}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["zones"], test.ShouldBeEmpty)
+	obstacles, err := svc.Obstacles(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, obstacles, test.ShouldBeEmpty)
 }
 
 func TestValidateGeometry(t *testing.T) {
@@ -0,0 +1,310 @@
+package navigation
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go.viam.com/rdk/utils"
+)
+
+// DefaultFileNavStorePath returns where the FileNavigationStore of the named navigation service keeps
+// its waypoints when no path is configured, so that navigation services don't share waypoints.
+func DefaultFileNavStorePath(serviceName string) string {
+	return filepath.Join(utils.ViamDotDir, "navigation", serviceName, "waypoints.json")
+}
+
+// fileWaypoint is the on disk representation of a Waypoint.
+type fileWaypoint struct {
+	ID      string  `json:"id"`
+	Visited bool    `json:"visited"`
+	Order   int     `json:"order"`
+	Lat     float64 `json:"latitude"`
+	Long    float64 `json:"longitude"`
+}
+
+type fileNavStoreContents struct {
+	Waypoints []fileWaypoint `json:"waypoints"`
+	Zones     []Zone         `json:"zones,omitempty"`
+}
+
+// NewFileNavigationStore returns a FileNavigationStore holding the waypoints saved at config["path"],
+// or at DefaultFileNavStorePath(serviceName) if no path is configured. The file is created on the
+// first write.
+func NewFileNavigationStore(serviceName string, config map[string]interface{}) (*FileNavigationStore, error) {
+	path := DefaultFileNavStorePath(serviceName)
+	if p, ok := config["path"].(string); ok && p != "" {
+		expanded, err := utils.ExpandHomeDir(p)
+		if err != nil {
+			return nil, err
+		}
+		path = expanded
+	}
+
+	store := &FileNavigationStore{path: path}
+	//nolint:gosec
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read navigation store %q", path)
+	}
+
+	var decoded fileNavStoreContents
+	if err := json.Unmarshal(contents, &decoded); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse navigation store %q", path)
+	}
+	for _, fwp := range decoded.Waypoints {
+		id, err := primitive.ObjectIDFromHex(fwp.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid waypoint id in navigation store %q", path)
+		}
+		store.waypoints = append(store.waypoints, &Waypoint{
+			ID:      id,
+			Visited: fwp.Visited,
+			Order:   fwp.Order,
+			Lat:     fwp.Lat,
+			Long:    fwp.Long,
+		})
+	}
+	store.zones = decoded.Zones
+	return store, nil
+}
+
+// FileNavigationStore holds the waypoints and zones for the navigation service in a JSON file, so that they
+// survive restarts without requiring a database. Every change is written to a temporary file which
+// then replaces the previous one, so a crash never leaves a partially written store behind.
+type FileNavigationStore struct {
+	path string
+
+	mu        sync.Mutex
+	waypoints []*Waypoint
+	zones     []Zone
+}
+
+// Path returns the path of the file the waypoints are saved to.
+func (store *FileNavigationStore) Path() string {
+	return store.path
+}
+
+// Waypoints returns a copy of all of the unvisited waypoints in the FileNavigationStore.
+func (store *FileNavigationStore) Waypoints(ctx context.Context) ([]Waypoint, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	wps := make([]Waypoint, 0, len(store.waypoints))
+	for _, wp := range store.waypoints {
+		if wp.Visited {
+			continue
+		}
+		wps = append(wps, *wp)
+	}
+	return wps, nil
+}
+
+// AddWaypoint adds a waypoint to the FileNavigationStore.
+func (store *FileNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+	if ctx.Err() != nil {
+		return Waypoint{}, ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	newPoint := Waypoint{
+		ID:   primitive.NewObjectID(),
+		Lat:  point.Lat(),
+		Long: point.Lng(),
+	}
+	if err := store.save(append(store.waypoints, &newPoint), store.zones); err != nil {
+		return Waypoint{}, err
+	}
+	return newPoint, nil
+}
+
+// AddWaypoints adds waypoints to the FileNavigationStore in order, writing the file once.
+func (store *FileNavigationStore) AddWaypoints(ctx context.Context, points []*geo.Point) ([]Waypoint, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	newWps := append([]*Waypoint{}, store.waypoints...)
+	added := make([]Waypoint, 0, len(points))
+	for _, point := range points {
+		newPoint := Waypoint{
+			ID:   primitive.NewObjectID(),
+			Lat:  point.Lat(),
+			Long: point.Lng(),
+		}
+		newWps = append(newWps, &newPoint)
+		added = append(added, newPoint)
+	}
+	if err := store.save(newWps, store.zones); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// RemoveWaypoint removes a waypoint from the FileNavigationStore.
+func (store *FileNavigationStore) RemoveWaypoint(ctx context.Context, id primitive.ObjectID) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	newWps := make([]*Waypoint, 0, len(store.waypoints))
+	for _, wp := range store.waypoints {
+		if wp.ID == id {
+			continue
+		}
+		newWps = append(newWps, wp)
+	}
+	return store.save(newWps, store.zones)
+}
+
+// NextWaypoint gets the next waypoint that has not been visited.
+func (store *FileNavigationStore) NextWaypoint(ctx context.Context) (Waypoint, error) {
+	if ctx.Err() != nil {
+		return Waypoint{}, ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, wp := range store.waypoints {
+		if !wp.Visited {
+			return *wp, nil
+		}
+	}
+	return Waypoint{}, errNoMoreWaypoints
+}
+
+// WaypointVisited sets that a waypoint has been visited.
+func (store *FileNavigationStore) WaypointVisited(ctx context.Context, id primitive.ObjectID) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	newWps := make([]*Waypoint, 0, len(store.waypoints))
+	for _, wp := range store.waypoints {
+		if wp.ID == id {
+			visited := *wp
+			visited.Visited = true
+			wp = &visited
+		}
+		newWps = append(newWps, wp)
+	}
+	return store.save(newWps, store.zones)
+}
+
+// Zones returns a copy of all of the zones in the FileNavigationStore.
+func (store *FileNavigationStore) Zones(ctx context.Context) ([]Zone, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return append([]Zone{}, store.zones...), nil
+}
+
+// AddZone adds a zone to the FileNavigationStore, replacing any zone with the same name.
+func (store *FileNavigationStore) AddZone(ctx context.Context, zone Zone) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.save(store.waypoints, upsertZone(store.zones, zone))
+}
+
+// RemoveZone removes a zone from the FileNavigationStore.
+func (store *FileNavigationStore) RemoveZone(ctx context.Context, name string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.save(store.waypoints, removeZone(store.zones, name))
+}
+
+// Close does nothing, as every change has already been written.
+func (store *FileNavigationStore) Close(ctx context.Context) error {
+	return nil
+}
+
+// save atomically writes waypoints and zones to the store's file and, if that succeeds, makes them
+// the store's waypoints and zones. It must be called with mu held.
+func (store *FileNavigationStore) save(waypoints []*Waypoint, zones []Zone) error {
+	contents := fileNavStoreContents{Waypoints: make([]fileWaypoint, 0, len(waypoints)), Zones: zones}
+	for _, wp := range waypoints {
+		contents.Waypoints = append(contents.Waypoints, fileWaypoint{
+			ID:      wp.ID.Hex(),
+			Visited: wp.Visited,
+			Order:   wp.Order,
+			Lat:     wp.Lat,
+			Long:    wp.Long,
+		})
+	}
+	encoded, err := json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(store.path, encoded); err != nil {
+		return errors.Wrapf(err, "failed to save navigation store %q", store.path)
+	}
+	store.waypoints = waypoints
+	store.zones = zones
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with contents such that readers, including after a
+// crash, see either the old or the new contents in full.
+func writeFileAtomic(path string, contents []byte) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			//nolint:errcheck
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(contents); err != nil {
+		//nolint:errcheck
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		//nolint:errcheck
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	// Sync the directory so the rename itself survives a power loss. Not every platform supports
+	// syncing a directory, and the new contents are already in place, so errors are ignored.
+	//nolint:gosec
+	if d, err := os.Open(dir); err == nil {
+		//nolint:errcheck
+		d.Sync()
+		//nolint:errcheck
+		d.Close()
+	}
+	return nil
+}
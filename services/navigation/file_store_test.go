@@ -0,0 +1,167 @@
+package navigation_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/services/navigation"
+)
+
+func TestFileNavigationStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nav", "waypoints.json")
+	storeConfig := navigation.StoreConfig{Type: navigation.StoreTypeFile, Config: map[string]interface{}{"path": path}}
+	test.That(t, storeConfig.Validate(""), test.ShouldBeNil)
+
+	store, err := navigation.NewStoreFromConfig(ctx, "nav", storeConfig)
+	test.That(t, err, test.ShouldBeNil)
+	wps, err := store.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldBeEmpty)
+
+	wp1, err := store.AddWaypoint(ctx, geo.NewPoint(40.1, -73.2))
+	test.That(t, err, test.ShouldBeNil)
+	wp2, err := store.AddWaypoint(ctx, geo.NewPoint(40.2, -73.3))
+	test.That(t, err, test.ShouldBeNil)
+	wp3, err := store.AddWaypoint(ctx, geo.NewPoint(40.3, -73.4))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, store.WaypointVisited(ctx, wp1.ID), test.ShouldBeNil)
+	test.That(t, store.RemoveWaypoint(ctx, wp2.ID), test.ShouldBeNil)
+	test.That(t, store.Close(ctx), test.ShouldBeNil)
+
+	// only the final file should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 1)
+
+	t.Run("waypoints survive a restart", func(t *testing.T) {
+		reopened, err := navigation.NewStoreFromConfig(ctx, "nav", storeConfig)
+		test.That(t, err, test.ShouldBeNil)
+		wps, err := reopened.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{wp3})
+		next, err := reopened.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next, test.ShouldResemble, wp3)
+
+		test.That(t, reopened.WaypointVisited(ctx, wp3.ID), test.ShouldBeNil)
+		_, err = reopened.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("corrupt store", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "waypoints.json")
+		test.That(t, os.WriteFile(corrupt, []byte("{"), 0o600), test.ShouldBeNil)
+		_, err := navigation.NewFileNavigationStore("nav", map[string]interface{}{"path": corrupt})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("imports are added at once", func(t *testing.T) {
+		store, err := navigation.NewFileNavigationStore("nav", map[string]interface{}{
+			"path": filepath.Join(t.TempDir(), "waypoints.json"),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		first, err := store.AddWaypoint(ctx, geo.NewPoint(1, 2))
+		test.That(t, err, test.ShouldBeNil)
+		imported, err := navigation.ImportWaypoints(ctx, store,
+			[]byte(`{"type": "MultiPoint", "coordinates": [[4, 3], [6, 5]]}`), navigation.WaypointFormatGeoJSON)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, imported, test.ShouldHaveLength, 2)
+
+		reopened, err := navigation.NewFileNavigationStore("nav", map[string]interface{}{"path": store.Path()})
+		test.That(t, err, test.ShouldBeNil)
+		wps, err := reopened.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{first, imported[0], imported[1]})
+		test.That(t, wps[2].Lat, test.ShouldEqual, 5)
+	})
+
+	t.Run("default path", func(t *testing.T) {
+		store, err := navigation.NewFileNavigationStore("nav1", nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, store.Path(), test.ShouldEqual, navigation.DefaultFileNavStorePath("nav1"))
+		test.That(t, store.Path(), test.ShouldNotEqual, navigation.DefaultFileNavStorePath("nav2"))
+		test.That(t, filepath.Base(filepath.Dir(store.Path())), test.ShouldEqual, "nav1")
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		invalid := navigation.StoreConfig{Type: navigation.StoreTypeFile, Config: map[string]interface{}{"path": 1}}
+		test.That(t, invalid.Validate(""), test.ShouldNotBeNil)
+	})
+}
+
+func TestWaypointFormats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, format := range []navigation.WaypointFormat{navigation.WaypointFormatGPX, navigation.WaypointFormatGeoJSON} {
+			t.Run(string(format), func(t *testing.T) {
+				src := navigation.NewMemoryNavigationStore()
+				_, err := src.AddWaypoint(ctx, geo.NewPoint(40.1, -73.2))
+				test.That(t, err, test.ShouldBeNil)
+				_, err = src.AddWaypoint(ctx, geo.NewPoint(-33.9, 151.2))
+				test.That(t, err, test.ShouldBeNil)
+				wps, err := src.Waypoints(ctx)
+				test.That(t, err, test.ShouldBeNil)
+
+				data, err := navigation.ExportWaypoints(wps, format)
+				test.That(t, err, test.ShouldBeNil)
+
+				dst := navigation.NewMemoryNavigationStore()
+				imported, err := navigation.ImportWaypoints(ctx, dst, data, format)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, imported, test.ShouldHaveLength, 2)
+				for i, wp := range imported {
+					test.That(t, wp.LatLongApproxEqual(wps[i]), test.ShouldBeTrue)
+				}
+			})
+		}
+	})
+
+	t.Run("gpx routes and tracks", func(t *testing.T) {
+		gpx := `<?xml version="1.0"?>
+<gpx version="1.1" creator="test">
+  <wpt lat="1" lon="2"><name>start</name></wpt>
+  <rte><rtept lat="3" lon="4"/></rte>
+  <trk><trkseg><trkpt lat="5" lon="6"/><trkpt lat="7" lon="8"/></trkseg></trk>
+</gpx>`
+		points, err := navigation.ParseWaypoints([]byte(gpx), navigation.WaypointFormatGPX)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, points, test.ShouldHaveLength, 4)
+		test.That(t, points[3].Lat(), test.ShouldEqual, 7)
+		test.That(t, points[3].Lng(), test.ShouldEqual, 8)
+	})
+
+	t.Run("geojson geometries", func(t *testing.T) {
+		geojson := `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[2, 1], [4, 3]]}, "properties": {}},
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}, "properties": {}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [6, 5]}, "properties": {}}
+		]}`
+		points, err := navigation.ParseWaypoints([]byte(geojson), navigation.WaypointFormatGeoJSON)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, points, test.ShouldHaveLength, 3)
+		test.That(t, points[0].Lat(), test.ShouldEqual, 1)
+		test.That(t, points[0].Lng(), test.ShouldEqual, 2)
+		test.That(t, points[2].Lat(), test.ShouldEqual, 5)
+	})
+
+	t.Run("invalid documents add nothing", func(t *testing.T) {
+		store := navigation.NewMemoryNavigationStore()
+		_, err := navigation.ImportWaypoints(ctx, store,
+			[]byte(`{"type": "Point", "coordinates": [200, 1]}`), navigation.WaypointFormatGeoJSON)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = navigation.ImportWaypoints(ctx, store, []byte("<gpx"), navigation.WaypointFormatGPX)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = navigation.ImportWaypoints(ctx, store, []byte("{}"), "kml")
+		test.That(t, err, test.ShouldNotBeNil)
+		wps, err := store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldBeEmpty)
+	})
+}
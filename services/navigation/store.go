@@ -29,6 +29,15 @@ type NavStore interface {
 	Close(ctx context.Context) error
 }
 
+// ZoneStore is implemented by NavStores which can also hold zones, as the memory, file and MongoDB
+// stores do. Navigation services using a NavStore which does not implement it have no zones.
+type ZoneStore interface {
+	Zones(ctx context.Context) ([]Zone, error)
+	// AddZone adds the zone, replacing any existing zone with the same name.
+	AddZone(ctx context.Context, zone Zone) error
+	RemoveZone(ctx context.Context, name string) error
+}
+
 type storeType string
 
 const (
@@ -38,6 +47,8 @@ const (
 	StoreTypeMemory = "memory"
 	// StoreTypeMongoDB is the constant for the mongodb store type.
 	StoreTypeMongoDB = "mongodb"
+	// StoreTypeFile is the constant for the file store type.
+	StoreTypeFile = "file"
 )
 
 // StoreConfig describes how to configure data storage.
@@ -50,19 +61,28 @@ type StoreConfig struct {
 func (config *StoreConfig) Validate(path string) error {
 	switch config.Type {
 	case StoreTypeMemory, StoreTypeMongoDB, StoreTypeUnset:
+	case StoreTypeFile:
+		if p, ok := config.Config["path"]; ok {
+			if _, ok := p.(string); !ok {
+				return errors.New("file store path must be a string")
+			}
+		}
 	default:
 		return errors.Errorf("unknown store type %q", config.Type)
 	}
 	return nil
 }
 
-// NewStoreFromConfig builds a NavStore from the provided StoreConfig and returns it.
-func NewStoreFromConfig(ctx context.Context, conf StoreConfig) (NavStore, error) {
+// NewStoreFromConfig builds a NavStore for the named navigation service from the provided StoreConfig
+// and returns it.
+func NewStoreFromConfig(ctx context.Context, serviceName string, conf StoreConfig) (NavStore, error) {
 	switch conf.Type {
 	case StoreTypeMemory, StoreTypeUnset:
 		return NewMemoryNavigationStore(), nil
 	case StoreTypeMongoDB:
 		return NewMongoDBNavigationStore(ctx, conf.Config)
+	case StoreTypeFile:
+		return NewFileNavigationStore(serviceName, conf.Config)
 	default:
 		return nil, errors.Errorf("unknown store type %q", conf.Type)
 	}
@@ -97,6 +117,7 @@ func NewMemoryNavigationStore() *MemoryNavigationStore {
 type MemoryNavigationStore struct {
 	mu        sync.RWMutex
 	waypoints []*Waypoint
+	zones     []Zone
 }
 
 // Waypoints returns a copy of all of the waypoints in the MemoryNavigationStore.
@@ -185,16 +206,77 @@ func (store *MemoryNavigationStore) WaypointVisited(ctx context.Context, id prim
 	return nil
 }
 
+// Zones returns a copy of all of the zones in the MemoryNavigationStore.
+func (store *MemoryNavigationStore) Zones(ctx context.Context) ([]Zone, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return append([]Zone{}, store.zones...), nil
+}
+
+// AddZone adds a zone to the MemoryNavigationStore, replacing any zone with the same name.
+func (store *MemoryNavigationStore) AddZone(ctx context.Context, zone Zone) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.zones = upsertZone(store.zones, zone)
+	return nil
+}
+
+// RemoveZone removes a zone from the MemoryNavigationStore.
+func (store *MemoryNavigationStore) RemoveZone(ctx context.Context, name string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.zones = removeZone(store.zones, name)
+	return nil
+}
+
 // Close does nothing.
 func (store *MemoryNavigationStore) Close(ctx context.Context) error {
 	return nil
 }
 
+// upsertZone returns a copy of zones with zone added, or replacing the zone with the same name.
+func upsertZone(zones []Zone, zone Zone) []Zone {
+	newZones := make([]Zone, 0, len(zones)+1)
+	replaced := false
+	for _, z := range zones {
+		if z.Name == zone.Name {
+			z = zone
+			replaced = true
+		}
+		newZones = append(newZones, z)
+	}
+	if !replaced {
+		newZones = append(newZones, zone)
+	}
+	return newZones
+}
+
+// removeZone returns a copy of zones without the zone with the given name.
+func removeZone(zones []Zone, name string) []Zone {
+	newZones := make([]Zone, 0, len(zones))
+	for _, z := range zones {
+		if z.Name != name {
+			newZones = append(newZones, z)
+		}
+	}
+	return newZones
+}
+
 // Database and collection names used by the MongoDBNavigationStore.
 var (
 	defaultMongoDBURI                = "mongodb://127.0.0.1:27017"
 	MongoDBNavStoreDBName            = "navigation"
 	MongoDBNavStoreWaypointsCollName = "waypoints"
+	MongoDBNavStoreZonesCollName     = "zones"
 	mongoDBNavStoreIndexes           = []mongo.IndexModel{
 		{
 			Keys: bson.D{
@@ -231,13 +313,15 @@ func NewMongoDBNavigationStore(ctx context.Context, config map[string]interface{
 	return &MongoDBNavigationStore{
 		mongoClient:   mongoClient,
 		waypointsColl: waypoints,
+		zonesColl:     mongoClient.Database(MongoDBNavStoreDBName).Collection(MongoDBNavStoreZonesCollName),
 	}, nil
 }
 
-// MongoDBNavigationStore holds the mongodb client and waypoints collection.
+// MongoDBNavigationStore holds the mongodb client and the waypoints and zones collections.
 type MongoDBNavigationStore struct {
 	mongoClient   *mongo.Client
 	waypointsColl *mongo.Collection
+	zonesColl     *mongo.Collection
 }
 
 // Close closes the connection with the mongodb client.
@@ -307,3 +391,29 @@ func (store *MongoDBNavigationStore) WaypointVisited(ctx context.Context, id pri
 	_, err := store.waypointsColl.UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", bson.D{{"visited", true}}}})
 	return err
 }
+
+// Zones returns all the zones in the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) Zones(ctx context.Context) ([]Zone, error) {
+	cursor, err := store.zonesColl.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var all []Zone
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// AddZone adds a zone to the MongoDBNavigationStore, replacing any zone with the same name.
+func (store *MongoDBNavigationStore) AddZone(ctx context.Context, zone Zone) error {
+	_, err := store.zonesColl.ReplaceOne(ctx, bson.D{{"_id", zone.Name}}, zone, options.Replace().SetUpsert(true))
+	return err
+}
+
+// RemoveZone removes a zone from the MongoDBNavigationStore.
+func (store *MongoDBNavigationStore) RemoveZone(ctx context.Context, name string) error {
+	_, err := store.zonesColl.DeleteOne(ctx, bson.D{{"_id", name}})
+	return err
+}
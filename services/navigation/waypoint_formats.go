@@ -0,0 +1,227 @@
+package navigation
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"math"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+)
+
+// WaypointFormat is a file format waypoints can be imported from and exported to.
+type WaypointFormat string
+
+const (
+	// WaypointFormatGPX is the GPS Exchange Format. Waypoints are exported as <wpt> elements.
+	// Waypoints, route points and track points are imported, in that order.
+	WaypointFormatGPX WaypointFormat = "gpx"
+	// WaypointFormatGeoJSON is GeoJSON. Waypoints are exported as a FeatureCollection of Points.
+	// Points, MultiPoints and the vertices of LineStrings are imported.
+	WaypointFormatGeoJSON WaypointFormat = "geojson"
+)
+
+type gpxDocument struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Xmlns     string     `xml:"xmlns,attr,omitempty"`
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Name string  `xml:"name,omitempty"`
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   *geoJSONGeometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// ExportWaypoints encodes the waypoints in the given format.
+func ExportWaypoints(wps []Waypoint, format WaypointFormat) ([]byte, error) {
+	switch format {
+	case WaypointFormatGPX:
+		doc := gpxDocument{Version: "1.1", Creator: "viam", Xmlns: "http://www.topografix.com/GPX/1/1"}
+		for _, wp := range wps {
+			doc.Waypoints = append(doc.Waypoints, gpxPoint{Lat: wp.Lat, Lon: wp.Long, Name: wp.ID.Hex()})
+		}
+		encoded, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), encoded...), nil
+	case WaypointFormatGeoJSON:
+		collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+		for _, wp := range wps {
+			coordinates, err := json.Marshal([]float64{wp.Long, wp.Lat})
+			if err != nil {
+				return nil, err
+			}
+			collection.Features = append(collection.Features, geoJSONFeature{
+				Type:       "Feature",
+				Geometry:   &geoJSONGeometry{Type: "Point", Coordinates: coordinates},
+				Properties: map[string]interface{}{"id": wp.ID.Hex()},
+			})
+		}
+		return json.MarshalIndent(collection, "", "  ")
+	default:
+		return nil, errors.Errorf("unknown waypoint format %q", format)
+	}
+}
+
+// ParseWaypoints decodes the points of a document in the given format.
+func ParseWaypoints(data []byte, format WaypointFormat) ([]*geo.Point, error) {
+	var points []*geo.Point
+	addPoint := func(lat, lng float64) error {
+		if math.IsNaN(lat) || math.IsNaN(lng) || math.Abs(lat) > 90 || math.Abs(lng) > 180 {
+			return errors.Errorf("invalid coordinate (%v, %v)", lat, lng)
+		}
+		points = append(points, geo.NewPoint(lat, lng))
+		return nil
+	}
+
+	switch format {
+	case WaypointFormatGPX:
+		var doc gpxDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, errors.Wrap(err, "failed to parse gpx")
+		}
+		gpxPoints := doc.Waypoints
+		for _, rte := range doc.Routes {
+			gpxPoints = append(gpxPoints, rte.Points...)
+		}
+		for _, trk := range doc.Tracks {
+			for _, seg := range trk.Segments {
+				gpxPoints = append(gpxPoints, seg.Points...)
+			}
+		}
+		for _, p := range gpxPoints {
+			if err := addPoint(p.Lat, p.Lon); err != nil {
+				return nil, err
+			}
+		}
+	case WaypointFormatGeoJSON:
+		geometries, err := geoJSONGeometries(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse geojson")
+		}
+		for _, geometry := range geometries {
+			var positions [][]float64
+			switch geometry.Type {
+			case "Point":
+				var position []float64
+				if err := json.Unmarshal(geometry.Coordinates, &position); err != nil {
+					return nil, errors.Wrap(err, "failed to parse geojson Point")
+				}
+				positions = [][]float64{position}
+			case "MultiPoint", "LineString":
+				if err := json.Unmarshal(geometry.Coordinates, &positions); err != nil {
+					return nil, errors.Wrapf(err, "failed to parse geojson %s", geometry.Type)
+				}
+			default:
+				// Polygons and other geometries do not describe waypoints.
+				continue
+			}
+			for _, position := range positions {
+				if len(position) < 2 {
+					return nil, errors.New("geojson positions require a longitude and latitude")
+				}
+				if err := addPoint(position[1], position[0]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	default:
+		return nil, errors.Errorf("unknown waypoint format %q", format)
+	}
+	return points, nil
+}
+
+// geoJSONGeometries returns the geometries of a GeoJSON FeatureCollection, Feature or bare geometry.
+func geoJSONGeometries(data []byte) ([]geoJSONGeometry, error) {
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, err
+	}
+	switch typed.Type {
+	case "FeatureCollection":
+		var collection geoJSONFeatureCollection
+		if err := json.Unmarshal(data, &collection); err != nil {
+			return nil, err
+		}
+		var geometries []geoJSONGeometry
+		for _, feature := range collection.Features {
+			if feature.Geometry != nil {
+				geometries = append(geometries, *feature.Geometry)
+			}
+		}
+		return geometries, nil
+	case "Feature":
+		var feature geoJSONFeature
+		if err := json.Unmarshal(data, &feature); err != nil {
+			return nil, err
+		}
+		if feature.Geometry == nil {
+			return nil, nil
+		}
+		return []geoJSONGeometry{*feature.Geometry}, nil
+	default:
+		var geometry geoJSONGeometry
+		if err := json.Unmarshal(data, &geometry); err != nil {
+			return nil, err
+		}
+		return []geoJSONGeometry{geometry}, nil
+	}
+}
+
+// waypointsAdder is implemented by stores which add many waypoints more cheaply at once than one by
+// one, such as the FileNavigationStore, which rewrites its file for every change.
+type waypointsAdder interface {
+	AddWaypoints(ctx context.Context, points []*geo.Point) ([]Waypoint, error)
+}
+
+// ImportWaypoints adds the points of a document in the given format to the store, in document order.
+// No waypoints are added if the document can't be parsed.
+func ImportWaypoints(ctx context.Context, store NavStore, data []byte, format WaypointFormat) ([]Waypoint, error) {
+	points, err := ParseWaypoints(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if adder, ok := store.(waypointsAdder); ok {
+		return adder.AddWaypoints(ctx, points)
+	}
+	wps := make([]Waypoint, 0, len(points))
+	for _, point := range points {
+		wp, err := store.AddWaypoint(ctx, point)
+		if err != nil {
+			return wps, err
+		}
+		wps = append(wps, wp)
+	}
+	return wps, nil
+}
@@ -0,0 +1,178 @@
+package navigation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// ZoneKind is the kind of a Zone.
+type ZoneKind string
+
+const (
+	// ZoneKindGeofence zones are regions the base must stay within. When any geofences exist, the
+	// base must be within at least one of them.
+	ZoneKindGeofence ZoneKind = "geofence"
+	// ZoneKindKeepOut zones are regions the base must not enter.
+	ZoneKindKeepOut ZoneKind = "keep_out"
+)
+
+// zoneHeightMM is the height of the geometries zones are given for motion planning. Zones extend
+// equally above and below the ground.
+const zoneHeightMM = 10000.
+
+// zoneWallThicknessMM is the thickness of the walls zones are given for motion planning.
+const zoneWallThicknessMM = 100.
+
+// ZoneVertex is a vertex of a Zone's polygon.
+type ZoneVertex struct {
+	Lat  float64 `bson:"latitude" json:"latitude"`
+	Long float64 `bson:"longitude" json:"longitude"`
+}
+
+// A Zone is a named polygon which restricts where navigation may take the base. Unlike the
+// obstacles and bounding regions in the navigation service's config, zones are added and removed at
+// runtime and are persisted in the NavStore, if it is a ZoneStore.
+type Zone struct {
+	Name     string       `bson:"_id" json:"name"`
+	Kind     ZoneKind     `bson:"kind" json:"kind"`
+	Vertices []ZoneVertex `bson:"vertices" json:"vertices"`
+}
+
+// Validate returns an error if the zone is invalid.
+func (z Zone) Validate() error {
+	if z.Name == "" {
+		return errors.New("zones require a name")
+	}
+	switch z.Kind {
+	case ZoneKindGeofence, ZoneKindKeepOut:
+	default:
+		return errors.Errorf("unknown zone kind %q", z.Kind)
+	}
+	if len(z.Vertices) < 3 {
+		return errors.Errorf("zone %q requires at least 3 vertices", z.Name)
+	}
+	for _, v := range z.Vertices {
+		if math.IsNaN(v.Lat) || math.IsNaN(v.Long) || math.Abs(v.Lat) > 90 || math.Abs(v.Long) > 180 {
+			return errors.Errorf("zone %q has invalid vertex (%v, %v)", z.Name, v.Lat, v.Long)
+		}
+	}
+	return nil
+}
+
+// Contains returns whether the point is within the zone's polygon. Latitude and longitude are treated
+// as planar coordinates, which is accurate for zones which are small relative to the earth.
+func (z Zone) Contains(point *geo.Point) bool {
+	inside := false
+	x, y := point.Lng(), point.Lat()
+	for i, j := 0, len(z.Vertices)-1; i < len(z.Vertices); j, i = i, i+1 {
+		xi, yi := z.Vertices[i].Long, z.Vertices[i].Lat
+		xj, yj := z.Vertices[j].Long, z.Vertices[j].Lat
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// center returns the mean of the zone's vertices.
+func (z Zone) center() *geo.Point {
+	var lat, lng float64
+	for _, v := range z.Vertices {
+		lat += v.Lat
+		lng += v.Long
+	}
+	n := float64(len(z.Vertices))
+	return geo.NewPoint(lat/n, lng/n)
+}
+
+// GeoGeometry returns the zone as a GeoGeometry the motion planner can use as an obstacle: a thin box
+// along each edge of the polygon, so that the base can neither enter a keep out zone nor leave a
+// geofence without crossing one. The edges of overlapping geofences also block the base from moving
+// between them.
+func (z Zone) GeoGeometry() (*spatialmath.GeoGeometry, error) {
+	if err := z.Validate(); err != nil {
+		return nil, err
+	}
+	center := z.center()
+	points := make([]r3.Vector, 0, len(z.Vertices))
+	for _, v := range z.Vertices {
+		points = append(points, spatialmath.GeoPointToPoint(geo.NewPoint(v.Lat, v.Long), center))
+	}
+
+	walls := make([]spatialmath.Geometry, 0, len(points))
+	for i := range points {
+		a, b := points[i], points[(i+1)%len(points)]
+		edge := b.Sub(a)
+		pose := spatialmath.NewPose(
+			a.Add(edge.Mul(0.5)),
+			&spatialmath.OrientationVectorDegrees{OZ: 1, Theta: math.Atan2(edge.Y, edge.X) * 180 / math.Pi},
+		)
+		wall, err := spatialmath.NewBox(
+			pose,
+			r3.Vector{X: edge.Norm() + zoneWallThicknessMM, Y: zoneWallThicknessMM, Z: zoneHeightMM},
+			fmt.Sprintf("%s_%d", z.Name, i),
+		)
+		if err != nil {
+			return nil, err
+		}
+		walls = append(walls, wall)
+	}
+	return spatialmath.NewGeoGeometry(center, walls), nil
+}
+
+// ZoneViolationError is returned when the base's position is outside of every geofence or inside a
+// keep out zone.
+type ZoneViolationError struct {
+	// Zone is the keep out zone the base is in. It is empty when the base is outside of every geofence.
+	Zone     string
+	Position *geo.Point
+}
+
+func (e *ZoneViolationError) Error() string {
+	if e.Zone == "" {
+		return fmt.Sprintf("base at (%v, %v) is outside of every geofence", e.Position.Lat(), e.Position.Lng())
+	}
+	return fmt.Sprintf("base at (%v, %v) is inside keep out zone %q", e.Position.Lat(), e.Position.Lng(), e.Zone)
+}
+
+// CheckZones returns a *ZoneViolationError if position is inside any keep out zone, or outside of
+// every geofence when there are any.
+func CheckZones(zones []Zone, position *geo.Point) error {
+	hasGeofence, inGeofence := false, false
+	for _, z := range zones {
+		switch z.Kind {
+		case ZoneKindKeepOut:
+			if z.Contains(position) {
+				return &ZoneViolationError{Zone: z.Name, Position: position}
+			}
+		case ZoneKindGeofence:
+			hasGeofence = true
+			inGeofence = inGeofence || z.Contains(position)
+		}
+	}
+	if hasGeofence && !inGeofence {
+		return &ZoneViolationError{Position: position}
+	}
+	return nil
+}
+
+// ZoneGeometries returns the GeoGeometries of the zones, to be used as obstacles. Geofences are not
+// bounding regions, as the planner allows the base anywhere within any bounding region, which would
+// widen rather than restrict the bounding regions of the navigation service's config.
+func ZoneGeometries(zones []Zone) ([]*spatialmath.GeoGeometry, error) {
+	geometries := make([]*spatialmath.GeoGeometry, 0, len(zones))
+	for _, z := range zones {
+		g, err := z.GeoGeometry()
+		if err != nil {
+			return nil, err
+		}
+		geometries = append(geometries, g)
+	}
+	return geometries, nil
+}
@@ -0,0 +1,145 @@
+package navigation_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// square returns a zone whose vertices are the corners of a square of the given size in degrees.
+func square(name string, kind navigation.ZoneKind, lat, lng, size float64) navigation.Zone {
+	return navigation.Zone{
+		Name: name,
+		Kind: kind,
+		Vertices: []navigation.ZoneVertex{
+			{Lat: lat, Long: lng},
+			{Lat: lat, Long: lng + size},
+			{Lat: lat + size, Long: lng + size},
+			{Lat: lat + size, Long: lng},
+		},
+	}
+}
+
+func TestZones(t *testing.T) {
+	fence := square("yard", navigation.ZoneKindGeofence, 40, -74, 0.001)
+	pit := square("pit", navigation.ZoneKindKeepOut, 40.0004, -73.9996, 0.0002)
+
+	t.Run("validate", func(t *testing.T) {
+		test.That(t, fence.Validate(), test.ShouldBeNil)
+		test.That(t, navigation.Zone{Kind: navigation.ZoneKindGeofence, Vertices: fence.Vertices}.Validate(), test.ShouldNotBeNil)
+		test.That(t, navigation.Zone{Name: "a", Kind: "moat", Vertices: fence.Vertices}.Validate(), test.ShouldNotBeNil)
+		test.That(t, navigation.Zone{Name: "a", Kind: navigation.ZoneKindKeepOut, Vertices: fence.Vertices[:2]}.Validate(),
+			test.ShouldNotBeNil)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		zones := []navigation.Zone{fence, pit}
+		test.That(t, navigation.CheckZones(zones, geo.NewPoint(40.0001, -73.9999)), test.ShouldBeNil)
+
+		var violation *navigation.ZoneViolationError
+		err := navigation.CheckZones(zones, geo.NewPoint(40.0005, -73.9995))
+		test.That(t, errors.As(err, &violation), test.ShouldBeTrue)
+		test.That(t, violation.Zone, test.ShouldEqual, "pit")
+
+		err = navigation.CheckZones(zones, geo.NewPoint(40.002, -73.9995))
+		test.That(t, errors.As(err, &violation), test.ShouldBeTrue)
+		test.That(t, violation.Zone, test.ShouldEqual, "")
+		test.That(t, err.Error(), test.ShouldContainSubstring, "outside of every geofence")
+
+		// without geofences the base may be anywhere outside of keep out zones
+		test.That(t, navigation.CheckZones([]navigation.Zone{pit}, geo.NewPoint(50, 50)), test.ShouldBeNil)
+	})
+
+	t.Run("geometries", func(t *testing.T) {
+		geometries, err := navigation.ZoneGeometries([]navigation.Zone{fence, pit})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, geometries, test.ShouldHaveLength, 2)
+		test.That(t, geometries[0].Geometries(), test.ShouldHaveLength, 4)
+		test.That(t, geometries[1].Geometries(), test.ShouldHaveLength, 4)
+
+		collides := func(t *testing.T, zone *spatialmath.GeoGeometry, origin navigation.ZoneVertex, g spatialmath.Geometry) bool {
+			t.Helper()
+			walls := spatialmath.GeoGeometriesToGeometries([]*spatialmath.GeoGeometry{zone}, geo.NewPoint(origin.Lat, origin.Long))
+			for _, wall := range walls {
+				c, _, err := wall.CollidesWith(g, 0)
+				test.That(t, err, test.ShouldBeNil)
+				if c {
+					return true
+				}
+			}
+			return false
+		}
+
+		// a base crossing the edge of the keep out zone collides with it
+		crossing, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{X: 500, Y: 500, Z: 500}, "base")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, collides(t, geometries[1], pit.Vertices[0], crossing), test.ShouldBeTrue)
+
+		// but a base in the middle of the zone's edges does not
+		inner, err := spatialmath.NewBox(
+			spatialmath.NewPoseFromPoint(r3.Vector{X: 5000, Y: 5000}), r3.Vector{X: 500, Y: 500, Z: 500}, "base")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, collides(t, geometries[1], pit.Vertices[0], inner), test.ShouldBeFalse)
+
+		// a path from inside the geofence to a point outside of it is blocked by its edge, even though
+		// the path stays clear of the keep out zone
+		start := geo.NewPoint(40.0001, -73.9999)
+		origin := navigation.ZoneVertex{Lat: start.Lat(), Long: start.Lng()}
+		path := func(t *testing.T, dest *geo.Point) spatialmath.Geometry {
+			t.Helper()
+			end := spatialmath.GeoPointToPoint(dest, start)
+			pose := spatialmath.NewPose(
+				end.Mul(0.5), &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: math.Atan2(end.Y, end.X) * 180 / math.Pi})
+			box, err := spatialmath.NewBox(pose, r3.Vector{X: end.Norm(), Y: 100, Z: 100}, "path")
+			test.That(t, err, test.ShouldBeNil)
+			return box
+		}
+		leaving := path(t, geo.NewPoint(40.002, -73.9999))
+		test.That(t, collides(t, geometries[0], origin, leaving), test.ShouldBeTrue)
+		test.That(t, collides(t, geometries[1], origin, leaving), test.ShouldBeFalse)
+		test.That(t, collides(t, geometries[0], origin, path(t, geo.NewPoint(40.0009, -73.9999))), test.ShouldBeFalse)
+	})
+
+	t.Run("stores", func(t *testing.T) {
+		ctx := context.Background()
+		fileStore, err := navigation.NewFileNavigationStore("nav", map[string]interface{}{
+			"path": filepath.Join(t.TempDir(), "waypoints.json"),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		for name, store := range map[string]navigation.ZoneStore{
+			"memory": navigation.NewMemoryNavigationStore(),
+			"file":   fileStore,
+		} {
+			t.Run(name, func(t *testing.T) {
+				test.That(t, store.AddZone(ctx, fence), test.ShouldBeNil)
+				test.That(t, store.AddZone(ctx, pit), test.ShouldBeNil)
+				moved := square("pit", navigation.ZoneKindKeepOut, 40.0001, -73.9999, 0.0001)
+				test.That(t, store.AddZone(ctx, moved), test.ShouldBeNil)
+				zones, err := store.Zones(ctx)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, zones, test.ShouldResemble, []navigation.Zone{fence, moved})
+
+				test.That(t, store.RemoveZone(ctx, "yard"), test.ShouldBeNil)
+				zones, err = store.Zones(ctx)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, zones, test.ShouldResemble, []navigation.Zone{moved})
+			})
+		}
+
+		reopened, err := navigation.NewFileNavigationStore("nav", map[string]interface{}{"path": fileStore.Path()})
+		test.That(t, err, test.ShouldBeNil)
+		zones, err := reopened.Zones(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, zones, test.ShouldHaveLength, 1)
+		test.That(t, zones[0].Name, test.ShouldEqual, "pit")
+	})
+}
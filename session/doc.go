@@ -149,6 +149,24 @@ accessed last (if no one else accessed them) will be stopped. If the robot disco
 then the remote robot will have the remote session be expired and also terminate all resources that the connecting
 robot had accessed last in the same vein.
 
+# Resource Leases
+
+Sessions decide which resources are stopped when a client goes away, but not who may command a resource.
+When more than one client drives the same robot, e.g. a scheduler and a teleoperation client, a session
+can acquire an exclusive lease on a resource. While the lease lasts, requests to safety monitored methods
+of the resource from any other session, or from no session at all, fail with a ResourceLeasedError naming
+the holder of the lease. Methods that are not safety monitored, most importantly Stop, stay available to
+everyone.
+
+A lease lasts until it is released, until its optional TTL passes without a renewal, or until its session
+expires. Leases are served by the rdk.robot.v1.LeaseService gRPC service, which takes the session of a
+request from its "viam-sid" metadata like safety monitored methods do.
+
+Leases are checked by the session interceptor of the robot's gRPC server, so they only cover requests
+which name the leased resource, i.e. a client commanding the resource directly. A resource that is
+commanded in process, e.g. an arm moved by the motion service on behalf of another client, by a job,
+or by a module through the module gRPC server, is not checked against its lease.
+
 # Security Considerations
 
   - Since the loss of a session can result in stopping moves to components, which we would consider an authorized
@@ -0,0 +1,145 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/resource"
+)
+
+// A Lease gives one session exclusive use of the safety monitored methods of a resource, so that
+// two clients cannot command it at the same time. Methods that are not safety monitored, such as
+// Stop, stay available to every client. A lease ends when it is released, when it is not renewed
+// before it expires, or when its session expires. Leases only cover gRPC requests for the resource
+// itself: callers in the same process, such as services commanding the resource, are not checked.
+type Lease struct {
+	Resource  resource.Name
+	SessionID uuid.UUID
+	// Holder identifies the holder of the lease to the clients it blocks, e.g. "scheduler" or the
+	// name of an operator.
+	Holder string
+	// Expires is when the lease ends unless it is renewed. It is zero for a lease that lasts as
+	// long as its session.
+	Expires time.Time
+}
+
+// Active returns whether the lease has not expired at the given time.
+func (l Lease) Active(at time.Time) bool {
+	return l.Expires.IsZero() || l.Expires.After(at)
+}
+
+// A LeaseManager grants sessions exclusive leases on resources.
+type LeaseManager interface {
+	// AcquireLease leases a resource to a session for ttl, or for as long as the session lasts if
+	// ttl is zero. Acquiring a lease the session already holds renews it. It returns a
+	// *ResourceLeasedError if another session holds a lease on the resource.
+	AcquireLease(ctx context.Context, id uuid.UUID, resourceName resource.Name, holder string, ttl time.Duration) (Lease, error)
+	// RenewLease extends a lease held by a session by ttl from now.
+	RenewLease(ctx context.Context, id uuid.UUID, resourceName resource.Name, ttl time.Duration) (Lease, error)
+	// ReleaseLease ends a lease held by a session. Releasing a resource which is not leased has no
+	// effect.
+	ReleaseLease(ctx context.Context, id uuid.UUID, resourceName resource.Name) error
+	// Leases returns every active lease.
+	Leases() []Lease
+	// CheckLease returns a *ResourceLeasedError if the resource is leased by a session other than
+	// the one given. uuid.Nil stands for requests without a session. It is called by the session
+	// interceptor for safety monitored gRPC methods of the resource, and not for calls made in process.
+	CheckLease(id uuid.UUID, resourceName resource.Name) error
+}
+
+// resourceLeasedReason is the reason of the errdetails.ErrorInfo a ResourceLeasedError is sent as.
+const resourceLeasedReason = "RESOURCE_LEASED"
+
+// ResourceLeasedError is returned when a resource is commanded by a client other than the one
+// leasing it.
+type ResourceLeasedError struct {
+	Resource resource.Name
+	Holder   string
+	Expires  time.Time
+}
+
+// NewResourceLeasedError returns an error reporting that the lease blocks a request.
+func NewResourceLeasedError(lease Lease) *ResourceLeasedError {
+	return &ResourceLeasedError{Resource: lease.Resource, Holder: lease.Holder, Expires: lease.Expires}
+}
+
+func (e *ResourceLeasedError) Error() string {
+	msg := fmt.Sprintf("resource %q is leased by another session", e.Resource.String())
+	if e.Holder != "" {
+		msg = fmt.Sprintf("resource %q is leased by %q", e.Resource.String(), e.Holder)
+	}
+	if !e.Expires.IsZero() {
+		msg += " until " + e.Expires.Format(time.RFC3339)
+	}
+	return msg
+}
+
+// GRPCStatus returns the error as a gRPC status, which carries the details of the lease so that
+// clients can recover the error with ResourceLeasedErrorFromStatus.
+func (e *ResourceLeasedError) GRPCStatus() *status.Status {
+	metadata := map[string]string{
+		"resource": e.Resource.String(),
+		"holder":   e.Holder,
+	}
+	if !e.Expires.IsZero() {
+		metadata["expires"] = e.Expires.Format(time.RFC3339Nano)
+	}
+	st := status.New(codes.FailedPrecondition, e.Error())
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: resourceLeasedReason, Metadata: metadata})
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// ResourceLeasedErrorFromStatus returns the ResourceLeasedError carried by a gRPC error, if any.
+func ResourceLeasedErrorFromStatus(err error) (*ResourceLeasedError, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		return nil, false
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != resourceLeasedReason {
+			continue
+		}
+		resName, err := resource.NewFromString(info.GetMetadata()["resource"])
+		if err != nil {
+			return nil, false
+		}
+		leasedErr := &ResourceLeasedError{Resource: resName, Holder: info.GetMetadata()["holder"]}
+		if expires, ok := info.GetMetadata()["expires"]; ok {
+			if leasedErr.Expires, err = time.Parse(time.RFC3339Nano, expires); err != nil {
+				return nil, false
+			}
+		}
+		return leasedErr, true
+	}
+	return nil, false
+}
+
+// LeaseInfo describes a lease to clients. It leaves out the session holding the lease, which only
+// that session may know.
+type LeaseInfo struct {
+	Resource string     `json:"resource"`
+	Holder   string     `json:"holder,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	// HeldByCaller is whether the lease is held by the session of the client asking about it.
+	HeldByCaller bool `json:"held_by_caller"`
+}
+
+// Info describes the lease to the client using the given session.
+func (l Lease) Info(id uuid.UUID) LeaseInfo {
+	info := LeaseInfo{Resource: l.Resource.String(), Holder: l.Holder, HeldByCaller: id != uuid.Nil && l.SessionID == id}
+	if !l.Expires.IsZero() {
+		expires := l.Expires
+		info.Expires = &expires
+	}
+	return info
+}
@@ -0,0 +1,42 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/session"
+)
+
+func TestResourceLeasedError(t *testing.T) {
+	arm1 := resource.NewName(resource.APINamespaceRDK.WithComponentType("arm"), "arm1")
+	expires := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	leasedErr := session.NewResourceLeasedError(session.Lease{Resource: arm1, Holder: "scheduler", Expires: expires})
+	test.That(t, leasedErr.Error(), test.ShouldEqual,
+		`resource "rdk:component:arm/arm1" is leased by "scheduler" until 2024-01-02T03:04:05Z`)
+
+	// the error survives being sent as a gRPC status
+	st := status.Convert(leasedErr)
+	test.That(t, st.Code(), test.ShouldEqual, codes.FailedPrecondition)
+	received, ok := session.ResourceLeasedErrorFromStatus(st.Err())
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, received.Resource, test.ShouldResemble, arm1)
+	test.That(t, received.Holder, test.ShouldEqual, "scheduler")
+	test.That(t, received.Expires.Equal(expires), test.ShouldBeTrue)
+
+	leasedErr = session.NewResourceLeasedError(session.Lease{Resource: arm1})
+	test.That(t, leasedErr.Error(), test.ShouldEqual, `resource "rdk:component:arm/arm1" is leased by another session`)
+	received, ok = session.ResourceLeasedErrorFromStatus(status.Convert(leasedErr).Err())
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, received.Expires.IsZero(), test.ShouldBeTrue)
+
+	_, ok = session.ResourceLeasedErrorFromStatus(status.Error(codes.FailedPrecondition, leasedErr.Error()))
+	test.That(t, ok, test.ShouldBeFalse)
+	_, ok = session.ResourceLeasedErrorFromStatus(errors.New("not a status"))
+	test.That(t, ok, test.ShouldBeFalse)
+}
@@ -0,0 +1,190 @@
+package spatialmath
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+)
+
+// rayEpsilon is how close to parallel a ray and a surface can be before they are considered to
+// never meet.
+const rayEpsilon = 1e-12
+
+// RayIntersection returns the distance along the ray from origin in the direction, which must be a
+// unit vector, to the first point where it crosses the surface of the geometry, and whether it
+// does at all. A ray starting inside a geometry crosses the surface on its way out. Points have no
+// surface, so are never hit.
+func RayIntersection(g Geometry, origin, direction r3.Vector) (float64, bool) {
+	switch g := g.(type) {
+	case *sphere:
+		return raySphere(g.pose.Point(), g.radius, origin, direction)
+	case *box:
+		rm := g.center.Orientation().RotationMatrix()
+		o, d := toLocalFrame(rm, g.centerPt, origin, direction)
+		halfSize := r3.Vector{X: g.halfSize[0], Y: g.halfSize[1], Z: g.halfSize[2]}
+		tMin, tMax, ok := raySlabs(halfSize.Mul(-1), halfSize, o, d)
+		switch {
+		case !ok:
+			return 0, false
+		case tMin >= 0:
+			return tMin, true
+		default:
+			return tMax, true
+		}
+	case *capsule:
+		return rayCapsule(g, origin, direction)
+	case *Triangle:
+		return rayTriangle(g, origin, direction)
+	case *Mesh:
+		rm := g.pose.Orientation().RotationMatrix()
+		o, d := toLocalFrame(rm, g.pose.Point(), origin, direction)
+		return rayBVH(g.ensureBVH(), o, d)
+	default:
+		return 0, false
+	}
+}
+
+// toLocalFrame returns the ray in the frame with the rotation and origin.
+func toLocalFrame(rm *RotationMatrix, frameOrigin, origin, direction r3.Vector) (r3.Vector, r3.Vector) {
+	rel := origin.Sub(frameOrigin)
+	return r3.Vector{X: rm.Row(0).Dot(rel), Y: rm.Row(1).Dot(rel), Z: rm.Row(2).Dot(rel)},
+		r3.Vector{X: rm.Row(0).Dot(direction), Y: rm.Row(1).Dot(direction), Z: rm.Row(2).Dot(direction)}
+}
+
+func raySphere(center r3.Vector, radius float64, origin, direction r3.Vector) (float64, bool) {
+	rel := origin.Sub(center)
+	b := rel.Dot(direction)
+	c := rel.Norm2() - radius*radius
+	discriminant := b*b - c
+	if discriminant < 0 {
+		return 0, false
+	}
+	root := math.Sqrt(discriminant)
+	if t := -b - root; t >= 0 {
+		return t, true
+	}
+	if t := -b + root; t >= 0 {
+		return t, true
+	}
+	return 0, false
+}
+
+// raySlabs returns the distances along the ray where it enters and leaves the axis aligned box
+// between minPt and maxPt, and whether it meets the box in front of the origin at all.
+func raySlabs(minPt, maxPt, origin, direction r3.Vector) (float64, float64, bool) {
+	tMin, tMax := math.Inf(-1), math.Inf(1)
+	for axis := 0; axis < 3; axis++ {
+		o, d := vectorAxis(origin, axis), vectorAxis(direction, axis)
+		lo, hi := vectorAxis(minPt, axis), vectorAxis(maxPt, axis)
+		if math.Abs(d) < rayEpsilon {
+			if o < lo || o > hi {
+				return 0, 0, false
+			}
+			continue
+		}
+		t1, t2 := (lo-o)/d, (hi-o)/d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin, tMax = math.Max(tMin, t1), math.Min(tMax, t2)
+	}
+	return tMin, tMax, tMax >= math.Max(tMin, 0)
+}
+
+func vectorAxis(v r3.Vector, axis int) float64 {
+	switch axis {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
+
+// rayCapsule intersects the ray with the cylinder around the segment of the capsule and the
+// spheres at its ends, returning the nearest crossing.
+func rayCapsule(c *capsule, origin, direction r3.Vector) (float64, bool) {
+	best, hit := math.Inf(1), false
+	consider := func(t float64, ok bool) {
+		if ok && t < best {
+			best, hit = t, true
+		}
+	}
+	consider(raySphere(c.segA, c.radius, origin, direction))
+	consider(raySphere(c.segB, c.radius, origin, direction))
+
+	axis := c.segB.Sub(c.segA)
+	length := axis.Norm()
+	if length > 0 {
+		axis = axis.Mul(1 / length)
+		rel := origin.Sub(c.segA)
+		// Solve for where the part of the ray perpendicular to the axis is the radius away from it.
+		dPerp := direction.Sub(axis.Mul(direction.Dot(axis)))
+		relPerp := rel.Sub(axis.Mul(rel.Dot(axis)))
+		a := dPerp.Norm2()
+		b := relPerp.Dot(dPerp)
+		cc := relPerp.Norm2() - c.radius*c.radius
+		if discriminant := b*b - a*cc; a > rayEpsilon && discriminant >= 0 {
+			root := math.Sqrt(discriminant)
+			for _, t := range []float64{(-b - root) / a, (-b + root) / a} {
+				along := rel.Add(direction.Mul(t)).Dot(axis)
+				consider(t, t >= 0 && along >= 0 && along <= length)
+			}
+		}
+	}
+	return best, hit
+}
+
+// rayTriangle uses the Möller–Trumbore algorithm.
+func rayTriangle(tri *Triangle, origin, direction r3.Vector) (float64, bool) {
+	edge1 := tri.p1.Sub(tri.p0)
+	edge2 := tri.p2.Sub(tri.p0)
+	h := direction.Cross(edge2)
+	a := edge1.Dot(h)
+	if math.Abs(a) < rayEpsilon {
+		return 0, false
+	}
+	f := 1 / a
+	s := origin.Sub(tri.p0)
+	u := f * s.Dot(h)
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+	q := s.Cross(edge1)
+	v := f * direction.Dot(q)
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+	t := f * edge2.Dot(q)
+	return t, t >= 0
+}
+
+// rayBVH returns the nearest crossing of the ray with the geometries in the tree.
+func rayBVH(node *bvhNode, origin, direction r3.Vector) (float64, bool) {
+	if node == nil {
+		return 0, false
+	}
+	if _, _, ok := raySlabs(node.min, node.max, origin, direction); !ok {
+		return 0, false
+	}
+	if node.geoms != nil {
+		best, hit := math.Inf(1), false
+		for _, g := range node.geoms {
+			if t, ok := RayIntersection(g, origin, direction); ok && t < best {
+				best, hit = t, true
+			}
+		}
+		return best, hit
+	}
+	left, leftHit := rayBVH(node.left, origin, direction)
+	right, rightHit := rayBVH(node.right, origin, direction)
+	switch {
+	case leftHit && rightHit:
+		return math.Min(left, right), true
+	case leftHit:
+		return left, true
+	default:
+		return right, rightHit
+	}
+}
@@ -0,0 +1,81 @@
+package spatialmath
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+func TestRayIntersection(t *testing.T) {
+	down := r3.Vector{Z: -1}
+	origin := r3.Vector{Z: 100}
+
+	sphere, err := NewSphere(NewPoseFromPoint(r3.Vector{Z: 10}), 5, "")
+	test.That(t, err, test.ShouldBeNil)
+	dist, hit := RayIntersection(sphere, origin, down)
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 85)
+	_, hit = RayIntersection(sphere, r3.Vector{X: 6, Z: 100}, down)
+	test.That(t, hit, test.ShouldBeFalse)
+	// Rays leave a geometry they start in.
+	dist, hit = RayIntersection(sphere, r3.Vector{Z: 10}, down)
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 5)
+
+	// A box turned 45 degrees about Z is hit on an edge.
+	box, err := NewBox(NewPose(r3.Vector{X: 50}, &OrientationVectorDegrees{OZ: 1, Theta: 45}), r3.Vector{X: 10, Y: 10, Z: 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	dist, hit = RayIntersection(box, r3.Vector{}, r3.Vector{X: 1})
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 50-5*math.Sqrt2)
+	dist, hit = RayIntersection(box, origin.Add(r3.Vector{X: 50}), down)
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 95)
+	_, hit = RayIntersection(box, r3.Vector{X: 50}, r3.Vector{X: -1})
+	test.That(t, hit, test.ShouldBeTrue)
+	_, hit = RayIntersection(box, r3.Vector{X: 50, Y: 8}, r3.Vector{Z: 1})
+	test.That(t, hit, test.ShouldBeFalse)
+
+	// A long box turned 30 degrees is hit along its length, not its mirror image.
+	box, err = NewBox(NewPose(r3.Vector{}, &OrientationVectorDegrees{OZ: 1, Theta: 30}), r3.Vector{X: 20, Y: 2, Z: 2}, "")
+	test.That(t, err, test.ShouldBeNil)
+	along := r3.Vector{X: 8 * math.Cos(math.Pi/6), Y: 8 * math.Sin(math.Pi/6)}
+	_, hit = RayIntersection(box, along.Add(origin), down)
+	test.That(t, hit, test.ShouldBeTrue)
+	_, hit = RayIntersection(box, r3.Vector{X: along.X, Y: -along.Y}.Add(origin), down)
+	test.That(t, hit, test.ShouldBeFalse)
+
+	// A capsule lying along X is hit on its side and its ends.
+	capsule, err := NewCapsule(NewPose(r3.Vector{}, &OrientationVector{OX: 1}), 5, 30, "")
+	test.That(t, err, test.ShouldBeNil)
+	dist, hit = RayIntersection(capsule, r3.Vector{X: 5, Z: 100}, down)
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 95)
+	dist, hit = RayIntersection(capsule, r3.Vector{X: 100}, r3.Vector{X: -1})
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 85)
+	dist, hit = RayIntersection(capsule, r3.Vector{X: 13, Z: 100}, down)
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 100-math.Sqrt(25-9))
+	_, hit = RayIntersection(capsule, r3.Vector{X: 16, Z: 100}, down)
+	test.That(t, hit, test.ShouldBeFalse)
+
+	// A mesh is hit on the nearest of its triangles, wherever the mesh is.
+	mesh := NewMesh(NewPoseFromPoint(r3.Vector{Z: 20}), []*Triangle{
+		NewTriangle(r3.Vector{X: -10, Y: -10}, r3.Vector{X: 10, Y: -10}, r3.Vector{Y: 10}),
+		NewTriangle(r3.Vector{X: -10, Y: -10, Z: 5}, r3.Vector{X: 10, Y: -10, Z: 5}, r3.Vector{Y: 10, Z: 5}),
+	}, "")
+	dist, hit = RayIntersection(mesh, origin, down)
+	test.That(t, hit, test.ShouldBeTrue)
+	test.That(t, dist, test.ShouldAlmostEqual, 75)
+	_, hit = RayIntersection(mesh, r3.Vector{X: 9, Y: 9, Z: 100}, down)
+	test.That(t, hit, test.ShouldBeFalse)
+	// the ray points away from the mesh.
+	_, hit = RayIntersection(mesh, origin, r3.Vector{Z: 1})
+	test.That(t, hit, test.ShouldBeFalse)
+
+	_, hit = RayIntersection(NewPoint(r3.Vector{}, ""), origin, down)
+	test.That(t, hit, test.ShouldBeFalse)
+}
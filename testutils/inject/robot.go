@@ -52,13 +52,16 @@ type Robot struct {
 		dst string,
 		additionalTransforms []*referenceframe.LinkInFrame,
 	) (*referenceframe.PoseInFrame, error)
-	TransformPointCloudFunc func(ctx context.Context, srcpc pointcloud.PointCloud, srcName, dstName string) (pointcloud.PointCloud, error)
-	CurrentInputsFunc       func(ctx context.Context) (referenceframe.FrameSystemInputs, error)
-	ModuleAddressesFunc     func() (config.ParentSockAddrs, error)
-	CloudMetadataFunc       func(ctx context.Context) (cloud.Metadata, error)
-	MachineStatusFunc       func(ctx context.Context) (robot.MachineStatus, error)
-	ShutdownFunc            func(ctx context.Context) error
-	ListTunnelsFunc         func(ctx context.Context) ([]config.TrafficTunnelEndpoint, error)
+	TransformPointCloudFunc  func(ctx context.Context, srcpc pointcloud.PointCloud, srcName, dstName string) (pointcloud.PointCloud, error)
+	CurrentInputsFunc        func(ctx context.Context) (referenceframe.FrameSystemInputs, error)
+	ModuleAddressesFunc      func() (config.ParentSockAddrs, error)
+	CloudMetadataFunc        func(ctx context.Context) (cloud.Metadata, error)
+	MachineStatusFunc        func(ctx context.Context) (robot.MachineStatus, error)
+	ShutdownFunc             func(ctx context.Context) error
+	ListTunnelsFunc          func(ctx context.Context) ([]config.TrafficTunnelEndpoint, error)
+	InspectResourceGraphFunc func() resource.GraphInspection
+	ResourceFailureChainFunc func(name resource.Name) (resource.FailureChain, error)
+	QueryLogsFunc            func(query logging.LogQuery) ([]logging.StoredLogEntry, error)
 
 	ops        *operation.Manager
 	SessMgr    session.Manager
@@ -217,6 +220,36 @@ func (r *Robot) Config() *config.Config {
 	return r.ConfigFunc()
 }
 
+// InspectResourceGraph calls the injected InspectResourceGraph or the real version.
+func (r *Robot) InspectResourceGraph() resource.GraphInspection {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	if r.InspectResourceGraphFunc == nil {
+		return r.LocalRobot.InspectResourceGraph()
+	}
+	return r.InspectResourceGraphFunc()
+}
+
+// ResourceFailureChain calls the injected ResourceFailureChain or the real version.
+func (r *Robot) ResourceFailureChain(name resource.Name) (resource.FailureChain, error) {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	if r.ResourceFailureChainFunc == nil {
+		return r.LocalRobot.ResourceFailureChain(name)
+	}
+	return r.ResourceFailureChainFunc(name)
+}
+
+// QueryLogs calls the injected QueryLogs or the real version.
+func (r *Robot) QueryLogs(query logging.LogQuery) ([]logging.StoredLogEntry, error) {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	if r.QueryLogsFunc == nil {
+		return r.LocalRobot.QueryLogs(query)
+	}
+	return r.QueryLogsFunc(query)
+}
+
 // Logger calls the injected Logger or the real version.
 func (r *Robot) Logger() logging.Logger {
 	r.Mu.RLock()
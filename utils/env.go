@@ -23,6 +23,20 @@ const (
 	// that resources are allowed to (re)configure.
 	ResourceConfigurationTimeoutEnvVar = "VIAM_RESOURCE_CONFIGURATION_TIMEOUT"
 
+	// DefaultResourceConfigurationConcurrency is the default number of resources
+	// that are (re)configured at the same time.
+	DefaultResourceConfigurationConcurrency = 10
+
+	// ResourceConfigurationConcurrencyEnvVar is the environment variable that can
+	// be set to override DefaultResourceConfigurationConcurrency as the number of
+	// independent resources that are allowed to (re)configure at the same time.
+	ResourceConfigurationConcurrencyEnvVar = "VIAM_RESOURCE_CONFIGURATION_CONCURRENCY"
+
+	// OperationHistoryFileEnvVar is the environment variable that can be set to
+	// the path of a file the robot persists the history of its completed
+	// operations to.
+	OperationHistoryFileEnvVar = "VIAM_OPERATION_HISTORY_FILE"
+
 	// DefaultModuleStartupTimeout is the default module startup timeout.
 	DefaultModuleStartupTimeout = 5 * time.Minute
 
@@ -98,6 +112,10 @@ const (
 	// ViamTCPSocketsEnvVar if set to a true-like value, indicates that TCP sockets should be used
 	// in lieu of Unix sockets.
 	ViamTCPSocketsEnvVar = "VIAM_TCP_SOCKETS"
+
+	// ViamFTDCOpenMetricsAddrEnvVar is the environment variable that, when set to a listen address
+	// (e.g: `localhost:9090`), serves the latest FTDC metrics in the OpenMetrics format at `/metrics`.
+	ViamFTDCOpenMetricsAddrEnvVar = "VIAM_FTDC_OPENMETRICS_ADDR"
 )
 
 // EnvTrueValues contains strings that we interpret as boolean true in env vars.
@@ -119,6 +137,23 @@ func GetResourceConfigurationTimeout(logger logging.Logger) time.Duration {
 	return timeout
 }
 
+// GetResourceConfigurationConcurrency returns the number of resources that may be
+// (re)configured at the same time (env variable value if set,
+// DefaultResourceConfigurationConcurrency otherwise).
+func GetResourceConfigurationConcurrency(logger logging.Logger) int {
+	concurrencyVal := os.Getenv(ResourceConfigurationConcurrencyEnvVar)
+	if concurrencyVal == "" {
+		return DefaultResourceConfigurationConcurrency
+	}
+	concurrency, err := strconv.Atoi(concurrencyVal)
+	if err != nil || concurrency < 1 {
+		logger.Warnf("Failed to parse %s env var as a positive integer, falling back to default concurrency of %d",
+			ResourceConfigurationConcurrencyEnvVar, DefaultResourceConfigurationConcurrency)
+		return DefaultResourceConfigurationConcurrency
+	}
+	return concurrency
+}
+
 // GetModuleStartupTimeout calculates the module startup timeout
 // (env variable value if set, DefaultModuleStartupTimeout otherwise).
 func GetModuleStartupTimeout(logger logging.Logger) time.Duration {
@@ -51,6 +51,7 @@ type Arguments struct {
 	DumpResourcesPath          string `flag:"dump-resources,usage=dump all resource registrations as json to the provided file path"`
 	EnableFTDC                 bool   `flag:"ftdc,default=true,usage=enable fulltime data capture for diagnostics"`
 	OutputLogFile              string `flag:"log-file,usage=write logs to a file with log rotation"`
+	StructuredLogFile          string `flag:"structured-log-file,usage=also store logs as JSON lines in a rotated file that can be queried while offline"`
 	NoTLS                      bool   `flag:"no-tls,usage=starts an insecure http server without TLS certificates even if one exists"`
 	NetworkCheckOnly           bool   `flag:"network-check,usage=only runs normal network checks, logs results, and exits"`
 }
@@ -138,6 +139,17 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 		registry.AddAppenderToAll(logging.NewStdoutAppender())
 	}
 
+	if argsParsed.StructuredLogFile != "" {
+		logStore := logging.NewStructuredFileAppender(logging.StructuredFileAppenderConfig{
+			Filename:         argsParsed.StructuredLogFile,
+			RotationInterval: 24 * time.Hour,
+		})
+		defer func() {
+			utils.UncheckedError(logStore.Close())
+		}()
+		registry.AddAppenderToAll(logStore)
+	}
+
 	logging.RegisterEventLogger(rootLogger, "viam-server")
 	config.InitLoggingSettings(rootLogger, configLogger, argsParsed.Debug)
 
@@ -601,7 +613,11 @@ func (s *robotServer) serveWeb(ctx context.Context, cfg *config.Config) (err err
 	robotOptions = append(robotOptions, shutdownCallbackOpt)
 
 	if s.args.EnableFTDC {
-		robotOptions = append(robotOptions, robotimpl.WithFTDC())
+		robotOptions = append(robotOptions, robotimpl.WithFTDC(), robotimpl.WithLoggerRegistry(s.registry))
+	}
+
+	if s.args.StructuredLogFile != "" {
+		robotOptions = append(robotOptions, robotimpl.WithStructuredLogFile(s.args.StructuredLogFile))
 	}
 
 	// Create `minimalProcessedConfig`, a copy of `fullProcessedConfig`. Remove